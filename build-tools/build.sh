@@ -31,6 +31,9 @@ cleanup_and_exit() {
 tar_files_and_cleanup() {
   # Find the files to add to the tar archive
   tarFiles=$(find . \( -not -name 'devfile.yaml' \
+    -a -not -name 'devfile.yml' \
+    -a -not -name '.devfile.yaml' \
+    -a -not -name '.devfile.yml' \
     -a -not -name "meta.yaml" \
     -a -not -name "*.vsx" \
     -a -not -name "." \
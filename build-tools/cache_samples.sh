@@ -89,16 +89,29 @@ function cache_devfile() {
     local srcDir="$1"
     local outputDir="$2"
     local sampleName="$3"
-    # Cache the devfile for the sample
-    if [[ -f "$srcDir/devfile.yaml" ]]; then
-      cp $srcDir/devfile.yaml $outputDir/
-    elif [[ -f "$srcDir/.devfile/devfile.yaml" ]]; then
-      cp $srcDir/.devfile/devfile.yaml $outputDir/
-    else
+    # Cache the devfile for the sample, the devfile names are the same as for the index generator
+    local devfiles=()
+    for devfileDir in "$srcDir" "$srcDir/.devfile"; do
+      for devfileName in devfile.yaml .devfile.yaml devfile.yml .devfile.yml; do
+        if [[ -f "$devfileDir/$devfileName" ]]; then
+          devfiles+=("$devfileDir/$devfileName")
+        fi
+      done
+    done
+    if [[ ${#devfiles[@]} -eq 0 ]]; then
       echo "A devfile for sample $sampleName, version $(basename $srcDir) could not be found."
-      echo "Please ensure a devfile exists in the root of the repository or under .devfile/"
+      echo "Please ensure a devfile (devfile.yaml, .devfile.yaml, devfile.yml or .devfile.yml) exists in the root of the repository or under .devfile/"
+      exit 1
+    fi
+    if [[ ${#devfiles[@]} -gt 1 ]]; then
+      echo "Sample $sampleName, version $(basename $srcDir) contains more than one devfile:"
+      for devfilePath in "${devfiles[@]}"; do
+        echo "  ${devfilePath#$srcDir/}"
+      done
+      echo "Please keep only one devfile (devfile.yaml, .devfile.yaml, devfile.yml or .devfile.yml) in the root of the repository or under .devfile/"
       exit 1
     fi
+    cp "${devfiles[0]}" $outputDir/
 }
 
 if [[ "$OSTYPE" == "darwin"* ]]
@@ -0,0 +1,98 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/devfile/registry-support/index/generator/library"
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+const (
+	impactShortDesc = "List the stacks impacted by a stack change"
+	impactLongDesc  = "List the stack versions which inherit from a stack version, directly or through other parents, " +
+		"from either a registry directory or a generated index file. The version defaults to the default version of the stack."
+)
+
+// impactCmd represents the impact command
+var impactCmd = &cobra.Command{
+	Use:          "impact <registry directory path | index file path> <stack>[:<version>]",
+	Short:        impactShortDesc,
+	Long:         impactLongDesc,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index, err := loadIndex(args[0])
+		if err != nil {
+			return err
+		}
+
+		stack, version, found := strings.Cut(args[1], ":")
+		if !found || version == "" {
+			version = "default"
+		}
+
+		graph, errs := library.BuildDependencyGraph(index)
+		if len(errs) > 0 && !force {
+			return fmt.Errorf("stack dependency graph is not valid: %v", errs)
+		}
+		dependents, err := graph.Dependents(stack, version)
+		if err != nil {
+			return err
+		}
+		for _, dependent := range dependents {
+			fmt.Println(dependent)
+		}
+		return nil
+	},
+}
+
+// loadIndex reads the index from an index file or generates it from a registry directory
+func loadIndex(path string) ([]schema.Schema, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if info.IsDir() {
+		index, err := library.GenerateIndexStruct(path, force)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate index struct: %v", err)
+		}
+		return index, nil
+	}
+
+	/* #nosec G304 -- path is provided by the user running the command */
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	var index []schema.Schema
+	err = json.Unmarshal(bytes, &index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s data: %v", path, err)
+	}
+	return index, nil
+}
+
+func init() {
+	rootCmd.AddCommand(impactCmd)
+}
@@ -33,6 +33,9 @@ const (
 
 var cfgFile string
 var force bool
+var signKey string
+var lint bool
+var lintTags []string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -45,7 +48,15 @@ var rootCmd = &cobra.Command{
 		registryDirPath := args[0]
 		indexFilePath := args[1]
 
-		index, err := library.GenerateIndexStruct(registryDirPath, force)
+		opts := []library.Option{library.WithForce(force)}
+		if lint {
+			linter := library.NewLinter(library.DefaultLintRules()...)
+			if len(lintTags) > 0 {
+				linter.Register(library.AllowedTagsRule(lintTags))
+			}
+			opts = append(opts, library.WithLinter(linter))
+		}
+		index, err := library.NewGenerator(opts...).Generate(registryDirPath)
 		if err != nil {
 			return fmt.Errorf("failed to generate index struct: %v", err)
 		}
@@ -54,6 +65,13 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to create index file: %v", err)
 		}
+
+		if signKey != "" {
+			err = library.SignIndexFile(index, registryDirPath, indexFilePath, signKey)
+			if err != nil {
+				return fmt.Errorf("failed to sign index file: %v", err)
+			}
+		}
 		return nil
 	},
 }
@@ -79,6 +97,10 @@ func init() {
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.Flags().StringVar(&signKey, "sign-key", "", "PEM encoded ed25519 or cosign private key to sign the index file with, "+
+		"the signature is written to <index file path>.sig. cosign key passwords are read from COSIGN_PASSWORD")
+	rootCmd.Flags().BoolVar(&lint, "lint", false, "lint the stack devfiles with the built-in rules, lint errors fail the index generation")
+	rootCmd.Flags().StringSliceVar(&lintTags, "lint-tags", nil, "allowed vocabulary of stack tags, only checked with --lint")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -0,0 +1,320 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+
+	devfileParser "github.com/devfile/library/v2/pkg/devfile"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"github.com/devfile/registry-support/index/generator/schema"
+	"golang.org/x/sync/errgroup"
+)
+
+// Stage is a stage of the index generation reported to the progress callback
+type Stage string
+
+const (
+	// StacksStage is the stage parsing the stacks of the registry
+	StacksStage Stage = "stacks"
+	// ExtraDevfileEntriesStage is the stage parsing the entries of extraDevfileEntries.yaml
+	ExtraDevfileEntriesStage Stage = "extraDevfileEntries"
+)
+
+// Progress is reported to the progress callback each time a stack or sample has been processed
+type Progress struct {
+	Stage Stage
+	// Name is the name of the stack or sample that has been processed
+	Name string
+	// Done is the number of stacks or samples processed so far within the stage, out of Total
+	Done  int
+	Total int
+}
+
+// Logger prints the informational messages of the generator, *log.Logger satisfies this interface
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// IconResolver reports whether the icon of a stack or sample resolves to an image
+type IconResolver func(icon string) bool
+
+// DevfileFetcher returns the content of the devfile at the given path
+type DevfileFetcher func(devfilePath string) ([]byte, error)
+
+// ComponentHook post-processes each index component before it is added to the index,
+// returning an error stops the generation
+type ComponentHook func(indexComponent *schema.Schema) error
+
+// ProgressFunc is called with the progress of the index generation
+type ProgressFunc func(progress Progress)
+
+// Generator generates the index of a devfile registry
+type Generator struct {
+	devfileValidation    bool
+	stackInfoValidation  bool
+	componentValidation  bool
+	dependencyValidation bool
+	iconResolver         IconResolver
+	fs                   filesystem.Filesystem
+	logger               Logger
+	concurrency          int
+	componentHook        ComponentHook
+	devfileFetcher       DevfileFetcher
+	progress             ProgressFunc
+	linter               *Linter
+}
+
+// Option configures a Generator
+type Option func(*Generator)
+
+// stdoutLogger prints the generator messages to the standard output
+type stdoutLogger struct{}
+
+func (stdoutLogger) Printf(format string, v ...any) {
+	fmt.Printf(format, v...)
+}
+
+// NewGenerator creates a Generator, by default every validation is enabled, the local filesystem is used
+// and stacks are parsed one at a time
+func NewGenerator(opts ...Option) *Generator {
+	g := &Generator{
+		devfileValidation:    true,
+		stackInfoValidation:  true,
+		componentValidation:  true,
+		dependencyValidation: true,
+		iconResolver:         iconExists,
+		fs:                   filesystem.DefaultFs{},
+		logger:               stdoutLogger{},
+		concurrency:          1,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// WithForce disables every validation if force is set, validation errors are ignored
+func WithForce(force bool) Option {
+	return func(g *Generator) {
+		g.devfileValidation = !force
+		g.stackInfoValidation = !force
+		g.componentValidation = !force
+		g.dependencyValidation = !force
+	}
+}
+
+// WithDevfileValidation toggles the schema and required metadata validation of stack and sample devfiles
+func WithDevfileValidation(enabled bool) Option {
+	return func(g *Generator) {
+		g.devfileValidation = enabled
+	}
+}
+
+// WithStackInfoValidation toggles the validation of stack.yaml files
+func WithStackInfoValidation(enabled bool) Option {
+	return func(g *Generator) {
+		g.stackInfoValidation = enabled
+	}
+}
+
+// WithComponentValidation toggles the validation of the generated index components
+func WithComponentValidation(enabled bool) Option {
+	return func(g *Generator) {
+		g.componentValidation = enabled
+	}
+}
+
+// WithDependencyValidation toggles whether dangling parents and parent cycles fail the generation
+func WithDependencyValidation(enabled bool) Option {
+	return func(g *Generator) {
+		g.dependencyValidation = enabled
+	}
+}
+
+// WithIconResolver sets how icons are checked during component validation, by default icon URLs are fetched
+func WithIconResolver(iconResolver IconResolver) Option {
+	return func(g *Generator) {
+		g.iconResolver = iconResolver
+	}
+}
+
+// WithFilesystem sets the filesystem the registry is read from
+func WithFilesystem(fs filesystem.Filesystem) Option {
+	return func(g *Generator) {
+		g.fs = fs
+	}
+}
+
+// WithLogger sets the logger of the informational messages, by default they are printed to the standard output
+func WithLogger(logger Logger) Option {
+	return func(g *Generator) {
+		g.logger = logger
+	}
+}
+
+// WithConcurrency sets the number of stacks parsed in parallel, values below 1 are ignored
+func WithConcurrency(concurrency int) Option {
+	return func(g *Generator) {
+		if concurrency > 0 {
+			g.concurrency = concurrency
+		}
+	}
+}
+
+// WithComponentHook sets the hook called on each index component before it is added to the index
+func WithComponentHook(hook ComponentHook) Option {
+	return func(g *Generator) {
+		g.componentHook = hook
+	}
+}
+
+// WithDevfileFetcher sets how devfile contents are fetched, by default they are read from the filesystem
+func WithDevfileFetcher(fetcher DevfileFetcher) Option {
+	return func(g *Generator) {
+		g.devfileFetcher = fetcher
+	}
+}
+
+// WithProgress sets the callback reporting the progress of the generation
+func WithProgress(progress ProgressFunc) Option {
+	return func(g *Generator) {
+		g.progress = progress
+	}
+}
+
+// WithLinter sets the linter run against each stack version devfile, stacks are not linted by default
+func WithLinter(linter *Linter) Option {
+	return func(g *Generator) {
+		g.linter = linter
+	}
+}
+
+// Generate parses the registry then generates the index struct according to the schema
+func (g *Generator) Generate(registryDirPath string) ([]schema.Schema, error) {
+	// Parse devfile registry then populate index struct
+	index, err := g.parseDevfileRegistry(registryDirPath)
+	if err != nil {
+		return index, err
+	}
+
+	// Parse extraDevfileEntries.yaml then populate the index struct (optional)
+	extraDevfileEntriesPath := filepath.Join(registryDirPath, extraDevfileEntries)
+	if g.fileExists(extraDevfileEntriesPath) {
+		indexFromExtraDevfileEntries, err := g.parseExtraDevfileEntries(registryDirPath)
+		if err != nil {
+			return index, err
+		}
+		index = append(index, indexFromExtraDevfileEntries...)
+	}
+
+	// Resolve the parent references between stacks
+	index, err = g.resolveDependencies(index)
+	if err != nil {
+		return index, err
+	}
+
+	index, err = g.setLastModifiedValue(index, registryDirPath)
+	if err != nil {
+		return index, err
+	}
+	return index, nil
+}
+
+// GenerateStack parses the stack folder stackName under stackDirPath into an index component, the stack is
+// validated the same way as when generating the index of the whole registry
+func (g *Generator) GenerateStack(stackDirPath string, stackName string) (schema.Schema, error) {
+	return g.parseStack(stackDirPath, stackName)
+}
+
+// fetchDevfile returns the content of a devfile through the devfile fetcher, or from the filesystem
+func (g *Generator) fetchDevfile(devfilePath string) ([]byte, error) {
+	if g.devfileFetcher != nil {
+		return g.devfileFetcher(devfilePath)
+	}
+	return g.fs.ReadFile(devfilePath)
+}
+
+// parseAndValidateDevfile parses and validates a devfile. The devfile is parsed from its path when read from the
+// local filesystem so that relative references are resolved, otherwise from the content returned by fetchDevfile
+func (g *Generator) parseAndValidateDevfile(devfilePath string, flattenedDevfile bool) (parser.DevfileObj, error) {
+	convertUri := false
+	args := parser.ParserArgs{
+		ConvertKubernetesContentInUri: &convertUri,
+		FlattenedDevfile:              &flattenedDevfile,
+	}
+	if _, isDefaultFs := g.fs.(filesystem.DefaultFs); isDefaultFs && g.devfileFetcher == nil {
+		args.Path = devfilePath
+	} else {
+		data, err := g.fetchDevfile(devfilePath)
+		if err != nil {
+			return parser.DevfileObj{}, fmt.Errorf("failed to read %s: %v", devfilePath, err)
+		}
+		args.Data = data
+	}
+	devfileObj, _, err := devfileParser.ParseDevfileAndValidate(args)
+	return devfileObj, err
+}
+
+// fileExists checks if the file exists in the filesystem of the generator
+func (g *Generator) fileExists(filepath string) bool {
+	if _, err := g.fs.Stat(filepath); err != nil {
+		return false
+	}
+	return true
+}
+
+// reportProgress calls the progress callback if set
+func (g *Generator) reportProgress(stage Stage, name string, done int, total int) {
+	if g.progress != nil {
+		g.progress(Progress{Stage: stage, Name: name, Done: done, Total: total})
+	}
+}
+
+// runHook calls the component hook if set
+func (g *Generator) runHook(indexComponent *schema.Schema) error {
+	if g.componentHook == nil {
+		return nil
+	}
+	if err := g.componentHook(indexComponent); err != nil {
+		return fmt.Errorf("%s index component hook failed: %v", indexComponent.Name, err)
+	}
+	return nil
+}
+
+// forEach runs fn for each of the n items with at most the configured concurrency, returns the error
+// of the first failing item in order
+func (g *Generator) forEach(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	group := errgroup.Group{}
+	group.SetLimit(g.concurrency)
+	for i := 0; i < n; i++ {
+		i := i
+		group.Go(func() error {
+			errs[i] = fn(i)
+			return nil
+		})
+	}
+	_ = group.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+const generatorTestDevfile = `schemaVersion: 2.2.0
+metadata:
+  name: %s
+  version: %s
+  displayName: %s
+  language: %s
+  projectType: %s
+  provider: Red Hat
+  supportUrl: https://github.com/devfile/api/issues
+  architectures:
+    - amd64
+`
+
+type bufferLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *bufferLogger) Printf(format string, v ...any) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+// fakeRegistry writes a registry with a single version stack, a multi version stack and a sample to an in-memory filesystem
+func fakeRegistry(t *testing.T) filesystem.Filesystem {
+	fs := filesystem.NewFakeFs()
+	files := map[string]string{
+		"stacks/go/devfile.yaml":               fmt.Sprintf(generatorTestDevfile, "go", "1.0.2", "Go Runtime", "Go", "Go"),
+		"stacks/java-maven/stack.yaml":         "name: java-maven\ndisplayName: Maven Java\nicon: https://example.com/java.svg\nversions:\n  - version: 1.1.0\n    default: true\n  - version: 1.2.0\n",
+		"stacks/java-maven/1.1.0/devfile.yaml": fmt.Sprintf(generatorTestDevfile, "java-maven", "1.1.0", "Maven Java", "Java", "Maven"),
+		"stacks/java-maven/1.2.0/devfile.yaml": fmt.Sprintf(generatorTestDevfile, "java-maven", "1.2.0", "Maven Java", "Java", "Maven"),
+		"stacks/java-maven/1.2.0/archive.tar":  "",
+		"extraDevfileEntries.yaml":             "samples:\n  - name: nodejs-basic\n    displayName: Basic Node.js\n    icon: https://example.com/nodejs.svg\n    provider: Red Hat\n    supportUrl: https://github.com/devfile/api/issues\n    architectures:\n      - amd64\n    git:\n      remotes:\n        origin: https://github.com/devfile-samples/nodejs-basic.git\n",
+		"last_modified.json":                   `{"stacks":[],"samples":[]}`,
+	}
+	for path, content := range files {
+		if err := fs.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fs
+}
+
+func TestNewGenerator(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want Generator
+	}{
+		{
+			name: "Case 1: default generator",
+			want: Generator{devfileValidation: true, stackInfoValidation: true, componentValidation: true, dependencyValidation: true, concurrency: 1},
+		},
+		{
+			name: "Case 2: force disables every validation",
+			opts: []Option{WithForce(true), WithConcurrency(4)},
+			want: Generator{concurrency: 4},
+		},
+		{
+			name: "Case 3: toggle validations",
+			opts: []Option{WithForce(true), WithComponentValidation(true), WithDevfileValidation(true), WithConcurrency(0)},
+			want: Generator{devfileValidation: true, componentValidation: true, concurrency: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGenerator(tt.opts...)
+			got := Generator{
+				devfileValidation:    g.devfileValidation,
+				stackInfoValidation:  g.stackInfoValidation,
+				componentValidation:  g.componentValidation,
+				dependencyValidation: g.dependencyValidation,
+				concurrency:          g.concurrency,
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got: %+v, Expected: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratorGenerate(t *testing.T) {
+	tests := []struct {
+		name      string
+		hook      ComponentHook
+		linter    *Linter
+		icon      bool
+		force     bool
+		goDevfile string
+		wantNames []string
+		wantLog   string
+		wantErr   string
+	}{
+		{
+			name:      "Case 1: generate index from in-memory registry",
+			icon:      true,
+			wantNames: []string{"go", "java-maven", "nodejs-basic"},
+		},
+		{
+			name:    "Case 2: broken icons fail the component validation",
+			icon:    false,
+			wantErr: "has broken or not existing icon",
+		},
+		{
+			name: "Case 3: component hook error stops the generation",
+			icon: true,
+			hook: func(indexComponent *schema.Schema) error {
+				if indexComponent.Name == "java-maven" {
+					return fmt.Errorf("rejected")
+				}
+				return nil
+			},
+			wantErr: "java-maven index component hook failed: rejected",
+		},
+		{
+			name: "Case 4: lint errors stop the generation",
+			icon: true,
+			linter: NewLinter(NewLintRule("no-maven", ErrorSeverity, func(target LintTarget) []string {
+				if target.Devfile.Data.GetMetadata().ProjectType == "Maven" {
+					return []string{"maven stacks are not allowed"}
+				}
+				return nil
+			})),
+			wantErr: "[error] no-maven: java-maven:1.2.0: maven stacks are not allowed",
+		},
+		{
+			name:      "Case 5: devfiles which cannot be parsed are not linted with force",
+			icon:      true,
+			force:     true,
+			goDevfile: "schemaVersion: 2.2.0\nmetadata:\n  name: go\n  version: 1.0.2\ncomponents: invalid\n",
+			linter: NewLinter(NewLintRule("no-go", ErrorSeverity, func(target LintTarget) []string {
+				if target.Stack == "go" {
+					return []string{"go stacks are not allowed"}
+				}
+				return nil
+			})),
+			wantNames: []string{"go", "java-maven", "nodejs-basic"},
+			wantLog:   "devfile is not valid, skipping lint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var progressMutex sync.Mutex
+			var progress []Progress
+			logger := &bufferLogger{}
+			hook := tt.hook
+			if hook == nil {
+				hook = func(indexComponent *schema.Schema) error {
+					indexComponent.Tags = append(indexComponent.Tags, "hooked")
+					return nil
+				}
+			}
+
+			fs := fakeRegistry(t)
+			if tt.goDevfile != "" {
+				if err := fs.WriteFile("stacks/go/devfile.yaml", []byte(tt.goDevfile), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			g := NewGenerator(
+				WithFilesystem(fs),
+				WithIconResolver(func(string) bool { return tt.icon }),
+				WithLogger(logger),
+				WithConcurrency(2),
+				WithComponentHook(hook),
+				WithLinter(tt.linter),
+				WithForce(tt.force),
+				WithProgress(func(p Progress) {
+					progressMutex.Lock()
+					defer progressMutex.Unlock()
+					progress = append(progress, p)
+				}),
+			)
+			index, err := g.Generate("")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected err: %v", err)
+			}
+
+			var names []string
+			for _, indexComponent := range index {
+				names = append(names, indexComponent.Name)
+				if !inArray(indexComponent.Tags, "hooked") {
+					t.Errorf("%s was not post-processed by the hook", indexComponent.Name)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("Got: %v, Expected: %v", names, tt.wantNames)
+			}
+			wantResources := []string{"archive.tar", "devfile.yaml"}
+			gotResources := append([]string{}, index[1].Versions[0].Resources...)
+			sort.Strings(gotResources)
+			if index[1].Versions[0].Version != "1.2.0" || !reflect.DeepEqual(gotResources, wantResources) {
+				t.Errorf("Got version %s resources: %v, Expected version 1.2.0 resources: %v", index[1].Versions[0].Version, gotResources, wantResources)
+			}
+			if tt.wantLog != "" && !strings.Contains(strings.Join(logger.messages, "\n"), tt.wantLog) {
+				t.Errorf("Expected log containing %q, got: %v", tt.wantLog, logger.messages)
+			}
+			if len(progress) != 3 || progress[len(progress)-1] != (Progress{Stage: ExtraDevfileEntriesStage, Name: "nodejs-basic", Done: 1, Total: 1}) {
+				t.Errorf("Got progress: %+v", progress)
+			}
+		})
+	}
+}
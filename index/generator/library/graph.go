@@ -0,0 +1,297 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"github.com/devfile/registry-support/index/generator/schema"
+	versionpkg "github.com/hashicorp/go-version"
+)
+
+// DanglingParentError is an error if a parent reference points to a stack or version that is not in the registry
+type DanglingParentError struct {
+	devfile string
+	parent  string
+}
+
+func (e *DanglingParentError) Error() string {
+	return fmt.Sprintf("the %s devfile references parent %s which does not exist in the registry\n", e.devfile, e.parent)
+}
+
+// ParentCycleError is an error if parent references between stack versions form a cycle
+type ParentCycleError struct {
+	cycle []string
+}
+
+func (e *ParentCycleError) Error() string {
+	return fmt.Sprintf("parent references form a cycle: %s\n", strings.Join(e.cycle, " -> "))
+}
+
+// DependencyGraph is the graph of parent references between the stack versions of a registry.
+// Each node is a stack version named <name>:<version>.
+type DependencyGraph struct {
+	// parents maps a node to the node of its parent, only parents within the registry are tracked
+	parents map[string]string
+	// children maps a node to the nodes which directly reference it as their parent
+	children map[string][]string
+	// versions maps a stack name to its version map, includes the "default" and "latest" aliases
+	versions map[string]map[string]string
+}
+
+// NodeName returns the graph node name of a stack version
+func NodeName(stack string, version string) string {
+	return fmt.Sprintf("%s:%s", stack, version)
+}
+
+// BuildDependencyGraph builds the dependency graph of the stacks in the given index from the
+// parent references of each version. Returns the graph along with the dangling parent errors found.
+func BuildDependencyGraph(index []schema.Schema) (*DependencyGraph, []error) {
+	var errs []error
+	graph := &DependencyGraph{
+		parents:  make(map[string]string),
+		children: make(map[string][]string),
+		versions: make(map[string]map[string]string),
+	}
+
+	for _, indexComponent := range index {
+		if indexComponent.Type != schema.StackDevfileType {
+			continue
+		}
+		graph.versions[indexComponent.Name] = stackVersionMap(indexComponent)
+	}
+
+	for _, indexComponent := range index {
+		if indexComponent.Type != schema.StackDevfileType {
+			continue
+		}
+		for _, version := range indexComponent.Versions {
+			if version.Parent == nil {
+				continue
+			}
+			node := NodeName(indexComponent.Name, version.Version)
+			parentNode, local, err := graph.resolveParent(node, version.Parent)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !local {
+				continue
+			}
+			graph.parents[node] = parentNode
+			graph.children[parentNode] = append(graph.children[parentNode], node)
+		}
+	}
+
+	return graph, errs
+}
+
+// Parent returns the parent node of the given stack version, empty if it has no parent within the registry
+func (g *DependencyGraph) Parent(stack string, version string) string {
+	return g.parents[NodeName(stack, version)]
+}
+
+// Dependents returns every stack version which inherits from the given stack version, directly or through
+// other parents, i.e. the stack versions that need rebuilding if the given one changes.
+// The version can be a specific version or the "default" and "latest" aliases.
+func (g *DependencyGraph) Dependents(stack string, version string) ([]string, error) {
+	stackVersions, found := g.versions[stack]
+	if !found {
+		return nil, fmt.Errorf("stack %s does not exist in the registry", stack)
+	}
+	resolved, found := stackVersions[version]
+	if !found {
+		return nil, fmt.Errorf("version %s of stack %s does not exist in the registry", version, stack)
+	}
+	return g.dependents(NodeName(stack, resolved)), nil
+}
+
+// dependents returns the sorted transitive dependents of a node
+func (g *DependencyGraph) dependents(node string) []string {
+	var result []string
+	visited := map[string]bool{node: true}
+	queue := []string{node}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range g.children[current] {
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			result = append(result, child)
+			queue = append(queue, child)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Cycles returns an error for each cycle formed by parent references
+func (g *DependencyGraph) Cycles() []error {
+	var errs []error
+	nodes := make([]string, 0, len(g.parents))
+	for node := range g.parents {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	// every node has at most one parent, so following the parents from each node either ends
+	// at a root or loops back into a node that was seen during the same walk
+	done := make(map[string]bool)
+	for _, node := range nodes {
+		walk := map[string]int{}
+		var path []string
+		current := node
+		for current != "" && !done[current] {
+			if start, seen := walk[current]; seen {
+				cycle := append(append([]string{}, path[start:]...), current)
+				errs = append(errs, &ParentCycleError{cycle: cycle})
+				break
+			}
+			walk[current] = len(path)
+			path = append(path, current)
+			current = g.parents[current]
+		}
+		for _, visited := range path {
+			done[visited] = true
+		}
+	}
+	return errs
+}
+
+// resolveParent resolves the parent reference of a node to a node of the graph. Returns false if
+// the parent is outside of the registry, and a DanglingParentError if it is dangling.
+func (g *DependencyGraph) resolveParent(node string, parent *schema.Parent) (string, bool, error) {
+	var stack, version string
+	switch {
+	case parent.Resolved != "":
+		stack, version, _ = strings.Cut(parent.Resolved, ":")
+	case parent.Id != "" && parent.RegistryUrl == "":
+		stack, version = parent.Id, parent.Version
+	default:
+		return "", false, nil
+	}
+	if version == "" {
+		version = "default"
+	}
+
+	stackVersions, found := g.versions[stack]
+	if !found {
+		return "", true, &DanglingParentError{devfile: node, parent: stack}
+	}
+	resolved, found := stackVersions[version]
+	if !found {
+		return "", true, &DanglingParentError{devfile: node, parent: NodeName(stack, version)}
+	}
+	return NodeName(stack, resolved), true, nil
+}
+
+// stackVersionMap maps each version of a stack to itself along with the "default" and "latest" aliases
+func stackVersionMap(indexComponent schema.Schema) map[string]string {
+	versionMap := make(map[string]string)
+	var latest *versionpkg.Version
+	for _, version := range indexComponent.Versions {
+		versionMap[version.Version] = version.Version
+		if version.Default {
+			versionMap["default"] = version.Version
+		}
+		current, err := versionpkg.NewVersion(version.Version)
+		if err != nil {
+			continue
+		}
+		if latest == nil || current.GreaterThan(latest) {
+			latest = current
+			versionMap["latest"] = version.Version
+		}
+	}
+	return versionMap
+}
+
+// resolveParentUri resolves a relative parent uri of the devfile of node under devfileDirPath to the stack
+// it points at under stackDirPath, the result is stored as the resolved reference of the parent.
+// Remote uris are left unresolved, a DanglingParentError is returned if the uri does not point at a stack.
+func resolveParentUri(node string, stackDirPath string, devfileDirPath string, parent *schema.Parent, fs filesystem.Filesystem) error {
+	if parent == nil || parent.Uri == "" {
+		return nil
+	}
+	if uri, err := url.Parse(parent.Uri); err == nil && uri.Scheme != "" {
+		return nil
+	}
+
+	parentPath := filepath.Join(devfileDirPath, parent.Uri)
+	if _, err := fs.Stat(parentPath); err != nil {
+		return &DanglingParentError{devfile: node, parent: parent.Uri}
+	}
+	relPath, err := filepath.Rel(stackDirPath, parentPath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		// the parent devfile lives outside of the registry stacks
+		return nil
+	}
+
+	// <stack>/devfile.yaml for single version stacks and <stack>/<version>/devfile.yaml otherwise
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	switch len(parts) {
+	case 2:
+		parent.Resolved = parts[0]
+	case 3:
+		parent.Resolved = NodeName(parts[0], parts[1])
+	default:
+		return &DanglingParentError{devfile: node, parent: parent.Uri}
+	}
+	return nil
+}
+
+// ResolveDependencies builds the dependency graph of the stacks in the index, sets the resolved
+// parent reference and the dependents of each stack version. Dangling parents and cycles are
+// returned as errors unless force is set, in which case they are logged.
+func ResolveDependencies(index []schema.Schema, force bool) ([]schema.Schema, error) {
+	return NewGenerator(WithForce(force)).resolveDependencies(index)
+}
+
+func (g *Generator) resolveDependencies(index []schema.Schema) ([]schema.Schema, error) {
+	graph, errs := BuildDependencyGraph(index)
+	errs = append(errs, graph.Cycles()...)
+	if len(errs) > 0 {
+		if g.dependencyValidation {
+			return index, fmt.Errorf("stack dependency graph is not valid: %v", errs)
+		}
+		for _, err := range errs {
+			g.logger.Printf("%s", err.Error())
+		}
+	}
+
+	for i := range index {
+		if index[i].Type != schema.StackDevfileType {
+			continue
+		}
+		for j := range index[i].Versions {
+			version := &index[i].Versions[j]
+			node := NodeName(index[i].Name, version.Version)
+			if version.Parent != nil {
+				version.Parent.Resolved = graph.parents[node]
+			}
+			version.Dependents = graph.dependents(node)
+		}
+	}
+
+	return index, nil
+}
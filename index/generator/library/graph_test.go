@@ -0,0 +1,259 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+func testGraphIndex() []schema.Schema {
+	return []schema.Schema{
+		{
+			Name: "base",
+			Type: schema.StackDevfileType,
+			Versions: []schema.Version{
+				{Version: "1.0.0", Default: true},
+				{Version: "2.0.0"},
+			},
+		},
+		{
+			Name: "java",
+			Type: schema.StackDevfileType,
+			Versions: []schema.Version{
+				{Version: "1.0.0", Default: true, Parent: &schema.Parent{Id: "base"}},
+				{Version: "1.1.0", Parent: &schema.Parent{Id: "base", Version: "latest"}},
+			},
+		},
+		{
+			Name: "java-maven",
+			Type: schema.StackDevfileType,
+			Versions: []schema.Version{
+				{Version: "1.0.0", Default: true, Parent: &schema.Parent{Id: "java", Version: "1.0.0"}},
+			},
+		},
+		{
+			Name: "external",
+			Type: schema.StackDevfileType,
+			Versions: []schema.Version{
+				{Version: "1.0.0", Default: true, Parent: &schema.Parent{Id: "nodejs", RegistryUrl: "https://registry.devfile.io"}},
+			},
+		},
+	}
+}
+
+func TestDependents(t *testing.T) {
+	tests := []struct {
+		name    string
+		stack   string
+		version string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Case 1: transitive dependents of the default version",
+			stack:   "base",
+			version: "default",
+			want:    []string{"java-maven:1.0.0", "java:1.0.0"},
+		},
+		{
+			name:    "Case 2: dependents of a parent referenced as latest",
+			stack:   "base",
+			version: "2.0.0",
+			want:    []string{"java:1.1.0"},
+		},
+		{
+			name:    "Case 3: stack version without dependents",
+			stack:   "java-maven",
+			version: "1.0.0",
+			want:    nil,
+		},
+		{
+			name:    "Case 4: stack not in registry",
+			stack:   "python",
+			version: "default",
+			wantErr: true,
+		},
+		{
+			name:    "Case 5: version not in registry",
+			stack:   "base",
+			version: "3.0.0",
+			wantErr: true,
+		},
+	}
+
+	graph, errs := BuildDependencyGraph(testGraphIndex())
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors building dependency graph: %v", errs)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := graph.Dependents(tt.stack, tt.version)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Expected error: %t, got: %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got: %v, Expected: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveDependencies(t *testing.T) {
+	tests := []struct {
+		name           string
+		index          []schema.Schema
+		force          bool
+		wantErr        bool
+		wantResolved   map[string]string
+		wantDependents map[string][]string
+	}{
+		{
+			name:  "Case 1: resolve parents and dependents",
+			index: testGraphIndex(),
+			wantResolved: map[string]string{
+				"java:1.0.0":       "base:1.0.0",
+				"java:1.1.0":       "base:2.0.0",
+				"java-maven:1.0.0": "java:1.0.0",
+				"external:1.0.0":   "",
+			},
+			wantDependents: map[string][]string{
+				"base:1.0.0": {"java-maven:1.0.0", "java:1.0.0"},
+				"base:2.0.0": {"java:1.1.0"},
+				"java:1.0.0": {"java-maven:1.0.0"},
+			},
+		},
+		{
+			name: "Case 2: dangling parent",
+			index: []schema.Schema{
+				{
+					Name:     "java",
+					Type:     schema.StackDevfileType,
+					Versions: []schema.Version{{Version: "1.0.0", Default: true, Parent: &schema.Parent{Id: "base"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Case 3: parent cycle",
+			index: []schema.Schema{
+				{
+					Name:     "a",
+					Type:     schema.StackDevfileType,
+					Versions: []schema.Version{{Version: "1.0.0", Default: true, Parent: &schema.Parent{Id: "b"}}},
+				},
+				{
+					Name:     "b",
+					Type:     schema.StackDevfileType,
+					Versions: []schema.Version{{Version: "1.0.0", Default: true, Parent: &schema.Parent{Id: "a"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Case 4: dangling parent with force",
+			index: []schema.Schema{
+				{
+					Name:     "java",
+					Type:     schema.StackDevfileType,
+					Versions: []schema.Version{{Version: "1.0.0", Default: true, Parent: &schema.Parent{Id: "base"}}},
+				},
+			},
+			force:        true,
+			wantResolved: map[string]string{"java:1.0.0": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := ResolveDependencies(tt.index, tt.force)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Expected error: %t, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			for _, indexComponent := range index {
+				for _, version := range indexComponent.Versions {
+					node := NodeName(indexComponent.Name, version.Version)
+					if want, found := tt.wantResolved[node]; found && version.Parent.Resolved != want {
+						t.Errorf("%s parent got: %s, expected: %s", node, version.Parent.Resolved, want)
+					}
+					if !reflect.DeepEqual(version.Dependents, tt.wantDependents[node]) {
+						t.Errorf("%s dependents got: %v, expected: %v", node, version.Dependents, tt.wantDependents[node])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestResolveParentUri(t *testing.T) {
+	stackDirPath := t.TempDir()
+	for _, dir := range []string{"base", filepath.Join("java", "1.0.0")} {
+		if err := os.MkdirAll(filepath.Join(stackDirPath, dir), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(stackDirPath, dir, devfile), []byte{}, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		uri          string
+		wantResolved string
+		wantErr      bool
+	}{
+		{
+			name:         "Case 1: single version stack",
+			uri:          "../../base/devfile.yaml",
+			wantResolved: "base",
+		},
+		{
+			name:         "Case 2: multi version stack",
+			uri:          "../1.0.0/devfile.yaml",
+			wantResolved: "java:1.0.0",
+		},
+		{
+			name: "Case 3: remote uri",
+			uri:  "https://example.com/devfile.yaml",
+		},
+		{
+			name:    "Case 4: missing parent",
+			uri:     "../../python/devfile.yaml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := &schema.Parent{Uri: tt.uri}
+			err := resolveParentUri("java:2.0.0", stackDirPath, filepath.Join(stackDirPath, "java", "2.0.0"), parent, filesystem.DefaultFs{})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Expected error: %t, got: %v", tt.wantErr, err)
+			}
+			if parent.Resolved != tt.wantResolved {
+				t.Errorf("Got: %s, Expected: %s", parent.Resolved, tt.wantResolved)
+			}
+		})
+	}
+}
@@ -18,15 +18,14 @@ package library
 import (
 	"encoding/json"
 	"fmt"
-	"io/fs"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
-	devfileParser "github.com/devfile/library/v2/pkg/devfile"
 	"github.com/devfile/library/v2/pkg/devfile/parser"
 	"github.com/devfile/registry-support/index/generator/schema"
 	"gopkg.in/yaml.v2"
@@ -35,6 +34,8 @@ import (
 const (
 	devfile             = "devfile.yaml"
 	devfileHidden       = ".devfile.yaml"
+	devfileYml          = "devfile.yml"
+	devfileYmlHidden    = ".devfile.yml"
 	extraDevfileEntries = "extraDevfileEntries.yaml"
 	stackYaml           = "stack.yaml"
 	ownersFile          = "OWNERS"
@@ -68,6 +69,26 @@ func (e *MissingSupportUrlError) Error() string {
 	return fmt.Sprintf("the %s devfile has no supportUrl mentioned\n", e.devfile)
 }
 
+// MissingDevfileError is an error if a stack or sample directory contains no devfile
+type MissingDevfileError struct {
+	dir string
+}
+
+func (e *MissingDevfileError) Error() string {
+	return fmt.Sprintf("%s does not contain any of %s\n", e.dir, strings.Join(DevfileNames, ", "))
+}
+
+// AmbiguousDevfileError is an error if a stack or sample directory contains more than one devfile
+type AmbiguousDevfileError struct {
+	dir      string
+	devfiles []string
+}
+
+func (e *AmbiguousDevfileError) Error() string {
+	return fmt.Sprintf("%s contains more than one devfile: %s, only one of %s is allowed\n", e.dir,
+		strings.Join(e.devfiles, ", "), strings.Join(DevfileNames, ", "))
+}
+
 type IconUrlBrokenError struct {
 	devfile string
 }
@@ -107,27 +128,7 @@ func (e *TooManyDeploymentScopes) Error() string {
 
 // GenerateIndexStruct parses registry then generates index struct according to the schema
 func GenerateIndexStruct(registryDirPath string, force bool) ([]schema.Schema, error) {
-	// Parse devfile registry then populate index struct
-	index, err := parseDevfileRegistry(registryDirPath, force)
-	if err != nil {
-		return index, err
-	}
-
-	// Parse extraDevfileEntries.yaml then populate the index struct (optional)
-	extraDevfileEntriesPath := path.Join(registryDirPath, extraDevfileEntries)
-	if fileExists(extraDevfileEntriesPath) {
-		indexFromExtraDevfileEntries, err := parseExtraDevfileEntries(registryDirPath, force)
-		if err != nil {
-			return index, err
-		}
-		index = append(index, indexFromExtraDevfileEntries...)
-	}
-
-	index, err = SetLastModifiedValue(index, registryDirPath)
-	if err != nil {
-		return index, err
-	}
-	return index, nil
+	return NewGenerator(WithForce(force)).Generate(registryDirPath)
 }
 
 // CreateIndexFile creates index file in disk
@@ -147,6 +148,10 @@ func CreateIndexFile(index []schema.Schema, indexFilePath string) error {
 }
 
 func validateIndexComponent(indexComponent schema.Schema, componentType schema.DevfileType) error {
+	return NewGenerator().validateIndexComponent(indexComponent, componentType)
+}
+
+func (g *Generator) validateIndexComponent(indexComponent schema.Schema, componentType schema.DevfileType) error {
 	if componentType == schema.StackDevfileType {
 		if indexComponent.Name == "" {
 			return fmt.Errorf("index component name is not initialized")
@@ -215,7 +220,7 @@ func validateIndexComponent(indexComponent schema.Schema, componentType schema.D
 	}
 
 	// Fields to be validated for both stacks and samples
-	if !iconExists(indexComponent.Icon) {
+	if !g.iconResolver(indexComponent.Icon) {
 		return &IconUrlBrokenError{devfile: indexComponent.Name}
 	}
 	if indexComponent.Provider == "" {
@@ -251,18 +256,17 @@ func validateIndexComponent(indexComponent schema.Schema, componentType schema.D
 }
 
 func fileExists(filepath string) bool {
-	if _, err := os.Stat(filepath); os.IsNotExist(err) {
-		return false
-	}
-
-	return true
+	return NewGenerator().fileExists(filepath)
 }
 
-func dirExists(dirpath string) error {
-	dir, err := os.Stat(dirpath)
+func (g *Generator) dirExists(dirpath string) error {
+	dir, err := g.fs.Stat(dirpath)
 	if os.IsNotExist(err) {
 		return fmt.Errorf("path: %s does not exist: %w", dirpath, err)
 	}
+	if err != nil {
+		return err
+	}
 	if !dir.IsDir() {
 		return fmt.Errorf("%s is not a directory", dirpath)
 	}
@@ -292,130 +296,139 @@ func iconExists(iconUrl string) bool {
 }
 
 func parseDevfileRegistry(registryDirPath string, force bool) ([]schema.Schema, error) {
+	return NewGenerator(WithForce(force)).parseDevfileRegistry(registryDirPath)
+}
+
+func (g *Generator) parseDevfileRegistry(registryDirPath string) ([]schema.Schema, error) {
 
-	var index []schema.Schema
 	stackDirPath := path.Join(registryDirPath, "stacks")
-	dirEntries, err := os.ReadDir(stackDirPath)
+	stackDir, err := g.fs.ReadDir(stackDirPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read stack directory %s: %v", stackDirPath, err)
 	}
 
-	stackDir := make([]fs.FileInfo, 0, len(dirEntries))
-	for _, dirEntry := range dirEntries {
-		info, err := dirEntry.Info()
+	var stackNames []string
+	for _, stackFolderDir := range stackDir {
+		if stackFolderDir.IsDir() {
+			stackNames = append(stackNames, stackFolderDir.Name())
+		}
+	}
+
+	// Stacks are parsed concurrently, the index keeps the order of the stack directory
+	index := make([]schema.Schema, len(stackNames))
+	var mutex sync.Mutex
+	done := 0
+	err = g.forEach(len(stackNames), func(i int) error {
+		indexComponent, err := g.parseStack(stackDirPath, stackNames[i])
 		if err != nil {
-			return nil, fmt.Errorf("failed to read stack directory info for %s: %v", stackDirPath, err)
+			return err
 		}
-		stackDir = append(stackDir, info)
+		index[i] = indexComponent
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		done++
+		g.reportProgress(StacksStage, stackNames[i], done, len(stackNames))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	for _, stackFolderDir := range stackDir {
-		if !stackFolderDir.IsDir() {
-			continue
-		}
-		stackFolderPath := filepath.Join(stackDirPath, stackFolderDir.Name())
-		stackYamlPath := filepath.Join(stackFolderPath, stackYaml)
-		// if stack.yaml exist,  parse stack.yaml
-		var indexComponent schema.Schema
-		if fileExists(stackYamlPath) {
-			indexComponent, err = parseStackInfo(stackYamlPath)
-			if err != nil {
-				return nil, err
-			}
-			if !force {
-				stackYamlErrors := validateStackInfo(indexComponent, stackFolderPath)
-				if stackYamlErrors != nil {
-					return nil, fmt.Errorf("%s stack.yaml is not valid: %v", stackFolderDir.Name(), stackYamlErrors)
-				}
-			}
+	return index, nil
+}
 
-			indexComponent.Versions = SortVersionByDescendingOrder(indexComponent.Versions)
+// parseStack parses the stack folder stackName under stackDirPath into an index component
+func (g *Generator) parseStack(stackDirPath string, stackName string) (schema.Schema, error) {
+	stackFolderPath := filepath.Join(stackDirPath, stackName)
+	stackYamlPath := filepath.Join(stackFolderPath, stackYaml)
+	// if stack.yaml exist,  parse stack.yaml
+	var indexComponent schema.Schema
+	var err error
+	if g.fileExists(stackYamlPath) {
+		indexComponent, err = g.parseStackInfo(stackYamlPath)
+		if err != nil {
+			return indexComponent, err
+		}
+		if g.stackInfoValidation {
+			stackYamlErrors := g.validateStackInfo(indexComponent, stackFolderPath)
+			if stackYamlErrors != nil {
+				return indexComponent, fmt.Errorf("%s stack.yaml is not valid: %v", stackName, stackYamlErrors)
+			}
+		}
 
-			i := 0
-			for i < len(indexComponent.Versions) {
-				versionComponent := indexComponent.Versions[i]
-				if versionComponent.Git != nil {
-					// Todo: implement Git reference support, get stack content from remote repository and store in OCI registry
-					fmt.Printf("stack: %v, version:%v, Git reference is currently not supported", stackFolderDir.Name(), versionComponent.Version)
-					indexComponent.Versions = append(indexComponent.Versions[:i], indexComponent.Versions[i+1:]...)
-					continue
-				}
-				stackVersonDirPath := filepath.Join(stackFolderPath, versionComponent.Version)
+		indexComponent.Versions = SortVersionByDescendingOrder(indexComponent.Versions)
 
-				err := parseStackDevfile(stackVersonDirPath, stackFolderDir.Name(), force, &versionComponent, &indexComponent)
-				if err != nil {
-					return nil, err
-				}
-				indexComponent.Versions[i] = versionComponent
-				i++
+		i := 0
+		for i < len(indexComponent.Versions) {
+			versionComponent := indexComponent.Versions[i]
+			if versionComponent.Git != nil {
+				// Todo: implement Git reference support, get stack content from remote repository and store in OCI registry
+				g.logger.Printf("stack: %v, version:%v, Git reference is currently not supported", stackName, versionComponent.Version)
+				indexComponent.Versions = append(indexComponent.Versions[:i], indexComponent.Versions[i+1:]...)
+				continue
 			}
+			stackVersonDirPath := filepath.Join(stackFolderPath, versionComponent.Version)
 
-			for _, version := range indexComponent.Versions {
-				// if a particular version supports all architectures, the top architecture List should be empty (support all) as well
-				if version.Architectures == nil || len(version.Architectures) == 0 {
-					indexComponent.Architectures = nil
-					break
-				}
-			}
-		} else { // if stack.yaml not exist, old stack repo struct, directly lookfor & parse devfile.yaml
-			versionComponent := schema.Version{Default: true}
-			err := parseStackDevfile(stackFolderPath, stackFolderDir.Name(), force, &versionComponent, &indexComponent)
+			err := g.parseStackDevfile(stackVersonDirPath, stackName, &versionComponent, &indexComponent)
 			if err != nil {
-				return nil, err
+				return indexComponent, err
 			}
-			indexComponent.Versions = append(indexComponent.Versions, versionComponent)
-		}
-		indexComponent.Type = schema.StackDevfileType
-
-		if !force {
-			// Index component validation
-			err := validateIndexComponent(indexComponent, schema.StackDevfileType)
-			switch err.(type) {
-			case *MissingProviderError, *MissingSupportUrlError, *MissingArchError:
-				// log to the console as FYI if the devfile has no architectures/provider/supportUrl
-				fmt.Printf("%s", err.Error())
-			default:
-				// only return error if we dont want to print
-				if err != nil {
-					return nil, fmt.Errorf("%s index component is not valid: %v", stackFolderDir.Name(), err)
-				}
+			err = resolveParentUri(NodeName(stackName, versionComponent.Version), stackDirPath, stackVersonDirPath, versionComponent.Parent, g.fs)
+			if err != nil && g.dependencyValidation {
+				return indexComponent, err
 			}
+			indexComponent.Versions[i] = versionComponent
+			i++
 		}
 
-		index = append(index, indexComponent)
+		for _, version := range indexComponent.Versions {
+			// if a particular version supports all architectures, the top architecture List should be empty (support all) as well
+			if version.Architectures == nil || len(version.Architectures) == 0 {
+				indexComponent.Architectures = nil
+				break
+			}
+		}
+	} else { // if stack.yaml not exist, old stack repo struct, directly lookfor & parse devfile.yaml
+		versionComponent := schema.Version{Default: true}
+		err := g.parseStackDevfile(stackFolderPath, stackName, &versionComponent, &indexComponent)
+		if err != nil {
+			return indexComponent, err
+		}
+		err = resolveParentUri(NodeName(stackName, versionComponent.Version), stackDirPath, stackFolderPath, versionComponent.Parent, g.fs)
+		if err != nil && g.dependencyValidation {
+			return indexComponent, err
+		}
+		indexComponent.Versions = append(indexComponent.Versions, versionComponent)
+	}
+	indexComponent.Type = schema.StackDevfileType
+
+	if g.componentValidation {
+		// Index component validation
+		err := g.validateIndexComponent(indexComponent, schema.StackDevfileType)
+		switch err.(type) {
+		case *MissingProviderError, *MissingSupportUrlError, *MissingArchError:
+			// log to the console as FYI if the devfile has no architectures/provider/supportUrl
+			g.logger.Printf("%s", err.Error())
+		default:
+			// only return error if we dont want to print
+			if err != nil {
+				return indexComponent, fmt.Errorf("%s index component is not valid: %v", stackName, err)
+			}
+		}
 	}
 
-	return index, nil
+	return indexComponent, g.runHook(&indexComponent)
 }
 
-func parseStackDevfile(devfileDirPath string, stackName string, force bool, versionComponent *schema.Version, indexComponent *schema.Schema) error {
-	// Allow devfile.yaml or .devfile.yaml
-	devfilePath := filepath.Join(devfileDirPath, devfile)
-	devfileHiddenPath := filepath.Join(devfileDirPath, devfileHidden)
-	if fileExists(devfilePath) && fileExists(devfileHiddenPath) {
-		return fmt.Errorf("both %s and %s exist", devfilePath, devfileHiddenPath)
-	}
-	if fileExists(devfileHiddenPath) {
-		devfilePath = devfileHiddenPath
-	}
-	convertUri := false
-	if !force {
-		// Devfile validation
-		devfileObj, _, err := devfileParser.ParseDevfileAndValidate(parser.ParserArgs{
-			ConvertKubernetesContentInUri: &convertUri,
-			Path:                          devfilePath})
-		if err != nil {
-			return fmt.Errorf("%s devfile is not valid: %v", devfileDirPath, err)
-		}
-
-		metadataErrors := checkForRequiredMetadata(devfileObj)
-		if metadataErrors != nil {
-			return fmt.Errorf("%s devfile is not valid: %v", devfileDirPath, metadataErrors)
-		}
+func (g *Generator) parseStackDevfile(devfileDirPath string, stackName string, versionComponent *schema.Version, indexComponent *schema.Schema) error {
+	// Allow devfile.yaml, .devfile.yaml, devfile.yml or .devfile.yml
+	devfilePath, err := findDevfile(devfileDirPath, g.fs)
+	if err != nil {
+		return err
 	}
 
-	/* #nosec G304 -- devfilePath is produced using filepath.Join which cleans the input path */
-	bytes, err := os.ReadFile(devfilePath)
+	bytes, err := g.fetchDevfile(devfilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read %s: %v", devfilePath, err)
 	}
@@ -425,6 +438,35 @@ func parseStackDevfile(devfileDirPath string, stackName string, force bool, vers
 	if err != nil {
 		return fmt.Errorf("failed to unmarshal %s data: %v", devfilePath, err)
 	}
+
+	if g.devfileValidation || g.linter != nil {
+		// A parent referenced by id without a registry url is a stack of this registry which
+		// cannot be fetched by the parser, it is checked as part of the dependency graph instead
+		flattenedDevfile := devfile.Parent == nil || devfile.Parent.Id == "" || devfile.Parent.RegistryUrl != ""
+
+		// Devfile validation
+		devfileObj, err := g.parseAndValidateDevfile(devfilePath, flattenedDevfile)
+		if err != nil && g.devfileValidation {
+			return fmt.Errorf("%s devfile is not valid: %v", devfileDirPath, err)
+		} else if err != nil {
+			// Without devfile validation, stacks which cannot be parsed are still indexed but not linted
+			g.logger.Printf("%s devfile is not valid, skipping lint: %v", devfileDirPath, err)
+		}
+
+		if g.devfileValidation {
+			metadataErrors := checkForRequiredMetadata(devfileObj)
+			if metadataErrors != nil {
+				return fmt.Errorf("%s devfile is not valid: %v", devfileDirPath, metadataErrors)
+			}
+		}
+
+		if g.linter != nil && err == nil {
+			err = g.lintStack(LintTarget{Stack: stackName, Version: devfile.Meta.Version, DevfilePath: devfilePath, Devfile: devfileObj})
+			if err != nil {
+				return err
+			}
+		}
+	}
 	metaBytes, err := yaml.Marshal(devfile.Meta)
 	if err != nil {
 		return fmt.Errorf("failed to unmarshal %s data: %v", devfilePath, err)
@@ -465,6 +507,7 @@ func parseStackDevfile(devfileDirPath string, stackName string, force bool, vers
 	}
 
 	versionProp.Default = versionComponent.Default
+	versionProp.Parent = devfile.Parent
 	*versionComponent = versionProp
 	if versionComponent.Links == nil {
 		versionComponent.Links = make(map[string]string)
@@ -518,22 +561,11 @@ func parseStackDevfile(devfileDirPath string, stackName string, force bool, vers
 	}
 
 	// Get the files in the stack folder
-	fileEntries, err := os.ReadDir(devfileDirPath)
+	stackFiles, err := g.fs.ReadDir(devfileDirPath)
 	if err != nil {
 		return err
 	}
 
-	stackFiles := make([]fs.FileInfo, 0, len(fileEntries))
-
-	for _, fileEntry := range fileEntries {
-		info, err := fileEntry.Info()
-		if err != nil {
-			return err
-		}
-
-		stackFiles = append(stackFiles, info)
-	}
-
 	for _, stackFile := range stackFiles {
 		// The registry build should have already packaged any folders and miscellaneous files into an archive.tar file
 		// But, add this check as a safeguard, as OCI doesn't support unarchived folders being pushed up.
@@ -545,26 +577,27 @@ func parseStackDevfile(devfileDirPath string, stackName string, force bool, vers
 }
 
 func parseExtraDevfileEntries(registryDirPath string, force bool) ([]schema.Schema, error) {
+	return NewGenerator(WithForce(force)).parseExtraDevfileEntries(registryDirPath)
+}
+
+func (g *Generator) parseExtraDevfileEntries(registryDirPath string) ([]schema.Schema, error) {
 	var index []schema.Schema
 	extraDevfileEntriesPath := path.Join(registryDirPath, extraDevfileEntries)
-	/* #nosec G304 -- extraDevfileEntriesPath is produced using path.Join which cleans the input path */
-	bytes, err := os.ReadFile(extraDevfileEntriesPath)
+	bytes, err := g.fs.ReadFile(extraDevfileEntriesPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s: %v", extraDevfileEntriesPath, err)
 	}
 
 	// Only validate samples if they have been cached
 	samplesDir := filepath.Join(registryDirPath, "samples")
-	validateSamples := false
-	if _, err := os.Stat(samplesDir); !os.IsNotExist(err) {
-		validateSamples = true
-	}
+	validateSamples := g.fileExists(samplesDir)
 
 	var devfileEntries schema.ExtraDevfileEntries
 	err = yaml.Unmarshal(bytes, &devfileEntries)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal %s data: %v", extraDevfileEntriesPath, err)
 	}
+	total := len(devfileEntries.Samples) + len(devfileEntries.Stacks)
 	devfileTypes := []schema.DevfileType{schema.SampleDevfileType, schema.StackDevfileType}
 	for _, devfileType := range devfileTypes {
 		var devfileEntriesWithType []schema.Schema
@@ -576,51 +609,30 @@ func parseExtraDevfileEntries(registryDirPath string, force bool) ([]schema.Sche
 		for _, devfileEntry := range devfileEntriesWithType {
 			indexComponent := devfileEntry
 			indexComponent.Type = devfileType
-			if !force {
-				// If sample, validate devfile associated with sample as well
-				// Can't handle during registry build since we don't have access to devfile library/parser
-				if indexComponent.Type == schema.SampleDevfileType && validateSamples {
-					if indexComponent.Versions != nil && len(indexComponent.Versions) > 0 {
-						for _, version := range indexComponent.Versions {
-							sampleVersonDirPath := filepath.Join(samplesDir, devfileEntry.Name, version.Version)
-							devfilePath := filepath.Join(sampleVersonDirPath, "devfile.yaml")
-							_, err := os.Stat(filepath.Join(devfilePath))
-							if err != nil {
-								// This error shouldn't occur since we check for the devfile's existence during registry build, but check for it regardless
-								return nil, fmt.Errorf("%s devfile sample does not have a devfile.yaml: %v", indexComponent.Name, err)
-							}
-							convertUri := false
-							// Validate the sample devfile
-							_, _, err = devfileParser.ParseDevfileAndValidate(parser.ParserArgs{
-								ConvertKubernetesContentInUri: &convertUri,
-								Path:                          devfilePath})
-							if err != nil {
-								return nil, fmt.Errorf("%s sample devfile is not valid: %v", devfileEntry.Name, err)
-							}
-						}
-					} else {
-						devfilePath := filepath.Join(samplesDir, devfileEntry.Name, "devfile.yaml")
-						_, err := os.Stat(filepath.Join(devfilePath))
-						if err != nil {
-							// This error shouldn't occur since we check for the devfile's existence during registry build, but check for it regardless
-							return nil, fmt.Errorf("%s devfile sample does not have a devfile.yaml: %v", indexComponent.Name, err)
-						}
-						convertUri := false
-						// Validate the sample devfile
-						_, _, err = devfileParser.ParseDevfileAndValidate(parser.ParserArgs{Path: devfilePath,
-							ConvertKubernetesContentInUri: &convertUri})
-						if err != nil {
-							return nil, fmt.Errorf("%s sample devfile is not valid: %v", devfileEntry.Name, err)
+			// If sample, validate devfile associated with sample as well
+			// Can't handle during registry build since we don't have access to devfile library/parser
+			if g.devfileValidation && indexComponent.Type == schema.SampleDevfileType && validateSamples {
+				if indexComponent.Versions != nil && len(indexComponent.Versions) > 0 {
+					for _, version := range indexComponent.Versions {
+						sampleVersonDirPath := filepath.Join(samplesDir, devfileEntry.Name, version.Version)
+						if err := g.validateSampleDevfile(indexComponent.Name, sampleVersonDirPath); err != nil {
+							return nil, err
 						}
 					}
+				} else {
+					if err := g.validateSampleDevfile(indexComponent.Name, filepath.Join(samplesDir, devfileEntry.Name)); err != nil {
+						return nil, err
+					}
 				}
+			}
 
+			if g.componentValidation {
 				// Index component validation
-				err := validateIndexComponent(indexComponent, devfileType)
+				err := g.validateIndexComponent(indexComponent, devfileType)
 				switch err.(type) {
 				case *MissingProviderError, *MissingSupportUrlError, *MissingArchError:
 					// log to the console as FYI if the devfile has no architectures/provider/supportUrl
-					fmt.Printf("%s", err.Error())
+					g.logger.Printf("%s", err.Error())
 				default:
 					// only return error if we dont want to print
 					if err != nil {
@@ -628,17 +640,35 @@ func parseExtraDevfileEntries(registryDirPath string, force bool) ([]schema.Sche
 					}
 				}
 			}
+			if err := g.runHook(&indexComponent); err != nil {
+				return nil, err
+			}
 			index = append(index, indexComponent)
+			g.reportProgress(ExtraDevfileEntriesStage, indexComponent.Name, len(index), total)
 		}
 	}
 
 	return index, nil
 }
 
-/* #nosec G304 -- stackYamlPath is produced from file.Join which cleans the input path */
-func parseStackInfo(stackYamlPath string) (schema.Schema, error) {
+// validateSampleDevfile validates the devfile.yaml of the sample cached under sampleDirPath
+func (g *Generator) validateSampleDevfile(sampleName string, sampleDirPath string) error {
+	devfilePath, err := findDevfile(sampleDirPath, g.fs)
+	if err != nil {
+		// This error shouldn't occur since we check for the devfile's existence during registry build, but check for it regardless
+		return fmt.Errorf("%s devfile sample does not have a devfile: %v", sampleName, err)
+	}
+	// Validate the sample devfile
+	_, err = g.parseAndValidateDevfile(devfilePath, true)
+	if err != nil {
+		return fmt.Errorf("%s sample devfile is not valid: %v", sampleName, err)
+	}
+	return nil
+}
+
+func (g *Generator) parseStackInfo(stackYamlPath string) (schema.Schema, error) {
 	var index schema.Schema
-	bytes, err := os.ReadFile(stackYamlPath)
+	bytes, err := g.fs.ReadFile(stackYamlPath)
 	if err != nil {
 		return schema.Schema{}, fmt.Errorf("failed to read %s: %v", stackYamlPath, err)
 	}
@@ -670,7 +700,7 @@ func checkForRequiredMetadata(devfileObj parser.DevfileObj) []error {
 	return metadataErrors
 }
 
-func validateStackInfo(stackInfo schema.Schema, stackfolderDir string) []error {
+func (g *Generator) validateStackInfo(stackInfo schema.Schema, stackfolderDir string) []error {
 	var errors []error
 
 	if stackInfo.Name == "" {
@@ -697,7 +727,7 @@ func validateStackInfo(stackInfo schema.Schema, stackfolderDir string) []error {
 
 		if version.Git == nil {
 			versionFolder := path.Join(stackfolderDir, version.Version)
-			err := dirExists(versionFolder)
+			err := g.dirExists(versionFolder)
 			if err != nil {
 				errors = append(errors, fmt.Errorf("cannot find resorce folder for version %s defined in stack.yaml: %v", version.Version, err))
 			}
@@ -712,10 +742,13 @@ func validateStackInfo(stackInfo schema.Schema, stackfolderDir string) []error {
 
 // SetLastModifiedValue adds the last modified value to a pre-created index
 // The last modified dates are contained in a file named last_modified.json that is apart of the registry dir
-/* #nosec G304 -- lastModFile is produced from filepath.Join which cleans the input path */
 func SetLastModifiedValue(index []schema.Schema, registryDirPath string) ([]schema.Schema, error) {
+	return NewGenerator().setLastModifiedValue(index, registryDirPath)
+}
+
+func (g *Generator) setLastModifiedValue(index []schema.Schema, registryDirPath string) ([]schema.Schema, error) {
 	lastModFile := filepath.Join(registryDirPath, "last_modified.json")
-	bytes, err := os.ReadFile(lastModFile)
+	bytes, err := g.fs.ReadFile(lastModFile)
 	if err != nil {
 		return index, err
 	}
@@ -0,0 +1,264 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+)
+
+// Severity is the severity of a lint issue
+type Severity string
+
+const (
+	// ErrorSeverity issues fail the index generation
+	ErrorSeverity Severity = "error"
+	// WarningSeverity issues are logged
+	WarningSeverity Severity = "warning"
+
+	// DefaultMaxDescriptionLength is the maximum description length checked by the default lint rules
+	DefaultMaxDescriptionLength = 500
+)
+
+// LintTarget is the stack version devfile checked by the lint rules
+type LintTarget struct {
+	Stack       string
+	Version     string
+	DevfilePath string
+	Devfile     parser.DevfileObj
+}
+
+// LintIssue is a problem found by a lint rule
+type LintIssue struct {
+	Rule     string
+	Severity Severity
+	Devfile  string
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("[%s] %s: %s: %s\n", i.Severity, i.Rule, i.Devfile, i.Message)
+}
+
+// LintRule checks a stack version devfile, registries can implement their own rules
+type LintRule interface {
+	// Name is the name of the rule reported with its issues
+	Name() string
+	// Severity is the severity of the issues reported by the rule
+	Severity() Severity
+	// Check returns the messages of the problems found in the devfile
+	Check(target LintTarget) []string
+}
+
+// lintRuleFunc is a lint rule defined by a check function
+type lintRuleFunc struct {
+	name     string
+	severity Severity
+	check    func(target LintTarget) []string
+}
+
+func (r *lintRuleFunc) Name() string {
+	return r.name
+}
+
+func (r *lintRuleFunc) Severity() Severity {
+	return r.severity
+}
+
+func (r *lintRuleFunc) Check(target LintTarget) []string {
+	return r.check(target)
+}
+
+// NewLintRule creates a lint rule from a check function
+func NewLintRule(name string, severity Severity, check func(target LintTarget) []string) LintRule {
+	return &lintRuleFunc{name: name, severity: severity, check: check}
+}
+
+// Linter runs lint rules against stack version devfiles
+type Linter struct {
+	rules []LintRule
+}
+
+// NewLinter creates a linter with the given rules
+func NewLinter(rules ...LintRule) *Linter {
+	return &Linter{rules: rules}
+}
+
+// Register adds rules to the linter
+func (l *Linter) Register(rules ...LintRule) {
+	l.rules = append(l.rules, rules...)
+}
+
+// Lint runs every rule against the stack version devfile
+func (l *Linter) Lint(target LintTarget) []LintIssue {
+	var issues []LintIssue
+	for _, rule := range l.rules {
+		for _, message := range rule.Check(target) {
+			issues = append(issues, LintIssue{
+				Rule:     rule.Name(),
+				Severity: rule.Severity(),
+				Devfile:  NodeName(target.Stack, target.Version),
+				Message:  message,
+			})
+		}
+	}
+	return issues
+}
+
+// DefaultLintRules returns the built-in lint rules that do not need any registry specific configuration
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		ImagePinnedRule(),
+		MemoryLimitRule(),
+		DescriptionLengthRule(DefaultMaxDescriptionLength),
+		SupportUrlReachableRule(urlReachable),
+		StarterProjectPinnedRule(),
+	}
+}
+
+// ImagePinnedRule checks that container images are pinned by digest or by a tag other than latest
+func ImagePinnedRule() LintRule {
+	return NewLintRule("image-pinned", ErrorSeverity, func(target LintTarget) []string {
+		components, err := target.Devfile.Data.GetComponents(common.DevfileOptions{})
+		if err != nil {
+			return []string{err.Error()}
+		}
+		var messages []string
+		for _, component := range components {
+			if component.Container == nil {
+				continue
+			}
+			image := component.Container.Image
+			if strings.Contains(image, "@") {
+				continue
+			}
+			tag := ""
+			if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+				tag = image[i+1:]
+			}
+			if tag == "" || tag == "latest" {
+				messages = append(messages, fmt.Sprintf("container %s image %s is not pinned by digest or tag", component.Name, image))
+			}
+		}
+		return messages
+	})
+}
+
+// MemoryLimitRule checks that containers set a memory limit
+func MemoryLimitRule() LintRule {
+	return NewLintRule("memory-limit", ErrorSeverity, func(target LintTarget) []string {
+		components, err := target.Devfile.Data.GetComponents(common.DevfileOptions{})
+		if err != nil {
+			return []string{err.Error()}
+		}
+		var messages []string
+		for _, component := range components {
+			if component.Container != nil && component.Container.MemoryLimit == "" {
+				messages = append(messages, fmt.Sprintf("container %s has no memoryLimit", component.Name))
+			}
+		}
+		return messages
+	})
+}
+
+// DescriptionLengthRule checks that the description is set and at most maxLength characters long
+func DescriptionLengthRule(maxLength int) LintRule {
+	return NewLintRule("description-length", WarningSeverity, func(target LintTarget) []string {
+		description := target.Devfile.Data.GetMetadata().Description
+		if description == "" {
+			return []string{"metadata.description is not set"}
+		}
+		if length := len([]rune(description)); length > maxLength {
+			return []string{fmt.Sprintf("metadata.description is %d characters long, the limit is %d", length, maxLength)}
+		}
+		return nil
+	})
+}
+
+// AllowedTagsRule checks that the tags are part of the allowed vocabulary of the registry
+func AllowedTagsRule(allowedTags []string) LintRule {
+	return NewLintRule("allowed-tags", ErrorSeverity, func(target LintTarget) []string {
+		var messages []string
+		for _, tag := range target.Devfile.Data.GetMetadata().Tags {
+			if !inArray(allowedTags, tag) {
+				messages = append(messages, fmt.Sprintf("tag %s is not one of the allowed tags", tag))
+			}
+		}
+		return messages
+	})
+}
+
+// SupportUrlReachableRule checks that the supportUrl is reachable with the given check
+func SupportUrlReachableRule(reachable func(url string) bool) LintRule {
+	return NewLintRule("support-url-reachable", WarningSeverity, func(target LintTarget) []string {
+		supportUrl := target.Devfile.Data.GetMetadata().SupportUrl
+		if supportUrl != "" && !reachable(supportUrl) {
+			return []string{fmt.Sprintf("supportUrl %s is not reachable", supportUrl)}
+		}
+		return nil
+	})
+}
+
+// StarterProjectPinnedRule checks that git starter projects are pinned to a revision
+func StarterProjectPinnedRule() LintRule {
+	return NewLintRule("starter-project-pinned", WarningSeverity, func(target LintTarget) []string {
+		starterProjects, err := target.Devfile.Data.GetStarterProjects(common.DevfileOptions{})
+		if err != nil {
+			return []string{err.Error()}
+		}
+		var messages []string
+		for _, starterProject := range starterProjects {
+			if starterProject.Git != nil && (starterProject.Git.CheckoutFrom == nil || starterProject.Git.CheckoutFrom.Revision == "") {
+				messages = append(messages, fmt.Sprintf("starter project %s is not pinned to a revision", starterProject.Name))
+			}
+		}
+		return messages
+	})
+}
+
+// urlReachable checks that a GET request to the url succeeds
+func urlReachable(url string) bool {
+	client := http.Client{Timeout: 10 * time.Second}
+	/* #nosec G107 -- url is taken from the stack devfile, which are vetted beforehand */
+	resp, err := client.Get(url)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode < http.StatusBadRequest
+}
+
+// lintStack runs the linter of the generator against a stack version devfile, warnings are logged and errors
+// are returned
+func (g *Generator) lintStack(target LintTarget) error {
+	var errorIssues []string
+	for _, issue := range g.linter.Lint(target) {
+		if issue.Severity == ErrorSeverity {
+			errorIssues = append(errorIssues, strings.TrimSuffix(issue.String(), "\n"))
+		} else {
+			g.logger.Printf("%s", issue.String())
+		}
+	}
+	if len(errorIssues) > 0 {
+		return fmt.Errorf("%s devfile has lint errors: %s", target.DevfilePath, strings.Join(errorIssues, "; "))
+	}
+	return nil
+}
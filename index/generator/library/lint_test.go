@@ -0,0 +1,145 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	devfileParser "github.com/devfile/library/v2/pkg/devfile"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+)
+
+const lintTestDevfile = `schemaVersion: 2.2.0
+metadata:
+  name: go
+  version: 1.0.2
+  displayName: Go Runtime
+  description: Go is an open source programming language
+  language: Go
+  projectType: Go
+  supportUrl: https://github.com/devfile/api/issues
+  tags:
+    - Go
+    - Testing
+components:
+  - name: runtime
+    container:
+      image: registry.access.redhat.com/ubi9/go-toolset:1.18.10
+      memoryLimit: 1024Mi
+  - name: tools
+    container:
+      image: quay.io/devfile/universal-developer-image:latest
+  - name: cache
+    container:
+      image: localhost:5000/cache
+      memoryLimit: 512Mi
+  - name: pinned
+    container:
+      image: quay.io/devfile/base@sha256:1e5c3a1b3c5a52b0b4c0d7a3f0f3b2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c7b6a5
+      memoryLimit: 512Mi
+starterProjects:
+  - name: go-starter
+    git:
+      checkoutFrom:
+        revision: main
+      remotes:
+        origin: https://github.com/devfile-samples/devfile-stack-go.git
+  - name: go-unpinned
+    git:
+      remotes:
+        origin: https://github.com/devfile-samples/devfile-stack-go.git
+`
+
+func TestLinter(t *testing.T) {
+	convertUri := false
+	devfileObj, _, err := devfileParser.ParseDevfileAndValidate(parser.ParserArgs{
+		Data:                          []byte(lintTestDevfile),
+		ConvertKubernetesContentInUri: &convertUri,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := LintTarget{Stack: "go", Version: "1.0.2", DevfilePath: "stacks/go/devfile.yaml", Devfile: devfileObj}
+
+	tests := []struct {
+		name       string
+		rules      []LintRule
+		wantIssues []string
+	}{
+		{
+			name:  "Case 1: image pinned",
+			rules: []LintRule{ImagePinnedRule()},
+			wantIssues: []string{
+				"[error] image-pinned: go:1.0.2: container tools image quay.io/devfile/universal-developer-image:latest is not pinned by digest or tag\n",
+				"[error] image-pinned: go:1.0.2: container cache image localhost:5000/cache is not pinned by digest or tag\n",
+			},
+		},
+		{
+			name:       "Case 2: memory limit",
+			rules:      []LintRule{MemoryLimitRule()},
+			wantIssues: []string{"[error] memory-limit: go:1.0.2: container tools has no memoryLimit\n"},
+		},
+		{
+			name:       "Case 3: description within the length limit",
+			rules:      []LintRule{DescriptionLengthRule(DefaultMaxDescriptionLength)},
+			wantIssues: nil,
+		},
+		{
+			name:       "Case 4: description over the length limit",
+			rules:      []LintRule{DescriptionLengthRule(10)},
+			wantIssues: []string{"[warning] description-length: go:1.0.2: metadata.description is 41 characters long, the limit is 10\n"},
+		},
+		{
+			name:       "Case 5: allowed tags",
+			rules:      []LintRule{AllowedTagsRule([]string{"Go", "Java"})},
+			wantIssues: []string{"[error] allowed-tags: go:1.0.2: tag Testing is not one of the allowed tags\n"},
+		},
+		{
+			name:       "Case 6: unreachable support url",
+			rules:      []LintRule{SupportUrlReachableRule(func(string) bool { return false })},
+			wantIssues: []string{"[warning] support-url-reachable: go:1.0.2: supportUrl https://github.com/devfile/api/issues is not reachable\n"},
+		},
+		{
+			name:       "Case 7: starter project pinned",
+			rules:      []LintRule{StarterProjectPinnedRule()},
+			wantIssues: []string{"[warning] starter-project-pinned: go:1.0.2: starter project go-unpinned is not pinned to a revision\n"},
+		},
+		{
+			name: "Case 8: custom rule",
+			rules: []LintRule{NewLintRule("display-name", ErrorSeverity, func(target LintTarget) []string {
+				if !strings.HasSuffix(target.Devfile.Data.GetMetadata().DisplayName, "Stack") {
+					return []string{"displayName should end with Stack"}
+				}
+				return nil
+			})},
+			wantIssues: []string{"[error] display-name: go:1.0.2: displayName should end with Stack\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIssues []string
+			for _, issue := range NewLinter(tt.rules...).Lint(target) {
+				gotIssues = append(gotIssues, issue.String())
+			}
+			if !reflect.DeepEqual(gotIssues, tt.wantIssues) {
+				t.Errorf("Got: %q, Expected: %q", gotIssues, tt.wantIssues)
+			}
+		})
+	}
+}
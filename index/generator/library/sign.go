@@ -0,0 +1,245 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// Ed25519KeyType is the key type of signatures produced by ed25519 keys
+	Ed25519KeyType = "ed25519"
+	// ECDSAKeyType is the key type of signatures produced by ECDSA P-256 keys, including cosign keys
+	ECDSAKeyType = "ecdsa-p256-sha256"
+	// SignatureFileSuffix is appended to the index file path to form the signature file path
+	SignatureFileSuffix = ".sig"
+
+	// cosignPasswordEnv is the environment variable cosign reads the private key password from
+	cosignPasswordEnv = "COSIGN_PASSWORD"
+)
+
+// cosignEncryptedKey is the encrypted private key format generated by cosign generate-key-pair
+type cosignEncryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// SignIndexFile signs the index entry digest and the resource digests of each stack version and sample in the index,
+// the signature is written alongside the index file. Each entry is signed on its own so that a registry can serve the
+// signed entries a client can access. keyPath is a PEM encoded ed25519 or ECDSA P-256 private key, cosign encrypted
+// keys are decrypted with the password set in COSIGN_PASSWORD.
+func SignIndexFile(index []schema.Schema, registryDirPath string, indexFilePath string, keyPath string) error {
+	signer, keyType, err := LoadSigningKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	entries, err := buildSignedEntries(index, registryDirPath)
+	if err != nil {
+		return err
+	}
+	indexSignature := schema.IndexSignature{KeyType: keyType}
+	for _, entry := range entries {
+		payloadBytes, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the signed entry of %s: %v", entry.Name, err)
+		}
+		signature, err := signPayload(signer, payloadBytes)
+		if err != nil {
+			return fmt.Errorf("failed to sign the entry of %s: %v", entry.Name, err)
+		}
+		indexSignature.Entries = append(indexSignature.Entries, schema.EntrySignature{
+			Payload:   base64.StdEncoding.EncodeToString(payloadBytes),
+			Signature: base64.StdEncoding.EncodeToString(signature),
+		})
+	}
+
+	bytes, err := json.MarshalIndent(indexSignature, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal signature data: %v", err)
+	}
+
+	signatureFilePath := indexFilePath + SignatureFileSuffix
+	/* #nosec G306 -- signature file does not contain any sensitive data*/
+	err = os.WriteFile(signatureFilePath, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", signatureFilePath, err)
+	}
+	return nil
+}
+
+// LoadSigningKey reads a PEM encoded ed25519 or ECDSA P-256 private key and returns its signer along with the key type
+func LoadSigningKey(keyPath string) (crypto.Signer, string, error) {
+	/* #nosec G304 -- keyPath is provided by the user running the generator */
+	bytes, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %v", keyPath, err)
+	}
+	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, "", fmt.Errorf("%s is not a PEM encoded key", keyPath)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "ENCRYPTED SIGSTORE PRIVATE KEY", "ENCRYPTED COSIGN PRIVATE KEY":
+		var der []byte
+		der, err = decryptCosignKey(block.Bytes, []byte(os.Getenv(cosignPasswordEnv)))
+		if err == nil {
+			key, err = x509.ParsePKCS8PrivateKey(der)
+		}
+	default:
+		return nil, "", fmt.Errorf("%s has unsupported PEM block type %s", keyPath, block.Type)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s: %v", keyPath, err)
+	}
+
+	switch privateKey := key.(type) {
+	case ed25519.PrivateKey:
+		return privateKey, Ed25519KeyType, nil
+	case *ecdsa.PrivateKey:
+		if privateKey.Curve != elliptic.P256() {
+			return nil, "", fmt.Errorf("%s is not a P-256 ECDSA key", keyPath)
+		}
+		return privateKey, ECDSAKeyType, nil
+	default:
+		return nil, "", fmt.Errorf("%s is not an ed25519 or ECDSA key", keyPath)
+	}
+}
+
+// decryptCosignKey decrypts the content of a cosign encrypted private key, returns the PKCS8 encoded key
+func decryptCosignKey(data []byte, password []byte) ([]byte, error) {
+	var encryptedKey cosignEncryptedKey
+	if err := json.Unmarshal(data, &encryptedKey); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal encrypted key: %v", err)
+	}
+	if encryptedKey.KDF.Name != "scrypt" || encryptedKey.Cipher.Name != "nacl/secretbox" {
+		return nil, fmt.Errorf("unsupported key encryption %s with %s", encryptedKey.KDF.Name, encryptedKey.Cipher.Name)
+	}
+	if len(encryptedKey.Cipher.Nonce) != 24 {
+		return nil, fmt.Errorf("invalid nonce length %d", len(encryptedKey.Cipher.Nonce))
+	}
+
+	params := encryptedKey.KDF.Params
+	derivedKey, err := scrypt.Key(password, encryptedKey.KDF.Salt, params.N, params.R, params.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+	var secretKey [32]byte
+	var nonce [24]byte
+	copy(secretKey[:], derivedKey)
+	copy(nonce[:], encryptedKey.Cipher.Nonce)
+
+	der, ok := secretbox.Open(nil, encryptedKey.Ciphertext, &nonce, &secretKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to decrypt key, check the password set in %s", cosignPasswordEnv)
+	}
+	return der, nil
+}
+
+// signPayload signs the payload, ECDSA signatures are ASN.1 encoded signatures of the SHA-256 payload digest
+// to match the signatures produced by cosign sign-blob
+func signPayload(signer crypto.Signer, payload []byte) ([]byte, error) {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(payload)
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// buildSignedEntries builds the signed entries of the stack versions and samples of the index, with the resources
+// of each stack version
+func buildSignedEntries(index []schema.Schema, registryDirPath string) ([]schema.SignedEntry, error) {
+	var entries []schema.SignedEntry
+	stackDirPath := filepath.Join(registryDirPath, "stacks")
+
+	for _, indexComponent := range index {
+		if len(indexComponent.Versions) == 0 {
+			digest, err := schema.EntryDigest(indexComponent, nil)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, schema.SignedEntry{
+				Name:   indexComponent.Name,
+				Type:   indexComponent.Type,
+				Digest: digest,
+			})
+			continue
+		}
+		for _, versionComponent := range indexComponent.Versions {
+			digest, err := schema.EntryDigest(indexComponent, &versionComponent)
+			if err != nil {
+				return nil, err
+			}
+			entry := schema.SignedEntry{
+				Name:    indexComponent.Name,
+				Type:    indexComponent.Type,
+				Version: versionComponent.Version,
+				Digest:  digest,
+			}
+			for _, resource := range versionComponent.Resources {
+				resourcePath := filepath.Join(stackDirPath, indexComponent.Name, versionComponent.Version, resource)
+				if !fileExists(resourcePath) {
+					resourcePath = filepath.Join(stackDirPath, indexComponent.Name, resource)
+				}
+				/* #nosec G304 -- resourcePath is produced using filepath.Join which cleans the input path */
+				bytes, err := os.ReadFile(resourcePath)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %v", resourcePath, err)
+				}
+				if entry.Resources == nil {
+					entry.Resources = make(map[string]string)
+				}
+				entry.Resources[resource] = schema.ContentDigest(bytes)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
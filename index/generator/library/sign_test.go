@@ -0,0 +1,224 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// writeKey writes the PKCS8 encoded private key as a PEM file, the key is encrypted the same way as
+// cosign generate-key-pair if a password is given
+func writeKey(t *testing.T, path string, key crypto.Signer, password string) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	if password != "" {
+		var encryptedKey cosignEncryptedKey
+		encryptedKey.KDF.Name = "scrypt"
+		encryptedKey.KDF.Params.N, encryptedKey.KDF.Params.R, encryptedKey.KDF.Params.P = 1024, 8, 1
+		encryptedKey.KDF.Salt = make([]byte, 32)
+		encryptedKey.Cipher.Name = "nacl/secretbox"
+		encryptedKey.Cipher.Nonce = make([]byte, 24)
+		if _, err := rand.Read(encryptedKey.KDF.Salt); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rand.Read(encryptedKey.Cipher.Nonce); err != nil {
+			t.Fatal(err)
+		}
+		derivedKey, err := scrypt.Key([]byte(password), encryptedKey.KDF.Salt, 1024, 8, 1, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var secretKey [32]byte
+		var nonce [24]byte
+		copy(secretKey[:], derivedKey)
+		copy(nonce[:], encryptedKey.Cipher.Nonce)
+		encryptedKey.Ciphertext = secretbox.Seal(nil, der, &nonce, &secretKey)
+		bytes, err := json.Marshal(encryptedKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: bytes}
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignIndexFile(t *testing.T) {
+	registryDirPath := t.TempDir()
+	resources := map[string][]byte{
+		filepath.Join("stacks", "go", "devfile.yaml"):                  []byte("schemaVersion: 2.2.0"),
+		filepath.Join("stacks", "java-maven", "1.1.0", "devfile.yaml"): []byte("schemaVersion: 2.1.0"),
+	}
+	for resourcePath, content := range resources {
+		if err := os.MkdirAll(filepath.Join(registryDirPath, filepath.Dir(resourcePath)), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(registryDirPath, resourcePath), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index := []schema.Schema{
+		{
+			Name:     "go",
+			Type:     schema.StackDevfileType,
+			Versions: []schema.Version{{Version: "1.0.2", Default: true, Resources: []string{"devfile.yaml"}}},
+		},
+		{
+			Name:     "java-maven",
+			Type:     schema.StackDevfileType,
+			Versions: []schema.Version{{Version: "1.1.0", Default: true, Resources: []string{"devfile.yaml"}}},
+		},
+		{
+			Name: "nodejs-basic",
+			Type: schema.SampleDevfileType,
+		},
+	}
+	indexFilePath := filepath.Join(registryDirPath, "index.json")
+	if err := CreateIndexFile(index, indexFilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		key         crypto.Signer
+		password    string
+		envPassword string
+		wantKeyType string
+		wantErr     bool
+	}{
+		{
+			name:        "Case 1: sign with ed25519 key",
+			key:         ed25519Key,
+			wantKeyType: Ed25519KeyType,
+		},
+		{
+			name:        "Case 2: sign with ECDSA key",
+			key:         ecdsaKey,
+			wantKeyType: ECDSAKeyType,
+		},
+		{
+			name:        "Case 3: sign with cosign encrypted key",
+			key:         ecdsaKey,
+			password:    "password",
+			envPassword: "password",
+			wantKeyType: ECDSAKeyType,
+		},
+		{
+			name:        "Case 4: cosign encrypted key with wrong password",
+			key:         ecdsaKey,
+			password:    "password",
+			envPassword: "wrong",
+			wantErr:     true,
+		},
+		{
+			name:    "Case 5: unsupported RSA key",
+			key:     rsaKey,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(cosignPasswordEnv, tt.envPassword)
+			keyPath := filepath.Join(t.TempDir(), "key.pem")
+			writeKey(t, keyPath, tt.key, tt.password)
+
+			err := SignIndexFile(index, registryDirPath, indexFilePath, keyPath)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Expected error: %t, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			bytes, err := os.ReadFile(indexFilePath + SignatureFileSuffix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var signature schema.IndexSignature
+			if err := json.Unmarshal(bytes, &signature); err != nil {
+				t.Fatal(err)
+			}
+			if signature.KeyType != tt.wantKeyType {
+				t.Errorf("Got key type: %s, Expected: %s", signature.KeyType, tt.wantKeyType)
+			}
+			if len(signature.Entries) != 3 {
+				t.Fatalf("Got %d entries, Expected: 3", len(signature.Entries))
+			}
+			var entries []schema.SignedEntry
+			for _, entrySignature := range signature.Entries {
+				payloadBytes, _ := base64.StdEncoding.DecodeString(entrySignature.Payload)
+				signatureBytes, _ := base64.StdEncoding.DecodeString(entrySignature.Signature)
+
+				var verified bool
+				switch publicKey := tt.key.Public().(type) {
+				case ed25519.PublicKey:
+					verified = ed25519.Verify(publicKey, payloadBytes, signatureBytes)
+				case *ecdsa.PublicKey:
+					digest := sha256.Sum256(payloadBytes)
+					verified = ecdsa.VerifyASN1(publicKey, digest[:], signatureBytes)
+				}
+				if !verified {
+					t.Errorf("Signature does not verify with the public key")
+				}
+
+				var entry schema.SignedEntry
+				if err := json.Unmarshal(payloadBytes, &entry); err != nil {
+					t.Fatal(err)
+				}
+				entries = append(entries, entry)
+			}
+			wantDigest := schema.ContentDigest(resources[filepath.Join("stacks", "java-maven", "1.1.0", "devfile.yaml")])
+			if entries[1].Resources["devfile.yaml"] != wantDigest {
+				t.Errorf("Got resource digest: %s, Expected: %s", entries[1].Resources["devfile.yaml"], wantDigest)
+			}
+		})
+	}
+}
@@ -37,6 +37,38 @@ import (
 
 var semverRe = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)$`)
 
+// DevfileNames are the file names a devfile of a stack or sample can have, in order of precedence
+var DevfileNames = []string{devfile, devfileHidden, devfileYml, devfileYmlHidden}
+
+// IsDevfileName checks if the file name is one of the devfile names
+func IsDevfileName(name string) bool {
+	return inArray(DevfileNames, name)
+}
+
+// FindDevfile returns the path of the devfile under dirPath using the default filesystem, an error is returned
+// if the directory contains no devfile or more than one devfile
+func FindDevfile(dirPath string) (string, error) {
+	return findDevfile(dirPath, filesystem.DefaultFs{})
+}
+
+// findDevfile returns the path of the devfile under dirPath
+func findDevfile(dirPath string, fs filesystem.Filesystem) (string, error) {
+	var devfiles []string
+	for _, name := range DevfileNames {
+		if _, err := fs.Stat(filepath.Join(dirPath, name)); err == nil {
+			devfiles = append(devfiles, name)
+		}
+	}
+	switch len(devfiles) {
+	case 0:
+		return "", &MissingDevfileError{dir: dirPath}
+	case 1:
+		return filepath.Join(dirPath, devfiles[0]), nil
+	default:
+		return "", &AmbiguousDevfileError{dir: dirPath, devfiles: devfiles}
+	}
+}
+
 // CloneRemoteStack downloads the stack version from a git repo outside of the registry by
 // cloning then removing the local .git folder. When git.SubDir is set, fetches specified
 // subdirectory only.
@@ -20,8 +20,10 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
 	"github.com/devfile/registry-support/index/generator/schema"
 )
 
@@ -344,3 +346,57 @@ func TestZipDir(t *testing.T) {
 		t.Logf("Deleting %s failed.", zipPath)
 	}
 }
+
+func TestFindDevfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "Case 1: devfile.yaml",
+			files: []string{"devfile.yaml", "logo.png"},
+			want:  "devfile.yaml",
+		},
+		{
+			name:  "Case 2: hidden devfile.yml",
+			files: []string{".devfile.yml"},
+			want:  ".devfile.yml",
+		},
+		{
+			name:    "Case 3: no devfile",
+			files:   []string{"logo.png"},
+			wantErr: "does not contain any of devfile.yaml, .devfile.yaml, devfile.yml, .devfile.yml",
+		},
+		{
+			name:    "Case 4: devfile.yaml and .devfile.yml",
+			files:   []string{"devfile.yaml", ".devfile.yml"},
+			wantErr: "contains more than one devfile: devfile.yaml, .devfile.yml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := filesystem.NewFakeFs()
+			for _, file := range tt.files {
+				if err := fs.WriteFile(filepath.Join("stack", file), []byte{}, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := findDevfile("stack", fs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected err: %v", err)
+			}
+			if got != filepath.Join("stack", tt.want) {
+				t.Errorf("Got: %s, Expected: %s", got, filepath.Join("stack", tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// canonicalEntry is the content of an index entry covered by the entry digest. It only holds the fields which
+// are served identically by the v2index and the index endpoints, so that clients of both can compute the digest. Icons
+// are left out as the registry may serve them base64 encoded.
+type canonicalEntry struct {
+	Name            string            `json:"name"`
+	Type            DevfileType       `json:"type"`
+	Version         string            `json:"version,omitempty"`
+	DisplayName     string            `json:"displayName,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	Links           map[string]string `json:"links,omitempty"`
+	Resources       []string          `json:"resources,omitempty"`
+	StarterProjects []string          `json:"starterProjects,omitempty"`
+	Git             *Git              `json:"git,omitempty"`
+}
+
+// EntryDigest returns the digest of the canonical index entry of a stack version or of a sample, as signed in the
+// signed entries of an index signature. versionComponent is nil for samples without versions.
+func EntryDigest(indexComponent Schema, versionComponent *Version) (string, error) {
+	entry := canonicalEntry{
+		Name:        indexComponent.Name,
+		Type:        indexComponent.Type,
+		DisplayName: indexComponent.DisplayName,
+		Description: indexComponent.Description,
+		Git:         indexComponent.Git,
+	}
+	if versionComponent != nil {
+		if indexComponent.Type == SampleDevfileType {
+			entry.Git = versionComponent.Git
+		} else {
+			entry.Version = versionComponent.Version
+			entry.Links = versionComponent.Links
+			entry.Resources = append([]string{}, versionComponent.Resources...)
+			sort.Strings(entry.Resources)
+			entry.StarterProjects = versionComponent.StarterProjects
+			entry.Git = nil
+		}
+	}
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the index entry of %s: %v", indexComponent.Name, err)
+	}
+	return ContentDigest(bytes), nil
+}
+
+// ContentDigest returns the sha256 digest of the content in the form sha256:<hex>, as used for the entry and
+// resource digests of the signed entries
+func ContentDigest(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
@@ -0,0 +1,75 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+)
+
+func TestEntryDigest(t *testing.T) {
+	stack := Schema{Name: "go", Type: StackDevfileType, Description: "Go stack"}
+	version := Version{
+		Version:   "1.0.2",
+		Links:     map[string]string{"self": "devfile-catalog/go:1.0.2"},
+		Resources: []string{"devfile.yaml", "logo.png"},
+	}
+	digest, err := EntryDigest(stack, &version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		indexComponent Schema
+		version        *Version
+		wantEqual      bool
+	}{
+		{
+			name:           "Case 1: resources in a different order",
+			indexComponent: stack,
+			version:        &Version{Version: "1.0.2", Links: version.Links, Resources: []string{"logo.png", "devfile.yaml"}},
+			wantEqual:      true,
+		},
+		{
+			name:           "Case 2: icon served base64 encoded",
+			indexComponent: Schema{Name: "go", Type: StackDevfileType, Description: "Go stack", Icon: "data:image/png;base64,AA=="},
+			version:        &version,
+			wantEqual:      true,
+		},
+		{
+			name:           "Case 3: different self link",
+			indexComponent: stack,
+			version:        &Version{Version: "1.0.2", Links: map[string]string{"self": "other/go:1.0.2"}, Resources: version.Resources},
+		},
+		{
+			name:           "Case 4: different description",
+			indexComponent: Schema{Name: "go", Type: StackDevfileType, Description: "Other stack"},
+			version:        &version,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EntryDigest(tt.indexComponent, tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == digest) != tt.wantEqual {
+				t.Errorf("Got digest: %s, original digest: %s, expected equal: %t", got, digest, tt.wantEqual)
+			}
+		})
+	}
+}
@@ -228,6 +228,7 @@ type CommandGroup struct {
 // Devfile is the devfile structure that is used by index component
 type Devfile struct {
 	Meta            Schema           `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Parent          *Parent          `yaml:"parent,omitempty" json:"parent,omitempty"`
 	StarterProjects []StarterProject `yaml:"starterProjects,omitempty" json:"starterProjects,omitempty"`
 	Commands        []Commands       `yaml:"commands,omitempty" json:"commands,omitempty"`
 	SchemaVersion   string           `yaml:"schemaVersion,omitempty" json:"schemaVersion,omitempty"`
@@ -257,6 +258,26 @@ type StackInfo struct {
 	Versions    []Version `yaml:"versions,omitempty" json:"versions,omitempty"`
 }
 
+/*
+Stack version schema definition
+version: string - The stack version
+schemaVersion: string - The devfile schema version of the stack version
+default: bool - Whether the stack version is the default version of the stack
+git: *git - The information of remote repositories
+description: string - The description of the stack version
+tags: string[] - The tags associated to the stack version
+architectures: string[] - The architectures supported by the stack version
+icon: string - The stack version icon
+links: map[string]string - Links related to the stack version
+commandGroups: map[CommandGroupKind]bool - The command groups that are used in the stack version
+deploymentScopes: map[DeploymentScopeKind]bool - The deployment scope that are detected in the stack version
+resources: []string - The file resources that compose the stack version
+starterProjects: string[] - The project templates that can be used in the stack version
+lastModified: string - The date that the stack version was last changed
+parent: *Parent - The parent devfile reference of the stack version
+dependents: string[] - The stack versions that inherit from the stack version, directly or through other parents
+*/
+
 // Version stores the information for each stack version
 type Version struct {
 	Version          string                       `yaml:"version,omitempty" json:"version,omitempty"`
@@ -273,6 +294,19 @@ type Version struct {
 	Resources        []string                     `yaml:"resources,omitempty" json:"resources,omitempty"`
 	StarterProjects  []string                     `yaml:"starterProjects,omitempty" json:"starterProjects,omitempty"`
 	LastModified     string                       `yaml:"lastModified,omitempty" json:"lastModified,omitempty"`
+	Parent           *Parent                      `yaml:"parent,omitempty" json:"parent,omitempty"`
+	Dependents       []string                     `yaml:"dependents,omitempty" json:"dependents,omitempty"`
+}
+
+// Parent stores the parent devfile reference of a stack version
+type Parent struct {
+	Id          string `yaml:"id,omitempty" json:"id,omitempty"`
+	RegistryUrl string `yaml:"registryUrl,omitempty" json:"registryUrl,omitempty"`
+	Version     string `yaml:"version,omitempty" json:"version,omitempty"`
+	Uri         string `yaml:"uri,omitempty" json:"uri,omitempty"`
+	// Resolved is the stack version within the registry that the reference points to, in the form <name>:<version>.
+	// Empty if the parent is outside of the registry.
+	Resolved string `yaml:"resolved,omitempty" json:"resolved,omitempty"`
 }
 
 type LastModifiedEntry struct {
@@ -285,3 +319,31 @@ type LastModifiedInfo struct {
 	Stacks  []LastModifiedEntry `yaml:"stacks,omitempty" json:"stacks,omitempty"`
 	Samples []LastModifiedEntry `yaml:"samples,omitempty" json:"samples,omitempty"`
 }
+
+// IndexSignature is the signature of an index file, written alongside the index file. Each stack version and sample
+// is signed on its own, so that a registry serves the signed entries of the stacks and samples a client can access.
+type IndexSignature struct {
+	// KeyType is the type of the key which signed the entries, either ed25519 or ecdsa-p256-sha256
+	KeyType string `yaml:"keyType" json:"keyType"`
+	// Entries are the signatures of the stack versions and samples of the index
+	Entries []EntrySignature `yaml:"entries,omitempty" json:"entries,omitempty"`
+}
+
+// EntrySignature is the signature of the signed entry of a stack version or sample
+type EntrySignature struct {
+	// Payload is the base64 encoded JSON of the signed entry
+	Payload string `yaml:"payload" json:"payload"`
+	// Signature is the base64 encoded signature of the payload
+	Signature string `yaml:"signature" json:"signature"`
+}
+
+// SignedEntry stores the resource digests of a stack version or sample within a signature payload
+type SignedEntry struct {
+	Name    string      `yaml:"name" json:"name"`
+	Type    DevfileType `yaml:"type" json:"type"`
+	Version string      `yaml:"version,omitempty" json:"version,omitempty"`
+	// Digest is the digest of the canonical index entry of the stack version or sample, in the form sha256:<hex>
+	Digest string `yaml:"digest,omitempty" json:"digest,omitempty"`
+	// Resources maps the name of each resource to its digest, in the form sha256:<hex>
+	Resources map[string]string `yaml:"resources,omitempty" json:"resources,omitempty"`
+}
@@ -28,4 +28,4 @@ if [ ! -e "$DEVFILE_INDEX" ]; then
 fi
 
 # Start the index server
-/registry/index-server
+/registry/index-server "$@"
@@ -0,0 +1,258 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// everyoneIdentity matches every identity, including anonymous requests
+	everyoneIdentity = "*"
+	// authenticatedIdentity matches every authenticated identity
+	authenticatedIdentity = "authenticated"
+	// groupIdentityPrefix prefixes the group names matched by a rule
+	groupIdentityPrefix = "group:"
+	// readPermission allows listing and fetching stacks and samples
+	readPermission = "read"
+	// writePermission allows publishing, updating and deleting stacks through the publish API
+	writePermission = "write"
+)
+
+// accessRules restrict the stacks and samples each identity can list and fetch and the stacks it can publish,
+// everything is readable and nothing can be published if unset
+var accessRules *AccessRules
+
+// AccessRules grant identities access to stacks and samples, a stack or sample is only accessible to the identities
+// of the rules that match it
+type AccessRules struct {
+	Rules []AccessRule `yaml:"rules"`
+}
+
+// AccessRule grants identities access to the stacks and samples matching one of its names or tags
+type AccessRule struct {
+	// Permissions are read or write, a rule without permissions grants read
+	Permissions []string `yaml:"permissions,omitempty"`
+	// Identities are user names, group names prefixed with "group:", "authenticated", "anonymous" or "*"
+	Identities []string `yaml:"identities"`
+	// Names are stack and sample name patterns, such as "java-*"
+	Names []string `yaml:"names,omitempty"`
+	// Tags are stack and sample tags
+	Tags []string `yaml:"tags,omitempty"`
+}
+
+// LoadAccessRules loads the access rules of a YAML file
+func LoadAccessRules(rulesPath string) (*AccessRules, error) {
+	/* #nosec G304 -- rulesPath is set by the registry administrator */
+	content, err := os.ReadFile(rulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the access rules file %s: %v", rulesPath, err)
+	}
+	var rules AccessRules
+	if err := yaml.UnmarshalStrict(content, &rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the access rules file %s: %v", rulesPath, err)
+	}
+	for i, rule := range rules.Rules {
+		for _, permission := range rule.Permissions {
+			if permission != readPermission && permission != writePermission {
+				return nil, fmt.Errorf("rule %d of %s has an invalid permission %s, must be %s or %s", i, rulesPath, permission, readPermission, writePermission)
+			}
+		}
+		for _, name := range rule.Names {
+			if _, err := path.Match(name, ""); err != nil {
+				return nil, fmt.Errorf("rule %d of %s has an invalid name pattern %s: %v", i, rulesPath, name, err)
+			}
+		}
+	}
+	return &rules, nil
+}
+
+// Allowed reports whether the identity can list and fetch the stack or sample
+func (r *AccessRules) Allowed(identity *Identity, devfileIndex indexSchema.Schema) bool {
+	return r.granted(readPermission, identity, devfileIndex)
+}
+
+// AllowedWrite reports whether the identity can publish, update and delete the stack
+func (r *AccessRules) AllowedWrite(identity *Identity, devfileIndex indexSchema.Schema) bool {
+	return r.granted(writePermission, identity, devfileIndex)
+}
+
+// grantsWrite reports whether any rule grants the write permission, the publish API is disabled otherwise
+func (r *AccessRules) grantsWrite() bool {
+	for _, rule := range r.Rules {
+		if rule.hasPermission(writePermission) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *AccessRules) granted(permission string, identity *Identity, devfileIndex indexSchema.Schema) bool {
+	for _, rule := range r.Rules {
+		if rule.hasPermission(permission) && rule.matchesIdentity(identity) && rule.matchesDevfile(devfileIndex) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule AccessRule) hasPermission(permission string) bool {
+	if len(rule.Permissions) == 0 {
+		return permission == readPermission
+	}
+	for _, rulePermission := range rule.Permissions {
+		if rulePermission == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule AccessRule) matchesIdentity(identity *Identity) bool {
+	for _, ruleIdentity := range rule.Identities {
+		switch {
+		case ruleIdentity == everyoneIdentity:
+			return true
+		case identity.Anonymous:
+			if ruleIdentity == anonymousIdentity.Name {
+				return true
+			}
+		case ruleIdentity == authenticatedIdentity:
+			return true
+		case strings.HasPrefix(ruleIdentity, groupIdentityPrefix):
+			for _, group := range identity.Groups {
+				if group == strings.TrimPrefix(ruleIdentity, groupIdentityPrefix) {
+					return true
+				}
+			}
+		case ruleIdentity == identity.Name:
+			return true
+		}
+	}
+	return false
+}
+
+func (rule AccessRule) matchesDevfile(devfileIndex indexSchema.Schema) bool {
+	for _, name := range rule.Names {
+		if matched, _ := path.Match(name, devfileIndex.Name); matched {
+			return true
+		}
+	}
+	for _, tag := range rule.Tags {
+		for _, devfileTag := range devfileIndex.Tags {
+			if strings.EqualFold(tag, devfileTag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// authorized reports whether the identity of the request can list and fetch the stack or sample
+func authorized(c *gin.Context, devfileIndex indexSchema.Schema) bool {
+	return accessRules == nil || accessRules.Allowed(getIdentity(c), devfileIndex)
+}
+
+// filterAuthorized filters out the stacks and samples the identity of the request cannot access, the index is
+// filtered in place
+func filterAuthorized(c *gin.Context, index []indexSchema.Schema) []indexSchema.Schema {
+	if accessRules == nil {
+		return index
+	}
+	filtered := index[:0]
+	for _, devfileIndex := range index {
+		if authorized(c, devfileIndex) {
+			filtered = append(filtered, devfileIndex)
+		}
+	}
+	return filtered
+}
+
+// authorizeStackName is the middleware of the routes serving the content of a stack, the stack name is the first
+// element of the path parameter. Stacks the identity cannot access are answered as not found.
+func authorizeStackName(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if accessRules == nil {
+			return
+		}
+		name, _, _ := strings.Cut(strings.TrimPrefix(c.Param(param), "/"), "/")
+		if !authorizedName(c, name) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"status": fmt.Sprintf("the devfile of %s didn't exist", name),
+			})
+		}
+	}
+}
+
+// authorizedName reports whether the identity of the request can access the stack or sample with the given name,
+// names which are not part of the index are not accessible
+func authorizedName(c *gin.Context, name string) bool {
+	if accessRules == nil {
+		return true
+	}
+	store, err := getIndexStore()
+	if err != nil {
+		return false
+	}
+	devfileIndex, found := store.Snapshot().Component(name)
+	return found && authorized(c, devfileIndex)
+}
+
+// authorizedSignature filters the entry signatures of the index signature down to the stacks and samples the
+// identity of the request can access, each entry is signed on its own so the filtered signature still verifies
+func authorizedSignature(c *gin.Context, signatureBytes []byte) ([]byte, error) {
+	var signature indexSchema.IndexSignature
+	if err := json.Unmarshal(signatureBytes, &signature); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the index signature: %v", err)
+	}
+	entries := []indexSchema.EntrySignature{}
+	for _, entrySignature := range signature.Entries {
+		payloadBytes, err := base64.StdEncoding.DecodeString(entrySignature.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode an entry of the index signature: %v", err)
+		}
+		var entry indexSchema.SignedEntry
+		if err := json.Unmarshal(payloadBytes, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal an entry of the index signature: %v", err)
+		}
+		if authorizedName(c, entry.Name) {
+			entries = append(entries, entrySignature)
+		}
+	}
+	signature.Entries = entries
+	return json.Marshal(signature)
+}
+
+// authorizedProxyPath reports whether the identity of the request can access an OCI registry path, such as
+// /devfile-catalog/java-quarkus/manifests/1.0.0. Only the repositories of accessible stacks and the API version
+// check are accessible when access rules are set.
+func authorizedProxyPath(c *gin.Context, proxyPath string) bool {
+	if accessRules == nil || proxyPath == "" || proxyPath == "/" {
+		return true
+	}
+	parts := strings.Split(proxyPath, "/")
+	return len(parts) > 3 && parts[1] == "devfile-catalog" && authorizedName(c, parts[2])
+}
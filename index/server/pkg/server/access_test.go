@@ -0,0 +1,434 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+)
+
+const testAccessRules = `rules:
+  - identities: ["*"]
+    names: ["go", "nodejs*"]
+  - identities: ["group:java-developers"]
+    tags: ["java"]
+  - identities: ["bob"]
+    names: ["python"]
+  - identities: ["authenticated"]
+    names: ["python-django"]
+  - identities: ["group:java-developers"]
+    names: ["java-*"]
+    permissions: ["write"]
+`
+
+var (
+	aliceIdentity = &Identity{Name: "alice", Groups: []string{"java-developers"}}
+	bobIdentity   = &Identity{Name: "bob"}
+)
+
+func loadTestAccessRules(t *testing.T) *AccessRules {
+	rules, err := LoadAccessRules(writeTestFile(t, "rules.yaml", testAccessRules))
+	if err != nil {
+		t.Fatalf("failed to load the access rules: %v", err)
+	}
+	return rules
+}
+
+// newAccessTestContext creates a test context for a request authenticated as the identity
+func newAccessTestContext(method string, target string, identity *Identity) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, nil)
+	if identity != nil {
+		c.Set(identityKey, identity)
+	}
+	return c, w
+}
+
+func TestLoadAccessRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "Case 1: Valid access rules",
+			content: testAccessRules,
+		},
+		{
+			name:    "Case 2: Invalid name pattern",
+			content: "rules:\n  - identities: [\"*\"]\n    names: [\"java-[\"]\n",
+			wantErr: true,
+		},
+		{
+			name:    "Case 3: Unknown field",
+			content: "rules:\n  - identities: [\"*\"]\n    stacks: [\"go\"]\n",
+			wantErr: true,
+		},
+		{
+			name:    "Case 4: Invalid permission",
+			content: "rules:\n  - identities: [\"*\"]\n    names: [\"go\"]\n    permissions: [\"delete\"]\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadAccessRules(writeTestFile(t, "rules.yaml", test.content))
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Got error: %v, Expected error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccessRulesAllowed(t *testing.T) {
+	rules := loadTestAccessRules(t)
+	tests := []struct {
+		name         string
+		identity     *Identity
+		devfileIndex indexSchema.Schema
+		want         bool
+	}{
+		{
+			name:         "Case 1: Name granted to everyone",
+			identity:     anonymousIdentity,
+			devfileIndex: indexSchema.Schema{Name: "nodejs-basic"},
+			want:         true,
+		},
+		{
+			name:         "Case 2: Tag granted to a group, matched case-insensitively",
+			identity:     aliceIdentity,
+			devfileIndex: indexSchema.Schema{Name: "java-maven", Tags: []string{"Java", "Maven"}},
+			want:         true,
+		},
+		{
+			name:         "Case 3: Tag not granted outside of the group",
+			identity:     bobIdentity,
+			devfileIndex: indexSchema.Schema{Name: "java-maven", Tags: []string{"Java", "Maven"}},
+			want:         false,
+		},
+		{
+			name:         "Case 4: Name granted to a user",
+			identity:     bobIdentity,
+			devfileIndex: indexSchema.Schema{Name: "python"},
+			want:         true,
+		},
+		{
+			name:         "Case 5: Name granted to authenticated identities",
+			identity:     aliceIdentity,
+			devfileIndex: indexSchema.Schema{Name: "python-django"},
+			want:         true,
+		},
+		{
+			name:         "Case 6: Name granted to authenticated identities is not granted to anonymous requests",
+			identity:     anonymousIdentity,
+			devfileIndex: indexSchema.Schema{Name: "python-django"},
+			want:         false,
+		},
+		{
+			name:         "Case 7: Stack matching no rule",
+			identity:     aliceIdentity,
+			devfileIndex: indexSchema.Schema{Name: "php-laravel", Tags: []string{"PHP"}},
+			want:         false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := rules.Allowed(test.identity, test.devfileIndex); got != test.want {
+				t.Errorf("Got allowed: %v, Expected allowed: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAccessRulesAllowedWrite(t *testing.T) {
+	rules := loadTestAccessRules(t)
+	tests := []struct {
+		name         string
+		identity     *Identity
+		devfileIndex indexSchema.Schema
+		wantWrite    bool
+		wantRead     bool
+	}{
+		{
+			name:         "Case 1: Write granted to a group",
+			identity:     aliceIdentity,
+			devfileIndex: indexSchema.Schema{Name: "java-quarkus"},
+			wantWrite:    true,
+		},
+		{
+			name:         "Case 2: Write not granted outside of the group",
+			identity:     bobIdentity,
+			devfileIndex: indexSchema.Schema{Name: "java-quarkus"},
+			wantWrite:    false,
+		},
+		{
+			name:         "Case 3: Rules without permissions only grant read",
+			identity:     anonymousIdentity,
+			devfileIndex: indexSchema.Schema{Name: "go"},
+			wantWrite:    false,
+			wantRead:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := rules.AllowedWrite(test.identity, test.devfileIndex); got != test.wantWrite {
+				t.Errorf("Got write allowed: %v, Expected write allowed: %v", got, test.wantWrite)
+			}
+			if got := rules.Allowed(test.identity, test.devfileIndex); got != test.wantRead {
+				t.Errorf("Got read allowed: %v, Expected read allowed: %v", got, test.wantRead)
+			}
+		})
+	}
+}
+
+// TestServeDevfileIndexV2Authorized tests that '/v2index' only lists the stacks and samples the identity can access
+func TestServeDevfileIndexV2Authorized(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+	setupAuthenticators(t, nil, loadTestAccessRules(t), true)
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+
+	tests := []struct {
+		name      string
+		identity  *Identity
+		wantNames []string
+	}{
+		{
+			name:      "Case 1: Anonymous request",
+			wantNames: []string{"go", "nodejs", "nodejs-basic"},
+		},
+		{
+			name:     "Case 2: Member of a group",
+			identity: aliceIdentity,
+			wantNames: []string{"code-with-quarkus", "go", "java-maven", "java-quarkus", "java-springboot", "java-vertx",
+				"java-wildfly", "java-wildfly-bootable-jar", "nodejs", "nodejs-basic", "python-django"},
+		},
+		{
+			name:      "Case 3: User",
+			identity:  bobIdentity,
+			wantNames: []string{"go", "nodejs", "nodejs-basic", "python", "python-django"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, w := newAccessTestContext(http.MethodGet, "/v2index/all", test.identity)
+			c.Params = gin.Params{gin.Param{Key: "indexType", Value: "all"}}
+
+			server.ServeDevfileIndexV2WithType(c)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Did not get expected status code, Got: %v, Expected: %v", w.Code, http.StatusOK)
+			}
+			var index []indexSchema.Schema
+			if err := json.Unmarshal(w.Body.Bytes(), &index); err != nil {
+				t.Fatalf("failed to unmarshal the index: %v", err)
+			}
+			var gotNames []string
+			for _, devfileIndex := range index {
+				gotNames = append(gotNames, devfileIndex.Name)
+			}
+			sort.Strings(gotNames)
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Errorf("Got stacks and samples: %v, Expected: %v", gotNames, test.wantNames)
+			}
+		})
+	}
+}
+
+// TestAuthorizedContent tests that the devfiles, stack content and OCI proxy answer the stacks the identity cannot
+// access as not found
+func TestAuthorizedContent(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+	setupAuthenticators(t, nil, loadTestAccessRules(t), true)
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+
+	t.Run("Case 1: Devfile of an inaccessible stack", func(t *testing.T) {
+		c, w := newAccessTestContext(http.MethodGet, "/devfiles/java-maven", bobIdentity)
+		c.Params = gin.Params{gin.Param{Key: "stack", Value: "java-maven"}}
+
+		server.ServeDevfile(c)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Did not get expected status code, Got: %v, Expected: %v", w.Code, http.StatusNotFound)
+		}
+	})
+
+	stackTests := []struct {
+		name     string
+		filepath string
+		identity *Identity
+		wantCode int
+	}{
+		{
+			name:     "Case 2: Content of an accessible stack",
+			filepath: "/java-maven/devfile.yaml",
+			identity: aliceIdentity,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Case 3: Content of an inaccessible stack",
+			filepath: "/java-maven/devfile.yaml",
+			identity: bobIdentity,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Case 4: Content of a directory which is not a stack",
+			filepath: "/../index_main.json",
+			identity: aliceIdentity,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, test := range stackTests {
+		t.Run(test.name, func(t *testing.T) {
+			c, w := newAccessTestContext(http.MethodGet, "/stacks"+test.filepath, test.identity)
+			c.Params = gin.Params{gin.Param{Key: "filepath", Value: test.filepath}}
+
+			authorizeStackName("filepath")(c)
+			c.Writer.WriteHeaderNow()
+
+			if w.Code != test.wantCode {
+				t.Errorf("Did not get expected status code, Got: %v, Expected: %v", w.Code, test.wantCode)
+			}
+		})
+	}
+
+	proxyTests := []struct {
+		name      string
+		proxyPath string
+		identity  *Identity
+		want      bool
+	}{
+		{
+			name:      "Case 5: API version check",
+			proxyPath: "/",
+			want:      true,
+		},
+		{
+			name:      "Case 6: Manifest of an accessible stack",
+			proxyPath: "/devfile-catalog/java-maven/manifests/1.1.0",
+			identity:  aliceIdentity,
+			want:      true,
+		},
+		{
+			name:      "Case 7: Manifest of an inaccessible stack",
+			proxyPath: "/devfile-catalog/java-maven/manifests/1.1.0",
+			identity:  bobIdentity,
+			want:      false,
+		},
+		{
+			name:      "Case 8: Catalog listing",
+			proxyPath: "/_catalog",
+			identity:  aliceIdentity,
+			want:      false,
+		},
+	}
+	for _, test := range proxyTests {
+		t.Run(test.name, func(t *testing.T) {
+			c, _ := newAccessTestContext(http.MethodGet, "/v2"+test.proxyPath, test.identity)
+
+			if got := authorizedProxyPath(c, test.proxyPath); got != test.want {
+				t.Errorf("Got authorized: %v, Expected authorized: %v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestServeIndexSignatureAuthorized tests that '/signature' only serves the signed entries of the stacks and samples
+// the identity can access
+func TestServeIndexSignatureAuthorized(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+	setupAuthenticators(t, nil, loadTestAccessRules(t), true)
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+
+	signature := indexSchema.IndexSignature{KeyType: "ed25519"}
+	for _, entry := range []indexSchema.SignedEntry{
+		{Name: "go", Type: indexSchema.StackDevfileType, Version: "1.0.2"},
+		{Name: "java-maven", Type: indexSchema.StackDevfileType, Version: "1.1.0"},
+	} {
+		payload, err := json.Marshal(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature.Entries = append(signature.Entries, indexSchema.EntrySignature{
+			Payload:   base64.StdEncoding.EncodeToString(payload),
+			Signature: base64.StdEncoding.EncodeToString([]byte(entry.Name)),
+		})
+	}
+	signatureBytes, err := json.Marshal(signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalSignaturePath := indexSignaturePath
+	defer func() {
+		indexSignaturePath = originalSignaturePath
+	}()
+	indexSignaturePath = writeTestFile(t, "index.json.sig", string(signatureBytes))
+
+	tests := []struct {
+		name        string
+		identity    *Identity
+		wantEntries []indexSchema.EntrySignature
+	}{
+		{
+			name:        "Case 1: Identity can access every signed stack",
+			identity:    aliceIdentity,
+			wantEntries: signature.Entries,
+		},
+		{
+			name:        "Case 2: Identity cannot access a signed stack",
+			identity:    bobIdentity,
+			wantEntries: signature.Entries[:1],
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, w := newAccessTestContext(http.MethodGet, "/signature", test.identity)
+
+			server.ServeIndexSignature(c)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Did not get expected status code, Got: %v, Expected: %v", w.Code, http.StatusOK)
+			}
+			var gotSignature indexSchema.IndexSignature
+			if err := json.Unmarshal(w.Body.Bytes(), &gotSignature); err != nil {
+				t.Fatalf("Did not expect error: %v", err)
+			}
+			if !reflect.DeepEqual(gotSignature.Entries, test.wantEntries) {
+				t.Errorf("Got entries: %v, Expected: %v", gotSignature.Entries, test.wantEntries)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v2"
+)
+
+const identityKey = "identity"
+
+var (
+	// authenticators authenticate the requests of the registry, requests are anonymous if none is configured
+	authenticators []Authenticator
+
+	anonymousIdentity = &Identity{Name: "anonymous", Anonymous: true}
+)
+
+// Identity is the user or client a request is authenticated as
+type Identity struct {
+	Name   string
+	Groups []string
+	// Anonymous is set for requests without credentials
+	Anonymous bool
+}
+
+// Authenticator authenticates the credentials of a request. A nil identity is returned without error if the
+// request has no credentials of the kind the authenticator handles, so that the next authenticator is tried.
+type Authenticator interface {
+	Authenticate(req *http.Request) (*Identity, error)
+}
+
+// TokenAuthenticator authenticates static API tokens sent as bearer tokens
+type TokenAuthenticator struct {
+	tokens []apiToken
+}
+
+// apiToken is an API token of the tokens file with the identity it authenticates
+type apiToken struct {
+	Token  string   `yaml:"token"`
+	Name   string   `yaml:"name"`
+	Groups []string `yaml:"groups,omitempty"`
+}
+
+// NewTokenAuthenticator loads the API tokens of a YAML file listing the token, name and groups of each identity
+func NewTokenAuthenticator(tokensPath string) (*TokenAuthenticator, error) {
+	/* #nosec G304 -- tokensPath is set by the registry administrator */
+	content, err := os.ReadFile(tokensPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the API tokens file %s: %v", tokensPath, err)
+	}
+	var tokensFile struct {
+		Tokens []apiToken `yaml:"tokens"`
+	}
+	if err := yaml.Unmarshal(content, &tokensFile); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the API tokens file %s: %v", tokensPath, err)
+	}
+	for i, token := range tokensFile.Tokens {
+		if token.Token == "" || token.Name == "" {
+			return nil, fmt.Errorf("API token %d of %s has no token or name", i, tokensPath)
+		}
+	}
+	return &TokenAuthenticator{tokens: tokensFile.Tokens}, nil
+}
+
+func (a *TokenAuthenticator) Authenticate(req *http.Request) (*Identity, error) {
+	token, found := bearerToken(req)
+	if !found {
+		return nil, nil
+	}
+	// Compare every token so that the response time does not depend on which token matches
+	var identity *Identity
+	for _, apiToken := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(apiToken.Token)) == 1 {
+			identity = &Identity{Name: apiToken.Name, Groups: apiToken.Groups}
+		}
+	}
+	return identity, nil
+}
+
+// HtpasswdAuthenticator authenticates HTTP basic auth credentials against a htpasswd file with bcrypt passwords
+type HtpasswdAuthenticator struct {
+	passwords map[string][]byte
+}
+
+// NewHtpasswdAuthenticator loads the users of a htpasswd file, such as created by htpasswd -B
+func NewHtpasswdAuthenticator(htpasswdPath string) (*HtpasswdAuthenticator, error) {
+	/* #nosec G304 -- htpasswdPath is set by the registry administrator */
+	content, err := os.ReadFile(htpasswdPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the htpasswd file %s: %v", htpasswdPath, err)
+	}
+	passwords := make(map[string][]byte)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, hash, found := strings.Cut(line, ":")
+		if !found || user == "" {
+			return nil, fmt.Errorf("line %d of %s is not a htpasswd entry", lineNumber, htpasswdPath)
+		}
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("password of %s in %s is not a bcrypt hash: %v", user, htpasswdPath, err)
+		}
+		passwords[user] = []byte(hash)
+	}
+	return &HtpasswdAuthenticator{passwords: passwords}, scanner.Err()
+}
+
+func (a *HtpasswdAuthenticator) Authenticate(req *http.Request) (*Identity, error) {
+	user, password, found := req.BasicAuth()
+	if !found {
+		return nil, nil
+	}
+	hash, found := a.passwords[user]
+	if !found || bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return nil, fmt.Errorf("invalid user name or password for %s", user)
+	}
+	return &Identity{Name: user}, nil
+}
+
+// bearerToken returns the bearer token of the authorization header
+func bearerToken(req *http.Request) (string, bool) {
+	return strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+}
+
+// setupAuth configures the authenticators and access rules of the registry from the environment
+func setupAuth() error {
+	authenticators = nil
+	if authTokensPath != "" {
+		tokenAuthenticator, err := NewTokenAuthenticator(authTokensPath)
+		if err != nil {
+			return err
+		}
+		authenticators = append(authenticators, tokenAuthenticator)
+	}
+	if authHtpasswdPath != "" {
+		htpasswdAuthenticator, err := NewHtpasswdAuthenticator(authHtpasswdPath)
+		if err != nil {
+			return err
+		}
+		authenticators = append(authenticators, htpasswdAuthenticator)
+	}
+	if oidcIssuer != "" {
+		if oidcJwksUrl == "" {
+			return fmt.Errorf("REGISTRY_AUTH_OIDC_JWKS_URL must be set with REGISTRY_AUTH_OIDC_ISSUER")
+		}
+		authenticators = append(authenticators, NewOIDCAuthenticator(oidcIssuer, oidcJwksUrl, oidcAudience, oidcUsernameClaim, oidcGroupsClaim))
+	}
+
+	accessRules = nil
+	if authRulesPath != "" {
+		rules, err := LoadAccessRules(authRulesPath)
+		if err != nil {
+			return err
+		}
+		accessRules = rules
+	}
+	return nil
+}
+
+// authenticate is the middleware authenticating the requests of the registry. Requests without credentials are
+// anonymous if no authenticator is configured or anonymous access is allowed, the health check is always anonymous.
+func authenticate(c *gin.Context) {
+	if len(authenticators) == 0 || c.Request.URL.Path == "/health" {
+		return
+	}
+
+	for _, authenticator := range authenticators {
+		identity, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			log.Printf("authentication failed: %v", err)
+			setUnauthorizedResponse(c)
+			return
+		}
+		if identity != nil {
+			c.Set(identityKey, identity)
+			return
+		}
+	}
+
+	// Credentials that no authenticator accepted are rejected rather than treated as anonymous
+	if c.GetHeader("Authorization") != "" || !allowAnonymous {
+		setUnauthorizedResponse(c)
+	}
+}
+
+// setUnauthorizedResponse aborts the request with the authentication challenges of the configured authenticators
+func setUnauthorizedResponse(c *gin.Context) {
+	challenges := make(map[string]bool)
+	for _, authenticator := range authenticators {
+		scheme := "Bearer"
+		if _, ok := authenticator.(*HtpasswdAuthenticator); ok {
+			scheme = "Basic"
+		}
+		if !challenges[scheme] {
+			challenges[scheme] = true
+			c.Writer.Header().Add("WWW-Authenticate", fmt.Sprintf("%s realm=%q", scheme, registry))
+		}
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"status": "authentication is required",
+	})
+}
+
+// getIdentity returns the identity the request is authenticated as
+func getIdentity(c *gin.Context) *Identity {
+	if identity, found := c.Get(identityKey); found {
+		return identity.(*Identity)
+	}
+	return anonymousIdentity
+}
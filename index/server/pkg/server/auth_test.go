@@ -0,0 +1,263 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testTokens = `tokens:
+  - token: alice-token
+    name: alice
+    groups:
+      - java-developers
+  - token: bob-token
+    name: bob
+`
+
+// setupAuthenticators configures the authenticators and access rules of the test, the previous configuration is
+// restored when the test ends
+func setupAuthenticators(t *testing.T, testAuthenticators []Authenticator, rules *AccessRules, anonymous bool) {
+	oldAuthenticators, oldAccessRules, oldAllowAnonymous := authenticators, accessRules, allowAnonymous
+	t.Cleanup(func() {
+		authenticators, accessRules, allowAnonymous = oldAuthenticators, oldAccessRules, oldAllowAnonymous
+	})
+	authenticators, accessRules, allowAnonymous = testAuthenticators, rules, anonymous
+}
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	filePath := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filePath, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", filePath, err)
+	}
+	return filePath
+}
+
+func newTestTokenAuthenticator(t *testing.T) *TokenAuthenticator {
+	tokenAuthenticator, err := NewTokenAuthenticator(writeTestFile(t, "tokens.yaml", testTokens))
+	if err != nil {
+		t.Fatalf("failed to create the token authenticator: %v", err)
+	}
+	return tokenAuthenticator
+}
+
+func newTestHtpasswdAuthenticator(t *testing.T) *HtpasswdAuthenticator {
+	hash, err := bcrypt.GenerateFromPassword([]byte("carol-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash the password: %v", err)
+	}
+	htpasswdAuthenticator, err := NewHtpasswdAuthenticator(writeTestFile(t, "htpasswd", "# users\ncarol:"+string(hash)+"\n"))
+	if err != nil {
+		t.Fatalf("failed to create the htpasswd authenticator: %v", err)
+	}
+	return htpasswdAuthenticator
+}
+
+func TestNewTokenAuthenticator(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "Case 1: Valid tokens file",
+			content: testTokens,
+		},
+		{
+			name:    "Case 2: Token without name",
+			content: "tokens:\n  - token: secret\n",
+			wantErr: true,
+		},
+		{
+			name:    "Case 3: Invalid YAML",
+			content: "tokens: {",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewTokenAuthenticator(writeTestFile(t, "tokens.yaml", test.content))
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Got error: %v, Expected error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewHtpasswdAuthenticator(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "Case 1: Empty htpasswd file",
+			content: "\n# no users yet\n",
+		},
+		{
+			name:    "Case 2: Password which is not a bcrypt hash",
+			content: "dave:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n",
+			wantErr: true,
+		},
+		{
+			name:    "Case 3: Line without password",
+			content: "dave\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewHtpasswdAuthenticator(writeTestFile(t, "htpasswd", test.content))
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Got error: %v, Expected error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testAuthenticators := []Authenticator{newTestTokenAuthenticator(t), newTestHtpasswdAuthenticator(t)}
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		setAuth       func(req *http.Request)
+		anonymous     bool
+		wantCode      int
+		wantIdentity  *Identity
+		wantChallenge []string
+	}{
+		{
+			name:   "Case 1: Valid API token",
+			method: http.MethodGet,
+			target: "/index",
+			setAuth: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer alice-token")
+			},
+			wantCode:     http.StatusOK,
+			wantIdentity: &Identity{Name: "alice", Groups: []string{"java-developers"}},
+		},
+		{
+			name:   "Case 2: Valid basic auth credentials",
+			method: http.MethodGet,
+			target: "/devfiles/go",
+			setAuth: func(req *http.Request) {
+				req.SetBasicAuth("carol", "carol-password")
+			},
+			wantCode:     http.StatusOK,
+			wantIdentity: &Identity{Name: "carol"},
+		},
+		{
+			name:   "Case 3: Invalid basic auth password",
+			method: http.MethodGet,
+			target: "/index",
+			setAuth: func(req *http.Request) {
+				req.SetBasicAuth("carol", "wrong-password")
+			},
+			wantCode:      http.StatusUnauthorized,
+			wantChallenge: []string{`Bearer realm="devfile-registry"`, `Basic realm="devfile-registry"`},
+		},
+		{
+			name:   "Case 4: Unknown API token is rejected even if anonymous access is allowed",
+			method: http.MethodGet,
+			target: "/index",
+			setAuth: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer unknown-token")
+			},
+			anonymous:     true,
+			wantCode:      http.StatusUnauthorized,
+			wantChallenge: []string{`Bearer realm="devfile-registry"`, `Basic realm="devfile-registry"`},
+		},
+		{
+			name:          "Case 5: Missing credentials",
+			method:        http.MethodGet,
+			target:        "/v2index",
+			wantCode:      http.StatusUnauthorized,
+			wantChallenge: []string{`Bearer realm="devfile-registry"`, `Basic realm="devfile-registry"`},
+		},
+		{
+			name:         "Case 6: Missing credentials with anonymous access",
+			method:       http.MethodGet,
+			target:       "/v2index",
+			anonymous:    true,
+			wantCode:     http.StatusOK,
+			wantIdentity: anonymousIdentity,
+		},
+		{
+			name:         "Case 7: Health check is anonymous",
+			method:       http.MethodGet,
+			target:       "/health",
+			wantCode:     http.StatusOK,
+			wantIdentity: anonymousIdentity,
+		},
+		{
+			name:   "Case 8: Write requests are authenticated",
+			method: http.MethodPut,
+			target: "/devfiles/go/2.0.0",
+			setAuth: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer bob-token")
+			},
+			wantCode:     http.StatusOK,
+			wantIdentity: &Identity{Name: "bob"},
+		},
+		{
+			name:          "Case 9: Write requests without credentials",
+			method:        http.MethodPut,
+			target:        "/devfiles/go/2.0.0",
+			wantCode:      http.StatusUnauthorized,
+			wantChallenge: []string{`Bearer realm="devfile-registry"`, `Basic realm="devfile-registry"`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setupAuthenticators(t, testAuthenticators, nil, test.anonymous)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(test.method, test.target, nil)
+			if test.setAuth != nil {
+				test.setAuth(c.Request)
+			}
+
+			authenticate(c)
+			c.Writer.WriteHeaderNow()
+
+			if gotCode := w.Code; gotCode != test.wantCode {
+				t.Errorf("Did not get expected status code, Got: %v, Expected: %v", gotCode, test.wantCode)
+			}
+			if wantAborted := test.wantCode != http.StatusOK; c.IsAborted() != wantAborted {
+				t.Errorf("Got aborted: %v, Expected aborted: %v", c.IsAborted(), wantAborted)
+			}
+			if gotChallenge := w.Header().Values("WWW-Authenticate"); !reflect.DeepEqual(gotChallenge, test.wantChallenge) {
+				t.Errorf("Got challenges: %v, Expected challenges: %v", gotChallenge, test.wantChallenge)
+			}
+			if test.wantIdentity != nil {
+				if gotIdentity := getIdentity(c); !reflect.DeepEqual(gotIdentity, test.wantIdentity) {
+					t.Errorf("Got identity: %+v, Expected identity: %+v", gotIdentity, test.wantIdentity)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,697 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"gopkg.in/yaml.v2"
+	"oras.land/oras-go/pkg/content"
+)
+
+const (
+	// configPathEnv is the environment variable of the configuration file path, overridden by the --config flag
+	configPathEnv = "REGISTRY_CONFIG"
+
+	clientAuthRequire       = "require"
+	clientAuthVerifyIfGiven = "verify-if-given"
+)
+
+// Config is the configuration of the registry server. Settings are read from the configuration file, then
+// from the environment variables and then from the command line flags, each overriding the previous ones.
+type Config struct {
+	Server  ListenConfig  `yaml:"server"`
+	Metrics ListenConfig  `yaml:"metrics"`
+	Storage StorageConfig `yaml:"storage"`
+	OCI     OCIConfig     `yaml:"oci"`
+	Viewer  ViewerConfig  `yaml:"viewer"`
+	// StarterProjects is the configuration of the starter project downloads
+	StarterProjects StarterProjectConfig `yaml:"starterProjects"`
+	// Telemetry is the configuration of the telemetry sinks
+	Telemetry TelemetryConfig `yaml:"telemetry"`
+	// Stats is the configuration of the download statistics
+	Stats StatsConfig `yaml:"stats"`
+	// Tracing is the configuration of the OpenTelemetry tracing
+	Tracing TracingConfig `yaml:"tracing"`
+}
+
+// ListenConfig is the configuration of a listening HTTP server
+type ListenConfig struct {
+	// Address to listen on, e.g. ':8080'
+	Address string `yaml:"address"`
+	// ReadTimeout of the requests, no timeout if zero
+	ReadTimeout time.Duration `yaml:"readTimeout"`
+	// WriteTimeout of the responses, no timeout if zero
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	// IdleTimeout of the keep-alive connections, the read timeout is used if zero
+	IdleTimeout time.Duration `yaml:"idleTimeout"`
+	// HTTP2 enables HTTP/2, disabled by default due to CVE-2023-44487
+	HTTP2 bool `yaml:"http2"`
+	// TLS of the server, plain HTTP is served if no certificate is set
+	TLS TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig is the TLS configuration of a server
+type TLSConfig struct {
+	// CertFile is the path of the PEM certificate of the server
+	CertFile string `yaml:"certFile"`
+	// KeyFile is the path of the PEM private key of the server
+	KeyFile string `yaml:"keyFile"`
+	// ClientCAFile is the path of the PEM CA certificates client certificates are verified with, enables mTLS
+	ClientCAFile string `yaml:"clientCAFile"`
+	// ClientAuth is 'require' to require client certificates or 'verify-if-given' to only verify the given ones
+	ClientAuth string `yaml:"clientAuth"`
+}
+
+// StorageConfig is the configuration of the storage of the stack versions
+type StorageConfig struct {
+	// Type of the storage, 'oci' to push the stacks to the OCI registry, 'filesystem' to serve them from the
+	// stacks folder or 'memory' to load them in memory
+	Type string `yaml:"type"`
+	// PushWorkers is the number of stack versions pushed in parallel on startup
+	PushWorkers int `yaml:"pushWorkers"`
+	// PushRetries is the number of retries of a failed push, with an exponential backoff
+	PushRetries int `yaml:"pushRetries"`
+	// PushBackoff is the delay before the first retry of a failed push, doubled on each retry
+	PushBackoff time.Duration `yaml:"pushBackoff"`
+	// AllowDegraded starts the server with the stack versions which failed to be pushed marked unavailable,
+	// instead of exiting
+	AllowDegraded bool `yaml:"allowDegraded"`
+}
+
+// OCIConfig is the configuration of the upstream OCI registry the stacks are pushed to and pulled from
+type OCIConfig struct {
+	// URL of the OCI registry, e.g. 'http://localhost:5000'
+	URL string `yaml:"url"`
+	// Username of the OCI registry credentials
+	Username string `yaml:"username"`
+	// Password of the OCI registry credentials
+	Password string `yaml:"password"`
+	// CAFile is the path of the PEM CA certificates the OCI registry certificate is verified with
+	CAFile string `yaml:"caFile"`
+	// InsecureSkipVerify skips the verification of the OCI registry certificate
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+}
+
+// ViewerConfig is the configuration of the upstream registry viewer
+type ViewerConfig struct {
+	// URL of the registry viewer, e.g. 'http://localhost:3000'
+	URL string `yaml:"url"`
+	// Timeout of the viewer responses, no timeout if zero
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// StarterProjectConfig is the configuration of the cache of the downloaded starter projects
+type StarterProjectConfig struct {
+	// CacheDir is the directory the starter project archives are cached in
+	CacheDir string `yaml:"cacheDir"`
+	// CacheTTL is the time a starter project archive is served from the cache, archives are not cached if zero
+	CacheTTL time.Duration `yaml:"cacheTTL"`
+	// CacheMaxSize is the size limit in bytes of the cache, the oldest archives are evicted above it, no limit if zero
+	CacheMaxSize int64 `yaml:"cacheMaxSize"`
+}
+
+// TelemetryConfig is the configuration of the telemetry sinks, the events are queued and sent in batches to
+// every configured sink
+type TelemetryConfig struct {
+	// Sinks is the comma separated list of sinks, 'segment', 'otlp', 'jsonl' and 'webhook'. Segment is used
+	// if unset and the Segment key is set.
+	Sinks string `yaml:"sinks"`
+	// SegmentKey is the write key of the Segment sink
+	SegmentKey string `yaml:"segmentKey"`
+	// OTLPEndpoint is the OTLP/HTTP logs endpoint of the OTLP sink, e.g. 'http://otel-collector:4318/v1/logs'
+	OTLPEndpoint string `yaml:"otlpEndpoint"`
+	// JSONLFile is the path of the file the JSONL sink appends the events to
+	JSONLFile string `yaml:"jsonlFile"`
+	// WebhookURL is the URL the webhook sink posts the batches of events to
+	WebhookURL string `yaml:"webhookURL"`
+	// QueueSize is the number of events waiting to be sent, events are dropped when the queue is full
+	QueueSize int `yaml:"queueSize"`
+	// BatchSize is the maximum number of events sent at once
+	BatchSize int `yaml:"batchSize"`
+	// FlushInterval is the maximum time an event waits for its batch to be sent
+	FlushInterval time.Duration `yaml:"flushInterval"`
+}
+
+// StatsConfig is the configuration of the download statistics recorded by the registry
+type StatsConfig struct {
+	// File is the path of the bolt database of the statistics, the statistics are disabled if empty
+	File string `yaml:"file"`
+	// FlushInterval is the interval the recorded events are written to the database at
+	FlushInterval time.Duration `yaml:"flushInterval"`
+	// Retention is the time the daily counts are kept, forever if zero
+	Retention time.Duration `yaml:"retention"`
+}
+
+// TracingConfig is the configuration of the OpenTelemetry tracing, the W3C trace context of the requests is
+// propagated to the upstreams even if the spans are not exported
+type TracingConfig struct {
+	// OTLPEndpoint is the OTLP/HTTP traces endpoint the spans are exported to, e.g.
+	// 'http://otel-collector:4318/v1/traces', the spans are not exported if empty
+	OTLPEndpoint string `yaml:"otlpEndpoint"`
+	// SampleRatio is the ratio of the traces started by the registry which are sampled, the traces of the
+	// requests with a trace context follow the sampling decision of the client
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// configOption is a setting of the configuration that can be set by environment variable and flag
+type configOption struct {
+	flag  string
+	env   string
+	usage string
+	field func(*Config) interface{}
+}
+
+var configOptions = []configOption{
+	{"address", "REGISTRY_ADDRESS", "address the registry listens on", func(c *Config) interface{} { return &c.Server.Address }},
+	{"read-timeout", "REGISTRY_READ_TIMEOUT", "read timeout of the registry requests", func(c *Config) interface{} { return &c.Server.ReadTimeout }},
+	{"write-timeout", "REGISTRY_WRITE_TIMEOUT", "write timeout of the registry responses", func(c *Config) interface{} { return &c.Server.WriteTimeout }},
+	{"idle-timeout", "REGISTRY_IDLE_TIMEOUT", "idle timeout of the registry connections", func(c *Config) interface{} { return &c.Server.IdleTimeout }},
+	{"http2", "ENABLE_HTTP2", "enables HTTP/2", func(c *Config) interface{} { return &c.Server.HTTP2 }},
+	{"tls-cert", "REGISTRY_TLS_CERT", "path of the TLS certificate", func(c *Config) interface{} { return &c.Server.TLS.CertFile }},
+	{"tls-key", "REGISTRY_TLS_KEY", "path of the TLS private key", func(c *Config) interface{} { return &c.Server.TLS.KeyFile }},
+	{"tls-client-ca", "REGISTRY_TLS_CLIENT_CA", "path of the CA certificates of the mTLS client certificates", func(c *Config) interface{} { return &c.Server.TLS.ClientCAFile }},
+	{"tls-client-auth", "REGISTRY_TLS_CLIENT_AUTH", "mTLS client authentication, 'require' or 'verify-if-given'", func(c *Config) interface{} { return &c.Server.TLS.ClientAuth }},
+	{"metrics-address", "REGISTRY_METRICS_ADDRESS", "address the metrics server listens on", func(c *Config) interface{} { return &c.Metrics.Address }},
+	{"metrics-read-timeout", "REGISTRY_METRICS_READ_TIMEOUT", "read timeout of the metrics requests", func(c *Config) interface{} { return &c.Metrics.ReadTimeout }},
+	{"metrics-write-timeout", "REGISTRY_METRICS_WRITE_TIMEOUT", "write timeout of the metrics responses", func(c *Config) interface{} { return &c.Metrics.WriteTimeout }},
+	{"storage", "REGISTRY_STORAGE", "storage of the stacks, 'oci', 'filesystem' or 'memory'", func(c *Config) interface{} { return &c.Storage.Type }},
+	{"push-workers", "REGISTRY_PUSH_WORKERS", "number of stack versions pushed in parallel on startup", func(c *Config) interface{} { return &c.Storage.PushWorkers }},
+	{"push-retries", "REGISTRY_PUSH_RETRIES", "number of retries of a failed push on startup", func(c *Config) interface{} { return &c.Storage.PushRetries }},
+	{"push-backoff", "REGISTRY_PUSH_BACKOFF", "delay before the first retry of a failed push", func(c *Config) interface{} { return &c.Storage.PushBackoff }},
+	{"allow-degraded", "REGISTRY_ALLOW_DEGRADED", "starts with the stacks failing to be pushed marked unavailable", func(c *Config) interface{} { return &c.Storage.AllowDegraded }},
+	{"oci-url", "REGISTRY_OCI_URL", "URL of the upstream OCI registry", func(c *Config) interface{} { return &c.OCI.URL }},
+	{"oci-username", "REGISTRY_OCI_USERNAME", "username of the upstream OCI registry", func(c *Config) interface{} { return &c.OCI.Username }},
+	{"oci-password", "REGISTRY_OCI_PASSWORD", "password of the upstream OCI registry", func(c *Config) interface{} { return &c.OCI.Password }},
+	{"oci-ca", "REGISTRY_OCI_CA", "path of the CA certificates of the upstream OCI registry", func(c *Config) interface{} { return &c.OCI.CAFile }},
+	{"oci-insecure-skip-verify", "REGISTRY_OCI_INSECURE_SKIP_VERIFY", "skips the verification of the OCI registry certificate", func(c *Config) interface{} { return &c.OCI.InsecureSkipVerify }},
+	{"viewer-url", "REGISTRY_VIEWER_URL", "URL of the registry viewer", func(c *Config) interface{} { return &c.Viewer.URL }},
+	{"viewer-timeout", "REGISTRY_VIEWER_TIMEOUT", "timeout of the registry viewer responses", func(c *Config) interface{} { return &c.Viewer.Timeout }},
+	{"starter-project-cache-dir", "REGISTRY_STARTER_PROJECT_CACHE_DIR", "directory the starter projects are cached in", func(c *Config) interface{} { return &c.StarterProjects.CacheDir }},
+	{"starter-project-cache-ttl", "REGISTRY_STARTER_PROJECT_CACHE_TTL", "time the starter projects are cached, disabled if zero", func(c *Config) interface{} { return &c.StarterProjects.CacheTTL }},
+	{"starter-project-cache-max-size", "REGISTRY_STARTER_PROJECT_CACHE_MAX_SIZE", "size limit in bytes of the starter project cache", func(c *Config) interface{} { return &c.StarterProjects.CacheMaxSize }},
+	{"stats-file", "REGISTRY_STATS_FILE", "path of the download statistics database, disabled if empty", func(c *Config) interface{} { return &c.Stats.File }},
+	{"stats-flush-interval", "REGISTRY_STATS_FLUSH_INTERVAL", "interval the download statistics are written at", func(c *Config) interface{} { return &c.Stats.FlushInterval }},
+	{"stats-retention", "REGISTRY_STATS_RETENTION", "time the download statistics are kept, forever if zero", func(c *Config) interface{} { return &c.Stats.Retention }},
+	{"telemetry-sinks", "REGISTRY_TELEMETRY_SINKS", "comma separated telemetry sinks, 'segment', 'otlp', 'jsonl' or 'webhook'", func(c *Config) interface{} { return &c.Telemetry.Sinks }},
+	{"telemetry-key", "TELEMETRY_KEY", "write key of the Segment telemetry sink", func(c *Config) interface{} { return &c.Telemetry.SegmentKey }},
+	{"telemetry-otlp-endpoint", "REGISTRY_TELEMETRY_OTLP_ENDPOINT", "OTLP/HTTP logs endpoint of the OTLP telemetry sink", func(c *Config) interface{} { return &c.Telemetry.OTLPEndpoint }},
+	{"telemetry-jsonl-file", "REGISTRY_TELEMETRY_JSONL_FILE", "path of the file of the JSONL telemetry sink", func(c *Config) interface{} { return &c.Telemetry.JSONLFile }},
+	{"telemetry-webhook-url", "REGISTRY_TELEMETRY_WEBHOOK_URL", "URL of the webhook telemetry sink", func(c *Config) interface{} { return &c.Telemetry.WebhookURL }},
+	{"telemetry-queue-size", "REGISTRY_TELEMETRY_QUEUE_SIZE", "number of telemetry events waiting to be sent", func(c *Config) interface{} { return &c.Telemetry.QueueSize }},
+	{"telemetry-batch-size", "REGISTRY_TELEMETRY_BATCH_SIZE", "maximum number of telemetry events sent at once", func(c *Config) interface{} { return &c.Telemetry.BatchSize }},
+	{"telemetry-flush-interval", "REGISTRY_TELEMETRY_FLUSH_INTERVAL", "maximum time a telemetry event waits to be sent", func(c *Config) interface{} { return &c.Telemetry.FlushInterval }},
+	{"tracing-otlp-endpoint", "REGISTRY_TRACING_OTLP_ENDPOINT", "OTLP/HTTP traces endpoint the spans are exported to", func(c *Config) interface{} { return &c.Tracing.OTLPEndpoint }},
+	{"tracing-sample-ratio", "REGISTRY_TRACING_SAMPLE_RATIO", "ratio of the traces started by the registry which are sampled", func(c *Config) interface{} { return &c.Tracing.SampleRatio }},
+}
+
+var (
+	// serverConfig is the configuration of the running registry server
+	serverConfig = DefaultConfig()
+	// ociClient is the HTTP client of the OCI registry
+	ociClient = &http.Client{Transport: http.DefaultTransport}
+	// viewerTransport is the HTTP transport of the registry viewer proxy
+	viewerTransport = http.DefaultTransport
+)
+
+// DefaultConfig returns the default configuration, serving the registry on :8080 and the metrics on :7071
+// with the OCI registry and registry viewer running alongside
+func DefaultConfig() *Config {
+	return &Config{
+		Server: ListenConfig{
+			Address: ":8080",
+		},
+		Metrics: ListenConfig{
+			Address:      ":7071",
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		},
+		Storage: StorageConfig{
+			Type:        ociStorageType,
+			PushWorkers: 4,
+			PushRetries: 3,
+			PushBackoff: time.Second,
+		},
+		OCI: OCIConfig{
+			URL: "http://localhost:5000",
+		},
+		Viewer: ViewerConfig{
+			URL: "http://localhost:3000",
+		},
+		StarterProjects: StarterProjectConfig{
+			CacheDir:     filepath.Join(os.TempDir(), "registry-starter-projects"),
+			CacheTTL:     time.Hour,
+			CacheMaxSize: 512 << 20,
+		},
+		Stats: StatsConfig{
+			FlushInterval: 10 * time.Second,
+			Retention:     365 * 24 * time.Hour,
+		},
+		Telemetry: TelemetryConfig{
+			QueueSize:     1000,
+			BatchSize:     100,
+			FlushInterval: 5 * time.Second,
+		},
+		Tracing: TracingConfig{
+			SampleRatio: 1,
+		},
+	}
+}
+
+// optionValue is the flag value of a configuration option, only applied if the flag is set
+type optionValue struct {
+	value   string
+	boolean bool
+}
+
+func (v *optionValue) String() string {
+	return v.value
+}
+
+func (v *optionValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *optionValue) IsBoolFlag() bool {
+	return v.boolean
+}
+
+// setOption sets the configuration field to the string value of an environment variable or flag
+func setOption(field interface{}, value string) error {
+	switch field := field.(type) {
+	case *string:
+		*field = value
+	case *int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+	case *int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+	case *float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+	case *time.Duration:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+	default:
+		return fmt.Errorf("unsupported option type %T", field)
+	}
+	return nil
+}
+
+// LoadConfig loads the configuration from the configuration file, the environment variables and the command
+// line arguments
+func LoadConfig(args []string) (*Config, error) {
+	config := DefaultConfig()
+
+	flags := flag.NewFlagSet("registry-server", flag.ContinueOnError)
+	configPath := flags.String("config", os.Getenv(configPathEnv), "path of the YAML configuration file")
+	values := make([]*optionValue, len(configOptions))
+	for i, option := range configOptions {
+		_, boolean := option.field(config).(*bool)
+		values[i] = &optionValue{boolean: boolean}
+		flags.Var(values[i], option.flag, fmt.Sprintf("%s (env %s)", option.usage, option.env))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configPath != "" {
+		/* #nosec G304 -- the configuration file is given by the registry administrator */
+		bytes, err := os.ReadFile(*configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the configuration file %s: %v", *configPath, err)
+		}
+		if err = yaml.UnmarshalStrict(bytes, config); err != nil {
+			return nil, fmt.Errorf("failed to parse the configuration file %s: %v", *configPath, err)
+		}
+	}
+
+	for _, option := range configOptions {
+		if value, found := os.LookupEnv(option.env); found && value != "" {
+			if err := setOption(option.field(config), value); err != nil {
+				return nil, fmt.Errorf("invalid value of %s: %v", option.env, err)
+			}
+		}
+	}
+
+	setFlags := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+	for i, option := range configOptions {
+		if setFlags[option.flag] {
+			if err := setOption(option.field(config), values[i].value); err != nil {
+				return nil, fmt.Errorf("invalid value of --%s: %v", option.flag, err)
+			}
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks the consistency of the configuration
+func (c *Config) Validate() error {
+	for name, listen := range map[string]ListenConfig{"server": c.Server, "metrics": c.Metrics} {
+		if listen.Address == "" {
+			return fmt.Errorf("the %s address is not set", name)
+		}
+		if (listen.TLS.CertFile == "") != (listen.TLS.KeyFile == "") {
+			return fmt.Errorf("the %s TLS certificate and key must be set together", name)
+		}
+		if listen.TLS.ClientCAFile != "" && listen.TLS.CertFile == "" {
+			return fmt.Errorf("the %s mTLS client CA requires a TLS certificate", name)
+		}
+		if listen.TLS.ClientAuth != "" && listen.TLS.ClientAuth != clientAuthRequire && listen.TLS.ClientAuth != clientAuthVerifyIfGiven {
+			return fmt.Errorf("the %s mTLS client authentication %s is not one of %s or %s", name, listen.TLS.ClientAuth,
+				clientAuthRequire, clientAuthVerifyIfGiven)
+		}
+	}
+
+	if _, err := newStackStorage(c.Storage.Type); err != nil {
+		return err
+	}
+	if c.Storage.PushWorkers < 1 {
+		return fmt.Errorf("the number of push workers must be at least 1")
+	}
+	if c.Storage.PushRetries < 0 || c.Storage.PushBackoff < 0 {
+		return fmt.Errorf("the push retries and backoff cannot be negative")
+	}
+	if c.StarterProjects.CacheTTL < 0 || c.StarterProjects.CacheMaxSize < 0 {
+		return fmt.Errorf("the starter project cache TTL and size limit cannot be negative")
+	}
+	if c.StarterProjects.CacheTTL > 0 && c.StarterProjects.CacheDir == "" {
+		return fmt.Errorf("the starter project cache directory is not set")
+	}
+
+	if c.Stats.File != "" && c.Stats.FlushInterval <= 0 {
+		return fmt.Errorf("the download statistics flush interval must be positive")
+	}
+	if c.Stats.Retention < 0 {
+		return fmt.Errorf("the download statistics retention cannot be negative")
+	}
+	if err := c.Telemetry.validate(); err != nil {
+		return err
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("the tracing sample ratio must be between 0 and 1")
+	}
+
+	urls := map[string]string{"OCI registry": c.OCI.URL, "registry viewer": c.Viewer.URL}
+	if c.Tracing.OTLPEndpoint != "" {
+		urls["tracing OTLP endpoint"] = c.Tracing.OTLPEndpoint
+	}
+	for name, rawUrl := range urls {
+		parsed, err := url.Parse(rawUrl)
+		if err != nil {
+			return fmt.Errorf("the %s URL %s is not valid: %v", name, rawUrl, err)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("the %s URL %s must be an absolute http or https URL", name, rawUrl)
+		}
+	}
+	return nil
+}
+
+// loadCertPool loads the PEM CA certificates of a file into a certificate pool
+func loadCertPool(path string) (*x509.CertPool, error) {
+	/* #nosec G304 -- the CA file is given by the registry administrator */
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA certificates %s: %v", path, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bytes) {
+		return nil, fmt.Errorf("no PEM certificate found in %s", path)
+	}
+	return pool, nil
+}
+
+// newHTTPServer creates the HTTP server of the listen configuration, with its TLS configuration if TLS is enabled
+func (l ListenConfig) newHTTPServer(handler http.Handler) (*http.Server, error) {
+	httpServer := &http.Server{
+		Addr:         l.Address,
+		Handler:      handler,
+		ReadTimeout:  l.ReadTimeout,
+		WriteTimeout: l.WriteTimeout,
+		IdleTimeout:  l.IdleTimeout,
+	}
+
+	// Disable HTTP2 by default
+	if !l.HTTP2 {
+		httpServer.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+
+	if l.TLS.ClientCAFile != "" {
+		clientCAs, err := loadCertPool(l.TLS.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		httpServer.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientCAs:  clientCAs,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+		if l.TLS.ClientAuth == clientAuthVerifyIfGiven {
+			httpServer.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+	return httpServer, nil
+}
+
+// listenAndServe serves HTTPS if a TLS certificate is set and plain HTTP otherwise
+func (l ListenConfig) listenAndServe(httpServer *http.Server) error {
+	if l.TLS.CertFile != "" {
+		return httpServer.ListenAndServeTLS(l.TLS.CertFile, l.TLS.KeyFile)
+	}
+	return httpServer.ListenAndServe()
+}
+
+// url returns the parsed OCI registry URL, the URL is checked when the configuration is loaded
+func (o OCIConfig) url() *url.URL {
+	parsed, err := url.Parse(o.URL)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// host returns the host of the OCI registry the stack references are prefixed with
+func (o OCIConfig) host() string {
+	return o.url().Host
+}
+
+// httpClient returns the HTTP client of the OCI registry, verifying its certificate with the configured CA
+func (o OCIConfig) httpClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.CAFile != "" || o.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			/* #nosec G402 -- skipping the verification is explicitly configured */
+			InsecureSkipVerify: o.InsecureSkipVerify,
+		}
+		if o.CAFile != "" {
+			rootCAs, err := loadCertPool(o.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			transport.TLSClientConfig.RootCAs = rootCAs
+		}
+	}
+	return &http.Client{Transport: tracedTransport(transport, "oci")}, nil
+}
+
+// applyConfig sets the configuration of the running registry server and the clients of its upstreams
+func applyConfig(config *Config) error {
+	client, err := config.OCI.httpClient()
+	if err != nil {
+		return fmt.Errorf("failed to set up the OCI registry client: %v", err)
+	}
+	storage, err := newStackStorage(config.Storage.Type)
+	if err != nil {
+		return err
+	}
+	telemetry, err := config.Telemetry.newTelemetry()
+	if err != nil {
+		return fmt.Errorf("failed to set up the telemetry: %v", err)
+	}
+	serverConfig = config
+	stackStorage = storage
+	ociClient = client
+	viewerTransport = config.Viewer.transport()
+	starterProjectsCache = newStarterProjectCache(config.StarterProjects)
+	util.SetTelemetry(telemetry)
+	enableTelemetry = telemetry != nil
+	return nil
+}
+
+// sinkTypes returns the configured telemetry sinks, Segment if none is configured and the Segment key is set
+func (t TelemetryConfig) sinkTypes() []string {
+	sinkTypes := []string{}
+	for _, sinkType := range strings.Split(t.Sinks, ",") {
+		if sinkType = strings.TrimSpace(sinkType); sinkType != "" {
+			sinkTypes = append(sinkTypes, sinkType)
+		}
+	}
+	if len(sinkTypes) == 0 && t.SegmentKey != "" {
+		sinkTypes = append(sinkTypes, util.SegmentSinkType)
+	}
+	return sinkTypes
+}
+
+// validate checks that every configured sink is known and has its settings
+func (t TelemetryConfig) validate() error {
+	if t.QueueSize < 1 || t.BatchSize < 1 || t.FlushInterval <= 0 {
+		return fmt.Errorf("the telemetry queue size, batch size and flush interval must be positive")
+	}
+	for _, sinkType := range t.sinkTypes() {
+		var setting string
+		switch sinkType {
+		case util.SegmentSinkType:
+			setting = t.SegmentKey
+		case util.OTLPSinkType:
+			setting = t.OTLPEndpoint
+		case util.JSONLSinkType:
+			setting = t.JSONLFile
+		case util.WebhookSinkType:
+			setting = t.WebhookURL
+		default:
+			return fmt.Errorf("the telemetry sink %s is not one of %s, %s, %s or %s", sinkType, util.SegmentSinkType,
+				util.OTLPSinkType, util.JSONLSinkType, util.WebhookSinkType)
+		}
+		if setting == "" {
+			return fmt.Errorf("the %s telemetry sink is not configured", sinkType)
+		}
+		if sinkType == util.OTLPSinkType || sinkType == util.WebhookSinkType {
+			parsed, err := url.Parse(setting)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("the %s telemetry sink URL %s must be an absolute http or https URL", sinkType, setting)
+			}
+		}
+	}
+	return nil
+}
+
+// newTelemetry creates the dispatcher of the configured telemetry sinks, nil if no sink is configured
+func (t TelemetryConfig) newTelemetry() (*util.Telemetry, error) {
+	sinks := []util.TelemetrySink{}
+	for _, sinkType := range t.sinkTypes() {
+		var sink util.TelemetrySink
+		var err error
+		switch sinkType {
+		case util.SegmentSinkType:
+			sink = util.NewSegmentSink(t.SegmentKey)
+		case util.OTLPSinkType:
+			sink, err = util.NewOTLPSink(t.OTLPEndpoint, fmt.Sprint(registry))
+		case util.JSONLSinkType:
+			sink, err = util.NewJSONLSink(t.JSONLFile)
+		case util.WebhookSinkType:
+			sink = util.NewWebhookSink(t.WebhookURL)
+		}
+		if err != nil {
+			for _, opened := range sinks {
+				_ = opened.Close()
+			}
+			return nil, err
+		}
+		if sink != nil {
+			sinks = append(sinks, sink)
+		}
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return util.NewTelemetry(sinks, t.QueueSize, t.BatchSize, t.FlushInterval), nil
+}
+
+// newTracerProvider creates the tracer provider exporting the spans to the OTLP endpoint in batches, nil if
+// the spans are not exported
+func (t TracingConfig) newTracerProvider() (*sdktrace.TracerProvider, error) {
+	if t.OTLPEndpoint == "" {
+		return nil, nil
+	}
+	exporter, err := util.NewOTLPSpanExporter(t.OTLPEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", fmt.Sprint(registry)))),
+	), nil
+}
+
+// registry returns the oras store of the OCI registry
+func (o OCIConfig) registry() *content.Registry {
+	options := docker.ResolverOptions{
+		PlainHTTP: o.url().Scheme == "http",
+		Client:    ociClient,
+	}
+	if o.Username != "" || o.Password != "" {
+		options.Credentials = func(string) (string, string, error) {
+			return o.Username, o.Password, nil
+		}
+	}
+	return &content.Registry{Resolver: docker.NewResolver(options)}
+}
+
+// transport returns the HTTP transport of the registry viewer proxy
+func (v ViewerConfig) transport() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if v.Timeout > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: v.Timeout}).DialContext
+		transport.ResponseHeaderTimeout = v.Timeout
+	}
+	return tracedTransport(transport, "viewer")
+}
@@ -0,0 +1,358 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devfile/registry-support/index/server/pkg/ocitest"
+	"github.com/gin-gonic/gin"
+)
+
+// writeTestCA writes the certificate of a TLS test server as a PEM CA file
+func writeTestCA(t *testing.T, server *httptest.Server) string {
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return writeTestFile(t, "ca.pem", string(certificate))
+}
+
+func TestLoadConfig(t *testing.T) {
+	configFile := writeTestFile(t, "config.yaml", `
+server:
+  address: ":8443"
+  readTimeout: 30s
+oci:
+  url: https://oci.example.com
+  username: file-user
+viewer:
+  url: http://viewer:3000
+  timeout: 5s
+`)
+
+	tests := []struct {
+		name       string
+		args       []string
+		env        map[string]string
+		wantConfig func(*Config)
+		wantErr    string
+	}{
+		{
+			name:       "Case 1: Defaults",
+			wantConfig: func(*Config) {},
+		},
+		{
+			name: "Case 2: Configuration file",
+			args: []string{"--config", configFile},
+			wantConfig: func(c *Config) {
+				c.Server.Address = ":8443"
+				c.Server.ReadTimeout = 30 * time.Second
+				c.OCI.URL = "https://oci.example.com"
+				c.OCI.Username = "file-user"
+				c.Viewer.URL = "http://viewer:3000"
+				c.Viewer.Timeout = 5 * time.Second
+			},
+		},
+		{
+			name: "Case 3: Environment variables override the configuration file",
+			env: map[string]string{
+				configPathEnv:           configFile,
+				"REGISTRY_OCI_USERNAME": "env-user",
+				"REGISTRY_READ_TIMEOUT": "1m",
+				"ENABLE_HTTP2":          "true",
+			},
+			wantConfig: func(c *Config) {
+				c.Server.Address = ":8443"
+				c.Server.ReadTimeout = time.Minute
+				c.Server.HTTP2 = true
+				c.OCI.URL = "https://oci.example.com"
+				c.OCI.Username = "env-user"
+				c.Viewer.URL = "http://viewer:3000"
+				c.Viewer.Timeout = 5 * time.Second
+			},
+		},
+		{
+			name: "Case 4: Flags override the environment variables",
+			args: []string{"--config", configFile, "--oci-username", "flag-user", "--http2", "--metrics-address", ":9090"},
+			env: map[string]string{
+				"REGISTRY_OCI_USERNAME": "env-user",
+				"ENABLE_HTTP2":          "false",
+			},
+			wantConfig: func(c *Config) {
+				c.Server.Address = ":8443"
+				c.Server.ReadTimeout = 30 * time.Second
+				c.Server.HTTP2 = true
+				c.Metrics.Address = ":9090"
+				c.OCI.URL = "https://oci.example.com"
+				c.OCI.Username = "flag-user"
+				c.Viewer.URL = "http://viewer:3000"
+				c.Viewer.Timeout = 5 * time.Second
+			},
+		},
+		{
+			name:    "Case 5: Invalid duration",
+			env:     map[string]string{"REGISTRY_VIEWER_TIMEOUT": "soon"},
+			wantErr: "invalid value of REGISTRY_VIEWER_TIMEOUT",
+		},
+		{
+			name:    "Case 6: TLS key without certificate",
+			args:    []string{"--tls-key", "key.pem"},
+			wantErr: "the server TLS certificate and key must be set together",
+		},
+		{
+			name:    "Case 7: mTLS without TLS",
+			args:    []string{"--tls-client-ca", "ca.pem"},
+			wantErr: "the server mTLS client CA requires a TLS certificate",
+		},
+		{
+			name:    "Case 8: Relative OCI URL",
+			args:    []string{"--oci-url", "localhost:5000"},
+			wantErr: "the OCI registry URL localhost:5000 must be an absolute http or https URL",
+		},
+		{
+			name:    "Case 9: Unknown configuration file field",
+			args:    []string{"--config", writeTestFile(t, "unknown.yaml", "oci:\n  uri: http://localhost:5000\n")},
+			wantErr: "failed to parse the configuration file",
+		},
+		{
+			name: "Case 10: Starter project cache",
+			env: map[string]string{
+				"REGISTRY_STARTER_PROJECT_CACHE_TTL":      "10m",
+				"REGISTRY_STARTER_PROJECT_CACHE_MAX_SIZE": "1048576",
+			},
+			wantConfig: func(c *Config) {
+				c.StarterProjects.CacheTTL = 10 * time.Minute
+				c.StarterProjects.CacheMaxSize = 1 << 20
+			},
+		},
+		{
+			name:    "Case 11: Negative starter project cache size",
+			args:    []string{"--starter-project-cache-max-size", "-1"},
+			wantErr: "the starter project cache TTL and size limit cannot be negative",
+		},
+		{
+			name: "Case 12: Telemetry sinks",
+			env: map[string]string{
+				"REGISTRY_TELEMETRY_SINKS":          "jsonl, webhook",
+				"REGISTRY_TELEMETRY_JSONL_FILE":     "/var/log/registry/telemetry.jsonl",
+				"REGISTRY_TELEMETRY_WEBHOOK_URL":    "https://telemetry.example.com/events",
+				"REGISTRY_TELEMETRY_FLUSH_INTERVAL": "1s",
+			},
+			wantConfig: func(c *Config) {
+				c.Telemetry.Sinks = "jsonl, webhook"
+				c.Telemetry.JSONLFile = "/var/log/registry/telemetry.jsonl"
+				c.Telemetry.WebhookURL = "https://telemetry.example.com/events"
+				c.Telemetry.FlushInterval = time.Second
+			},
+		},
+		{
+			name:    "Case 13: Telemetry sink without its settings",
+			args:    []string{"--telemetry-sinks", "otlp"},
+			wantErr: "the otlp telemetry sink is not configured",
+		},
+		{
+			name:    "Case 14: Unknown telemetry sink",
+			args:    []string{"--telemetry-sinks", "kafka"},
+			wantErr: "the telemetry sink kafka is not one of segment, otlp, jsonl or webhook",
+		},
+		{
+			name: "Case 15: Tracing",
+			env: map[string]string{
+				"REGISTRY_TRACING_OTLP_ENDPOINT": "http://otel-collector:4318/v1/traces",
+				"REGISTRY_TRACING_SAMPLE_RATIO":  "0.25",
+			},
+			wantConfig: func(c *Config) {
+				c.Tracing.OTLPEndpoint = "http://otel-collector:4318/v1/traces"
+				c.Tracing.SampleRatio = 0.25
+			},
+		},
+		{
+			name:    "Case 16: Tracing sample ratio above 1",
+			args:    []string{"--tracing-sample-ratio", "2"},
+			wantErr: "the tracing sample ratio must be between 0 and 1",
+		},
+		{
+			name:    "Case 17: Relative tracing endpoint",
+			args:    []string{"--tracing-otlp-endpoint", "otel-collector:4318"},
+			wantErr: "the tracing OTLP endpoint URL otel-collector:4318 must be an absolute http or https URL",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, option := range configOptions {
+				t.Setenv(option.env, "")
+			}
+			t.Setenv(configPathEnv, "")
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			gotConfig, err := LoadConfig(test.args)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Expected error containing %q, got: %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			wantConfig := DefaultConfig()
+			test.wantConfig(wantConfig)
+			if !reflect.DeepEqual(gotConfig, wantConfig) {
+				t.Errorf("Got: %+v, Expected: %+v", gotConfig, wantConfig)
+			}
+		})
+	}
+}
+
+func TestTelemetrySinkTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		config TelemetryConfig
+		want   []string
+	}{
+		{
+			name: "Case 1: No sink",
+			want: []string{},
+		},
+		{
+			name:   "Case 2: Segment key without sinks",
+			config: TelemetryConfig{SegmentKey: "key"},
+			want:   []string{"segment"},
+		},
+		{
+			name:   "Case 3: Sinks",
+			config: TelemetryConfig{Sinks: "otlp,,jsonl ", SegmentKey: "key"},
+			want:   []string{"otlp", "jsonl"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.config.sinkTypes(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Got: %v, Expected: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewHTTPServer(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsServer.Close()
+	clientCA := writeTestCA(t, tlsServer)
+
+	listen := ListenConfig{
+		Address:     ":8443",
+		ReadTimeout: 5 * time.Second,
+		TLS: TLSConfig{
+			CertFile:     "cert.pem",
+			KeyFile:      "key.pem",
+			ClientCAFile: clientCA,
+			ClientAuth:   clientAuthVerifyIfGiven,
+		},
+	}
+	httpServer, err := listen.newHTTPServer(http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if httpServer.Addr != ":8443" || httpServer.ReadTimeout != 5*time.Second {
+		t.Errorf("Got address %s and read timeout %v, Expected :8443 and 5s", httpServer.Addr, httpServer.ReadTimeout)
+	}
+	if httpServer.TLSNextProto == nil {
+		t.Errorf("Expected HTTP/2 to be disabled")
+	}
+	if httpServer.TLSConfig == nil || httpServer.TLSConfig.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Errorf("Expected client certificates to be verified if given, got TLS config: %+v", httpServer.TLSConfig)
+	}
+
+	listen.TLS.ClientCAFile = writeTestFile(t, "empty.pem", "")
+	if _, err = listen.newHTTPServer(http.NotFoundHandler()); err == nil {
+		t.Errorf("Expected an error for a CA file without certificates")
+	}
+}
+
+func TestOCIUpstreamConfig(t *testing.T) {
+	ociServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer client-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ociServer.Close()
+
+	previousConfig, previousClient := serverConfig, ociClient
+	defer func() {
+		serverConfig, ociClient = previousConfig, previousClient
+	}()
+
+	config := DefaultConfig()
+	config.OCI.URL = ociServer.URL
+	config.OCI.Username = "user"
+	config.OCI.Password = "secret"
+	config.OCI.CAFile = writeTestCA(t, ociServer)
+	if err := applyConfig(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotHost := serverConfig.OCI.host(); gotHost != strings.TrimPrefix(ociServer.URL, "https://") {
+		t.Errorf("Got host: %s, Expected host of: %s", gotHost, ociServer.URL)
+	}
+
+	proxy := func() int {
+		w := ocitest.NewProxyRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v2/devfile-catalog/go/manifests/1.0.0", nil)
+		c.Request.Header.Set("Authorization", "Bearer client-token")
+		c.Params = append(c.Params, gin.Param{Key: "proxyPath", Value: "/devfile-catalog/go/manifests/1.0.0"})
+		ServeOciProxy(c)
+		return c.Writer.Status()
+	}
+
+	// The OCI proxy authenticates with the configured credentials over TLS verified with the CA
+	if gotCode := proxy(); gotCode != http.StatusOK {
+		t.Errorf("Got status code: %d, Expected: %d", gotCode, http.StatusOK)
+	}
+
+	// The client credentials are not forwarded when no credentials are configured
+	config.OCI.Username = ""
+	config.OCI.Password = ""
+	if err := applyConfig(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotCode := proxy(); gotCode != http.StatusUnauthorized {
+		t.Errorf("Got status code: %d, Expected: %d", gotCode, http.StatusUnauthorized)
+	}
+
+	config.OCI.CAFile = ""
+	if err := applyConfig(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ociClient.Get(ociServer.URL); err == nil {
+		t.Errorf("Expected the OCI registry certificate to be rejected without its CA")
+	}
+}
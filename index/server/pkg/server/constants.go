@@ -18,6 +18,7 @@ package server
 import (
 	"os"
 
+	indexLibrary "github.com/devfile/registry-support/index/generator/library"
 	"github.com/devfile/registry-support/index/server/pkg/util"
 )
 
@@ -28,7 +29,10 @@ const (
 	starterProjectMediaType = "application/zip"
 	devfileName             = "devfile.yaml"
 	devfileNameHidden       = ".devfile.yaml"
+	devfileNameYml          = "devfile.yml"
+	devfileNameYmlHidden    = ".devfile.yml"
 	devfileConfigMediaType  = "application/vnd.devfileio.devfile.config.v2+json"
+	devfileArtifactType     = devfileConfigMediaType
 	devfileMediaType        = "application/vnd.devfileio.devfile.layer.v1"
 	pngLogoMediaType        = "image/png"
 	pngLogoName             = "logo.png"
@@ -37,10 +41,7 @@ const (
 	vsxMediaType            = "application/vnd.devfileio.vsx.layer.v1.tar"
 	vsxName                 = "vsx"
 
-	scheme          = "http"
-	registryService = "localhost:5000"
-	viewerService   = "localhost:3000"
-	encodeFormat    = "base64"
+	encodeFormat = "base64"
 )
 
 var (
@@ -52,7 +53,20 @@ var (
 	sampleBase64IndexPath = os.Getenv("DEVFILE_SAMPLE_BASE64_INDEX")
 	stackIndexPath        = os.Getenv("DEVFILE_STACK_INDEX")
 	stackBase64IndexPath  = os.Getenv("DEVFILE_STACK_BASE64_INDEX")
+	indexSignaturePath    = util.GetOptionalEnv("DEVFILE_INDEX_SIGNATURE", indexPath+indexLibrary.SignatureFileSuffix).(string)
 	headless              = util.IsEnabled("REGISTRY_HEADLESS", false)
 	enableTelemetry       = util.IsTelemetryEnabled()
 	registry              = util.GetOptionalEnv("REGISTRY_NAME", "devfile-registry")
+	signingKeyPath        = os.Getenv("REGISTRY_INDEX_SIGNING_KEY")
+	authTokensPath        = os.Getenv("REGISTRY_AUTH_TOKENS")
+	authHtpasswdPath      = os.Getenv("REGISTRY_AUTH_HTPASSWD")
+	authRulesPath         = os.Getenv("REGISTRY_AUTH_RULES")
+	allowAnonymous        = util.IsEnabled("REGISTRY_AUTH_ANONYMOUS", false)
+	oidcIssuer            = os.Getenv("REGISTRY_AUTH_OIDC_ISSUER")
+	oidcJwksUrl           = os.Getenv("REGISTRY_AUTH_OIDC_JWKS_URL")
+	oidcAudience          = os.Getenv("REGISTRY_AUTH_OIDC_AUDIENCE")
+	oidcUsernameClaim     = util.GetOptionalEnv("REGISTRY_AUTH_OIDC_USERNAME_CLAIM", "sub").(string)
+	oidcGroupsClaim       = util.GetOptionalEnv("REGISTRY_AUTH_OIDC_GROUPS_CLAIM", "groups").(string)
+	indexCacheControl     = util.GetOptionalEnv("REGISTRY_INDEX_CACHE_CONTROL", "no-cache").(string)
+	devfileCacheControl   = util.GetOptionalEnv("REGISTRY_DEVFILE_CACHE_CONTROL", "no-cache").(string)
 )
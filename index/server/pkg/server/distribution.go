@@ -0,0 +1,135 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/pkg/content"
+)
+
+// serveStorageOCI serves the read-only subset of the OCI distribution API pulling the stack versions from storages
+// without OCI registry: the API version check, the manifests by tag or digest and their blobs. The OCI artifacts are
+// built from the stored resources as they would be pushed to an OCI registry.
+func serveStorageOCI(c *gin.Context, proxyPath string) {
+	proxyPath = strings.Trim(proxyPath, "/")
+	if proxyPath == "" {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+
+	var repository, kind, reference string
+	for _, candidate := range []string{"manifests", "blobs"} {
+		if before, after, found := strings.Cut(proxyPath, "/"+candidate+"/"); found {
+			repository, kind, reference = before, candidate, after
+			break
+		}
+	}
+	if kind == "" || reference == "" {
+		ociError(c, http.StatusNotFound, "UNSUPPORTED", "the registry only serves the manifests and blobs of the stacks")
+		return
+	}
+
+	store, err := getIndexStore()
+	if err != nil {
+		ociError(c, http.StatusInternalServerError, "UNKNOWN", fmt.Sprintf("failed to read the devfile index: %v", err))
+		return
+	}
+	snapshot := store.Snapshot()
+	devfileIndex, found := snapshot.Component(path.Base(repository))
+	if !found || devfileIndex.Type != indexSchema.StackDevfileType {
+		ociError(c, http.StatusNotFound, "NAME_UNKNOWN", "repository name not known to registry")
+		return
+	}
+
+	// Tags are the versions of the stack, the artifacts of every version are built to find a digest
+	var versions []indexSchema.Version
+	byTag := false
+	if versionComponent, found := snapshot.VersionMap(devfileIndex.Name)[reference]; found && kind == "manifests" {
+		versions = append(versions, versionComponent)
+		byTag = true
+	} else if _, err := digest.Parse(reference); err == nil {
+		versions = devfileIndex.Versions
+	}
+
+	for _, versionComponent := range versions {
+		stackRepository, _, _ := strings.Cut(versionComponent.Links["self"], ":")
+		if stackRepository != repository {
+			continue
+		}
+		memoryStore, manifestDesc, err := buildStackManifestFrom(devfileIndex, versionComponent, versionComponent.Links["self"], func(resource string) ([]byte, error) {
+			return stackStorage.Pull(c.Request.Context(), devfileIndex.Name, versionComponent, resource)
+		})
+		if err != nil {
+			log.Print(err.Error())
+			ociError(c, http.StatusInternalServerError, "UNKNOWN", fmt.Sprintf("failed to build the OCI artifact of %s version %s: %v",
+				devfileIndex.Name, versionComponent.Version, err))
+			return
+		}
+		if kind == "manifests" && !byTag && reference != manifestDesc.Digest.String() {
+			continue
+		}
+		if served := serveStoredContent(c, memoryStore, kind, reference, manifestDesc); served {
+			return
+		}
+	}
+
+	if kind == "manifests" {
+		ociError(c, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest unknown to registry")
+	} else {
+		ociError(c, http.StatusNotFound, "BLOB_UNKNOWN", "blob unknown to registry")
+	}
+}
+
+// serveStoredContent writes the manifest or the blob of the OCI artifact of a stack version, false if the blob is not
+// part of the artifact
+func serveStoredContent(c *gin.Context, memoryStore *content.Memory, kind string, reference string, manifestDesc ocispec.Descriptor) bool {
+	desc := manifestDesc
+	if kind == "blobs" {
+		desc = ocispec.Descriptor{Digest: digest.Digest(reference), MediaType: "application/octet-stream"}
+	}
+	_, bytes, found := memoryStore.Get(desc)
+	if !found {
+		return false
+	}
+
+	c.Header("Docker-Content-Digest", desc.Digest.String())
+	c.Header("Content-Length", strconv.Itoa(len(bytes)))
+	if c.Request.Method == http.MethodHead {
+		c.Header("Content-Type", desc.MediaType)
+		c.Status(http.StatusOK)
+		c.Writer.WriteHeaderNow()
+		return true
+	}
+	c.Data(http.StatusOK, desc.MediaType, bytes)
+	return true
+}
+
+// ociError writes an error of the OCI distribution API
+func ociError(c *gin.Context, code int, errorCode string, message string) {
+	c.JSON(code, gin.H{
+		"errors": []gin.H{{"code": errorCode, "message": message}},
+	})
+}
@@ -0,0 +1,100 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"regexp"
+
+	devfileCtx "github.com/devfile/library/v2/pkg/devfile/parser/context"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data"
+	versionpkg "github.com/hashicorp/go-version"
+	"gopkg.in/yaml.v2"
+)
+
+// schemaVersionKey is the devfile field of the schema version
+const schemaVersionKey = "schemaVersion"
+
+// majorMinorPattern matches schema versions without bugfix version, e.g. 2.1
+var majorMinorPattern = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// schemaConversionError is returned when a devfile cannot be converted to an older schema version without
+// losing the features of its schema version
+type schemaConversionError struct {
+	SchemaVersion string
+	Message       string
+}
+
+func (e *schemaConversionError) Error() string {
+	return fmt.Sprintf("the devfile cannot be converted to schema version %s without losing features: %s", e.SchemaVersion, e.Message)
+}
+
+// convertSchemaVersion converts a devfile to an older schema version. The devfile is unchanged apart from its
+// schema version, and a schemaConversionError is returned if it does not validate against the older schema.
+func convertSchemaVersion(devfileBytes []byte, schemaVersion string) ([]byte, error) {
+	if majorMinorPattern.MatchString(schemaVersion) {
+		schemaVersion += ".0"
+	}
+	if _, err := data.GetDevfileJSONSchema(schemaVersion); err != nil {
+		return nil, err
+	}
+	target, err := versionpkg.NewVersion(schemaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("schemaVersion %s is not valid: %v", schemaVersion, err)
+	}
+
+	// The fields of the devfile are kept in order
+	var devfile yaml.MapSlice
+	if err := yaml.Unmarshal(devfileBytes, &devfile); err != nil {
+		return nil, fmt.Errorf("failed to parse the devfile: %v", err)
+	}
+	index := -1
+	for i, item := range devfile {
+		if item.Key == schemaVersionKey {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("the devfile has no schema version")
+	}
+	current, err := versionpkg.NewVersion(fmt.Sprint(devfile[index].Value))
+	if err != nil {
+		return nil, fmt.Errorf("the devfile schema version %v is not valid: %v", devfile[index].Value, err)
+	}
+	switch {
+	case target.Equal(current):
+		return devfileBytes, nil
+	case target.GreaterThan(current):
+		return nil, fmt.Errorf("the devfile of schema version %s cannot be converted to the newer schema version %s", current, schemaVersion)
+	}
+
+	devfile[index].Value = schemaVersion
+	converted, err := yaml.Marshal(devfile)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := devfileCtx.NewByteContentDevfileCtx(converted)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.PopulateFromRaw(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Validate(); err != nil {
+		return nil, &schemaConversionError{SchemaVersion: schemaVersion, Message: err.Error()}
+	}
+	return converted, nil
+}
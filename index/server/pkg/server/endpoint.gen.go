@@ -51,7 +51,7 @@ type ServerInterface interface {
 
 	// (POST /devfiles/{stack})
 	PostDevfile(c *gin.Context, stack string)
-
+	// Update a stack.
 	// (PUT /devfiles/{stack})
 	PutDevfile(c *gin.Context, stack string)
 
@@ -66,7 +66,7 @@ type ServerInterface interface {
 
 	// (PUT /devfiles/{stack}/starter-projects/{starterProject})
 	PutDevfileStarterProject(c *gin.Context, stack string, starterProject string)
-
+	// Delete a stack version.
 	// (DELETE /devfiles/{stack}/{version})
 	DeleteDevfileWithVersion(c *gin.Context, stack string, version string)
 	// Get devfile by stack name.
@@ -75,10 +75,34 @@ type ServerInterface interface {
 
 	// (POST /devfiles/{stack}/{version})
 	PostDevfileWithVersion(c *gin.Context, stack string, version string)
-
+	// Publish a stack version.
 	// (PUT /devfiles/{stack}/{version})
 	PutDevfileWithVersion(c *gin.Context, stack string, version string)
 
+	// (DELETE /devfiles/{stack}/{version}/bundle)
+	DeleteStackBundle(c *gin.Context, stack string, version string)
+	// Downloads all resources of a stack version
+	// (GET /devfiles/{stack}/{version}/bundle)
+	ServeStackBundle(c *gin.Context, stack string, version string, params ServeStackBundleParams)
+
+	// (POST /devfiles/{stack}/{version}/bundle)
+	PostStackBundle(c *gin.Context, stack string, version string)
+
+	// (PUT /devfiles/{stack}/{version}/bundle)
+	PutStackBundle(c *gin.Context, stack string, version string)
+
+	// (DELETE /devfiles/{stack}/{version}/resources/{resource})
+	DeleteStackResource(c *gin.Context, stack string, version string, resource string)
+	// Fetches a resource of a stack version
+	// (GET /devfiles/{stack}/{version}/resources/{resource})
+	ServeStackResource(c *gin.Context, stack string, version string, resource string)
+
+	// (POST /devfiles/{stack}/{version}/resources/{resource})
+	PostStackResource(c *gin.Context, stack string, version string, resource string)
+
+	// (PUT /devfiles/{stack}/{version}/resources/{resource})
+	PutStackResource(c *gin.Context, stack string, version string, resource string)
+
 	// (DELETE /devfiles/{stack}/{version}/starter-projects/{starterProject})
 	DeleteDevfileStarterProjectWithVersion(c *gin.Context, stack string, version string, starterProject string)
 	// Fetches starter project by stack name, stack version, and project name
@@ -127,6 +151,18 @@ type ServerInterface interface {
 	// (PUT /index/{indexType})
 	PutDevfileIndexV1WithType(c *gin.Context, indexType string)
 
+	// (DELETE /signature)
+	DeleteIndexSignature(c *gin.Context)
+	// Gets the signature of the registry index.
+	// (GET /signature)
+	ServeIndexSignature(c *gin.Context)
+
+	// (POST /signature)
+	PostIndexSignature(c *gin.Context)
+
+	// (PUT /signature)
+	PutIndexSignature(c *gin.Context)
+
 	// (DELETE /v2index)
 	DeleteDevfileIndexV2(c *gin.Context)
 	// Gets V2 index schemas of the stack devfiles.
@@ -150,6 +186,30 @@ type ServerInterface interface {
 
 	// (PUT /v2index/{indexType})
 	PutDevfileIndexV2WithType(c *gin.Context, indexType string)
+
+	// (DELETE /v2search)
+	DeleteDevfileSearch(c *gin.Context)
+	// Searches the stacks and samples.
+	// (GET /v2search)
+	ServeDevfileSearch(c *gin.Context, params ServeDevfileSearchParams)
+
+	// (POST /v2search)
+	PostDevfileSearch(c *gin.Context)
+
+	// (PUT /v2search)
+	PutDevfileSearch(c *gin.Context)
+
+	// (DELETE /v2stats)
+	DeleteDevfileStats(c *gin.Context)
+	// Gets the download statistics of the stacks.
+	// (GET /v2stats)
+	ServeDevfileStats(c *gin.Context, params ServeDevfileStatsParams)
+
+	// (POST /v2stats)
+	PostDevfileStats(c *gin.Context)
+
+	// (PUT /v2stats)
+	PutDevfileStats(c *gin.Context)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -255,6 +315,22 @@ func (siw *ServerInterfaceWrapper) ServeDevfile(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "flatten" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "flatten", c.Request.URL.Query(), &params.Flatten)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter flatten: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "schemaVersion" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "schemaVersion", c.Request.URL.Query(), &params.SchemaVersion)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter schemaVersion: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
@@ -297,6 +373,10 @@ func (siw *ServerInterfaceWrapper) PutDevfile(c *gin.Context) {
 		return
 	}
 
+	c.Set(BearerScopes, []string{""})
+
+	c.Set(BasicScopes, []string{""})
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
@@ -466,6 +546,10 @@ func (siw *ServerInterfaceWrapper) DeleteDevfileWithVersion(c *gin.Context) {
 		return
 	}
 
+	c.Set(BearerScopes, []string{""})
+
+	c.Set(BasicScopes, []string{""})
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
@@ -515,6 +599,22 @@ func (siw *ServerInterfaceWrapper) ServeDevfileWithVersion(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "flatten" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "flatten", c.Request.URL.Query(), &params.Flatten)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter flatten: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "schemaVersion" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "schemaVersion", c.Request.URL.Query(), &params.SchemaVersion)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter schemaVersion: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
@@ -575,6 +675,10 @@ func (siw *ServerInterfaceWrapper) PutDevfileWithVersion(c *gin.Context) {
 		return
 	}
 
+	c.Set(BearerScopes, []string{""})
+
+	c.Set(BasicScopes, []string{""})
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
@@ -582,8 +686,8 @@ func (siw *ServerInterfaceWrapper) PutDevfileWithVersion(c *gin.Context) {
 	siw.Handler.PutDevfileWithVersion(c, stack, version)
 }
 
-// DeleteDevfileStarterProjectWithVersion operation middleware
-func (siw *ServerInterfaceWrapper) DeleteDevfileStarterProjectWithVersion(c *gin.Context) {
+// DeleteStackBundle operation middleware
+func (siw *ServerInterfaceWrapper) DeleteStackBundle(c *gin.Context) {
 
 	var err error
 
@@ -605,24 +709,15 @@ func (siw *ServerInterfaceWrapper) DeleteDevfileStarterProjectWithVersion(c *gin
 		return
 	}
 
-	// ------------- Path parameter "starterProject" -------------
-	var starterProject string
-
-	err = runtime.BindStyledParameter("simple", false, "starterProject", c.Param("starterProject"), &starterProject)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProject: %s", err), http.StatusBadRequest)
-		return
-	}
-
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.DeleteDevfileStarterProjectWithVersion(c, stack, version, starterProject)
+	siw.Handler.DeleteStackBundle(c, stack, version)
 }
 
-// ServeDevfileStarterProjectWithVersion operation middleware
-func (siw *ServerInterfaceWrapper) ServeDevfileStarterProjectWithVersion(c *gin.Context) {
+// ServeStackBundle operation middleware
+func (siw *ServerInterfaceWrapper) ServeStackBundle(c *gin.Context) {
 
 	var err error
 
@@ -644,31 +739,22 @@ func (siw *ServerInterfaceWrapper) ServeDevfileStarterProjectWithVersion(c *gin.
 		return
 	}
 
-	// ------------- Path parameter "starterProject" -------------
-	var starterProject string
-
-	err = runtime.BindStyledParameter("simple", false, "starterProject", c.Param("starterProject"), &starterProject)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProject: %s", err), http.StatusBadRequest)
-		return
-	}
-
 	// Parameter object where we will unmarshal all parameters from the context
-	var params ServeDevfileStarterProjectWithVersionParams
+	var params ServeStackBundleParams
 
-	// ------------- Optional query parameter "minSchemaVersion" -------------
+	// ------------- Optional query parameter "format" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "minSchemaVersion", c.Request.URL.Query(), &params.MinSchemaVersion)
+	err = runtime.BindQueryParameter("form", true, false, "format", c.Request.URL.Query(), &params.Format)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter minSchemaVersion: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter format: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "maxSchemaVersion" -------------
+	// ------------- Optional query parameter "expandArchive" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "maxSchemaVersion", c.Request.URL.Query(), &params.MaxSchemaVersion)
+	err = runtime.BindQueryParameter("form", true, false, "expandArchive", c.Request.URL.Query(), &params.ExpandArchive)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter maxSchemaVersion: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter expandArchive: %s", err), http.StatusBadRequest)
 		return
 	}
 
@@ -676,11 +762,11 @@ func (siw *ServerInterfaceWrapper) ServeDevfileStarterProjectWithVersion(c *gin.
 		middleware(c)
 	}
 
-	siw.Handler.ServeDevfileStarterProjectWithVersion(c, stack, version, starterProject, params)
+	siw.Handler.ServeStackBundle(c, stack, version, params)
 }
 
-// PostDevfileStarterProjectWithVersion operation middleware
-func (siw *ServerInterfaceWrapper) PostDevfileStarterProjectWithVersion(c *gin.Context) {
+// PostStackBundle operation middleware
+func (siw *ServerInterfaceWrapper) PostStackBundle(c *gin.Context) {
 
 	var err error
 
@@ -702,12 +788,33 @@ func (siw *ServerInterfaceWrapper) PostDevfileStarterProjectWithVersion(c *gin.C
 		return
 	}
 
-	// ------------- Path parameter "starterProject" -------------
-	var starterProject string
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
 
-	err = runtime.BindStyledParameter("simple", false, "starterProject", c.Param("starterProject"), &starterProject)
+	siw.Handler.PostStackBundle(c, stack, version)
+}
+
+// PutStackBundle operation middleware
+func (siw *ServerInterfaceWrapper) PutStackBundle(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "stack" -------------
+	var stack string
+
+	err = runtime.BindStyledParameter("simple", false, "stack", c.Param("stack"), &stack)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProject: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter stack: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "version" -------------
+	var version string
+
+	err = runtime.BindStyledParameter("simple", false, "version", c.Param("version"), &version)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter version: %s", err), http.StatusBadRequest)
 		return
 	}
 
@@ -715,11 +822,11 @@ func (siw *ServerInterfaceWrapper) PostDevfileStarterProjectWithVersion(c *gin.C
 		middleware(c)
 	}
 
-	siw.Handler.PostDevfileStarterProjectWithVersion(c, stack, version, starterProject)
+	siw.Handler.PutStackBundle(c, stack, version)
 }
 
-// PutDevfileStarterProjectWithVersion operation middleware
-func (siw *ServerInterfaceWrapper) PutDevfileStarterProjectWithVersion(c *gin.Context) {
+// DeleteStackResource operation middleware
+func (siw *ServerInterfaceWrapper) DeleteStackResource(c *gin.Context) {
 
 	var err error
 
@@ -741,12 +848,12 @@ func (siw *ServerInterfaceWrapper) PutDevfileStarterProjectWithVersion(c *gin.Co
 		return
 	}
 
-	// ------------- Path parameter "starterProject" -------------
-	var starterProject string
+	// ------------- Path parameter "resource" -------------
+	var resource string
 
-	err = runtime.BindStyledParameter("simple", false, "starterProject", c.Param("starterProject"), &starterProject)
+	err = runtime.BindStyledParameter("simple", false, "resource", c.Param("resource"), &resource)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProject: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter resource: %s", err), http.StatusBadRequest)
 		return
 	}
 
@@ -754,172 +861,729 @@ func (siw *ServerInterfaceWrapper) PutDevfileStarterProjectWithVersion(c *gin.Co
 		middleware(c)
 	}
 
-	siw.Handler.PutDevfileStarterProjectWithVersion(c, stack, version, starterProject)
+	siw.Handler.DeleteStackResource(c, stack, version, resource)
 }
 
-// DeleteHealthCheck operation middleware
-func (siw *ServerInterfaceWrapper) DeleteHealthCheck(c *gin.Context) {
-
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-	}
+// ServeStackResource operation middleware
+func (siw *ServerInterfaceWrapper) ServeStackResource(c *gin.Context) {
 
-	siw.Handler.DeleteHealthCheck(c)
-}
+	var err error
 
-// ServeHealthCheck operation middleware
-func (siw *ServerInterfaceWrapper) ServeHealthCheck(c *gin.Context) {
+	// ------------- Path parameter "stack" -------------
+	var stack string
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
+	err = runtime.BindStyledParameter("simple", false, "stack", c.Param("stack"), &stack)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter stack: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	siw.Handler.ServeHealthCheck(c)
-}
-
-// PostHealthCheck operation middleware
-func (siw *ServerInterfaceWrapper) PostHealthCheck(c *gin.Context) {
+	// ------------- Path parameter "version" -------------
+	var version string
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
+	err = runtime.BindStyledParameter("simple", false, "version", c.Param("version"), &version)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter version: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	siw.Handler.PostHealthCheck(c)
-}
-
-// PutHealthCheck operation middleware
-func (siw *ServerInterfaceWrapper) PutHealthCheck(c *gin.Context) {
+	// ------------- Path parameter "resource" -------------
+	var resource string
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
+	err = runtime.BindStyledParameter("simple", false, "resource", c.Param("resource"), &resource)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter resource: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	siw.Handler.PutHealthCheck(c)
-}
-
-// DeleteDevfileIndexV1 operation middleware
-func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV1(c *gin.Context) {
-
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.DeleteDevfileIndexV1(c)
+	siw.Handler.ServeStackResource(c, stack, version, resource)
 }
 
-// ServeDevfileIndexV1 operation middleware
-func (siw *ServerInterfaceWrapper) ServeDevfileIndexV1(c *gin.Context) {
+// PostStackResource operation middleware
+func (siw *ServerInterfaceWrapper) PostStackResource(c *gin.Context) {
 
 	var err error
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params ServeDevfileIndexV1Params
-
-	// ------------- Optional query parameter "name" -------------
+	// ------------- Path parameter "stack" -------------
+	var stack string
 
-	err = runtime.BindQueryParameter("form", true, false, "name", c.Request.URL.Query(), &params.Name)
+	err = runtime.BindStyledParameter("simple", false, "stack", c.Param("stack"), &stack)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter stack: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "displayName" -------------
+	// ------------- Path parameter "version" -------------
+	var version string
 
-	err = runtime.BindQueryParameter("form", true, false, "displayName", c.Request.URL.Query(), &params.DisplayName)
+	err = runtime.BindStyledParameter("simple", false, "version", c.Param("version"), &version)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter displayName: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter version: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "description" -------------
+	// ------------- Path parameter "resource" -------------
+	var resource string
 
-	err = runtime.BindQueryParameter("form", true, false, "description", c.Request.URL.Query(), &params.Description)
+	err = runtime.BindStyledParameter("simple", false, "resource", c.Param("resource"), &resource)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter description: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter resource: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "attributeNames" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "attributeNames", c.Request.URL.Query(), &params.AttributeNames)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter attributeNames: %s", err), http.StatusBadRequest)
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
 	}
 
-	// ------------- Optional query parameter "tags" -------------
+	siw.Handler.PostStackResource(c, stack, version, resource)
+}
 
-	err = runtime.BindQueryParameter("form", true, false, "tags", c.Request.URL.Query(), &params.Tags)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tags: %s", err), http.StatusBadRequest)
-		return
-	}
+// PutStackResource operation middleware
+func (siw *ServerInterfaceWrapper) PutStackResource(c *gin.Context) {
 
-	// ------------- Optional query parameter "arch" -------------
+	var err error
 
-	err = runtime.BindQueryParameter("form", true, false, "arch", c.Request.URL.Query(), &params.Arch)
+	// ------------- Path parameter "stack" -------------
+	var stack string
+
+	err = runtime.BindStyledParameter("simple", false, "stack", c.Param("stack"), &stack)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter arch: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter stack: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "icon" -------------
+	// ------------- Path parameter "version" -------------
+	var version string
 
-	err = runtime.BindQueryParameter("form", true, false, "icon", c.Request.URL.Query(), &params.Icon)
+	err = runtime.BindStyledParameter("simple", false, "version", c.Param("version"), &version)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter icon: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter version: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "iconUri" -------------
+	// ------------- Path parameter "resource" -------------
+	var resource string
 
-	err = runtime.BindQueryParameter("form", true, false, "iconUri", c.Request.URL.Query(), &params.IconUri)
+	err = runtime.BindStyledParameter("simple", false, "resource", c.Param("resource"), &resource)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter iconUri: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter resource: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "projectType" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "projectType", c.Request.URL.Query(), &params.ProjectType)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter projectType: %s", err), http.StatusBadRequest)
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
 	}
 
-	// ------------- Optional query parameter "language" -------------
+	siw.Handler.PutStackResource(c, stack, version, resource)
+}
 
-	err = runtime.BindQueryParameter("form", true, false, "language", c.Request.URL.Query(), &params.Language)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter language: %s", err), http.StatusBadRequest)
-		return
-	}
+// DeleteDevfileStarterProjectWithVersion operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDevfileStarterProjectWithVersion(c *gin.Context) {
 
-	// ------------- Optional query parameter "deprecated" -------------
+	var err error
 
-	err = runtime.BindQueryParameter("form", true, false, "deprecated", c.Request.URL.Query(), &params.Deprecated)
+	// ------------- Path parameter "stack" -------------
+	var stack string
+
+	err = runtime.BindStyledParameter("simple", false, "stack", c.Param("stack"), &stack)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter deprecated: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter stack: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "resources" -------------
+	// ------------- Path parameter "version" -------------
+	var version string
 
-	err = runtime.BindQueryParameter("form", true, false, "resources", c.Request.URL.Query(), &params.Resources)
+	err = runtime.BindStyledParameter("simple", false, "version", c.Param("version"), &version)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter resources: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter version: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "starterProjects" -------------
+	// ------------- Path parameter "starterProject" -------------
+	var starterProject string
 
-	err = runtime.BindQueryParameter("form", true, false, "starterProjects", c.Request.URL.Query(), &params.StarterProjects)
+	err = runtime.BindStyledParameter("simple", false, "starterProject", c.Param("starterProject"), &starterProject)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProjects: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProject: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "linkNames" -------------
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.DeleteDevfileStarterProjectWithVersion(c, stack, version, starterProject)
+}
+
+// ServeDevfileStarterProjectWithVersion operation middleware
+func (siw *ServerInterfaceWrapper) ServeDevfileStarterProjectWithVersion(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "stack" -------------
+	var stack string
+
+	err = runtime.BindStyledParameter("simple", false, "stack", c.Param("stack"), &stack)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter stack: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "version" -------------
+	var version string
+
+	err = runtime.BindStyledParameter("simple", false, "version", c.Param("version"), &version)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter version: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "starterProject" -------------
+	var starterProject string
+
+	err = runtime.BindStyledParameter("simple", false, "starterProject", c.Param("starterProject"), &starterProject)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProject: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ServeDevfileStarterProjectWithVersionParams
+
+	// ------------- Optional query parameter "minSchemaVersion" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minSchemaVersion", c.Request.URL.Query(), &params.MinSchemaVersion)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter minSchemaVersion: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "maxSchemaVersion" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxSchemaVersion", c.Request.URL.Query(), &params.MaxSchemaVersion)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter maxSchemaVersion: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.ServeDevfileStarterProjectWithVersion(c, stack, version, starterProject, params)
+}
+
+// PostDevfileStarterProjectWithVersion operation middleware
+func (siw *ServerInterfaceWrapper) PostDevfileStarterProjectWithVersion(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "stack" -------------
+	var stack string
+
+	err = runtime.BindStyledParameter("simple", false, "stack", c.Param("stack"), &stack)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter stack: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "version" -------------
+	var version string
+
+	err = runtime.BindStyledParameter("simple", false, "version", c.Param("version"), &version)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter version: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "starterProject" -------------
+	var starterProject string
+
+	err = runtime.BindStyledParameter("simple", false, "starterProject", c.Param("starterProject"), &starterProject)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProject: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PostDevfileStarterProjectWithVersion(c, stack, version, starterProject)
+}
+
+// PutDevfileStarterProjectWithVersion operation middleware
+func (siw *ServerInterfaceWrapper) PutDevfileStarterProjectWithVersion(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "stack" -------------
+	var stack string
+
+	err = runtime.BindStyledParameter("simple", false, "stack", c.Param("stack"), &stack)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter stack: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "version" -------------
+	var version string
+
+	err = runtime.BindStyledParameter("simple", false, "version", c.Param("version"), &version)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter version: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "starterProject" -------------
+	var starterProject string
+
+	err = runtime.BindStyledParameter("simple", false, "starterProject", c.Param("starterProject"), &starterProject)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProject: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PutDevfileStarterProjectWithVersion(c, stack, version, starterProject)
+}
+
+// DeleteHealthCheck operation middleware
+func (siw *ServerInterfaceWrapper) DeleteHealthCheck(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.DeleteHealthCheck(c)
+}
+
+// ServeHealthCheck operation middleware
+func (siw *ServerInterfaceWrapper) ServeHealthCheck(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.ServeHealthCheck(c)
+}
+
+// PostHealthCheck operation middleware
+func (siw *ServerInterfaceWrapper) PostHealthCheck(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PostHealthCheck(c)
+}
+
+// PutHealthCheck operation middleware
+func (siw *ServerInterfaceWrapper) PutHealthCheck(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PutHealthCheck(c)
+}
+
+// DeleteDevfileIndexV1 operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV1(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.DeleteDevfileIndexV1(c)
+}
+
+// ServeDevfileIndexV1 operation middleware
+func (siw *ServerInterfaceWrapper) ServeDevfileIndexV1(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ServeDevfileIndexV1Params
+
+	// ------------- Optional query parameter "name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "name", c.Request.URL.Query(), &params.Name)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "displayName" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "displayName", c.Request.URL.Query(), &params.DisplayName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter displayName: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "description" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "description", c.Request.URL.Query(), &params.Description)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter description: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "attributeNames" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "attributeNames", c.Request.URL.Query(), &params.AttributeNames)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter attributeNames: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "tags" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tags", c.Request.URL.Query(), &params.Tags)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tags: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "arch" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "arch", c.Request.URL.Query(), &params.Arch)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter arch: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "icon" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "icon", c.Request.URL.Query(), &params.Icon)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter icon: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "iconUri" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "iconUri", c.Request.URL.Query(), &params.IconUri)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter iconUri: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "projectType" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "projectType", c.Request.URL.Query(), &params.ProjectType)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter projectType: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "language" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "language", c.Request.URL.Query(), &params.Language)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter language: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "deprecated" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "deprecated", c.Request.URL.Query(), &params.Deprecated)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter deprecated: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", c.Request.URL.Query(), &params.Filter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter filter: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "resources" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "resources", c.Request.URL.Query(), &params.Resources)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter resources: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "starterProjects" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "starterProjects", c.Request.URL.Query(), &params.StarterProjects)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProjects: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "linkNames" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "linkNames", c.Request.URL.Query(), &params.LinkNames)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter linkNames: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "links" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "links", c.Request.URL.Query(), &params.Links)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter links: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitRemoteNames" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitRemoteNames", c.Request.URL.Query(), &params.GitRemoteNames)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRemoteNames: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitRemotes" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitRemotes", c.Request.URL.Query(), &params.GitRemotes)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRemotes: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitUrl" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitUrl", c.Request.URL.Query(), &params.GitUrl)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitUrl: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitRemoteName" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitRemoteName", c.Request.URL.Query(), &params.GitRemoteName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRemoteName: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitSubDir" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitSubDir", c.Request.URL.Query(), &params.GitSubDir)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitSubDir: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitRevision" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitRevision", c.Request.URL.Query(), &params.GitRevision)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRevision: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "provider" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "provider", c.Request.URL.Query(), &params.Provider)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter provider: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "supportUrl" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "supportUrl", c.Request.URL.Query(), &params.SupportUrl)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter supportUrl: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.ServeDevfileIndexV1(c, params)
+}
+
+// PostDevfileIndexV1 operation middleware
+func (siw *ServerInterfaceWrapper) PostDevfileIndexV1(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PostDevfileIndexV1(c)
+}
+
+// PutDevfileIndexV1 operation middleware
+func (siw *ServerInterfaceWrapper) PutDevfileIndexV1(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PutDevfileIndexV1(c)
+}
+
+// DeleteDevfileIndexV1WithType operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV1WithType(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "indexType" -------------
+	var indexType string
+
+	err = runtime.BindStyledParameter("simple", false, "indexType", c.Param("indexType"), &indexType)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter indexType: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.DeleteDevfileIndexV1WithType(c, indexType)
+}
+
+// ServeDevfileIndexV1WithType operation middleware
+func (siw *ServerInterfaceWrapper) ServeDevfileIndexV1WithType(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "indexType" -------------
+	var indexType string
+
+	err = runtime.BindStyledParameter("simple", false, "indexType", c.Param("indexType"), &indexType)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter indexType: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ServeDevfileIndexV1WithTypeParams
+
+	// ------------- Optional query parameter "name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "name", c.Request.URL.Query(), &params.Name)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "displayName" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "displayName", c.Request.URL.Query(), &params.DisplayName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter displayName: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "description" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "description", c.Request.URL.Query(), &params.Description)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter description: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "attributeNames" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "attributeNames", c.Request.URL.Query(), &params.AttributeNames)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter attributeNames: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "tags" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tags", c.Request.URL.Query(), &params.Tags)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tags: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "arch" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "arch", c.Request.URL.Query(), &params.Arch)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter arch: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "icon" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "icon", c.Request.URL.Query(), &params.Icon)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter icon: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "iconUri" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "iconUri", c.Request.URL.Query(), &params.IconUri)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter iconUri: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "projectType" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "projectType", c.Request.URL.Query(), &params.ProjectType)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter projectType: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "language" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "language", c.Request.URL.Query(), &params.Language)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter language: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "deprecated" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "deprecated", c.Request.URL.Query(), &params.Deprecated)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter deprecated: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", c.Request.URL.Query(), &params.Filter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter filter: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "resources" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "resources", c.Request.URL.Query(), &params.Resources)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter resources: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "starterProjects" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "starterProjects", c.Request.URL.Query(), &params.StarterProjects)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter starterProjects: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "linkNames" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "linkNames", c.Request.URL.Query(), &params.LinkNames)
 	if err != nil {
@@ -1003,31 +1667,32 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV1(c *gin.Context) {
 		middleware(c)
 	}
 
-	siw.Handler.ServeDevfileIndexV1(c, params)
+	siw.Handler.ServeDevfileIndexV1WithType(c, indexType, params)
 }
 
-// PostDevfileIndexV1 operation middleware
-func (siw *ServerInterfaceWrapper) PostDevfileIndexV1(c *gin.Context) {
+// PostDevfileIndexV1WithType operation middleware
+func (siw *ServerInterfaceWrapper) PostDevfileIndexV1WithType(c *gin.Context) {
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-	}
+	var err error
 
-	siw.Handler.PostDevfileIndexV1(c)
-}
+	// ------------- Path parameter "indexType" -------------
+	var indexType string
 
-// PutDevfileIndexV1 operation middleware
-func (siw *ServerInterfaceWrapper) PutDevfileIndexV1(c *gin.Context) {
+	err = runtime.BindStyledParameter("simple", false, "indexType", c.Param("indexType"), &indexType)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter indexType: %s", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.PutDevfileIndexV1(c)
+	siw.Handler.PostDevfileIndexV1WithType(c, indexType)
 }
 
-// DeleteDevfileIndexV1WithType operation middleware
-func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV1WithType(c *gin.Context) {
+// PutDevfileIndexV1WithType operation middleware
+func (siw *ServerInterfaceWrapper) PutDevfileIndexV1WithType(c *gin.Context) {
 
 	var err error
 
@@ -1044,25 +1709,66 @@ func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV1WithType(c *gin.Context)
 		middleware(c)
 	}
 
-	siw.Handler.DeleteDevfileIndexV1WithType(c, indexType)
+	siw.Handler.PutDevfileIndexV1WithType(c, indexType)
 }
 
-// ServeDevfileIndexV1WithType operation middleware
-func (siw *ServerInterfaceWrapper) ServeDevfileIndexV1WithType(c *gin.Context) {
+// DeleteIndexSignature operation middleware
+func (siw *ServerInterfaceWrapper) DeleteIndexSignature(c *gin.Context) {
 
-	var err error
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
 
-	// ------------- Path parameter "indexType" -------------
-	var indexType string
+	siw.Handler.DeleteIndexSignature(c)
+}
 
-	err = runtime.BindStyledParameter("simple", false, "indexType", c.Param("indexType"), &indexType)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter indexType: %s", err), http.StatusBadRequest)
-		return
+// ServeIndexSignature operation middleware
+func (siw *ServerInterfaceWrapper) ServeIndexSignature(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.ServeIndexSignature(c)
+}
+
+// PostIndexSignature operation middleware
+func (siw *ServerInterfaceWrapper) PostIndexSignature(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PostIndexSignature(c)
+}
+
+// PutIndexSignature operation middleware
+func (siw *ServerInterfaceWrapper) PutIndexSignature(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
 	}
 
+	siw.Handler.PutIndexSignature(c)
+}
+
+// DeleteDevfileIndexV2 operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV2(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.DeleteDevfileIndexV2(c)
+}
+
+// ServeDevfileIndexV2 operation middleware
+func (siw *ServerInterfaceWrapper) ServeDevfileIndexV2(c *gin.Context) {
+
+	var err error
+
 	// Parameter object where we will unmarshal all parameters from the context
-	var params ServeDevfileIndexV1WithTypeParams
+	var params ServeDevfileIndexV2Params
 
 	// ------------- Optional query parameter "name" -------------
 
@@ -1144,6 +1850,38 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV1WithType(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "minVersion" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minVersion", c.Request.URL.Query(), &params.MinVersion)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter minVersion: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "maxVersion" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxVersion", c.Request.URL.Query(), &params.MaxVersion)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter maxVersion: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "minSchemaVersion" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minSchemaVersion", c.Request.URL.Query(), &params.MinSchemaVersion)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter minSchemaVersion: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "maxSchemaVersion" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "maxSchemaVersion", c.Request.URL.Query(), &params.MaxSchemaVersion)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter maxSchemaVersion: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	// ------------- Optional query parameter "deprecated" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "deprecated", c.Request.URL.Query(), &params.Deprecated)
@@ -1152,6 +1890,22 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV1WithType(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", c.Request.URL.Query(), &params.Filter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter filter: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "default" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "default", c.Request.URL.Query(), &params.Default)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter default: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	// ------------- Optional query parameter "resources" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "resources", c.Request.URL.Query(), &params.Resources)
@@ -1176,75 +1930,155 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV1WithType(c *gin.Context) {
 		return
 	}
 
-	// ------------- Optional query parameter "links" -------------
+	// ------------- Optional query parameter "links" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "links", c.Request.URL.Query(), &params.Links)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter links: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "commandGroups" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "commandGroups", c.Request.URL.Query(), &params.CommandGroups)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter commandGroups: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "deploymentScopes" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "deploymentScopes", c.Request.URL.Query(), &params.DeploymentScopes)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter deploymentScopes: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitRemoteNames" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitRemoteNames", c.Request.URL.Query(), &params.GitRemoteNames)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRemoteNames: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitRemotes" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitRemotes", c.Request.URL.Query(), &params.GitRemotes)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRemotes: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitUrl" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitUrl", c.Request.URL.Query(), &params.GitUrl)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitUrl: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitRemoteName" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitRemoteName", c.Request.URL.Query(), &params.GitRemoteName)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRemoteName: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitSubDir" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitSubDir", c.Request.URL.Query(), &params.GitSubDir)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitSubDir: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "gitRevision" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gitRevision", c.Request.URL.Query(), &params.GitRevision)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRevision: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "provider" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "provider", c.Request.URL.Query(), &params.Provider)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter provider: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "supportUrl" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "links", c.Request.URL.Query(), &params.Links)
+	err = runtime.BindQueryParameter("form", true, false, "supportUrl", c.Request.URL.Query(), &params.SupportUrl)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter links: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter supportUrl: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "gitRemoteNames" -------------
+	// ------------- Optional query parameter "minLastModified" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "gitRemoteNames", c.Request.URL.Query(), &params.GitRemoteNames)
+	err = runtime.BindQueryParameter("form", true, false, "minLastModified", c.Request.URL.Query(), &params.MinLastModified)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRemoteNames: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter minLastModified: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "gitRemotes" -------------
+	// ------------- Optional query parameter "maxLastModified" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "gitRemotes", c.Request.URL.Query(), &params.GitRemotes)
+	err = runtime.BindQueryParameter("form", true, false, "maxLastModified", c.Request.URL.Query(), &params.MaxLastModified)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRemotes: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter maxLastModified: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "gitUrl" -------------
+	// ------------- Optional query parameter "sort" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "gitUrl", c.Request.URL.Query(), &params.GitUrl)
+	err = runtime.BindQueryParameter("form", true, false, "sort", c.Request.URL.Query(), &params.Sort)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitUrl: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sort: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "gitRemoteName" -------------
+	// ------------- Optional query parameter "order" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "gitRemoteName", c.Request.URL.Query(), &params.GitRemoteName)
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRemoteName: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "gitSubDir" -------------
+	// ------------- Optional query parameter "limit" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "gitSubDir", c.Request.URL.Query(), &params.GitSubDir)
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitSubDir: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "gitRevision" -------------
+	// ------------- Optional query parameter "offset" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "gitRevision", c.Request.URL.Query(), &params.GitRevision)
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter gitRevision: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "provider" -------------
+	// ------------- Optional query parameter "cursor" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "provider", c.Request.URL.Query(), &params.Provider)
+	err = runtime.BindQueryParameter("form", true, false, "cursor", c.Request.URL.Query(), &params.Cursor)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter provider: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter cursor: %s", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "supportUrl" -------------
+	// ------------- Optional query parameter "fields" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "supportUrl", c.Request.URL.Query(), &params.SupportUrl)
+	err = runtime.BindQueryParameter("form", true, false, "fields", c.Request.URL.Query(), &params.Fields)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter supportUrl: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter fields: %s", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1252,32 +2086,31 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV1WithType(c *gin.Context) {
 		middleware(c)
 	}
 
-	siw.Handler.ServeDevfileIndexV1WithType(c, indexType, params)
+	siw.Handler.ServeDevfileIndexV2(c, params)
 }
 
-// PostDevfileIndexV1WithType operation middleware
-func (siw *ServerInterfaceWrapper) PostDevfileIndexV1WithType(c *gin.Context) {
+// PostDevfileIndexV2 operation middleware
+func (siw *ServerInterfaceWrapper) PostDevfileIndexV2(c *gin.Context) {
 
-	var err error
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
 
-	// ------------- Path parameter "indexType" -------------
-	var indexType string
+	siw.Handler.PostDevfileIndexV2(c)
+}
 
-	err = runtime.BindStyledParameter("simple", false, "indexType", c.Param("indexType"), &indexType)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter indexType: %s", err), http.StatusBadRequest)
-		return
-	}
+// PutDevfileIndexV2 operation middleware
+func (siw *ServerInterfaceWrapper) PutDevfileIndexV2(c *gin.Context) {
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.PostDevfileIndexV1WithType(c, indexType)
+	siw.Handler.PutDevfileIndexV2(c)
 }
 
-// PutDevfileIndexV1WithType operation middleware
-func (siw *ServerInterfaceWrapper) PutDevfileIndexV1WithType(c *gin.Context) {
+// DeleteDevfileIndexV2WithType operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV2WithType(c *gin.Context) {
 
 	var err error
 
@@ -1294,26 +2127,25 @@ func (siw *ServerInterfaceWrapper) PutDevfileIndexV1WithType(c *gin.Context) {
 		middleware(c)
 	}
 
-	siw.Handler.PutDevfileIndexV1WithType(c, indexType)
+	siw.Handler.DeleteDevfileIndexV2WithType(c, indexType)
 }
 
-// DeleteDevfileIndexV2 operation middleware
-func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV2(c *gin.Context) {
-
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-	}
+// ServeDevfileIndexV2WithType operation middleware
+func (siw *ServerInterfaceWrapper) ServeDevfileIndexV2WithType(c *gin.Context) {
 
-	siw.Handler.DeleteDevfileIndexV2(c)
-}
+	var err error
 
-// ServeDevfileIndexV2 operation middleware
-func (siw *ServerInterfaceWrapper) ServeDevfileIndexV2(c *gin.Context) {
+	// ------------- Path parameter "indexType" -------------
+	var indexType string
 
-	var err error
+	err = runtime.BindStyledParameter("simple", false, "indexType", c.Param("indexType"), &indexType)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter indexType: %s", err), http.StatusBadRequest)
+		return
+	}
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params ServeDevfileIndexV2Params
+	var params ServeDevfileIndexV2WithTypeParams
 
 	// ------------- Optional query parameter "name" -------------
 
@@ -1435,6 +2267,14 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV2(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", c.Request.URL.Query(), &params.Filter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter filter: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	// ------------- Optional query parameter "default" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "default", c.Request.URL.Query(), &params.Default)
@@ -1571,35 +2411,63 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV2(c *gin.Context) {
 		return
 	}
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", c.Request.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sort: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	siw.Handler.ServeDevfileIndexV2(c, params)
-}
+	// ------------- Optional query parameter "order" -------------
 
-// PostDevfileIndexV2 operation middleware
-func (siw *ServerInterfaceWrapper) PostDevfileIndexV2(c *gin.Context) {
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %s", err), http.StatusBadRequest)
+		return
+	}
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	siw.Handler.PostDevfileIndexV2(c)
-}
+	// ------------- Optional query parameter "offset" -------------
 
-// PutDevfileIndexV2 operation middleware
-func (siw *ServerInterfaceWrapper) PutDevfileIndexV2(c *gin.Context) {
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", c.Request.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter cursor: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "fields" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fields", c.Request.URL.Query(), &params.Fields)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter fields: %s", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.PutDevfileIndexV2(c)
+	siw.Handler.ServeDevfileIndexV2WithType(c, indexType, params)
 }
 
-// DeleteDevfileIndexV2WithType operation middleware
-func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV2WithType(c *gin.Context) {
+// PostDevfileIndexV2WithType operation middleware
+func (siw *ServerInterfaceWrapper) PostDevfileIndexV2WithType(c *gin.Context) {
 
 	var err error
 
@@ -1616,11 +2484,11 @@ func (siw *ServerInterfaceWrapper) DeleteDevfileIndexV2WithType(c *gin.Context)
 		middleware(c)
 	}
 
-	siw.Handler.DeleteDevfileIndexV2WithType(c, indexType)
+	siw.Handler.PostDevfileIndexV2WithType(c, indexType)
 }
 
-// ServeDevfileIndexV2WithType operation middleware
-func (siw *ServerInterfaceWrapper) ServeDevfileIndexV2WithType(c *gin.Context) {
+// PutDevfileIndexV2WithType operation middleware
+func (siw *ServerInterfaceWrapper) PutDevfileIndexV2WithType(c *gin.Context) {
 
 	var err error
 
@@ -1633,8 +2501,53 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV2WithType(c *gin.Context) {
 		return
 	}
 
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PutDevfileIndexV2WithType(c, indexType)
+}
+
+// DeleteDevfileSearch operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDevfileSearch(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.DeleteDevfileSearch(c)
+}
+
+// ServeDevfileSearch operation middleware
+func (siw *ServerInterfaceWrapper) ServeDevfileSearch(c *gin.Context) {
+
+	var err error
+
 	// Parameter object where we will unmarshal all parameters from the context
-	var params ServeDevfileIndexV2WithTypeParams
+	var params ServeDevfileSearchParams
+
+	// ------------- Required query parameter "q" -------------
+
+	if paramValue := c.Query("q"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument q is required, but not found: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", c.Request.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter type: %s", err), http.StatusBadRequest)
+		return
+	}
 
 	// ------------- Optional query parameter "name" -------------
 
@@ -1756,6 +2669,14 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV2WithType(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", c.Request.URL.Query(), &params.Filter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter filter: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	// ------------- Optional query parameter "default" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "default", c.Request.URL.Query(), &params.Default)
@@ -1892,45 +2813,80 @@ func (siw *ServerInterfaceWrapper) ServeDevfileIndexV2WithType(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %s", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.ServeDevfileIndexV2WithType(c, indexType, params)
+	siw.Handler.ServeDevfileSearch(c, params)
 }
 
-// PostDevfileIndexV2WithType operation middleware
-func (siw *ServerInterfaceWrapper) PostDevfileIndexV2WithType(c *gin.Context) {
+// PostDevfileSearch operation middleware
+func (siw *ServerInterfaceWrapper) PostDevfileSearch(c *gin.Context) {
 
-	var err error
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
 
-	// ------------- Path parameter "indexType" -------------
-	var indexType string
+	siw.Handler.PostDevfileSearch(c)
+}
 
-	err = runtime.BindStyledParameter("simple", false, "indexType", c.Param("indexType"), &indexType)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter indexType: %s", err), http.StatusBadRequest)
-		return
+// PutDevfileSearch operation middleware
+func (siw *ServerInterfaceWrapper) PutDevfileSearch(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
 	}
 
+	siw.Handler.PutDevfileSearch(c)
+}
+
+// DeleteDevfileStats operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDevfileStats(c *gin.Context) {
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 	}
 
-	siw.Handler.PostDevfileIndexV2WithType(c, indexType)
+	siw.Handler.DeleteDevfileStats(c)
 }
 
-// PutDevfileIndexV2WithType operation middleware
-func (siw *ServerInterfaceWrapper) PutDevfileIndexV2WithType(c *gin.Context) {
+// ServeDevfileStats operation middleware
+func (siw *ServerInterfaceWrapper) ServeDevfileStats(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "indexType" -------------
-	var indexType string
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ServeDevfileStatsParams
 
-	err = runtime.BindStyledParameter("simple", false, "indexType", c.Param("indexType"), &indexType)
+	// ------------- Optional query parameter "window" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "window", c.Request.URL.Query(), &params.Window)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter indexType: %s", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter window: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "stack" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "stack", c.Request.URL.Query(), &params.Stack)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter stack: %s", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1938,7 +2894,27 @@ func (siw *ServerInterfaceWrapper) PutDevfileIndexV2WithType(c *gin.Context) {
 		middleware(c)
 	}
 
-	siw.Handler.PutDevfileIndexV2WithType(c, indexType)
+	siw.Handler.ServeDevfileStats(c, params)
+}
+
+// PostDevfileStats operation middleware
+func (siw *ServerInterfaceWrapper) PostDevfileStats(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PostDevfileStats(c)
+}
+
+// PutDevfileStats operation middleware
+func (siw *ServerInterfaceWrapper) PutDevfileStats(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+	}
+
+	siw.Handler.PutDevfileStats(c)
 }
 
 // GinServerOptions provides options for the Gin server.
@@ -2002,6 +2978,22 @@ func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options
 
 	router.PUT(options.BaseURL+"/devfiles/:stack/:version", wrapper.PutDevfileWithVersion)
 
+	router.DELETE(options.BaseURL+"/devfiles/:stack/:version/bundle", wrapper.DeleteStackBundle)
+
+	router.GET(options.BaseURL+"/devfiles/:stack/:version/bundle", wrapper.ServeStackBundle)
+
+	router.POST(options.BaseURL+"/devfiles/:stack/:version/bundle", wrapper.PostStackBundle)
+
+	router.PUT(options.BaseURL+"/devfiles/:stack/:version/bundle", wrapper.PutStackBundle)
+
+	router.DELETE(options.BaseURL+"/devfiles/:stack/:version/resources/:resource", wrapper.DeleteStackResource)
+
+	router.GET(options.BaseURL+"/devfiles/:stack/:version/resources/:resource", wrapper.ServeStackResource)
+
+	router.POST(options.BaseURL+"/devfiles/:stack/:version/resources/:resource", wrapper.PostStackResource)
+
+	router.PUT(options.BaseURL+"/devfiles/:stack/:version/resources/:resource", wrapper.PutStackResource)
+
 	router.DELETE(options.BaseURL+"/devfiles/:stack/:version/starter-projects/:starterProject", wrapper.DeleteDevfileStarterProjectWithVersion)
 
 	router.GET(options.BaseURL+"/devfiles/:stack/:version/starter-projects/:starterProject", wrapper.ServeDevfileStarterProjectWithVersion)
@@ -2034,6 +3026,14 @@ func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options
 
 	router.PUT(options.BaseURL+"/index/:indexType", wrapper.PutDevfileIndexV1WithType)
 
+	router.DELETE(options.BaseURL+"/signature", wrapper.DeleteIndexSignature)
+
+	router.GET(options.BaseURL+"/signature", wrapper.ServeIndexSignature)
+
+	router.POST(options.BaseURL+"/signature", wrapper.PostIndexSignature)
+
+	router.PUT(options.BaseURL+"/signature", wrapper.PutIndexSignature)
+
 	router.DELETE(options.BaseURL+"/v2index", wrapper.DeleteDevfileIndexV2)
 
 	router.GET(options.BaseURL+"/v2index", wrapper.ServeDevfileIndexV2)
@@ -2050,72 +3050,149 @@ func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options
 
 	router.PUT(options.BaseURL+"/v2index/:indexType", wrapper.PutDevfileIndexV2WithType)
 
+	router.DELETE(options.BaseURL+"/v2search", wrapper.DeleteDevfileSearch)
+
+	router.GET(options.BaseURL+"/v2search", wrapper.ServeDevfileSearch)
+
+	router.POST(options.BaseURL+"/v2search", wrapper.PostDevfileSearch)
+
+	router.PUT(options.BaseURL+"/v2search", wrapper.PutDevfileSearch)
+
+	router.DELETE(options.BaseURL+"/v2stats", wrapper.DeleteDevfileStats)
+
+	router.GET(options.BaseURL+"/v2stats", wrapper.ServeDevfileStats)
+
+	router.POST(options.BaseURL+"/v2stats", wrapper.PostDevfileStats)
+
+	router.PUT(options.BaseURL+"/v2stats", wrapper.PutDevfileStats)
+
 	return router
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xdbXPbNhL+KxheZy6eoyVbcTtTf+m0TZN6Js1l7Dj3IfLNQORKQkMCDADKVn367zd4",
-	"o0iRlKC3xE34JZElYPHsYoF9sEtBj0HE0oxRoFIEl49BhjlOQQLXf2EeTd+qd9QfMYiIk0wSRoPL4FeW",
-	"JBCpPxAbIwGqKRKSEzoRSDI0JokEjoTE0UeBRnMkp0A4Us2IhEjmHEQQBkTJ+pQDnwdhQHEKwaUeNQgD",
-	"EU0hxWrk7ziMg8vgH/0l1r75VPR/rghcLMIAS8nJKJfwBqcgDggfKXxCtR/SGMaEQozGHOB0zHiKimFb",
-	"1argqihIJKTa4HKeqaYGSLAI3RuYczzX2kUsTTGNX3GWZ+Kwc5NxEEAlskOgIZ3oUVr0qSDxnq9fK72U",
-	"RjGMcZ7IFl1+YSwBTOuwyVjBniPMAVkRiHFEmWzBaxt5I31h2xuMWcLmKVB5E7EMjmT45ShDKvQ4rapU",
-	"4Wyh00pHqxyHCEuI95sDJ2XTNLh226B2XQzeAlwL4Juy5VuXc6kPkvDQDngp2h/xso+GTESW4Lla+ftA",
-	"JhxZSWYvakO8HM0fcamPQjwh8hpSZnarPTFPiERcC9OwW1BXRvTG/arSq4b8WPu/+nOplvBRSeymk6gq",
-	"dVCFbq+vdtNnB11KesyI2HPtFk5lRK2DW7TYAq/tYwHf5KMXhO8JV2I+AYlEPooJh0gyPkdDyvQGanXJ",
-	"mCDq/XZtDJJtdLE9rCa3PDmA1XOerHGQW554A1RtFTQStbrDOzaZJIAYRUAjFit0EaNShcsMC6GjSBMS",
-	"JdIbx1VkZ1v1uuVkTyMpKSjnZA20W/2pPzrVXgFMMJ3keLLvjpxxNuE4TVUrJ7IFbeljP7ivXQeNl9CP",
-	"R9qHi9WjxkCC5Txq3bgKGP5aFD2cGgcmfRr1kG7GvR1mgzfFD6+xkH+wmIxJK697NwWU4geS5il6lmAJ",
-	"Qp6gBAuJUtsRxViCUgsb/IraCZxmSZu3rAy8hdOUOlkNbvRn74GviRZlFWKYjUkCyMhEM9OxHWhFvjfS",
-	"ai8L1R+kMeNGbNuiquAh1HfyCTWTD5gn5BDTXx16j+kn1Hv6rRLbTf+K/H2mn1B/kF7TXwjcafrp/oR9",
-	"DUun25DzgpNnnP0JkXw3zw4QuZQkpBMjzRBLg3kjfVvqYwHPSAx7sT472U5UO1r3sTdU00Hh5GADyGGD",
-	"05A6wapFa3gqRvcGf130UOiFxFwCt8Y/JkewIzn3aVNoBZD/rrDSTyuXZxnjB+HcM6DIilPsuw18MeDW",
-	"BFziyYE9SElswWk/2iH9aRw+Y1SAMEj1lv8b54xf2w/U+/aEoFPYWZaQCCv8/T+F0uixNHLGWQZcEiMO",
-	"lJw6jjB4OJ2wU4teDxYY55W52NT8xrRaLJVhI+UjJlVdAjfHafKEwFXzbMFl8BKTBGI14+oca/Jn2vq9",
-	"QLfVr98w+ZLlND7AZHxm8x7TYGNC4zaL7WSp9alHLdfDAH5Sanrd5FEEQozzBCkDaum9IR3SGx3uHA2z",
-	"ymhdp4ATOT2AU6QghDpabpimP2wzs2F8ygmHOLj8UHS/29dbjofD39y/a6Mi47jazITG8HBwh7pSUg3t",
-	"3dOpqpL8NdX9Kg6Vgpyy+A2TPycJu4e4c61dXOsPbUWUC4gREYgy6VgGxL2gxs88jPwXyaq6jRlPsQwu",
-	"gxGhWJOAlRi/hRu8VNvKaC5B846Y3dOEYQN0Nrja2fe1VR0q/W7P+mi4/OyUpMoupkQupybVOc1HvYil",
-	"fbvl9TlMiJB8fmqt2NcLsj8BqtRg3C4Eo/R6n/gioPyn4v0ArS7KhWN02ourZfkaqfy3foETlBAhFa/M",
-	"OFMjsZUnBJCc4grZcA4qQgRpJudGgMgnExCyoXmEKRqBcXFGEabzygCKoTryWUVYVsBWCkcaD1QEuHMo",
-	"0DxVyw+n8Q8XQRhgnur/syz64UKnS8TzH88eSsuyjeSGQbVEXkP22prMlelNkR65JxIIdcqXlXP4RjlJ",
-	"4iAMeE7VWgQhAzXpo3wSuCr3ZoxhkFPyKYcrI13yHBZh4OrlNcAvEzxBY8aLMr2bHOeaCKj6d5kVsaON",
-	"TN050MJXCtetZlnWxpGpoRunMCVqNWvGRiUnabIToRR4wlgWhAHLpX29s2WK+vU64xT182b7tNilJGxV",
-	"dulDY5n1Ypc6OfbXItEtBCF5blaBThFGCcvjU4olmWnb3jP+UWQ4AqTcNIYZJCzTEwN0Rjijqd45wspm",
-	"NzvHSTbFg96LYnK22+9wRvqzQT/7OFEvRb9AIfpOtt6sygXvmp63AjjigGM8SkwuYTsDVqvSNfGvKvW+",
-	"ldr4emFrPH/SKlWUXXyLarqXd5cqvFtCc4VEH2Q6U1HfK8vl2vpzKxzTaBoiiSchYlzvmBrIGDjQqM3Y",
-	"tmZaz9eUa7d1pSRTYQZhsX4AXWtsD4jOx3QNsSh52ijTKOyWE195OSehC4kY3V6/VlbBiENiFq1aVW5z",
-	"tJmyxlFLPL4x260pBipl41cW+ediNGFQFCNrON82lECRzRY6oxV7R5MVKjWLuud711Hgwby6DAZng4vT",
-	"s/PTs3PFHLCUwJWo/w6H8ePFYjg8fXb24fz0x7v/nX84Ox/cnZTe+XA+uPtwpl49/3B2fnfyXSPioqZZ",
-	"g/vGPdriVHeVR8/cXPPWYAqSa0J1y1g7bATNG+2brXfuciGg7tvzDCxbNUWIBmLaItSk7FsBuirAqgdu",
-	"BrzMqLfa2Uqy7TTbaKE/G7lptfzVwA+aanCI5ulIVzhKvt4b9M5CpP57fqrDctXntTP/azjsmRfPyq9M",
-	"+5OfTn5qdPPVZHyrXVaKAvVQ2TxXq93KVZBdVony6DoD4QkaE0ji1r1/4yQ00+umyTjvXfTOPO3faHR1",
-	"+IMo50TOtYcYbxxhQaLiXKuZq36n6D6VMjOnYULHrEETFuWKJOJWCnv928079PPbK30qfde0cFwLRIQ5",
-	"Bir3J1QCx5FUm/89kdNatx66UmGQCBSXMYR64UyZkEqcAD7T0VmFy3yUkKgmJ0RzluuQG00xnQAiUhGF",
-	"Ocs5YvfUihrrVveYSsciMk5mKmbUcCnjEaln7UWrMYIwcIcpNblnvXPlMCwDijMSXAbP9Vt6vqd6pvrG",
-	"9glIvesVh/2rWI+j3r9mTP5G44wRqkhBpQ5zcfZ927ZdtOu35uy0A0xANlXIpEB5ZoyOaZwAL3YvzphE",
-	"z/onCCwodb7XpxLgM+BDejVGU5kmaqI4fMpBqKPVM9KDHhpzliKM7mGERpzdC+AnZmZnBO6Bqy72GVqI",
-	"Q8TkFPg9EVDZmQ3HsV4AsbJ51Ww36v11VouXB+YnnagNAwkPsq+MGVw+1rNESkfkNLOZoDxNMZ+7D5dT",
-	"NF56q5knnb7LmJB1v3vLhDyy12V507j5cYddhIHjsqL/qAnhYvP6W56Hy9/R+dBEvw3HLD/Eocl0ucod",
-	"fQzKeWMVicoV2Q01sOijmrWGN+8+08ZwDTLndrlnEJExiazWK+WnpqDRslT/FgYOm425RNxvfnLKp2Pj",
-	"E3dmSvX2+QuL59XNqrYVKOPY1mjE4jlKc/UKTLJWr/WKfwzOzjb7x2qldBEGF2cX3v1qNelFGHy/xbjV",
-	"pwuqu9srWGYzR/OSX+g4jSfKgVylN7hbu9N9q+u7bQv+Nu3RFBv69sBx6h5e0h+Ujjj+0aN6NHryW10j",
-	"nNqRzWWsKgGgFW1V/51hl8Us1n/6uYLiS5DRFETNRjZCmrrHkgoviVglcOrj0JDa88M/RRFJMY1dmkIg",
-	"TE0ZbAbo2V8kOzE5BleTRdgUCH5/9+5tiRmuC7udZ34Bz/xCbGJbAtDyGMKSB6xkdvEE9MMMYxX2e4eM",
-	"921LrIj9dpEUHhD4xPzO97+OXXkDlemm+WuY5kaG9mjDpT8T+w+R0+W3PP5unuByycun6/XoDcCWD3Xs",
-	"Bq34ZkvL219BxqFzhcO4Qpcb+cpzI91CebJ75gbq083cE5259WzmaJmnzh8OFvI6mv6N5si6NdStoS6b",
-	"d9RsnjJ0WL2zITxEiq9but3SfVLJyM4hO4c8ftrUfA1885HBfLP51ylYJ/q86b3aE6TT6hetGxnaGshe",
-	"QXHlG/LewbCWcamBdZkW87jdhkTLcS3ftiUdc1Tld/pRTe+Tqn748f158JlPJPqZVudypS/QlNLK1UOI",
-	"WuxoSPWzrJVDxNozxFK7lV1+AwNc3irlQRdr19369Fm91dejT9NV3x7dlrfd+IxR3IHu0Xh5kaVn4+Ky",
-	"SY/2tUu0PPpU74v0mobqZdAeXVbuoPLo0Xjvk4821dskPXv4t266Onibblt1KS6G2hbYNr3Kd9f6jlO+",
-	"ntfPLUtXpfnM/srFWE+gjFK9OWXn6Cvsxm2fo68QUrdvi53LH8cLTBvOCscauIjN/Uf9n9rZFtvGaXV0",
-	"sVf9bTy3VEKnubismXMXcHam21eFhEXrB3dPkGK42vZBWMbfeG7CjhF1jKhjRB0j6hjRERiR40KVkKO2",
-	"7H3JUccGtud1nc2qlHQ22CVhNPiiCSOXAh+08LohbUge7UTqBl3qqCNKbdXlHerKW3c5cA37KASw8pt2",
-	"3xRhbPh9Qj8TN/y+XsdPnyo/9Vqm9R8h8VulDf2+PB9evWF2D0b8fvAZ0oSDL5UmHARH5GR7JAoHHc09",
-	"ArXUP7DRnDTcjV12KcOOCXdMuGPCHRPumHDHhL9RJnys9HDHAXfg853NSkcRfdEpnzkL5Dyx15iKy35x",
-	"MXVPSDyBnvu1J8L6er23NK40u1v8PwAA//+Y36ezkoMAAA==",
+	"H4sIAAAAAAAC/+w9a5PbNpJ/BcfbKtu7HM3D3tzuVKW2snaS9VVi++xxclXWXA1EQhLWJMAAoMaKb+63",
+	"X3UD4JsSpRmNJxt+sTUkHt1Ad6Of4OcgkmkmBRNGB+efg4wqmjLDFP5FVbR8A0/gj5jpSPHMcCmC8+C5",
+	"TBIWwR9Ezolm0JRoo7hYaGIkmfPEMEW0odFHTWZrYpaMKwLNuGGRyRXTQRhwGOuXnKl1EAaCpiw4x1mD",
+	"MNDRkqUUZv6DYvPgPPj34xLWY/tWH39TG/DmJgyoMYrPcsNe0ZTpOwSfAHwa2k9FzOZcsJjMFWNHc6lS",
+	"Ukzbi1YNrhqC3LAUF9ysM2hqAQluQv+AKkXXiN0sF3HCvpMqpaYHN1ySFSNzbATwAvC2Y0iufuXZFWAU",
+	"sznNE9MDre08eBv+XgEL4YxkmlIRf69knum7paFMMc2EIW4KMhULnKUHkxokgxF6XuuFGOVKS9WDysWS",
+	"EdvAr3dGFwyQUMzkSoQkokJIQ2aM5JrF5JqbJaGA+Fyzvk2wIw6H2TYHYN3m9kD7dykTRkV7jTnCviZU",
+	"MU8fRCoiZB+EJRENA/GFa29hzBK5Tpkw7yKZsQNRSTnLVGicpxeVOjg74NTo6JBTLKKGxbfbAz/Ktm3w",
+	"7XaB2nex8BbA9QD8rrryvTKy0ocY9qkf4HLo4RCXfRBkrrOErkGc3gZkrogbyQr4PojL2YZDXOkDELNP",
+	"GRWxk8/b6cI2x2VlwihuD58ravtPDFVXhAsjK/KdcKENozE05CJK8hgw54ZQTbgOi3EAqZgk/CMj1C4H",
+	"UUzLXEUMCS9LaMRikouYKRBTftIrMpdJzFTPItUQHLxM39Z6wULNOUviPoHwHbwkGTVL5H+LJmCO66CY",
+	"zqTQLCQ0SYgdCFFy7eIBZx92Ggz+d7a5hRvoa8vOOiJkn0BEaWSUJmXCtmuaZkkvOdr2O8CIzRHGhBrD",
+	"xHbyA4JIVsydZgrGQ8CyJF9wof1BF7PVnCPlGQnExMUiYcTNwmL/vg8P2244Iq49YLLg5i1LpdWobikC",
+	"FtwQhYMhb/RAW5txMMzf13q1ID+Ujgp/lmjpISjp/XDSdaTuFKH3b1/uh88euFTwWHF9y6OwICo71CZw",
+	"ixY7wOv6OIDf5bMXXN0SXEPVghmi81nMFYuMVGsyFY7NLS6Z1Bye92NjIdkFF9fDYfJeJXew6rlKNhDI",
+	"e5UMBhDaAmg86iWHC7kAkScFYSKSeOJGUhgmDMmo1izugQSGHAzHy8jtNvR6r/gtFwlGIbniG0B7j2+H",
+	"QwftAcCEikVOF7eVyJmSC0XTFFr5IXugrbweBu4PvgPCy1NuNth1Kf3E0zwlIk9nDO279ildtfRA7wCF",
+	"w/7NYtDoc9Fv5eH8w0HH1hZu8fFA50fB9TAHsXqh7gXfgbEDCr6HR+OObT+Eeiq2w70bzBbelH76gWrz",
+	"o4z5nLN4AOE8Tqhh2jwhCdWGpK4jialhgJZXwKVy1NQDcGPiHYi90slh8A7f/cTUhlOuioJX8uyYZGU7",
+	"9gNaG38wpPVeDtThQNpl3ArbrlDV4OFi6OZzYTefUZXwu9j++tS32H4uBm8/F3tsf2P822w/F8OBHLT9",
+	"XOwKVRUecXtDY4N1IXYxKgpbwnoQN6zPtnNLf+RZj3+SFB7ILngL3+UwiF/b5gizitkmV6qWyhBsFBKq",
+	"IyZQqdpquGOP4fBgawAnU/KfLDIX6+wOlBYYiaDfvhvIymSDQX1T6eMAXvH+JRwErf2L+KH6ofWvB4Nq",
+	"OwCcONimfbaA+jm7APglCAPFfsm5YnFwblTOqpCAUGRiYZbB+WnYDJ/cQE+nBNytgjEVfmBo0atiFLMP",
+	"Xr23RQ+AXg8U0+iS6xHSgEUkxYopU3PXGBkSNllMyNnkdHLSg4C+IzluF3YDfwEWsHuwCw5CK5uwo9Wp",
+	"4b3eLgRMk7F88/OAJkkQBkzkaXD+wf2FWwv/27P3souKtFSbBCz6CxFa6Ra5Q9DO+ggcOg1fWmiMIBmq",
+	"wNVoBcMhTQA3kxdtfbTeAGg4Ro1+Djmj3wFQPXjB4UdK+KKPgAz4LtwDw7XhEfiNLOmUCG6mnYIYCtA7",
+	"iAGA+5mLWF730QRPGbnGFhUgPUzuMDMypuuQ0MrxHNO1JjqPluCtv/qP+Apex7miuIHFi7NnyyvQFq9o",
+	"klyF5OrpSTwgoGvh2YZbnmVS3YkPZsUEccOBN6ZvwYsJd3bIGLq4Y6kOI/YJFftqj5C9PYQwJKEtpCjd",
+	"vlVKqrfuBTx3HiP4SbMs4RHu+vE/NWD0uTJzpmTGlOF2OAbjtOEIg09HC3nkoMfJAke7ud7W/J1tdVMi",
+	"I2fAnPikCtyapskDAu4mbEaKKE9YXJUNbvUnAbbF36+k+U7mIr6Dzbjn5T3kgs25izx2rNheK7U5sovj",
+	"DliAYaO08HqXRxHTep4nBBYQR59MxVTgIVPoRA4ZxHUu1YzHMRMPgS5uwg61g8dMGG7WhGsipIGDTl7b",
+	"3cvyWcL1sjwfEaUlo4lZ3gE+KdOaLtg2hH50zW7CIBd0RXlCZwlzeqHuOFyqBrwm10seLcm8IMkZI1mu",
+	"l/YPi5lUdAGRXk0eTfOTk6cRIos/2bl94oazzx4F4VDJXUflfQf8NzdVy+RDsSqXt+XrcXl3XN7h/P4P",
+	"ZAFiGRKZgouYfbpzifYSRrV20C2lWn2k4Zhiv5pES5lZyviVNN9YQfEFBMFD4ZhbkNaPuIrWSeYEr1Ni",
+	"WYzLLKT5JopYZoCaRw3vsBreRcWjUXowNVMr2B5BKElZzGnhWwC5dmW354osGUUPo1SVvs5XUopBIBSm",
+	"4UHDsQIuUpkbkkgNxtCcUUxC9kTgfe5VEmgD7wPFMY+RlqIlFQtGNBeRzbz59oIuCshfzo9eScGOfqQm",
+	"WnoEAH546wMKvqUH4OgdDOZbTwJUBGKX4v2cRkt29FwKo2TSAyI0IZlMeLT24xdyZbM9CcB3D6qNkmJR",
+	"w27gmBDPKHDrHjyV2hDFIljY7qDL4PlgRqdN/UZNtg1KfreaaO3lu8L27uF/KVY04XHNeQw8UNYuVNDY",
+	"C4NCh9nouMLx/8FNt9E/1ACxSCy50eg+9PmJkWIUxYpiCVtREbEG4/730YU0NDl6LnOL0sbATzlLSGZs",
+	"LhUmqHNhAeniAC4MW6AbH7DRfCFQvB1IU/LD76HiFKDVlB0kZ1uVMADkxa88q4Ps6h/OgxkXFD1BbUlU",
+	"HWGPAYYjavEgLgcXODeW1yKRNC5x9ZGDHmz/ePzHw8Hn5yaztWHaxg5BprTPXh8R8XBXXL8Dtumgi/wd",
+	"TzwCHQts9MMXiKUABHHoMaj6nmOuQSutYHXn/AyA68EL74zX3Cyl4r+y+ME6WyLF0N1CE5tNnnKN4lkq",
+	"wu3yIy6rs5d725MInocZn06c3ReW7454mkmFo0EGvM1nXOazSSTTY6cJHyu24Nqo9ZGzTI7RyD1eMAHr",
+	"LpXbLIvrZnX/iwA1nGl/OiMNQ7d2SELWVl/MTmkTkgzSc2WuQyLYJ4MBO1QYoXpLg+Pj7XfPyV/O/vIX",
+	"zCnTE/LGvlA2Nc6e1jYPglwvmahaDEQzzJh370McfbYu6sTMkqlrrtk2hXe3k74deUzBWMBwzZK50Mde",
+	"WkARkMalrRdetqB6jT9oQhKusQYxUxK2UDZqQIlZ0npg2pGHDglLM7O2A+h8sWDadDSPqCiSVqQgVKxr",
+	"E1Q9Uh1lkq6VK1uaITysNoBP5Siixmn81bMgDKhK8f8si756hklb+ulfTz51BJCb2mEY1KolB9Rv+hQx",
+	"W+NTAQYOxDAwVE0Wv3bO/DzhUJeGMrk10bcrW0aZC6PtNBE2r0wZkrmSqTUp7Vil2ZyLj0JeC9fJMgUO",
+	"xmKMUyKBXwVhQz7b5p0eQgvK1nIhAPu5bdr05LjBi6HaHp0wqJd2tlblB0ewvrzUFpcSX/HLRbWixZOW",
+	"35BZzpM4CAOVC9gYpgGWmM3yReALHrdTCByJ/JecvbSjG5UzABvFRhen0V/yzurTuQRHH3A+LQQdybxL",
+	"14ZofcwfpB+mzfoBqu2DDoh9JWe7PCuhC6DdooDUc6o/ALAIbV1J1HNjz2zpkR28UVLZu0tl1Sax1Z1W",
+	"QtjiSWBhu2UVidG1bVwIphIpgZtkbtzvPTeqUlm5aXF8o5716VmXymDNsSsvK2k0vcNWtxJb9o3opaI2",
+	"Krci0ckKmcdHcHascG2vpfqoMxoxPHxitmKJzHBjmFhxJUXqzueqSrE6pUm2pGeTF8Xm7KZV0Iwfr86O",
+	"s48L+KmPCyj0sR8bhUS1FLOF53vNFFGMxqAcY/7LbgtYlUig5sUxt6ffm5rkK6wVLsxXz8qBikO2qfa8",
+	"Ko51tkIRO1vbXyF5BMfio5A8WnF2Df97df8RLv8jnRUPgg4ZWC+57CbTegWqNz4rxxFW/HGjy3OpTbGu",
+	"OLIjRSNNKdEMbBZggnm1qBOCm6jV+XJVLEm1ZiKLy9S1K9iqsFKZG/ro1sT9uOraLlcMuUOBplwxVQEL",
+	"gdUeiMe+/IR8Tf6Trih5/ZZUHn0vn5BvXr0gr15fVLj+KvSKDsjnb169CMnrtyE0Cl2x5ZJpZhU5mNiq",
+	"vVJpcvU1JP38G/77f1fkcSSFoVzoJyG5sjG5q+LX18VPdoVDuT++7l4XV1vZSw6uWJPQgjfQ1AcS6CgQ",
+	"7aSHei1ka6bva1VmjYrMFsC1wTYcEoveUXX1NNihhnPQQVD02Rk0X742BDLMh2rrmNUiwTalKyogrdLQ",
+	"hQ1/yDRFQOZMMRH1Lbar1GtHlqsVg22kjAT1HHSOjRNghVu/IVHUGUfVQjunnXcOBgVpA8fLFQ+9KUHJ",
+	"+7c/wKpQcL7a8w0Ek9cjnCDqnLUSrO001Lz8KNJ0G+fhfZnYHtTC79oJbeladVphCX433I1BDwl/UcLX",
+	"gvxNR+Fgs1i9UBO6drFWMdPm3MFVPOyT/XUenJ2cPTs6OT06OQ1CwN8wBUP9z3Qaf352M50ePT75cHr0",
+	"18v/Pf1wcnp2+aTy5MPp2eWHE/j19MPJ6eWTP3RCjEWBLVB/HFK5iMA7Ld9VUgXnpycnJ1h+4/7s0lXK",
+	"Mr7O/Fxd1aF8sd3g7JAuefoDDrLBFOiZaw/p2X06vdpZM3Q1L+2RBtTkeBeNt+eCyoacdG2ILWhpS+ft",
+	"xTSFc0NHgVVCO42fahlKW2A4N39RAtPh0wm6B7UFI73L7WtQmky8ffnLcopeqnEjuXZom/UYi1vdOvW6",
+	"hw5rqrM+wzJnXVxMziYnIYH/nh6hEVMXGygP/jSdTuyPx9Vftv2Tvz35W6ekKKOWbb9TU4SVnkParNRp",
+	"RLRx/TcceA7lWsZ+VVJ+mSNwyRfLhC+Wm6221hI2uEvwLGOmuA4F18wbNGix4S/UNsNai2up3NU0TESJ",
+	"1NZTYRV3luL/zOektww4Hcm+U7uIGPesd2ijzTBxZ8TZDh2W5mos81nVvHMU2/S9+X6VZQ0daXS54d65",
+	"vey63GdrLU1YVKrYX9UbnOyTdt6HW/1KbohnQcfz5TEJRrWd0lvRxf7iuE9PsFijIjmdiVK/JSqp1+uW",
+	"Hq9MZnlCFTfd3kDM1BzstPVFK11uVj04naHqKe6QbXu4Zn3xRJfkhIDGplMRNiAsQ5i4+fU6pPKl841U",
+	"iT0IhzhbVpVU2UFr5ER7zyI12MEbrYhqsYCVWcNijzrZA/B4nwHh9qTy2pfOMa0rnM6FRR5I2+aTgQ9B",
+	"yXxhw/I+7eebNy8nLbpxSkHvKQYSplJamNKPzB2Y2K94V92OSeskqgdjX9TnbK5kA6Se1apVkfUe900q",
+	"ajkBulWQZrdqZec+qmwPc7/oiNkXmRNO2UFfFCWmLDJr8/4+zIoph10CWWkD0s4DUpk4tPeaFErT9VIm",
+	"jCy5dlcElScIUHHHSlnZ3kPfPQlRnqGGJWmVkrRDqOXC8KTHwuvGeDtSDdq1U1TY36HcRcTvVQcw71Xi",
+	"C0x7XB1bFc7uwEuX4nk6eTY5Gahr9iiYNRk59PSqBIPugJJX5YJs3pxy1t5wIRApi3I4qlEjtWDNqOZR",
+	"kSiBLk58UnRfGpMBKDNGVZdp840A6UuM/MhQ/Xj98sVz/1ed34uwPM5ih2tMc4NlDHPZJU+iPGXC0N6g",
+	"0Ntv313gQQDZFBdL1t+CcG2j7HPMeTFM0Qid1+gDbnabkJfgLeOaxFUYrA62lNrAcJqplU9MwEMpao0T",
+	"krXM0TPnMqO5gWNnLXNFIPhsh5pjq2sqjHc2Zoqv7OnYgAsWj5uEdfFHsRgVZQ2Y4mRyCpspMyZoxoPz",
+	"4Ck+Qj5ZIkEc27VPmD2tiySVlzHOA8/fSmm+FXEmOQaoa0Whz07+3EfjRbvj3voNJIBFl5vhHTOa5Jld",
+	"dApBGlUIayWlIY+PnxDmgCLS+jdhV5iaipdzsjRpAhtV5sA/5hM2sUkBlFyzGZkpea2ZemJ3FvQ2pqCL",
+	"04RZHJaZLo37IsEydFQAKWlhY9newfNNqxaXIegHXbQTBoZ9MsewmMH553Z2E+BY3Fs6cYXYaUrV2r8s",
+	"t2heUqvdJ0w7y6Q2bbp7I7U5MNVlede8+WGnvQkDb+/r4894gNxs578ywly95P5Dd3ECnEnVm3qA0dvX",
+	"BPTfS7Il9TD6GNx0Pry8J8HwFu+ms+yesYjPeeSwbtTCdh0aPaz6m1jgsHsxS4iPu6/HGtKRftqvY+1G",
+	"3gHtO66FsYSDQvrvMl7XRWJnQqtrTWYyXpM011j8hBl3KFFqVHh2crKdCpvF4Tdh8PTk2fZ+XaVSN2Hw",
+	"bIc566nZ2PnZ4M6t+n/s/9UgwDsK/W7C4M/7g14T/d+zMnlqtq4wDSoxdKGtcYwNgsuNx8DvVfi586mR",
+	"e2I1yR6vRZH5WOhCcamr5OhziYn05XnO46IJ5oDDu0KTrrhZnEKEufckFwnTmBhMMauZqDxhZKGoMNaL",
+	"c624YSRjCpPMQWl2zGqdtpAtz4ThNnsMtG97N3LluVQtv8GEvBbJ2ntTXajbgQhKmvetViohreuoLe3f",
+	"5L8heuqRibercCg8czc3N03I9xKezbqBoQKwszQRO59u79xZeIGdn27v3L4f407k7q04Hgb46/6L9uf9",
+	"V7ziJkA28Hb/h0s4z5274MPlzWVVvjsHLq04SZ1Qd5MEl5267rHzRh75K7mOP7snzv85XBuu+00fvOrW",
+	"CU7Ln+sTdWoKbS+0dfz3Brs6zM3mt/el5H/HINCoW2vkNH4bbyxN+9KwrBkC6N6ZCndCPtKFZYCpfjY0",
+	"b08zlyH6+FeePbFx9TKAY0+2f1xcvKlYupvMiJEyvwBl3r91dLnPadlTOFoeQI2EMEgAExLiFLmIJ4NF",
+	"/QAVvY/FCnXdMUlBAcEQNX2k/X8NqdznHRu3+V9omzs1tM/uuGxoYs34HDxvxsDCul2pWCpX/kJubrRN",
+	"QHFtJ+Siw4gtTTg7czwV10s4jsuBl1RbtzwpKib+5c3WmuL7MzfL8l7j3xrjdSVZdANWhjj3A63Iyeh5",
+	"3D6/nwXn/StY3J1EtSfOyWiv/kbs1W551W233n/cYeTou+HoMUIyRkh+vxGSUYocRi84VCznfWb9Sw39",
+	"gmqS5onhGVXGfqIDC/pQfX39/CXhKfgEErqWuSGGqhlNkhBesk9cY1aTHwlVcPs53IlVkOszVUM/YeMG",
+	"XZip8Kr5St6ekNLvKWw0MtkdMtmQANdKxBMZ8QmS/cSS/WR1+idDVT3u1cpS7GcWWwyxZsqSV/V2t3oy",
+	"frFg9qPT5ecWuEkYQbopLiAacu1ewdig06dHMTV0031g3VdbXGwFNywCM5D7Zf3Zlld7cu0n1aTkrYhs",
+	"qR6wJ1bn5WRjuPH3aX69cQfDIPtrs1fq2N3YsTVM+K68RnOU1A9LHepO+DWK0VTjR49a4q031FfRmooP",
+	"uP/KM6yLw7vFpsIF90Jy5X5NDFVXqBBId6NCskYDCa+MQQXHaUGW1nqM95HA7stqn1UunRtsSLPqPUEP",
+	"xpDuutz3SxvEd2XSvigKTls83C6W2c3EHXntodu2LYNp3LIHumVb9KuCb48/+583A7Utf2fFuN+3Ow77",
+	"7b2OOHVh8+GVbolcyIle4e3OFXWnGxVV7td+uBQbftP3/L4TxgolsFixgRpkWHylEiPVciHDqahrjFKR",
+	"lbZ3SOlw2FXx/YrjyCsjr3TxypfXTlufY7h1sOdhqKeliKjKhjvRTEdmHpn58AffRk17JMGRBA9Nglss",
+	"h0PVdowxtwNS95gI+7uoQhl5aOShsV7moPUy9gLHZiD+9kU0I+uOrPugyn1GghwJ8vCFSUv83v12k8F+",
+	"F//5kjkiut8riFp3zi3rn+nv1NA2gDzoULRz7H4YthKQW8B635e9oGuL6+uwK98nkg45K9AdZrcOtlQx",
+	"Ge2n0+CeLZLataqVL3NUSlDqRgiGJ6YCb7+rGREbbYgSu4aU36IBgpwYrC5WLp4e3qdclcF9qDGKz3L3",
+	"yZ3B3YAlhs+houXgxjySYqfG7xUf3D4rP3gwuI//5MgO2+A/CjW4i/1C1eDmRTR6cI/6CTi8X+K/CbJT",
+	"j+GtF7UvPu3ebacu71WyH2C79LLfVNptHvtZp12oGL+fMXz37bcTSvy/fFCNN/OV7yCYtuNBr2sfs2gk",
+	"jXuP7t6FR4c7A7eYJYeauFADjj/jfyBEb3ZVCcBKurD3Xm81kWqntJWQ3ep9Ac7emv3LYoSb3heXD1Cb",
+	"8SW3d6LQ/Ib3JhyVr1H5GpWvUfkala/ftvLl1a7a6Qanw231sFHx2F2FHNesrv3q+tdVN2m9rQ+n3rvq",
+	"2PrWa5cu6T6j56+jotgLquU/snVY/IGMyRdMm6mQc8JotKx8z7ushC9ygmxjTXzjelWa/SwFx+99TKbi",
+	"5yWrlcC7L9kxExIJpeoeGRYXnw+vWmqNT20uGeExE4abNRaz2YGnwo654cMUm3dsWFjW994qHi/a+8E1",
+	"ykuLaafAHLCru0vJgxNqn6g58MTAr6uzfdzWZ1/Ube2Z5KzH5JuKDhf2Xvbe2ejAHm2ovhyXPbJbdu5y",
+	"77dOHd42dDc3/g5tyUimKRXx90rmmd5lRxK5Tpkw7yKZsdF0feCm6yCurn5ufxem3quflmo4u+FH03dg",
+	"gJTvMDZ+GX44v+RKS7WDHGJJrB+MA6Hjvpkv4UL46eweQjhnXyqEc3ZIpfgWQZyz0S9wAN1+KqourztQ",
+	"78dwzmiKjKbIaIqMpshoioymyGiKjKbIlzBFDhXQHJXwPQyqcc2atqBmoFAOL0G3ze/ta/dUFVaULSR0",
+	"qrz/q2weEmCmkHgNs1ZjWLmGaCraUcOQKCo+2quMFUvYioqITch/5UytiWEq1SSlJlqSa6li7e44WmfS",
+	"DgIPpyJTbM4/MW2/4GOXlSim88QU12xbKvLBVkCqpEIvL7wc2VLG7PdhtwDOL4DR8PMMJ9lJvx/NstEs",
+	"G82y0SwbzbLRLHsoZtktbKcvb93YM/hevzBkp9x06UNNL2urU3tbNgfTLrcV8h9mXqdhG2r0Lnc8GX1f",
+	"+vVzmftvzSRcm5CsOLsOK9frwJ42Ku+Ll2wFABDFIqni4jMgU1FEO+SKKUKJ4Snc9iNieR2SjClLMGHz",
+	"pnoRkyjhTJit9/fg+uym9+Ie/Iww7HKMGo1XJjyka9KN3p2fodd+16N3de3OSyzIArpwbXjUyNLcXygc",
+	"iCO2X+5xgGlx9YCcHd3mKgnOg6UxmT4/Pi6+0aQNfNbHLdOEy2Mk2J7GtWaXN/8/AJfRqZEC6gAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -17,6 +17,7 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -24,20 +25,23 @@ import (
 	"net/url"
 	"os"
 	"path"
-	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 	"github.com/devfile/library/v2/pkg/devfile/parser"
 	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
-	dfutil "github.com/devfile/library/v2/pkg/util"
 	libutil "github.com/devfile/registry-support/index/generator/library"
 	indexSchema "github.com/devfile/registry-support/index/generator/schema"
 	"github.com/devfile/registry-support/index/server/pkg/util"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/segmentio/analytics-go.v3"
+	"sigs.k8s.io/yaml"
 )
 
 type Server struct {
@@ -101,10 +105,9 @@ func (*Server) DeleteDevfileIndexV2(c *gin.Context) {
 
 // ServeDevfileIndex serves the index.json file located in the container at `ServeDevfileIndex`
 func ServeDevfileIndex(c *gin.Context, wantV1Index bool, params IndexParams) {
-	// Start the counter for the request
-	var status string
+	// Start the counter for the request, labelled with the status code of the response
 	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
-		getIndexLatency.WithLabelValues(status).Observe(v)
+		getIndexLatency.WithLabelValues(strconv.Itoa(c.Writer.Status())).Observe(v)
 	}))
 	defer func() {
 		timer.ObserveDuration()
@@ -150,8 +153,55 @@ func (*Server) DeleteDevfileIndexV2WithType(c *gin.Context, indexType string) {
 	SetMethodNotAllowedJSONResponse(c)
 }
 
+// ServeDevfileSearch serves endpoint `/v2search` for searching the stacks and samples with GET request
+func (*Server) ServeDevfileSearch(c *gin.Context, params ServeDevfileSearchParams) {
+	serveDevfileSearch(c, params)
+}
+
+// PostDevfileSearch serves endpoint `/v2search` for searching the stacks and samples with POST request
+func (*Server) PostDevfileSearch(c *gin.Context) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+// PutDevfileSearch serves endpoint `/v2search` for searching the stacks and samples with PUT request
+func (*Server) PutDevfileSearch(c *gin.Context) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+// DeleteDevfileSearch serves endpoint `/v2search` for searching the stacks and samples with DELETE request
+func (*Server) DeleteDevfileSearch(c *gin.Context) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+// ServeDevfileStats serves endpoint `/v2stats` for the download statistics of the stacks with GET request
+func (*Server) ServeDevfileStats(c *gin.Context, params ServeDevfileStatsParams) {
+	serveDevfileStats(c, params)
+}
+
+// PostDevfileStats serves endpoint `/v2stats` for the download statistics of the stacks with POST request
+func (*Server) PostDevfileStats(c *gin.Context) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+// PutDevfileStats serves endpoint `/v2stats` for the download statistics of the stacks with PUT request
+func (*Server) PutDevfileStats(c *gin.Context) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+// DeleteDevfileStats serves endpoint `/v2stats` for the download statistics of the stacks with DELETE request
+func (*Server) DeleteDevfileStats(c *gin.Context) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
 // ServeHealthCheck serves endpoint `/health` for registry health check with GET request
 func (*Server) ServeHealthCheck(c *gin.Context) {
+	if unavailable := listUnavailable(); len(unavailable) != 0 {
+		c.JSON(http.StatusOK, HealthResponse{
+			Message:             "the server is up and running in degraded mode",
+			UnavailableVersions: &unavailable,
+		})
+		return
+	}
 	c.JSON(http.StatusOK, HealthResponse{
 		Message: "the server is up and running",
 	})
@@ -172,10 +222,69 @@ func (*Server) DeleteHealthCheck(c *gin.Context) {
 	SetMethodNotAllowedJSONResponse(c)
 }
 
+// ServeIndexSignature serves endpoint `/signature` for the signature of the registry index with GET request
+func (*Server) ServeIndexSignature(c *gin.Context) {
+	// Sets Access-Control-Allow-Origin response header to allow cross origin requests
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	bytes, err := os.ReadFile(indexSignaturePath)
+	if os.IsNotExist(err) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": "the registry index is not signed",
+		})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":  err.Error(),
+			"status": "failed to read the registry index signature",
+		})
+		return
+	}
+	// only the signed entries of the stacks and samples the identity can access are served
+	if accessRules != nil {
+		if bytes, err = authorizedSignature(c, bytes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":  err.Error(),
+				"status": "failed to read the registry index signature",
+			})
+			return
+		}
+	}
+	c.Data(http.StatusOK, "application/json", bytes)
+}
+
+// PostIndexSignature serves endpoint `/signature` for the signature of the registry index with POST request
+func (*Server) PostIndexSignature(c *gin.Context) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+// PutIndexSignature serves endpoint `/signature` for the signature of the registry index with PUT request
+func (*Server) PutIndexSignature(c *gin.Context) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+// DeleteIndexSignature serves endpoint `/signature` for the signature of the registry index with DELETE request
+func (*Server) DeleteIndexSignature(c *gin.Context) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
 func (*Server) ServeDevfileWithVersion(c *gin.Context, name string, version string, params ServeDevfileWithVersionParams) {
+	contentType, acceptable := negotiateDevfileType(c.GetHeader("Accept"))
+	if !acceptable {
+		c.JSON(http.StatusNotAcceptable, gin.H{
+			"status": fmt.Sprintf("devfiles are served as %s or %s", devfileYAMLMediaType, devfileJSONMediaType),
+		})
+		return
+	}
 	bytes, devfileIndex := fetchDevfile(c, name, version, params)
+	if len(bytes) != 0 {
+		bytes = renderDevfile(c, name, version, devfileIndex, bytes, contentType, params)
+	}
 
 	if len(bytes) != 0 {
+		if devfileIndex.Type == indexSchema.StackDevfileType {
+			recordStat(c, "view", name, resolveStatsVersion(name, version))
+		}
 		// Track event for telemetry.  Ignore events from the registry-viewer and DevConsole since those are tracked on the client side.  Ignore indirect calls from clients.
 		if enableTelemetry && !util.IsWebClient(c) && !util.IsIndirectCall(c) {
 
@@ -196,7 +305,9 @@ func (*Server) ServeDevfileWithVersion(c *gin.Context, name string, version stri
 				log.Println(err)
 			}
 		}
-		c.Data(http.StatusOK, http.DetectContentType(bytes), bytes)
+		// The representation of the devfile depends on the Accept header
+		c.Writer.Header().Add("Vary", "Accept")
+		serveCacheable(c, contentType, bytes, devfileLastModified(devfileIndex, version), devfileCacheControl)
 	}
 }
 
@@ -204,12 +315,14 @@ func (*Server) PostDevfileWithVersion(c *gin.Context, name string, version strin
 	SetMethodNotAllowedJSONResponse(c)
 }
 
+// PutDevfileWithVersion publishes a stack version through the publish API
 func (*Server) PutDevfileWithVersion(c *gin.Context, name string, version string) {
-	SetMethodNotAllowedJSONResponse(c)
+	publishDevfileWithVersion(c, name, version)
 }
 
+// DeleteDevfileWithVersion deletes a stack version through the publish API
 func (*Server) DeleteDevfileWithVersion(c *gin.Context, name string, version string) {
-	SetMethodNotAllowedJSONResponse(c)
+	deleteDevfileWithVersion(c, name, version)
 }
 
 // ServeDevfile returns the devfile content
@@ -222,8 +335,9 @@ func (s *Server) PostDevfile(c *gin.Context, name string) {
 	SetMethodNotAllowedJSONResponse(c)
 }
 
+// PutDevfile changes the default version of a stack through the publish API
 func (s *Server) PutDevfile(c *gin.Context, name string) {
-	SetMethodNotAllowedJSONResponse(c)
+	updateDevfile(c, name)
 }
 
 func (s *Server) DeleteDevfile(c *gin.Context, name string) {
@@ -249,9 +363,12 @@ func (s *Server) DeleteDevfileStarterProject(c *gin.Context, name string, starte
 
 // ServeDevfileStarterProject returns the starter project content for the devfile using specified version
 func (*Server) ServeDevfileStarterProjectWithVersion(c *gin.Context, name string, version string, starterProject string, params ServeDevfileStarterProjectWithVersionParams) {
-	downloadTmpLoc := path.Join("/tmp", starterProject)
 	stackLoc := path.Join(stacksPath, name)
-	devfileBytes, devfileIndex := fetchDevfile(c, name, version, ServeDevfileWithVersionParams(params))
+	devfileBytes, devfileIndex := fetchDevfile(c, name, version, ServeDevfileWithVersionParams{
+		MinSchemaVersion: params.MinSchemaVersion,
+		MaxSchemaVersion: params.MaxSchemaVersion,
+	})
+	stackVersion := ""
 
 	if len(devfileIndex.Versions) > 1 {
 		versionMap, err := util.MakeVersionMap(devfileIndex)
@@ -264,7 +381,10 @@ func (*Server) ServeDevfileStarterProjectWithVersion(c *gin.Context, name string
 			return
 		}
 
-		stackLoc = path.Join(stackLoc, versionMap[version].Version)
+		stackVersion = versionMap[version].Version
+		stackLoc = path.Join(stackLoc, stackVersion)
+	} else if len(devfileIndex.Versions) == 1 {
+		stackVersion = devfileIndex.Versions[0].Version
 	}
 
 	if len(devfileBytes) == 0 {
@@ -302,112 +422,41 @@ func (*Server) ServeDevfileStarterProjectWithVersion(c *gin.Context, name string
 			return
 		}
 
-		if selStarterProject := starterProjects[0]; selStarterProject.Git != nil {
-			gitScheme := indexSchema.Git{
-				Remotes:    selStarterProject.Git.Remotes,
-				RemoteName: "origin",
-				SubDir:     selStarterProject.SubDir,
-			}
-
-			if selStarterProject.Git.CheckoutFrom != nil {
-				if selStarterProject.Git.CheckoutFrom.Remote != "" {
-					gitScheme.RemoteName = selStarterProject.Git.CheckoutFrom.Remote
-				}
-				gitScheme.Revision = selStarterProject.Git.CheckoutFrom.Revision
-			}
-
-			gitScheme.Url = gitScheme.Remotes[gitScheme.RemoteName]
-
-			if downloadBytes, err = libutil.DownloadStackFromGit(&gitScheme, downloadTmpLoc, false); err != nil {
-				log.Print(err.Error())
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": err.Error(),
-					"status": fmt.Sprintf("Problem with downloading starter project %s from location: %s",
-						starterProject, gitScheme.Url),
-				})
-				return
-			}
-		} else if selStarterProject.Zip != nil {
-			if _, err = url.ParseRequestURI(selStarterProject.Zip.Location); err != nil {
-				localLoc := path.Join(stackLoc, selStarterProject.Zip.Location)
-				log.Printf("zip location is not a valid http url: %v\nTrying local path %s..", err, localLoc)
-
-				// If subdirectory is specified for starter project download then extract subdirectory
-				// and create new archive for download.
-				if selStarterProject.SubDir != "" {
-					downloadFilePath := fmt.Sprintf("%s.zip", downloadTmpLoc)
-
-					if _, err = os.Stat(downloadTmpLoc); os.IsExist(err) {
-						err = os.Remove(downloadTmpLoc)
-						if err != nil {
-							log.Print(err.Error())
-							c.JSON(http.StatusInternalServerError, gin.H{
-								"error": err.Error(),
-								"status": fmt.Sprintf("Problem removing existing temporary download directory '%s' for starter project %s",
-									downloadTmpLoc,
-									starterProject),
-							})
-							return
-						}
-					}
-
-					_, err = dfutil.Unzip(localLoc, downloadTmpLoc, selStarterProject.SubDir)
-					if err != nil {
-						log.Print(err.Error())
-						c.JSON(http.StatusInternalServerError, gin.H{
-							"error": err.Error(),
-							"status": fmt.Sprintf("Problem with reading subDir '%s' of starter project %s at %s",
-								selStarterProject.SubDir,
-								starterProject,
-								localLoc),
-						})
-						return
-					}
-
-					err = libutil.ZipDir(downloadTmpLoc, downloadFilePath)
-					if err != nil {
-						log.Print(err.Error())
-						c.JSON(http.StatusInternalServerError, gin.H{
-							"error": err.Error(),
-							"status": fmt.Sprintf("Problem with archiving subDir '%s' of starter project %s at %s",
-								selStarterProject.SubDir,
-								starterProject,
-								downloadFilePath),
-						})
-						return
-					}
-
-					localLoc = downloadFilePath
-				}
-
-				downloadBytes, err = os.ReadFile(filepath.Clean(localLoc))
-				if err != nil {
-					log.Print(err.Error())
-					c.JSON(http.StatusInternalServerError, gin.H{
-						"error": err.Error(),
-						"status": fmt.Sprintf("Problem with reading starter project %s at %s", starterProject,
-							localLoc),
-					})
-					return
-				}
-			} else {
-				downloadBytes, err = libutil.DownloadStackFromZipUrl(selStarterProject.Zip.Location, selStarterProject.SubDir, downloadTmpLoc)
-				if err != nil {
-					log.Print(err.Error())
-					c.JSON(http.StatusInternalServerError, gin.H{
-						"error":  err.Error(),
-						"status": fmt.Sprintf("Problem with downloading starter project %s", starterProject),
-					})
-					return
-				}
-			}
-		} else {
+		selStarterProject := starterProjects[0]
+		if selStarterProject.Git == nil && selStarterProject.Zip == nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"status": fmt.Sprintf("Starter project %s has no source to download from", starterProject),
 			})
 			return
 		}
 
+		cacheKey := starterProjectKey{
+			Stack:   name,
+			Version: stackVersion,
+			Project: starterProject,
+			SubDir:  selStarterProject.SubDir,
+		}
+		if selStarterProject.Git != nil && selStarterProject.Git.CheckoutFrom != nil {
+			cacheKey.Revision = selStarterProject.Git.CheckoutFrom.Revision
+		}
+		downloadBytes, err = starterProjectsCache.get(cacheKey, func(downloadTmpLoc string) ([]byte, error) {
+			return downloadStarterProject(c.Request.Context(), selStarterProject, stackLoc, downloadTmpLoc)
+		})
+		if err != nil {
+			log.Print(err.Error())
+			status := fmt.Sprintf("Problem with downloading starter project %s", starterProject)
+			var downloadErr *starterProjectError
+			if errors.As(err, &downloadErr) {
+				status = downloadErr.status
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":  err.Error(),
+				"status": status,
+			})
+			return
+		}
+
+		recordStat(c, "spdownload", name, stackVersion)
 		// Track event for telemetry. Ignore events from the registry-viewer and DevConsole since those are tracked on the client side. Ignore indirect calls from clients.
 		if enableTelemetry && !util.IsWebClient(c) && !util.IsIndirectCall(c) {
 
@@ -445,6 +494,40 @@ func (*Server) DeleteDevfileStarterProjectWithVersion(c *gin.Context, name strin
 	SetMethodNotAllowedJSONResponse(c)
 }
 
+// ServeStackResource serves a single resource of a stack version, e.g. its logo or archive.tar
+func (*Server) ServeStackResource(c *gin.Context, name string, version string, resource string) {
+	serveStackResource(c, name, version, resource)
+}
+
+func (*Server) PostStackResource(c *gin.Context, name string, version string, resource string) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+func (*Server) PutStackResource(c *gin.Context, name string, version string, resource string) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+func (*Server) DeleteStackResource(c *gin.Context, name string, version string, resource string) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+// ServeStackBundle streams all resources of a stack version as a single archive
+func (*Server) ServeStackBundle(c *gin.Context, name string, version string, params ServeStackBundleParams) {
+	serveStackBundle(c, name, version, params)
+}
+
+func (*Server) PostStackBundle(c *gin.Context, name string, version string) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+func (*Server) PutStackBundle(c *gin.Context, name string, version string) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
+func (*Server) DeleteStackBundle(c *gin.Context, name string, version string) {
+	SetMethodNotAllowedJSONResponse(c)
+}
+
 // ServeUI handles registry viewer proxy requests
 func ServeUI(c *gin.Context) {
 	if headless {
@@ -452,12 +535,14 @@ func ServeUI(c *gin.Context) {
 		return
 	}
 
-	remote, err := url.Parse(scheme + "://" + viewerService + "/viewer/")
+	remote, err := url.Parse(serverConfig.Viewer.URL)
 	if err != nil {
 		panic(err)
 	}
+	remote = remote.JoinPath("/viewer/")
 
 	proxy := httputil.NewSingleHostReverseProxy(remote)
+	proxy.Transport = viewerTransport
 
 	// Set up the request to the proxy
 	// This is a good place to set up telemetry for requests to the OCI server (e.g. by parsing the path)
@@ -483,7 +568,6 @@ func buildIndexAPIResponse(c *gin.Context, indexType string, wantV1Index bool, p
 	iconType := ""
 
 	var bytes []byte
-	var responseIndexPath, responseBase64IndexPath string
 
 	if params.Icon != nil {
 		iconType = *params.Icon
@@ -492,38 +576,34 @@ func buildIndexAPIResponse(c *gin.Context, indexType string, wantV1Index bool, p
 	// Sets Access-Control-Allow-Origin response header to allow cross origin requests
 	c.Header("Access-Control-Allow-Origin", "*")
 
-	// Load the appropriate index file name based on the devfile type
-	switch indexType {
-	case string(indexSchema.StackDevfileType):
-		responseIndexPath = stackIndexPath
-		responseBase64IndexPath = stackBase64IndexPath
-	case string(indexSchema.SampleDevfileType):
-		responseIndexPath = sampleIndexPath
-		responseBase64IndexPath = sampleBase64IndexPath
-	case "all":
-		responseIndexPath = indexPath
-		responseBase64IndexPath = base64IndexPath
-	default:
+	store, err := getIndexStore()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": fmt.Sprintf("failed to read the devfile index: %v", err),
+		})
+		return
+	}
+	snapshot := store.Snapshot()
+
+	// Load the appropriate index view based on the devfile type
+	index, found := snapshot.Index(indexType)
+	if !found {
 		c.JSON(http.StatusNotFound, gin.H{
 			"status": fmt.Sprintf("the devfile with %s type doesn't exist", indexType),
 		})
 		return
 	}
 
-	// cache index with the encoded icon if required and save the encoded index location
+	// use the index with the encoded icons if required
 	if iconType != "" {
 		if iconType == encodeFormat {
-			if _, err := os.Stat(responseBase64IndexPath); os.IsNotExist(err) {
-				_, err := util.EncodeIndexIconToBase64(responseIndexPath, responseBase64IndexPath)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{
-						"status": fmt.Sprintf("failed to encode %s icons to base64 format: %v", indexType, err),
-					})
-					return
-				}
+			index, err = snapshot.Base64Index(indexType)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"status": fmt.Sprintf("failed to encode %s icons to base64 format: %v", indexType, err),
+				})
+				return
 			}
-
-			responseIndexPath = responseBase64IndexPath
 		} else {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"status": fmt.Sprintf("the icon type %s is not supported", iconType),
@@ -531,13 +611,9 @@ func buildIndexAPIResponse(c *gin.Context, indexType string, wantV1Index bool, p
 			return
 		}
 	}
-	index, err := util.ReadIndexPath(responseIndexPath)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": fmt.Sprintf("failed to read the devfile index: %v", err),
-		})
-		return
-	}
+
+	// Only list the stacks and samples the identity of the request can access
+	index = filterAuthorized(c, index)
 
 	// Filter based on deprecation if deprecated parameter is set
 	if params.Deprecated != nil {
@@ -552,7 +628,81 @@ func buildIndexAPIResponse(c *gin.Context, indexType string, wantV1Index bool, p
 
 	if wantV1Index {
 		index = util.ConvertToOldIndexFormat(index)
-	} else {
+	}
+
+	// Filter the index by the range and field parameters
+	index, code, err := filterIndexByParams(index, wantV1Index, params)
+	if err != nil {
+		c.JSON(code, gin.H{
+			"status": err.Error(),
+		})
+		return
+	}
+
+	// Sort and paginate the filtered index
+	if !wantV1Index {
+		index, err = sortAndPaginate(c, index, params)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status": fmt.Sprintf("failed to paginate the index: %v", err),
+			})
+			return
+		}
+	}
+
+	// Project the index to the requested fields
+	var response interface{} = index
+	if util.StrPtrIsSet(params.Fields) {
+		tree, err := parseFields(*params.Fields)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status": err.Error(),
+			})
+			return
+		}
+		response, err = projectIndex(index, tree)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status": fmt.Sprintf("failed to project the index fields: %v", err),
+			})
+			return
+		}
+	}
+
+	bytes, err = json.MarshalIndent(&response, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": fmt.Sprintf("failed to serialize index data: %v", err),
+		})
+		return
+	}
+	serveCacheable(c, http.DetectContentType(bytes), bytes, snapshot.LastModified(), indexCacheControl)
+	recordStat(c, "list", "", "")
+
+	// Track event for telemetry.  Ignore events from the registry-viewer and DevConsole since those are tracked on the client side
+	if enableTelemetry && !util.IsWebClient(c) && !util.IsIndirectCall(c) {
+		user := util.GetUser(c)
+		client := util.GetClient(c)
+		err := util.TrackEvent(analytics.Track{
+			Event:   eventTrackMap["list"],
+			UserId:  user,
+			Context: util.SetContext(c),
+			Properties: analytics.NewProperties().
+				Set("type", indexType).
+				Set("registry", registry).
+				Set("client", client),
+		})
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// filterIndexByParams filters the index by the range parameters of the V2 index, the field parameters and the filter
+// expression. The status code of the error response is returned with the error.
+func filterIndexByParams(index []indexSchema.Schema, wantV1Index bool, params IndexParams) ([]indexSchema.Schema, int, error) {
+	var err error
+	if !wantV1Index {
 		minSchemaVersion := params.MinSchemaVersion
 		maxSchemaVersion := params.MaxSchemaVersion
 		minVersion := params.MinVersion
@@ -566,28 +716,19 @@ func buildIndexAPIResponse(c *gin.Context, indexType string, wantV1Index bool, p
 			if util.StrPtrIsSet(minSchemaVersion) {
 				matched, err := regexp.MatchString(`^([2-9])\.([0-9]+)(\.[0-9]+)?$`, *minSchemaVersion)
 				if !matched || err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{
-						"status": fmt.Sprintf("minSchemaVersion %s is not valid, version format should be '+2.x' or '+2.x.x'. %v", *minSchemaVersion, err),
-					})
-					return
+					return nil, http.StatusBadRequest, fmt.Errorf("minSchemaVersion %s is not valid, version format should be '+2.x' or '+2.x.x'. %v", *minSchemaVersion, err)
 				}
 			}
 			if util.StrPtrIsSet(maxSchemaVersion) {
 				matched, err := regexp.MatchString(`^([2-9])\.([0-9]+)(\.[0-9]+)?$`, *maxSchemaVersion)
 				if !matched || err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{
-						"status": fmt.Sprintf("maxSchemaVersion %s is not valid, version format should be '+2.x' or '+2.x.x'. %v", *maxSchemaVersion, err),
-					})
-					return
+					return nil, http.StatusBadRequest, fmt.Errorf("maxSchemaVersion %s is not valid, version format should be '+2.x' or '+2.x.x'. %v", *maxSchemaVersion, err)
 				}
 			}
 
 			index, err = util.FilterDevfileSchemaVersion(index, minSchemaVersion, maxSchemaVersion)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"status": fmt.Sprintf("failed to apply schema version filter: %v", err),
-				})
-				return
+				return nil, http.StatusInternalServerError, fmt.Errorf("failed to apply schema version filter: %v", err)
 			}
 		}
 
@@ -597,89 +738,51 @@ func buildIndexAPIResponse(c *gin.Context, indexType string, wantV1Index bool, p
 			if util.StrPtrIsSet(minVersion) {
 				matched, err := regexp.MatchString(`^([0-9])\.([0-9]+)(\.[0-9]+)?$`, *minVersion)
 				if !matched || err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{
-						"status": fmt.Sprintf("minVersion %s is not valid, version format should be 'x.x' or 'x.x.x'. %v", *minVersion, err),
-					})
-					return
+					return nil, http.StatusBadRequest, fmt.Errorf("minVersion %s is not valid, version format should be 'x.x' or 'x.x.x'. %v", *minVersion, err)
 				}
 			}
 			if util.StrPtrIsSet(maxVersion) {
 				matched, err := regexp.MatchString(`^([0-9]+)\.([0-9]+)(\.[0-9]+)?$`, *maxVersion)
 				if !matched || err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{
-						"status": fmt.Sprintf("maxVersion %s is not valid, version format should be 'x.x' or 'x.x.x'. %v", *maxVersion, err),
-					})
-					return
+					return nil, http.StatusBadRequest, fmt.Errorf("maxVersion %s is not valid, version format should be 'x.x' or 'x.x.x'. %v", *maxVersion, err)
 				}
 			}
 
 			index, err = util.FilterDevfileVersion(index, minVersion, maxVersion)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"status": fmt.Sprintf("failed to apply version filter: %v", err),
-				})
-				return
+				return nil, http.StatusInternalServerError, fmt.Errorf("failed to apply version filter: %v", err)
 			}
 		}
 
 		if util.StrPtrIsSet(minLastModified) || util.StrPtrIsSet(maxLastModified) {
 			if util.StrPtrIsSet(minLastModified) && util.IsInvalidLastModifiedDate(minLastModified) {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"status": fmt.Sprintf("minLastModified %s is not valid, format should be 'YYYY-MM-DD' and be a valid date. %v", *minLastModified, err),
-				})
-				return
+				return nil, http.StatusBadRequest, fmt.Errorf("minLastModified %s is not valid, format should be 'YYYY-MM-DD' and be a valid date. %v", *minLastModified, err)
 			}
 			if util.StrPtrIsSet(maxLastModified) && util.IsInvalidLastModifiedDate(maxLastModified) {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"status": fmt.Sprintf("maxLastModified %s is not valid, format should be 'YYYY-MM-DD' and be a valid date. %v", *maxLastModified, err),
-				})
-				return
+				return nil, http.StatusBadRequest, fmt.Errorf("maxLastModified %s is not valid, format should be 'YYYY-MM-DD' and be a valid date. %v", *maxLastModified, err)
 			}
 			index, err = util.FilterLastModifiedDate(index, minLastModified, maxLastModified)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"status": fmt.Sprintf("failed to apply last modified filter: %v", err),
-				})
-				return
+				return nil, http.StatusInternalServerError, fmt.Errorf("failed to apply last modified filter: %v", err)
 			}
 		}
-
 	}
 
 	// Filter the fields of the index
 	index, err = filterFieldsByParams(index, wantV1Index, params)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": fmt.Sprintf("failed to perform field filtering: %v", err),
-		})
-	}
-
-	bytes, err = json.MarshalIndent(&index, "", "  ")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": fmt.Sprintf("failed to serialize index data: %v", err),
-		})
-		return
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to perform field filtering: %v", err)
 	}
-	c.Data(http.StatusOK, http.DetectContentType(bytes), bytes)
 
-	// Track event for telemetry.  Ignore events from the registry-viewer and DevConsole since those are tracked on the client side
-	if enableTelemetry && !util.IsWebClient(c) && !util.IsIndirectCall(c) {
-		user := util.GetUser(c)
-		client := util.GetClient(c)
-		err := util.TrackEvent(analytics.Track{
-			Event:   eventTrackMap["list"],
-			UserId:  user,
-			Context: util.SetContext(c),
-			Properties: analytics.NewProperties().
-				Set("type", indexType).
-				Set("registry", registry).
-				Set("client", client),
-		})
+	// Filter the index by the boolean filter expression
+	if util.StrPtrIsSet(params.Filter) {
+		expression, err := util.ParseFilterExpression(*params.Filter)
 		if err != nil {
-			log.Println(err)
+			return nil, http.StatusBadRequest, err
 		}
+		index = util.FilterDevfileExpression(index, expression, wantV1Index).Index
 	}
+	return index, http.StatusOK, nil
 }
 
 // buildProxyErrorResponse builds an error response for proxy routes
@@ -704,10 +807,16 @@ func buildProxyErrorResponse(w http.ResponseWriter, r *http.Request, err error,
 
 // fetchDevfile retrieves a specified devfile by fetching stacks from the OCI
 // registry and samples from the `samplesPath` given by server. Also retrieves index
-// schema from `indexPath` given by server.
+// schema from the index store of the server.
 func fetchDevfile(c *gin.Context, name string, version string, params ServeDevfileWithVersionParams) ([]byte, indexSchema.Schema) {
+	ctx, span := tracer.Start(c.Request.Context(), "fetchDevfile", trace.WithAttributes(
+		attribute.String("devfile.name", name),
+		attribute.String("devfile.version", version),
+	))
+	defer span.End()
+
 	var index []indexSchema.Schema
-	bytes, err := os.ReadFile(indexPath)
+	store, err := getIndexStore()
 	if err != nil {
 		log.Print(err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -716,15 +825,12 @@ func fetchDevfile(c *gin.Context, name string, version string, params ServeDevfi
 		})
 		return []byte{}, indexSchema.Schema{}
 	}
-	err = json.Unmarshal(bytes, &index)
-	if err != nil {
-		log.Print(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":  err.Error(),
-			"status": fmt.Sprintf("failed to pull the devfile of %s", name),
-		})
-		return []byte{}, indexSchema.Schema{}
+	snapshot := store.Snapshot()
+	// Stacks and samples the identity of the request cannot access are answered as not found
+	if devfileIndex, found := snapshot.Component(name); found && authorized(c, devfileIndex) {
+		index = []indexSchema.Schema{devfileIndex}
 	}
+	schemaVersionFiltered := false
 
 	// minSchemaVersion and maxSchemaVersion will only be applied if looking for latest stack version
 	if version == "latest" {
@@ -758,18 +864,22 @@ func fetchDevfile(c *gin.Context, name string, version string, params ServeDevfi
 			})
 			return []byte{}, indexSchema.Schema{}
 		}
+		schemaVersionFiltered = true
 	}
 
 	for _, devfileIndex := range index {
 		if devfileIndex.Name == name {
-			var sampleDevfilePath string
+			var sampleDirPath string
 			var bytes []byte
 			if devfileIndex.Versions == nil || len(devfileIndex.Versions) == 0 {
 				if devfileIndex.Type == indexSchema.SampleDevfileType {
-					sampleDevfilePath = path.Join(samplesPath, devfileIndex.Name, devfileName)
+					sampleDirPath = path.Join(samplesPath, devfileIndex.Name)
 				}
 			} else {
-				versionMap, err := util.MakeVersionMap(devfileIndex)
+				versionMap := snapshot.VersionMap(name)
+				if versionMap == nil || schemaVersionFiltered {
+					versionMap, err = util.MakeVersionMap(devfileIndex)
+				}
 				if err != nil {
 					log.Print(err.Error())
 					c.JSON(http.StatusInternalServerError, gin.H{
@@ -780,18 +890,27 @@ func fetchDevfile(c *gin.Context, name string, version string, params ServeDevfi
 				}
 				if foundVersion, ok := versionMap[version]; ok {
 					if devfileIndex.Type == indexSchema.StackDevfileType {
-						bytes, err = pullStackFromRegistry(foundVersion)
+						if err := unavailableError(devfileIndex.Name, foundVersion.Version); err != nil {
+							c.JSON(http.StatusServiceUnavailable, gin.H{
+								"error":  err.Error(),
+								"status": fmt.Sprintf("version %s of stack %s is unavailable", foundVersion.Version, name),
+							})
+							return []byte{}, indexSchema.Schema{}
+						}
+						bytes, err = pullDevfile(ctx, devfileIndex.Name, foundVersion)
 						if err != nil {
 							log.Print(err.Error())
+							span.RecordError(err)
+							span.SetStatus(codes.Error, err.Error())
 							c.JSON(http.StatusInternalServerError, gin.H{
 								"error":  err.Error(),
-								"status": fmt.Sprintf("Problem pulling version %s from OCI Registry", foundVersion.Version),
+								"status": fmt.Sprintf("Problem pulling version %s from the stack storage", foundVersion.Version),
 							})
 							return []byte{}, indexSchema.Schema{}
 						}
 					} else {
 						// Retrieve the sample devfile stored under /registry/samples/<devfile>
-						sampleDevfilePath = path.Join(samplesPath, devfileIndex.Name, foundVersion.Version, devfileName)
+						sampleDirPath = path.Join(samplesPath, devfileIndex.Name, foundVersion.Version)
 					}
 				} else {
 					c.JSON(http.StatusNotFound, gin.H{
@@ -800,9 +919,11 @@ func fetchDevfile(c *gin.Context, name string, version string, params ServeDevfi
 					return []byte{}, indexSchema.Schema{}
 				}
 			}
-			if sampleDevfilePath != "" {
-				if _, err = os.Stat(sampleDevfilePath); err == nil {
-					/* #nosec G304 -- sampleDevfilePath is constructed from path.Join which cleans the input paths */
+			if sampleDirPath != "" {
+				// The sample devfile can be named devfile.yaml, .devfile.yaml, devfile.yml or .devfile.yml
+				sampleDevfilePath, err := libutil.FindDevfile(sampleDirPath)
+				if err == nil {
+					/* #nosec G304 -- sampleDevfilePath is constructed from filepath.Join which cleans the input paths */
 					bytes, err = os.ReadFile(sampleDevfilePath)
 				}
 				if err != nil {
@@ -825,18 +946,60 @@ func fetchDevfile(c *gin.Context, name string, version string, params ServeDevfi
 	return []byte{}, indexSchema.Schema{}
 }
 
-func ServeOciProxy(c *gin.Context) {
-	proxyPath := c.Param("proxyPath")
-	remote, err := url.Parse(scheme + "://" + registryService + "/v2")
-	if err != nil {
-		panic(err)
+// renderDevfile flattens the devfile and converts it to the requested schema version and media type. Errors are
+// written to the response and an empty devfile is returned.
+func renderDevfile(c *gin.Context, name string, version string, devfileIndex indexSchema.Schema, bytes []byte, contentType string,
+	params ServeDevfileWithVersionParams) []byte {
+	var err error
+	if params.Flatten != nil && *params.Flatten {
+		if bytes, err = flattenDevfile(c, devfileIndex, version, bytes); err != nil {
+			log.Print(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":  err.Error(),
+				"status": fmt.Sprintf("failed to flatten the devfile of %s", name),
+			})
+			return []byte{}
+		}
 	}
 
-	proxy := httputil.NewSingleHostReverseProxy(remote)
+	if util.StrPtrIsSet(params.SchemaVersion) {
+		if bytes, err = convertSchemaVersion(bytes, *params.SchemaVersion); err != nil {
+			var conversionErr *schemaConversionError
+			code := http.StatusBadRequest
+			if errors.As(err, &conversionErr) {
+				code = http.StatusNotAcceptable
+			}
+			c.JSON(code, gin.H{
+				"error":  err.Error(),
+				"status": fmt.Sprintf("failed to convert the devfile of %s to schema version %s", name, *params.SchemaVersion),
+			})
+			return []byte{}
+		}
+	}
 
-	// Set up the request to the proxy
+	if contentType == devfileJSONMediaType {
+		if bytes, err = yaml.YAMLToJSON(bytes); err != nil {
+			log.Print(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":  err.Error(),
+				"status": fmt.Sprintf("failed to convert the devfile of %s to JSON", name),
+			})
+			return []byte{}
+		}
+	}
+	return bytes
+}
+
+func ServeOciProxy(c *gin.Context) {
+	proxyPath := c.Param("proxyPath")
+	if !authorizedProxyPath(c, proxyPath) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"errors": []gin.H{{"code": "NAME_UNKNOWN", "message": "repository name not known to registry"}},
+		})
+		return
+	}
 	// Track event for telemetry for GET requests only
-	if enableTelemetry && c.Request.Method == http.MethodGet && proxyPath != "" {
+	if c.Request.Method == http.MethodGet && proxyPath != "" {
 		var name string
 		var resource string
 		parts := strings.Split(proxyPath, "/")
@@ -845,9 +1008,13 @@ func ServeOciProxy(c *gin.Context) {
 			name = parts[2]
 			resource = parts[3]
 		}
+		if resource == "blobs" {
+			// The version of a blob is not known
+			recordStat(c, "download", name, "")
+		}
 
 		//Ignore events from the registry-viewer and DevConsole since those are tracked on the client side.  Ignore indirect calls from clients.
-		if resource == "blobs" && !util.IsWebClient(c) && !util.IsIndirectCall(c) {
+		if enableTelemetry && resource == "blobs" && !util.IsWebClient(c) && !util.IsIndirectCall(c) {
 			user := util.GetUser(c)
 			client := util.GetClient(c)
 
@@ -866,11 +1033,29 @@ func ServeOciProxy(c *gin.Context) {
 		}
 	}
 
+	// Storages without OCI registry serve the stacks as OCI artifacts themselves
+	if !stackStorage.ServesOCI() {
+		serveStorageOCI(c, proxyPath)
+		return
+	}
+	remote := serverConfig.OCI.url().JoinPath("/v2")
+
+	proxy := httputil.NewSingleHostReverseProxy(remote)
+	proxy.Transport = ociClient.Transport
+
+	// Set up the request to the proxy
 	proxy.Director = func(req *http.Request) {
 		req.Header.Add("X-Forwarded-Host", req.Host)
 		req.Header.Add("X-Origin-Host", remote.Host)
 		req.URL.Scheme = remote.Scheme
 		req.URL.Host = remote.Host
+		req.Host = remote.Host
+
+		// Never forward the client credentials, authenticate to the OCI registry with its configured credentials
+		req.Header.Del("Authorization")
+		if serverConfig.OCI.Username != "" || serverConfig.OCI.Password != "" {
+			req.SetBasicAuth(serverConfig.OCI.Username, serverConfig.OCI.Password)
+		}
 	}
 
 	proxy.ServeHTTP(c.Writer, c.Request)
@@ -878,6 +1063,6 @@ func ServeOciProxy(c *gin.Context) {
 
 func SetMethodNotAllowedJSONResponse(c *gin.Context) {
 	c.JSON(http.StatusMethodNotAllowed, MethodNotAllowedResponse{
-		Message: "Only GET requests are supported.",
+		Message: "The request method is not supported by this endpoint.",
 	})
 }
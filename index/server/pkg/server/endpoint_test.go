@@ -33,6 +33,7 @@ import (
 	"testing"
 
 	"github.com/devfile/library/v2/pkg/devfile/parser"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
 	"github.com/devfile/registry-support/index/server/pkg/ocitest"
 	"github.com/gin-gonic/gin"
 	"github.com/opencontainers/go-digest"
@@ -416,6 +417,80 @@ func TestServeHealthCheck(t *testing.T) {
 	}
 }
 
+// TestServeIndexSignature tests '/signature' endpoint
+func TestServeIndexSignature(t *testing.T) {
+	signaturePath := filepath.Join(t.TempDir(), "index.json.sig")
+	wantSignature := IndexSignature{
+		KeyType: "ed25519",
+		Entries: []indexSchema.EntrySignature{{Payload: "cGF5bG9hZA==", Signature: "c2lnbmF0dXJl"}},
+	}
+	bytes, err := json.Marshal(wantSignature)
+	if err != nil {
+		t.Fatalf("Did not expect error: %v", err)
+	}
+	if err := os.WriteFile(signaturePath, bytes, 0600); err != nil {
+		t.Fatalf("Did not expect error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		signaturePath string
+		wantCode      int
+		wantSignature *IndexSignature
+	}{
+		{
+			name:          "Case 1: signed registry index",
+			signaturePath: signaturePath,
+			wantCode:      http.StatusOK,
+			wantSignature: &wantSignature,
+		},
+		{
+			name:          "Case 2: registry index is not signed",
+			signaturePath: filepath.Join(t.TempDir(), "index.json.sig"),
+			wantCode:      http.StatusNotFound,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	setupVars()
+
+	originalSignaturePath := indexSignaturePath
+	defer func() {
+		indexSignaturePath = originalSignaturePath
+	}()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexSignaturePath = test.signaturePath
+
+			server := &ServerInterfaceWrapper{
+				Handler:      &Server{},
+				ErrorHandler: testErrorHandler,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			server.ServeIndexSignature(c)
+
+			if gotStatusCode := w.Code; !reflect.DeepEqual(gotStatusCode, test.wantCode) {
+				t.Errorf("Did not get expected status code, Got: %v, Expected: %v", gotStatusCode, test.wantCode)
+				return
+			}
+
+			if test.wantSignature != nil {
+				var gotSignature IndexSignature
+				if err := json.Unmarshal(w.Body.Bytes(), &gotSignature); err != nil {
+					t.Fatalf("Did not expect error: %v", err)
+				}
+				if !reflect.DeepEqual(gotSignature, *test.wantSignature) {
+					t.Errorf("Did not get expected signature, Got: %v, Expected: %v", gotSignature, *test.wantSignature)
+				}
+			}
+		})
+	}
+}
+
 // TestServeDevfileIndexV1 tests '/index' endpoint
 func TestServeDevfileIndexV1(t *testing.T) {
 	const wantStatusCode = http.StatusOK
@@ -669,6 +744,91 @@ func TestServeDevfileIndexV2WithType(t *testing.T) {
 	}
 }
 
+// TestServeDevfileIndexV2WithFilter tests the filter expression of the '/v2index/:indexType' endpoint
+func TestServeDevfileIndexV2WithFilter(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+
+	tests := []struct {
+		name       string
+		indexType  string
+		filter     string
+		wantCode   int
+		wantNames  []string
+		wantStatus string
+	}{
+		{
+			name:      "Case 1: OR of languages",
+			indexType: "stack",
+			filter:    "language = go OR language = nodejs",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"go", "nodejs"},
+		},
+		{
+			name:      "Case 2: NOT within parentheses",
+			indexType: "all",
+			filter:    "tags = Quarkus AND NOT (type = sample)",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"java-quarkus"},
+		},
+		{
+			name:      "Case 3: Version range",
+			indexType: "stack",
+			filter:    "version >= 1.2",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"go"},
+		},
+		{
+			name:       "Case 4: Parse error",
+			indexType:  "all",
+			filter:     "language = go OR",
+			wantCode:   http.StatusBadRequest,
+			wantStatus: "invalid filter expression at position 17: expected a field, found end of expression",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			query := url.Values{"filter": []string{test.filter}}
+			c.Request = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v2index/%s?%s", test.indexType, query.Encode()), nil)
+			c.Params = append(c.Params, gin.Param{Key: "indexType", Value: test.indexType})
+
+			server.ServeDevfileIndexV2WithType(c)
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Did not get expected status code, Got: %v, Expected: %v", w.Code, test.wantCode)
+			}
+			if test.wantCode != http.StatusOK {
+				var body map[string]string
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("failed to unmarshal the error response: %v", err)
+				}
+				if body["status"] != test.wantStatus {
+					t.Errorf("Got status: %q, Expected status: %q", body["status"], test.wantStatus)
+				}
+				return
+			}
+			var index []map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &index); err != nil {
+				t.Fatalf("failed to unmarshal the index: %v", err)
+			}
+			gotNames := []string{}
+			for _, entry := range index {
+				gotNames = append(gotNames, entry["name"].(string))
+			}
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Errorf("Got: %v, Expected: %v", gotNames, test.wantNames)
+			}
+		})
+	}
+}
+
 // TestServeDevfile tests '/devfiles/:stack' endpoint
 func TestServeDevfile(t *testing.T) {
 	tests := []struct {
@@ -972,6 +1132,7 @@ func TestServeDevfileStarterProject(t *testing.T) {
 	}
 	defer closeServer()
 	setupVars()
+	useStarterProjectCache(t)
 	server := &ServerInterfaceWrapper{
 		Handler:      &Server{},
 		ErrorHandler: testErrorHandler,
@@ -1083,6 +1244,7 @@ func TestServeDevfileStarterProjectWithVersion(t *testing.T) {
 	}
 	defer closeServer()
 	setupVars()
+	useStarterProjectCache(t)
 	server := &ServerInterfaceWrapper{
 		Handler:      &Server{},
 		ErrorHandler: testErrorHandler,
@@ -1197,7 +1359,7 @@ func TestOCIServerProxy(t *testing.T) {
 
 			w := ocitest.NewProxyRecorder()
 			c, _ := gin.CreateTestContext(w)
-			url := fmt.Sprintf("%s://%s", scheme, filepath.Join(ociServerIP, "v2", test.url))
+			url := fmt.Sprintf("http://%s", filepath.Join(ociServerIP, "v2", test.url))
 
 			c.Request, err = http.NewRequest(test.method, url, bytes.NewBuffer([]byte{}))
 			if err != nil {
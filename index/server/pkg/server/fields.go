@@ -0,0 +1,146 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+var (
+	// indexFieldPaths is the set of JSON field paths of the index schema, e.g. 'versions.version'
+	indexFieldPaths     map[string]bool
+	indexFieldPathsOnce sync.Once
+)
+
+// fieldTree is a tree of the requested field paths, a nil subtree selects the whole field
+type fieldTree map[string]fieldTree
+
+// collectFieldPaths adds the JSON field paths of the struct type to paths, nested structs, pointers and
+// slices of structs are walked, maps are only selectable as a whole
+func collectFieldPaths(t reflect.Type, prefix string, paths map[string]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		path := prefix + name
+		paths[path] = true
+		collectFieldPaths(field.Type, path+".", paths)
+	}
+}
+
+// parseFields parses the comma separated field paths of the fields parameter, unknown paths are rejected
+func parseFields(fields string) (fieldTree, error) {
+	indexFieldPathsOnce.Do(func() {
+		indexFieldPaths = map[string]bool{}
+		collectFieldPaths(reflect.TypeOf(indexSchema.Schema{}), "", indexFieldPaths)
+	})
+
+	tree := fieldTree{}
+	for _, path := range strings.Split(fields, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return nil, fmt.Errorf("the fields parameter contains an empty field path")
+		}
+		if !indexFieldPaths[path] {
+			return nil, fmt.Errorf("the field path %s is unknown", path)
+		}
+
+		node := tree
+		names := strings.Split(path, ".")
+		for i, name := range names {
+			subtree, found := node[name]
+			if found && subtree == nil {
+				// the whole field is already selected
+				break
+			}
+			if i == len(names)-1 {
+				node[name] = nil
+				break
+			}
+			if !found {
+				subtree = fieldTree{}
+				node[name] = subtree
+			}
+			node = subtree
+		}
+	}
+	return tree, nil
+}
+
+// project keeps the fields of the tree in a decoded JSON value, the elements of arrays are projected
+// individually
+func (tree fieldTree) project(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		projected := make(map[string]interface{}, len(tree))
+		for name, subtree := range tree {
+			fieldValue, found := value[name]
+			if !found {
+				continue
+			}
+			if subtree == nil {
+				projected[name] = fieldValue
+			} else {
+				projected[name] = subtree.project(fieldValue)
+			}
+		}
+		return projected
+	case []interface{}:
+		projected := make([]interface{}, len(value))
+		for i, element := range value {
+			projected[i] = tree.project(element)
+		}
+		return projected
+	default:
+		return value
+	}
+}
+
+// projectIndex projects the entries of the index to the requested field paths
+func projectIndex(index []indexSchema.Schema, tree fieldTree) (interface{}, error) {
+	encoded, err := json.Marshal(&index)
+	if err != nil {
+		return nil, err
+	}
+	// keep the numbers as they are encoded
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err = decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return tree.project(decoded), nil
+}
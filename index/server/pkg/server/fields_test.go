@@ -0,0 +1,188 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   string
+		wantTree fieldTree
+		wantErr  bool
+	}{
+		{
+			name:   "Case 1: Top level and version fields",
+			fields: "name, displayName,versions.version,versions.default",
+			wantTree: fieldTree{
+				"name":        nil,
+				"displayName": nil,
+				"versions":    fieldTree{"version": nil, "default": nil},
+			},
+		},
+		{
+			name:     "Case 2: Whole field takes precedence over its subfields",
+			fields:   "versions.version,versions,versions.default",
+			wantTree: fieldTree{"versions": nil},
+		},
+		{
+			name:     "Case 3: Nested struct field",
+			fields:   "git.remotes",
+			wantTree: fieldTree{"git": fieldTree{"remotes": nil}},
+		},
+		{
+			name:    "Case 4: Unknown field",
+			fields:  "name,colour",
+			wantErr: true,
+		},
+		{
+			name:    "Case 5: Unknown version field",
+			fields:  "versions.colour",
+			wantErr: true,
+		},
+		{
+			name:    "Case 6: Empty field path",
+			fields:  "name,,icon",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotTree, err := parseFields(test.fields)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got tree: %v", gotTree)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(gotTree, test.wantTree) {
+				t.Errorf("Got: %v, Expected: %v", gotTree, test.wantTree)
+			}
+		})
+	}
+}
+
+func TestProjectIndex(t *testing.T) {
+	index := []indexSchema.Schema{
+		{
+			Name:        "go",
+			DisplayName: "Go Runtime",
+			Icon:        "data:image/png;base64,aWNvbg==",
+			Language:    "Go",
+			Versions: []indexSchema.Version{
+				{Version: "1.0.0", SchemaVersion: "2.1.0"},
+				{Version: "2.0.0", SchemaVersion: "2.2.0", Default: true},
+			},
+		},
+	}
+	tree, err := parseFields("name,icon,versions.version,versions.default")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	projected, err := projectIndex(index, tree)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []interface{}{
+		map[string]interface{}{
+			"name": "go",
+			"icon": "data:image/png;base64,aWNvbg==",
+			"versions": []interface{}{
+				map[string]interface{}{"version": "1.0.0"},
+				map[string]interface{}{"version": "2.0.0", "default": true},
+			},
+		},
+	}
+	if !reflect.DeepEqual(projected, want) {
+		t.Errorf("Got: %v, Expected: %v", projected, want)
+	}
+}
+
+func TestServeDevfileIndexV2WithFields(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+
+	tests := []struct {
+		name      string
+		target    string
+		wantCode  int
+		wantIndex []map[string]interface{}
+	}{
+		{
+			name:     "Case 1: Projected page",
+			target:   "/v2index/stack?fields=name,versions.version&limit=2",
+			wantCode: http.StatusOK,
+			wantIndex: []map[string]interface{}{
+				{
+					"name":     "go",
+					"versions": []interface{}{map[string]interface{}{"version": "1.1.0"}, map[string]interface{}{"version": "1.2.0"}},
+				},
+				{
+					"name":     "java-maven",
+					"versions": []interface{}{map[string]interface{}{"version": "1.1.0"}},
+				},
+			},
+		},
+		{
+			name:     "Case 2: Unknown field path",
+			target:   "/v2index/stack?fields=name,versions.colour",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, test.target, nil)
+			c.Params = append(c.Params, gin.Param{Key: "indexType", Value: "stack"})
+
+			server.ServeDevfileIndexV2WithType(c)
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Did not get expected status code, Got: %v, Expected: %v", w.Code, test.wantCode)
+			}
+			if test.wantCode != http.StatusOK {
+				return
+			}
+			var gotIndex []map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &gotIndex); err != nil {
+				t.Fatalf("failed to unmarshal the index: %v", err)
+			}
+			if !reflect.DeepEqual(gotIndex, test.wantIndex) {
+				t.Errorf("Got: %v, Expected: %v", gotIndex, test.wantIndex)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	apiOverride "github.com/devfile/api/v2/pkg/utils/overriding"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/yaml"
+)
+
+// flattenedView is the flattened devfile of a stack or sample version
+type flattenedView struct {
+	devfile []byte
+	// registryHosts are the hosts of the registry URLs the flattening compared to the host of the registry
+	registryHosts map[string]bool
+}
+
+// FlattenedDevfile returns the flattened devfile of a stack or sample version of the snapshot for the host of the
+// registry, flattening it on first use. References with a registry URL are only local for the host of the URL, so
+// the flattened devfile is kept once for the hosts none of its registry URLs refer to and once for each host of its
+// registry URLs, arbitrary hosts of the requests do not add flattened devfiles. Failed flattenings are not kept so
+// that they are retried on the next request.
+func (s *IndexSnapshot) FlattenedDevfile(node string, host string, flatten func(host string) ([]byte, map[string]bool, error)) ([]byte, error) {
+	hostKey := node + "@" + host
+	if value, found := s.flattenedViews.Load(hostKey); found {
+		return value.(*flattenedView).devfile, nil
+	}
+	if value, found := s.flattenedViews.Load(node); found && !value.(*flattenedView).registryHosts[host] {
+		return value.(*flattenedView).devfile, nil
+	}
+
+	devfile, registryHosts, err := flatten(host)
+	if err != nil {
+		return nil, err
+	}
+	// The devfile flattened for a host that none of the registry URLs refer to is the same for all such hosts
+	key := node
+	if registryHosts[host] {
+		key = hostKey
+	}
+	value, _ := s.flattenedViews.LoadOrStore(key, &flattenedView{devfile: devfile, registryHosts: registryHosts})
+	return value.(*flattenedView).devfile, nil
+}
+
+// devfileFlattener resolves the parents and plugins of devfiles into flattened devfiles. References to
+// stacks of this registry are pulled from the stack storage, other references are resolved by the devfile
+// library parser.
+type devfileFlattener struct {
+	// ctx carries the span of the reference being resolved, so that the spans of its parents are nested in it
+	ctx      context.Context
+	snapshot *IndexSnapshot
+	// host of the registry, registry URLs with this host refer to this registry
+	host string
+	// registryHosts are the hosts of the registry URLs compared to host
+	registryHosts map[string]bool
+	// resolving are the stack versions being flattened, to detect cycles
+	resolving map[string]bool
+}
+
+// newDevfileFlattener creates a flattener of the devfiles of the index snapshot
+func newDevfileFlattener(ctx context.Context, snapshot *IndexSnapshot, host string) *devfileFlattener {
+	return &devfileFlattener{
+		ctx:           ctx,
+		snapshot:      snapshot,
+		host:          host,
+		registryHosts: map[string]bool{},
+		resolving:     map[string]bool{},
+	}
+}
+
+// flattenDevfile returns the flattened devfile of a stack or sample version, kept in the index snapshot so
+// that each version is only flattened once
+func flattenDevfile(c *gin.Context, devfileIndex indexSchema.Schema, version string, devfileBytes []byte) (flattened []byte, err error) {
+	store, err := getIndexStore()
+	if err != nil {
+		return nil, err
+	}
+	node := devfileIndex.Name
+	var parent *indexSchema.Parent
+	if len(devfileIndex.Versions) > 0 {
+		versionMap, err := util.MakeVersionMap(devfileIndex)
+		if err != nil {
+			return nil, err
+		}
+		versionComponent := versionMap[version]
+		node += ":" + versionComponent.Version
+		parent = versionComponent.Parent
+	}
+
+	ctx, span := tracer.Start(c.Request.Context(), "flattenDevfile", trace.WithAttributes(attribute.String("devfile.node", node)))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	snapshot := store.Snapshot()
+	return snapshot.FlattenedDevfile(node, strings.ToLower(c.Request.Host), func(host string) ([]byte, map[string]bool, error) {
+		flattener := newDevfileFlattener(ctx, snapshot, host)
+		flattened, err := flattener.flatten(node, devfileBytes, parent)
+		return flattened, flattener.registryHosts, err
+	})
+}
+
+// flatten returns the flattened devfile of the devfile content of a stack or sample version, parent is the
+// reference of its parent resolved in the index if any
+func (f *devfileFlattener) flatten(node string, devfileBytes []byte, parent *indexSchema.Parent) ([]byte, error) {
+	f.resolving[node] = true
+	defer delete(f.resolving, node)
+
+	devfileObj, err := f.flattenObj(devfileBytes, parent)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(devfileObj.Data)
+}
+
+// flattenObj parses the devfile content and merges its flattened parent and plugins into it
+func (f *devfileFlattener) flattenObj(devfileBytes []byte, indexParent *indexSchema.Parent) (parser.DevfileObj, error) {
+	// The references are resolved below, the devfile is only parsed
+	disabled := false
+	devfileObj, err := parser.ParseDevfile(parser.ParserArgs{
+		Data:                          devfileBytes,
+		FlattenedDevfile:              &disabled,
+		ConvertKubernetesContentInUri: &disabled,
+		SetBooleanDefaults:            &disabled,
+	})
+	if err != nil {
+		return parser.DevfileObj{}, fmt.Errorf("failed to parse the devfile: %v", err)
+	}
+	schemaVersion := devfileObj.Data.GetSchemaVersion()
+
+	var flattenedParent *v1alpha2.DevWorkspaceTemplateSpecContent
+	if parent := devfileObj.Data.GetParent(); parent != nil && !reflect.DeepEqual(parent, &v1alpha2.Parent{}) {
+		resolved := ""
+		if indexParent != nil {
+			resolved = indexParent.Resolved
+		}
+		flattenedParent, err = f.resolve(parent.ImportReference, resolved, schemaVersion, "")
+		if err != nil {
+			return parser.DevfileObj{}, fmt.Errorf("failed to resolve the parent: %v", err)
+		}
+		if !reflect.DeepEqual(parent.ParentOverrides, v1alpha2.ParentOverrides{}) {
+			flattenedParent, err = apiOverride.OverrideDevWorkspaceTemplateSpec(flattenedParent, parent.ParentOverrides)
+			if err != nil {
+				return parser.DevfileObj{}, fmt.Errorf("failed to override the parent: %v", err)
+			}
+		}
+	}
+
+	components, err := devfileObj.Data.GetComponents(common.DevfileOptions{})
+	if err != nil {
+		return parser.DevfileObj{}, err
+	}
+	flattenedPlugins := []*v1alpha2.DevWorkspaceTemplateSpecContent{}
+	for _, component := range components {
+		plugin := component.Plugin
+		if plugin == nil || reflect.DeepEqual(plugin, &v1alpha2.PluginComponent{}) {
+			continue
+		}
+		flattenedPlugin, err := f.resolve(plugin.ImportReference, "", schemaVersion, component.Name)
+		if err != nil {
+			return parser.DevfileObj{}, fmt.Errorf("failed to resolve the plugin %s: %v", component.Name, err)
+		}
+		if !reflect.DeepEqual(plugin.PluginOverrides, v1alpha2.PluginOverrides{}) {
+			flattenedPlugin, err = apiOverride.OverrideDevWorkspaceTemplateSpec(flattenedPlugin, plugin.PluginOverrides)
+			if err != nil {
+				return parser.DevfileObj{}, fmt.Errorf("failed to override the plugin %s: %v", component.Name, err)
+			}
+		}
+		flattenedPlugins = append(flattenedPlugins, flattenedPlugin)
+	}
+
+	if flattenedParent == nil {
+		flattenedParent = &v1alpha2.DevWorkspaceTemplateSpecContent{}
+	}
+	mergedContent, err := apiOverride.MergeDevWorkspaceTemplateSpec(devfileObj.Data.GetDevfileWorkspaceSpecContent(), flattenedParent, flattenedPlugins...)
+	if err != nil {
+		return parser.DevfileObj{}, fmt.Errorf("failed to merge the parent and plugins: %v", err)
+	}
+	devfileObj.Data.SetDevfileWorkspaceSpecContent(*mergedContent)
+	devfileObj.Data.SetParent(nil)
+	return devfileObj, nil
+}
+
+// resolve returns the flattened content of a parent or plugin reference. resolved is the stack version of
+// this registry the reference points to in the index, in the form <name>:<version>, if already known.
+func (f *devfileFlattener) resolve(reference v1alpha2.ImportReference, resolved string, schemaVersion string, pluginName string) (content *v1alpha2.DevWorkspaceTemplateSpecContent, err error) {
+	ctx, span := tracer.Start(f.ctx, "resolveReference", trace.WithAttributes(
+		attribute.String("devfile.reference.id", reference.Id),
+		attribute.String("devfile.reference.uri", reference.Uri),
+		attribute.String("devfile.reference.registry_url", reference.RegistryUrl),
+		attribute.String("devfile.plugin", pluginName),
+	))
+	previous := f.ctx
+	f.ctx = ctx
+	defer func() {
+		f.ctx = previous
+		endSpan(span, err)
+	}()
+
+	if resolved == "" && f.isLocal(reference) {
+		resolved = reference.Id
+		if reference.Version != "" {
+			resolved += ":" + reference.Version
+		}
+	}
+	if resolved != "" {
+		stack, version, _ := strings.Cut(resolved, ":")
+		devfileObj, err := f.flattenStack(stack, version)
+		if err != nil {
+			return nil, err
+		}
+		return devfileObj.Data.GetDevfileWorkspaceSpecContent(), nil
+	}
+	return resolveRemote(reference, schemaVersion, pluginName)
+}
+
+// isLocal returns true if the reference is a stack id of this registry
+func (f *devfileFlattener) isLocal(reference v1alpha2.ImportReference) bool {
+	if reference.Id == "" {
+		return false
+	}
+	if reference.RegistryUrl == "" {
+		return true
+	}
+	registryUrl, err := url.Parse(reference.RegistryUrl)
+	if err != nil {
+		return false
+	}
+	registryHost := strings.ToLower(registryUrl.Host)
+	f.registryHosts[registryHost] = true
+	return f.host != "" && registryHost == f.host
+}
+
+// flattenStack pulls a stack version from the stack storage and flattens it
+func (f *devfileFlattener) flattenStack(stack string, version string) (parser.DevfileObj, error) {
+	if version == "" {
+		version = "default"
+	}
+	devfileIndex, found := f.snapshot.Component(stack)
+	if !found || devfileIndex.Type != indexSchema.StackDevfileType {
+		return parser.DevfileObj{}, fmt.Errorf("the stack %s does not exist in the registry", stack)
+	}
+	versionComponent, found := f.snapshot.VersionMap(stack)[version]
+	if !found {
+		return parser.DevfileObj{}, fmt.Errorf("version %s not found in stack %s", version, stack)
+	}
+
+	node := stack + ":" + versionComponent.Version
+	if f.resolving[node] {
+		return parser.DevfileObj{}, fmt.Errorf("the parents of %s form a cycle", node)
+	}
+	f.resolving[node] = true
+	defer delete(f.resolving, node)
+
+	if err := unavailableError(stack, versionComponent.Version); err != nil {
+		return parser.DevfileObj{}, err
+	}
+	devfileBytes, err := pullDevfile(f.ctx, stack, versionComponent)
+	if err != nil {
+		return parser.DevfileObj{}, err
+	}
+	return f.flattenObj(devfileBytes, versionComponent.Parent)
+}
+
+// resolveRemote resolves a reference outside of this registry with the devfile library parser, by flattening
+// a devfile holding only the reference
+func resolveRemote(reference v1alpha2.ImportReference, schemaVersion string, pluginName string) (*v1alpha2.DevWorkspaceTemplateSpecContent, error) {
+	wrapper := map[string]interface{}{
+		"schemaVersion": schemaVersion,
+	}
+	if pluginName == "" {
+		wrapper["parent"] = reference
+	} else {
+		wrapper["components"] = []interface{}{
+			map[string]interface{}{
+				"name":   pluginName,
+				"plugin": reference,
+			},
+		}
+	}
+	wrapperBytes, err := yaml.Marshal(wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	disabled := false
+	devfileObj, err := parser.ParseDevfile(parser.ParserArgs{
+		Data:                          wrapperBytes,
+		ConvertKubernetesContentInUri: &disabled,
+		SetBooleanDefaults:            &disabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return devfileObj.Data.GetDevfileWorkspaceSpecContent(), nil
+}
@@ -0,0 +1,328 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/gin-gonic/gin"
+)
+
+const flattenTestIndex = `[
+  {"name": "base", "type": "stack", "versions": [
+    {"version": "1.0.0", "default": true, "schemaVersion": "2.2.0", "resources": ["devfile.yaml"]},
+    {"version": "2.0.0", "schemaVersion": "2.2.0", "resources": ["devfile.yaml"]}
+  ]},
+  {"name": "child", "type": "stack", "versions": [{"version": "1.0.0", "default": true, "schemaVersion": "2.2.0", "resources": ["devfile.yaml"]}]},
+  {"name": "child-latest", "type": "stack", "versions": [{"version": "1.0.0", "default": true, "schemaVersion": "2.2.0", "resources": ["devfile.yaml"]}]},
+  {"name": "grandchild", "type": "stack", "versions": [{"version": "1.0.0", "default": true, "schemaVersion": "2.2.0", "resources": ["devfile.yaml"]}]},
+  {"name": "registry-url", "type": "stack", "versions": [{"version": "1.0.0", "default": true, "schemaVersion": "2.2.0", "resources": ["devfile.yaml"]}]},
+  {"name": "missing-parent", "type": "stack", "versions": [{"version": "1.0.0", "default": true, "schemaVersion": "2.2.0", "resources": ["devfile.yaml"]}]},
+  {"name": "cycle-a", "type": "stack", "versions": [{"version": "1.0.0", "default": true, "schemaVersion": "2.2.0", "resources": ["devfile.yaml"]}]},
+  {"name": "cycle-b", "type": "stack", "versions": [{"version": "1.0.0", "default": true, "schemaVersion": "2.2.0", "resources": ["devfile.yaml"]}]}
+]`
+
+// flattenTestDevfiles are the devfiles of the flatten test stacks by stack and version
+var flattenTestDevfiles = map[string]string{
+	"base:1.0.0": `schemaVersion: 2.2.0
+metadata:
+  name: base
+components:
+  - name: runtime
+    container:
+      image: base:1.0.0
+commands:
+  - id: build
+    exec:
+      component: runtime
+      commandLine: make
+`,
+	"base:2.0.0": `schemaVersion: 2.2.0
+metadata:
+  name: base
+components:
+  - name: runtime
+    container:
+      image: base:2.0.0
+`,
+	"child:1.0.0": `schemaVersion: 2.2.0
+metadata:
+  name: child
+parent:
+  id: base
+  components:
+    - name: runtime
+      container:
+        image: child:1.0.0
+components:
+  - name: tools
+    container:
+      image: tools:1.0.0
+`,
+	"child-latest:1.0.0": `schemaVersion: 2.2.0
+metadata:
+  name: child-latest
+parent:
+  id: base
+  version: latest
+`,
+	"grandchild:1.0.0": `schemaVersion: 2.2.0
+metadata:
+  name: grandchild
+parent:
+  id: child
+components:
+  - name: debugger
+    container:
+      image: debugger:1.0.0
+`,
+	"registry-url:1.0.0": `schemaVersion: 2.2.0
+metadata:
+  name: registry-url
+parent:
+  id: base
+  registryUrl: http://example.com
+`,
+	"missing-parent:1.0.0": `schemaVersion: 2.2.0
+metadata:
+  name: missing-parent
+parent:
+  id: not-exist
+`,
+	"cycle-a:1.0.0": `schemaVersion: 2.2.0
+metadata:
+  name: cycle-a
+parent:
+  id: cycle-b
+`,
+	"cycle-b:1.0.0": `schemaVersion: 2.2.0
+metadata:
+  name: cycle-b
+parent:
+  id: cycle-a
+`,
+}
+
+// useFlattenTestRegistry serves the flatten test stacks from memory for the duration of a test
+func useFlattenTestRegistry(t *testing.T) (*IndexStore, *memoryStorage) {
+	originalStore := indexStore
+	t.Cleanup(func() {
+		indexStore = originalStore
+	})
+	store, err := NewIndexStore(writeTestFile(t, "index.json", flattenTestIndex), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexStore = store
+
+	storage := newMemoryStorage()
+	for node, devfile := range flattenTestDevfiles {
+		stack, version, _ := strings.Cut(node, ":")
+		storage.Put(stack, version, devfileName, []byte(devfile))
+	}
+	useStackStorage(t, storage)
+	return store, storage
+}
+
+// serveFlattened requests the flattened devfile of a stack version
+func serveFlattened(stack string, version string) *httptest.ResponseRecorder {
+	return serveFlattenedFromHost("example.com", stack, version)
+}
+
+// serveFlattenedFromHost requests the flattened devfile of a stack version with the given registry host
+func serveFlattenedFromHost(host string, stack string, version string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/devfiles/"+stack+"/"+version+"?"+url.Values{"flatten": {"true"}}.Encode(), nil)
+	c.Request.Host = host
+	c.Params = gin.Params{{Key: "stack", Value: stack}, {Key: "version", Value: version}}
+	server.ServeDevfileWithVersion(c)
+	return w
+}
+
+// flattenedImages returns the container images of the flattened devfile by component name
+func flattenedImages(t *testing.T, devfileBytes []byte) map[string]string {
+	devfileObj, err := parser.ParseFromData(devfileBytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if parent := devfileObj.Data.GetParent(); parent != nil {
+		t.Errorf("Got parent: %+v, Expected the parent to be flattened", parent)
+	}
+	components, err := devfileObj.Data.GetComponents(common.DevfileOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	images := map[string]string{}
+	for _, component := range components {
+		if component.Container == nil {
+			t.Errorf("Got component %s without container", component.Name)
+			continue
+		}
+		images[component.Name] = component.Container.Image
+	}
+	return images
+}
+
+func TestServeFlattenedDevfile(t *testing.T) {
+	setupVars()
+	useFlattenTestRegistry(t)
+
+	tests := []struct {
+		name       string
+		stack      string
+		version    string
+		wantCode   int
+		wantImages map[string]string
+	}{
+		{
+			name:       "Case 1: Parent with overrides",
+			stack:      "child",
+			version:    "default",
+			wantCode:   http.StatusOK,
+			wantImages: map[string]string{"runtime": "child:1.0.0", "tools": "tools:1.0.0"},
+		},
+		{
+			name:       "Case 2: Parent of the parent",
+			stack:      "grandchild",
+			version:    "1.0.0",
+			wantCode:   http.StatusOK,
+			wantImages: map[string]string{"runtime": "child:1.0.0", "tools": "tools:1.0.0", "debugger": "debugger:1.0.0"},
+		},
+		{
+			name:       "Case 3: Parent version",
+			stack:      "child-latest",
+			version:    "latest",
+			wantCode:   http.StatusOK,
+			wantImages: map[string]string{"runtime": "base:2.0.0"},
+		},
+		{
+			name:       "Case 4: Registry URL of this registry",
+			stack:      "registry-url",
+			version:    "1.0.0",
+			wantCode:   http.StatusOK,
+			wantImages: map[string]string{"runtime": "base:1.0.0"},
+		},
+		{
+			name:     "Case 5: Parent not in the registry",
+			stack:    "missing-parent",
+			version:  "1.0.0",
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "Case 6: Parent cycle",
+			stack:    "cycle-a",
+			version:  "1.0.0",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveFlattened(test.stack, test.version)
+			if w.Code != test.wantCode {
+				t.Fatalf("Got status code: %d, Expected: %d, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if test.wantImages == nil {
+				return
+			}
+			if gotImages := flattenedImages(t, w.Body.Bytes()); !reflect.DeepEqual(gotImages, test.wantImages) {
+				t.Errorf("Got images: %v, Expected: %v", gotImages, test.wantImages)
+			}
+		})
+	}
+}
+
+func TestFlattenedDevfileCache(t *testing.T) {
+	setupVars()
+	store, storage := useFlattenTestRegistry(t)
+
+	if w := serveFlattened("child", "1.0.0"); w.Code != http.StatusOK {
+		t.Fatalf("Got status code: %d, Expected: %d", w.Code, http.StatusOK)
+	}
+	storage.Put("base", "1.0.0", devfileName, []byte(strings.Replace(flattenTestDevfiles["base:1.0.0"], "make", "make all", 1)))
+
+	buildCommand := func() string {
+		w := serveFlattened("child", "1.0.0")
+		devfileObj, err := parser.ParseFromData(w.Body.Bytes())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
+		if err != nil || len(commands) != 1 || commands[0].Exec == nil {
+			t.Fatalf("Got commands: %+v and error: %v, Expected the build command", commands, err)
+		}
+		return commands[0].Exec.CommandLine
+	}
+	if got := buildCommand(); got != "make" {
+		t.Errorf("Got command line: %s, Expected the cached flattened devfile", got)
+	}
+
+	// Reloading the index discards the flattened devfiles of the previous index
+	if err := store.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buildCommand(); got != "make all" {
+		t.Errorf("Got command line: %s, Expected the devfile to be flattened again", got)
+	}
+
+	// Hosts that no registry URL refers to share the flattened devfile, the hosts of the registry URLs have their own
+	for _, host := range []string{"registry.example.com", "Other.Example.com:8080"} {
+		if w := serveFlattenedFromHost(host, "child", "1.0.0"); w.Code != http.StatusOK {
+			t.Fatalf("Got status code: %d, Expected: %d", w.Code, http.StatusOK)
+		}
+	}
+	if w := serveFlattened("registry-url", "1.0.0"); w.Code != http.StatusOK {
+		t.Fatalf("Got status code: %d, Expected: %d", w.Code, http.StatusOK)
+	}
+
+	snapshot := store.Snapshot()
+	var keys []string
+	snapshot.flattenedViews.Range(func(key, _ interface{}) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	sort.Strings(keys)
+	if want := []string{"child:1.0.0", "registry-url:1.0.0@example.com"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Got flattened versions: %v, Expected: %v", keys, want)
+	}
+}
+
+func TestServeDevfileWithoutFlatten(t *testing.T) {
+	setupVars()
+	useFlattenTestRegistry(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/devfiles/child/1.0.0", nil)
+	(&Server{}).ServeDevfileWithVersion(c, "child", "1.0.0", ServeDevfileWithVersionParams{})
+	if w.Code != http.StatusOK || w.Body.String() != flattenTestDevfiles["child:1.0.0"] {
+		t.Errorf("Got status code: %d and devfile: %s, Expected the devfile unchanged", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,105 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// serveCacheable writes a response with its ETag, Last-Modified and Cache-Control validators. The ETag is the
+// digest of the content, so that each combination of query parameters gets its own ETag, and a zero lastModified
+// leaves out the Last-Modified header. Conditional requests matching the validators are answered with 304.
+func serveCacheable(c *gin.Context, contentType string, content []byte, lastModified time.Time, cacheControl string) {
+	digest := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(digest[:]) + `"`
+
+	header := c.Writer.Header()
+	header.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if cacheControl != "" {
+		header.Set("Cache-Control", cacheControl)
+	}
+	if len(authenticators) > 0 {
+		// responses depend on the identity of the request
+		header.Add("Vary", "Authorization")
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// notModified evaluates the If-None-Match and If-Modified-Since preconditions of a GET or HEAD request,
+// If-Modified-Since is ignored when If-None-Match is set as per RFC 9110
+func notModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// If-None-Match uses the weak comparison
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ifModifiedSince := req.Header.Get("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		// HTTP dates have a precision of one second
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// devfileLastModified returns the last modified time of the requested version of a stack or sample, the last
+// modified time of the stack or sample is used if the version has none
+func devfileLastModified(devfileIndex indexSchema.Schema, version string) time.Time {
+	if len(devfileIndex.Versions) > 0 {
+		if versionMap, err := util.MakeVersionMap(devfileIndex); err == nil {
+			if foundVersion, found := versionMap[version]; found && foundVersion.LastModified != "" {
+				return parseLastModified(foundVersion.LastModified)
+			}
+		}
+	}
+	return parseLastModified(devfileIndex.LastModified)
+}
+
+// parseLastModified parses the RFC 3339 last modified time of the index, zero if unset or invalid
+func parseLastModified(lastModified string) time.Time {
+	if lastModified == "" {
+		return time.Time{}
+	}
+	modified, err := time.Parse(time.RFC3339, lastModified)
+	if err != nil {
+		return time.Time{}
+	}
+	return modified
+}
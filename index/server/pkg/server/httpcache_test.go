@@ -0,0 +1,176 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNotModified(t *testing.T) {
+	const etag = `"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"`
+	lastModified := time.Date(2024, 4, 19, 11, 45, 48, 500, time.UTC)
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    bool
+	}{
+		{
+			name:   "Case 1: No precondition",
+			method: http.MethodGet,
+			want:   false,
+		},
+		{
+			name:    "Case 2: Matching ETag",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": etag},
+			want:    true,
+		},
+		{
+			name:    "Case 3: Matching weak ETag in a list",
+			method:  http.MethodHead,
+			headers: map[string]string{"If-None-Match": `"other", W/` + etag},
+			want:    true,
+		},
+		{
+			name:    "Case 4: Wildcard ETag",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": "*"},
+			want:    true,
+		},
+		{
+			name:    "Case 5: Different ETag takes precedence over a matching date",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			want:    false,
+		},
+		{
+			name:    "Case 6: Not modified since the date",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			want:    true,
+		},
+		{
+			name:    "Case 7: Modified since the date",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)},
+			want:    false,
+		},
+		{
+			name:    "Case 8: Invalid date",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-Modified-Since": "yesterday"},
+			want:    false,
+		},
+		{
+			name:    "Case 9: Preconditions of write requests are ignored",
+			method:  http.MethodPut,
+			headers: map[string]string{"If-None-Match": etag},
+			want:    false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/index", nil)
+			for header, value := range test.headers {
+				req.Header.Set(header, value)
+			}
+			if got := notModified(req, etag, lastModified); got != test.want {
+				t.Errorf("Got not modified: %v, Expected not modified: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDevfileLastModified(t *testing.T) {
+	devfileIndex := indexSchema.Schema{
+		Name:         "go",
+		LastModified: "2024-04-19T11:45:48+01:00",
+		Versions: []indexSchema.Version{
+			{Version: "1.0.0", LastModified: "2023-11-08T12:54:08Z"},
+			{Version: "2.0.0", Default: true},
+		},
+	}
+	tests := []struct {
+		name    string
+		version string
+		want    time.Time
+	}{
+		{
+			name:    "Case 1: Version with a last modified time",
+			version: "1.0.0",
+			want:    time.Date(2023, 11, 8, 12, 54, 8, 0, time.UTC),
+		},
+		{
+			name:    "Case 2: Default version without last modified time",
+			version: "default",
+			want:    time.Date(2024, 4, 19, 10, 45, 48, 0, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := devfileLastModified(devfileIndex, test.version); !got.Equal(test.want) {
+				t.Errorf("Got last modified: %v, Expected last modified: %v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestServeDevfileIndexV2Cached tests the validators and conditional requests of the '/v2index' endpoint
+func TestServeDevfileIndexV2Cached(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+	serveIndex := func(target string, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		server.ServeDevfileIndexV2(c)
+		return w
+	}
+
+	w := serveIndex("/v2index", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Got status code %v and ETag %q, Expected status code %v and an ETag", w.Code, etag, http.StatusOK)
+	}
+	if gotCacheControl := w.Header().Get("Cache-Control"); gotCacheControl != indexCacheControl {
+		t.Errorf("Got Cache-Control: %q, Expected Cache-Control: %q", gotCacheControl, indexCacheControl)
+	}
+
+	if w = serveIndex("/v2index", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Got status code %v with a body of %d bytes, Expected status code %v without body", w.Code, w.Body.Len(), http.StatusNotModified)
+	}
+
+	// Each combination of filter parameters has its own ETag
+	w = serveIndex("/v2index?arch=arm64", etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("Got status code %v and ETag %q for a filtered index, Expected status code %v and another ETag", w.Code, w.Header().Get("ETag"), http.StatusOK)
+	}
+}
@@ -16,11 +16,14 @@
 package server
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -32,10 +35,16 @@ import (
 
 	oapiMiddleware "github.com/deepmap/oapi-codegen/pkg/gin-middleware"
 	_ "github.com/devfile/registry-support/index/server/docs"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
+// shutdownTimeout is how long the requests in progress are waited for on shutdown
+const shutdownTimeout = 30 * time.Second
+
 var eventTrackMap = map[string]string{
 	"list":       "list devfile",
 	"view":       "view devfile",
@@ -44,12 +53,14 @@ var eventTrackMap = map[string]string{
 }
 
 var mediaTypeMapping = map[string]string{
-	devfileName:       devfileMediaType,
-	devfileNameHidden: devfileMediaType,
-	vsxName:           vsxMediaType,
-	svgLogoName:       svgLogoMediaType,
-	pngLogoName:       pngLogoMediaType,
-	archiveName:       archiveMediaType,
+	devfileName:          devfileMediaType,
+	devfileNameHidden:    devfileMediaType,
+	devfileNameYml:       devfileMediaType,
+	devfileNameYmlHidden: devfileMediaType,
+	vsxName:              vsxMediaType,
+	svgLogoName:          svgLogoMediaType,
+	pngLogoName:          pngLogoMediaType,
+	archiveName:          archiveMediaType,
 }
 
 var getIndexLatency = prometheus.NewHistogramVec(
@@ -62,53 +73,42 @@ var getIndexLatency = prometheus.NewHistogramVec(
 )
 
 func ServeRegistry() {
+	// Load the configuration of the listen addresses and upstreams
+	config, err := LoadConfig(os.Args[1:])
+	if err != nil {
+		log.Fatalf("failed to load the configuration: %v", err)
+	}
+	if err := applyConfig(config); err != nil {
+		log.Fatal(err.Error())
+	}
+
 	// Enable metrics
 	// Run on a separate port and router from the index server so that it's not exposed publicly
 
 	handler := http.NewServeMux()
 	handler.Handle("/metrics", promhttp.Handler())
-	prometheus.MustRegister(getIndexLatency)
-
-	// Retrieve the option to enable HTTP2
-	enableHTTP2 := os.Getenv("ENABLE_HTTP2")
-	if enableHTTP2 == "" {
-		enableHTTP2 = "false"
-	}
-
-	indexServer := &http.Server{
-		Addr:         ":7071",
-		Handler:      handler,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-	}
-
-	// Disable HTTP2 by default
-	if enableHTTP2 == "false" {
-		indexServer.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
-	}
+	prometheus.MustRegister(metricsCollectors()...)
+	prometheus.MustRegister(util.TelemetryCollectors()...)
 
-	// Disable HTTP2 by default
-	if enableHTTP2 == "false" {
-		indexServer.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	metricsServer, err := config.Metrics.newHTTPServer(handler)
+	if err != nil {
+		log.Fatalf("failed to set up the metrics server: %v", err)
 	}
-
-	go indexServer.ListenAndServe()
+	go func() {
+		if err := config.Metrics.listenAndServe(metricsServer); err != nil {
+			log.Printf("metrics server stopped: %v", err)
+		}
+	}()
 
 	// Wait until registry is up and running
-	err := wait.PollImmediate(time.Millisecond, time.Second*30, func() (bool, error) {
-		resp, err := http.Get(scheme + "://" + registryService)
-		if err != nil {
-			log.Println(err.Error())
+	err = wait.PollImmediate(time.Millisecond, time.Second*30, func() (bool, error) {
+		if err := stackStorage.Ready(); err != nil {
+			log.Printf("Waiting for the %s storage to be ready: %v", config.Storage.Type, err)
 			return false, nil
 		}
 
-		if resp.StatusCode == http.StatusOK {
-			log.Println("Registry is up and running")
-			return true, nil
-		}
-
-		log.Println("Waiting for registry to start...")
-		return false, nil
+		log.Printf("The %s storage is ready", config.Storage.Type)
+		return true, nil
 	})
 	if err != nil {
 		log.Fatal(err.Error())
@@ -135,17 +135,16 @@ func ServeRegistry() {
 		} else if devfileIndex.Type == indexSchema.StackDevfileType {
 			stackIndex = append(stackIndex, devfileIndex)
 		}
-
-		if devfileIndex.Versions != nil && len(devfileIndex.Versions) != 0 {
-			for _, versionComponent := range devfileIndex.Versions {
-				if len(versionComponent.Resources) != 0 {
-					err := pushStackToRegistry(versionComponent, devfileIndex.Name)
-					if err != nil {
-						log.Fatal(err.Error())
-					}
-				}
-			}
+	}
+	if failures := pushStacks(index, config.Storage); len(failures) != 0 {
+		for _, failure := range failures {
+			log.Printf("failed to push %s version %s: %v", failure.stackName, failure.version, failure.err)
+		}
+		if !config.Storage.AllowDegraded {
+			log.Fatalf("failed to push %d stack versions", len(failures))
 		}
+		log.Printf("Starting in degraded mode, %d stack versions are unavailable", len(failures))
+		markUnavailable(failures)
 	}
 	err = indexLibrary.CreateIndexFile(sampleIndex, sampleIndexPath)
 	if err != nil {
@@ -156,9 +155,42 @@ func ServeRegistry() {
 		log.Fatalf("failed to generate %s: %v", stackIndexPath, err)
 	}
 
+	// Load the index in memory, the stack and sample views are derived from the index so that they are
+	// kept up to date when the index file is reloaded
+	indexStore, err = NewIndexStore(indexPath, "", "")
+	if err != nil {
+		log.Fatalf("failed to load the index: %v", err)
+	}
+	go func() {
+		// the stack versions added or changed by a reload are pushed to the storage before they can be pulled
+		pushReloaded := func(previous *IndexSnapshot, current *IndexSnapshot) {
+			pushReloadedStacks(previous, current, config.Storage)
+		}
+		if err := indexStore.Watch(nil, pushReloaded); err != nil {
+			log.Printf("failed to watch the index file, the index will not be reloaded: %v", err)
+		}
+	}()
+
+	// Record the download statistics, the registry is served without them if the database cannot be opened
+	if config.Stats.File != "" {
+		if downloadStats, err = openDownloadStats(config.Stats); err != nil {
+			log.Printf("%v, the download statistics are disabled", err)
+		}
+	}
+
+	// Propagate the trace context of the requests to the upstreams, and export the spans if configured
+	tracerProvider, err := config.Tracing.newTracerProvider()
+	if err != nil {
+		log.Fatalf("failed to set up the tracing: %v", err)
+	}
+	setupTracing(tracerProvider)
+	if tracerProvider != nil {
+		log.Printf("Tracing is enabled, the spans are exported to %s\n", config.Tracing.OTLPEndpoint)
+	}
+
 	// Logs for telemetry configuration
 	if enableTelemetry {
-		log.Println("Telemetry is enabled")
+		log.Printf("Telemetry is enabled with the sinks %v\n", config.Telemetry.sinkTypes())
 		log.Printf("The registry name for telemetry is %s\n", registry)
 	} else {
 		log.Println("Telemetry is not enabled")
@@ -175,14 +207,24 @@ func ServeRegistry() {
 	// Create server context
 	server := &Server{}
 
+	// Set up the authentication and access rules of private registries
+	if err := setupAuth(); err != nil {
+		log.Fatalf("failed to set up authentication: %v", err)
+	}
+
 	// Start the server and serve requests and index.json
 	router := gin.Default()
+	router.Use(otelgin.Middleware(fmt.Sprint(registry)), metricsMiddleware, authenticate)
 
 	// Register Devfile Registry REST APIs and use OpenAPI validator middleware
 	router = RegisterHandlersWithOptions(router, server, GinServerOptions{
 		Middlewares: []MiddlewareFunc{
 			func(c *gin.Context) {
-				oapiMiddleware.OapiRequestValidator(swagger)(c)
+				// Requests are authenticated by the authenticate middleware and the write permission is checked by the
+				// publish handlers, which answer with 401 or 403 instead of 400
+				oapiMiddleware.OapiRequestValidatorWithOptions(swagger, &oapiMiddleware.Options{
+					Options: openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+				})(c)
 			},
 		},
 	})
@@ -197,7 +239,45 @@ func ServeRegistry() {
 	router.GET("/viewer/*proxyPath", ServeUI)
 
 	// Serve static content for stacks
-	router.Static("/stacks", stacksPath)
+	router.Group("/stacks", authorizeStackName("filepath")).Static("/", stacksPath)
 
-	router.Run(":8080")
+	httpServer, err := config.Server.newHTTPServer(router)
+	if err != nil {
+		log.Fatalf("failed to set up the registry server: %v", err)
+	}
+	log.Printf("Listening on %s\n", config.Server.Address)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- config.Server.listenAndServe(httpServer)
+	}()
+
+	// Stop on SIGTERM or interrupt, the requests in progress are completed before the queued telemetry events
+	// and spans are sent
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+	select {
+	case err := <-serveErr:
+		log.Fatal(err.Error())
+	case sig := <-stop:
+		log.Printf("Received %s, shutting down\n", sig)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("failed to shut down the registry server: %v", err)
+	}
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		log.Printf("failed to shut down the metrics server: %v", err)
+	}
+	if downloadStats != nil {
+		if err := downloadStats.Close(); err != nil {
+			log.Printf("failed to close the download statistics: %v", err)
+		}
+	}
+	util.CloseTelemetry()
+	if tracerProvider != nil {
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			log.Printf("failed to export the remaining spans: %v", err)
+		}
+	}
 }
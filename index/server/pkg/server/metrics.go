@@ -0,0 +1,158 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"strconv"
+	"time"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Label values of the OCI operations
+	ociPushOperation   = "push"
+	ociPullOperation   = "pull"
+	ociDeleteOperation = "delete"
+
+	// Label values of the starter project cache requests
+	cacheHit  = "hit"
+	cacheMiss = "miss"
+
+	// unmatchedRoute labels the requests which do not match any route, so that unknown paths cannot grow
+	// the number of series
+	unmatchedRoute = "unmatched"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "registry_http_requests_total",
+		Help: "Number of HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "registry_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests in seconds by route, method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+	httpResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "registry_http_response_size_bytes",
+		Help:    "Size of HTTP response bodies in bytes by route, method and status code.",
+		Buckets: prometheus.ExponentialBuckets(256, 4, 10),
+	}, []string{"route", "method", "status"})
+
+	ociRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "registry_oci_request_duration_seconds",
+		Help:    "Latency of stack pushes and pulls to the OCI registry in seconds by operation.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+	ociRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "registry_oci_request_errors_total",
+		Help: "Number of failed stack pushes and pulls to the OCI registry by operation.",
+	}, []string{"operation"})
+
+	starterProjectDownloadDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "registry_starter_project_download_duration_seconds",
+		Help:    "Duration of starter project downloads from their remote sources in seconds.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+	starterProjectCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "registry_starter_project_cache_requests_total",
+		Help: "Number of starter project requests served from the cache (hit) or downloaded (miss).",
+	}, []string{"result"})
+
+	indexReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "registry_index_reloads_total",
+		Help: "Number of index loads and reloads by result.",
+	}, []string{"result"})
+	indexEntries = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "registry_index_entries",
+		Help: "Number of stacks and samples in the current index by type.",
+	}, []string{"type"})
+	indexStackVersions = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "registry_index_stack_versions",
+		Help: "Number of stack versions in the current index.",
+	})
+)
+
+// metricsCollectors returns the metrics of the registry server endpoints and backends
+func metricsCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		getIndexLatency,
+		httpRequests,
+		httpRequestDuration,
+		httpResponseSize,
+		ociRequestDuration,
+		ociRequestErrors,
+		starterProjectDownloadDuration,
+		starterProjectCacheRequests,
+		indexReloads,
+		indexEntries,
+		indexStackVersions,
+	}
+}
+
+// metricsMiddleware records the count, latency and response size of the requests labelled by their route
+// template, so that stack names and versions in the paths do not grow the number of series
+func metricsMiddleware(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	status := strconv.Itoa(c.Writer.Status())
+	size := c.Writer.Size()
+	if size < 0 {
+		size = 0
+	}
+
+	httpRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+	httpRequestDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	httpResponseSize.WithLabelValues(route, c.Request.Method, status).Observe(float64(size))
+}
+
+// observeOCIRequest records the latency of an OCI operation started at start, and counts it as failed if
+// *err is set. It is meant to be deferred.
+func observeOCIRequest(operation string, start time.Time, err *error) {
+	ociRequestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if *err != nil {
+		ociRequestErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// observeIndexReload counts an index load or reload by result. It is meant to be deferred.
+func observeIndexReload(err *error) {
+	result := "success"
+	if *err != nil {
+		result = "failure"
+	}
+	indexReloads.WithLabelValues(result).Inc()
+}
+
+// setIndexEntries sets the number of stacks, samples and stack versions of the current index, stacks without
+// versions count as a single version
+func setIndexEntries(stackIndex []indexSchema.Schema, sampleIndex []indexSchema.Schema) {
+	versions := 0
+	for _, devfileIndex := range stackIndex {
+		versions += max(len(devfileIndex.Versions), 1)
+	}
+	indexEntries.WithLabelValues(string(indexSchema.StackDevfileType)).Set(float64(len(stackIndex)))
+	indexEntries.WithLabelValues(string(indexSchema.SampleDevfileType)).Set(float64(len(sampleIndex)))
+	indexStackVersions.Set(float64(versions))
+}
@@ -0,0 +1,182 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// writeMetric returns the current value of a metric
+func writeMetric(t *testing.T, metric prometheus.Metric) *dto.Metric {
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		t.Fatalf("failed to write metric: %v", err)
+	}
+	return &m
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(metricsMiddleware)
+	router.GET("/devfiles/:stack", func(c *gin.Context) {
+		c.String(http.StatusOK, "schemaVersion: 2.2.0")
+	})
+
+	tests := []struct {
+		name      string
+		target    string
+		wantRoute string
+		wantCode  int
+		wantSize  float64
+	}{
+		{
+			name:      "Case 1: Requests are labelled by route template",
+			target:    "/devfiles/go",
+			wantRoute: "/devfiles/:stack",
+			wantCode:  http.StatusOK,
+			wantSize:  float64(len("schemaVersion: 2.2.0")),
+		},
+		{
+			name:      "Case 2: Requests without route",
+			target:    "/unknown/path",
+			wantRoute: unmatchedRoute,
+			wantCode:  http.StatusNotFound,
+			// gin writes the default 404 body after the handlers
+			wantSize: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := strconv.Itoa(test.wantCode)
+			requests := httpRequests.WithLabelValues(test.wantRoute, http.MethodGet, status)
+			durations := httpRequestDuration.WithLabelValues(test.wantRoute, http.MethodGet, status).(prometheus.Histogram)
+			sizes := httpResponseSize.WithLabelValues(test.wantRoute, http.MethodGet, status).(prometheus.Histogram)
+			requestsBefore := writeMetric(t, requests).Counter.GetValue()
+			durationsBefore := writeMetric(t, durations).Histogram.GetSampleCount()
+			sizesBefore := writeMetric(t, sizes).Histogram.GetSampleSum()
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.target, nil))
+			if w.Code != test.wantCode {
+				t.Fatalf("Got status code: %d, Expected: %d", w.Code, test.wantCode)
+			}
+
+			if got := writeMetric(t, requests).Counter.GetValue() - requestsBefore; got != 1 {
+				t.Errorf("Got %v requests, Expected: 1", got)
+			}
+			if got := writeMetric(t, durations).Histogram.GetSampleCount() - durationsBefore; got != 1 {
+				t.Errorf("Got %v latency observations, Expected: 1", got)
+			}
+			if got := writeMetric(t, sizes).Histogram.GetSampleSum() - sizesBefore; got != test.wantSize {
+				t.Errorf("Got response size: %v, Expected: %v", got, test.wantSize)
+			}
+		})
+	}
+}
+
+func TestObserveOCIRequest(t *testing.T) {
+	errorsBefore := writeMetric(t, ociRequestErrors.WithLabelValues(ociPullOperation)).Counter.GetValue()
+	durations := ociRequestDuration.WithLabelValues(ociPullOperation).(prometheus.Histogram)
+	durationsBefore := writeMetric(t, durations).Histogram.GetSampleCount()
+
+	pull := func(pullErr error) (err error) {
+		defer observeOCIRequest(ociPullOperation, time.Now(), &err)
+		return pullErr
+	}
+	_ = pull(nil)
+	_ = pull(errors.New("manifest unknown"))
+
+	if got := writeMetric(t, durations).Histogram.GetSampleCount() - durationsBefore; got != 2 {
+		t.Errorf("Got %v latency observations, Expected: 2", got)
+	}
+	if got := writeMetric(t, ociRequestErrors.WithLabelValues(ociPullOperation)).Counter.GetValue() - errorsBefore; got != 1 {
+		t.Errorf("Got %v errors, Expected: 1", got)
+	}
+}
+
+func TestStarterProjectCacheMetrics(t *testing.T) {
+	cache := useStarterProjectCache(t)
+	key := starterProjectKey{Stack: "go", Version: "1.2.0", Project: "go-starter"}
+	download := func(downloadTmpLoc string) ([]byte, error) {
+		return []byte("archive"), nil
+	}
+	hitsBefore := writeMetric(t, starterProjectCacheRequests.WithLabelValues(cacheHit)).Counter.GetValue()
+	missesBefore := writeMetric(t, starterProjectCacheRequests.WithLabelValues(cacheMiss)).Counter.GetValue()
+	downloadsBefore := writeMetric(t, starterProjectDownloadDuration).Histogram.GetSampleCount()
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.get(key, download); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if got := writeMetric(t, starterProjectCacheRequests.WithLabelValues(cacheHit)).Counter.GetValue() - hitsBefore; got != 2 {
+		t.Errorf("Got %v cache hits, Expected: 2", got)
+	}
+	if got := writeMetric(t, starterProjectCacheRequests.WithLabelValues(cacheMiss)).Counter.GetValue() - missesBefore; got != 1 {
+		t.Errorf("Got %v cache misses, Expected: 1", got)
+	}
+	if got := writeMetric(t, starterProjectDownloadDuration).Histogram.GetSampleCount() - downloadsBefore; got != 1 {
+		t.Errorf("Got %v download observations, Expected: 1", got)
+	}
+}
+
+func TestIndexMetrics(t *testing.T) {
+	indexFile := writeTestFile(t, "index.json", `[
+  {"name": "go", "type": "stack", "versions": [{"version": "1.0.0"}, {"version": "2.0.0"}]},
+  {"name": "nodejs", "type": "stack", "version": "1.0.0"},
+  {"name": "go-sample", "type": "sample"}
+]`)
+	successesBefore := writeMetric(t, indexReloads.WithLabelValues("success")).Counter.GetValue()
+	failuresBefore := writeMetric(t, indexReloads.WithLabelValues("failure")).Counter.GetValue()
+
+	if _, err := NewIndexStore(indexFile, "", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := NewIndexStore(filepath.Join(t.TempDir(), "missing.json"), "", ""); err == nil {
+		t.Fatalf("Expected an error for a missing index file")
+	}
+
+	if got := writeMetric(t, indexReloads.WithLabelValues("success")).Counter.GetValue() - successesBefore; got != 1 {
+		t.Errorf("Got %v successful reloads, Expected: 1", got)
+	}
+	if got := writeMetric(t, indexReloads.WithLabelValues("failure")).Counter.GetValue() - failuresBefore; got != 1 {
+		t.Errorf("Got %v failed reloads, Expected: 1", got)
+	}
+	// The entries of the failed reload are not counted
+	wantEntries := map[indexSchema.DevfileType]float64{indexSchema.StackDevfileType: 2, indexSchema.SampleDevfileType: 1}
+	for devfileType, want := range wantEntries {
+		if got := writeMetric(t, indexEntries.WithLabelValues(string(devfileType))).Gauge.GetValue(); got != want {
+			t.Errorf("Got %v %s entries, Expected: %v", got, devfileType, want)
+		}
+	}
+	if got := writeMetric(t, indexStackVersions).Gauge.GetValue(); got != 3 {
+		t.Errorf("Got %v stack versions, Expected: 3", got)
+	}
+}
@@ -0,0 +1,108 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+const (
+	devfileYAMLMediaType = "application/yaml"
+	devfileJSONMediaType = "application/json"
+)
+
+// devfileYAMLAliases are the media types YAML devfiles are also requested with
+var devfileYAMLAliases = []string{devfileYAMLMediaType, "application/x-yaml", "text/yaml", "text/x-yaml"}
+
+// mediaRange is a media range of an Accept header with its quality
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept parses the media ranges of an Accept header, invalid media ranges are skipped
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// acceptance returns the quality and the specificity of the most specific media range matching one of the
+// media types, the specificity is 2 for a full match, 1 for a type wildcard, 0 for */* and -1 for no match
+func acceptance(ranges []mediaRange, mediaTypes ...string) (float64, int) {
+	quality, specificity := 0.0, -1
+	for _, r := range ranges {
+		for _, mediaType := range mediaTypes {
+			matched := -1
+			switch {
+			case r.mediaType == mediaType:
+				matched = 2
+			case strings.HasSuffix(r.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(r.mediaType, "*")):
+				matched = 1
+			case r.mediaType == "*/*":
+				matched = 0
+			}
+			if matched > specificity || (matched == specificity && r.quality > quality) {
+				quality, specificity = r.quality, matched
+			}
+		}
+	}
+	return quality, specificity
+}
+
+// negotiateDevfileType returns the media type devfiles are served with for an Accept header, YAML by default and
+// JSON when preferred. Returns false if neither YAML nor JSON is acceptable.
+func negotiateDevfileType(accept string) (string, bool) {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return devfileYAMLMediaType, true
+	}
+
+	yamlQuality, yamlSpecificity := acceptance(ranges, devfileYAMLAliases...)
+	jsonQuality, jsonSpecificity := acceptance(ranges, devfileJSONMediaType)
+	if yamlSpecificity < 0 || yamlQuality <= 0 {
+		yamlQuality = 0
+	}
+	if jsonSpecificity < 0 || jsonQuality <= 0 {
+		jsonQuality = 0
+	}
+
+	switch {
+	case yamlQuality == 0 && jsonQuality == 0:
+		return "", false
+	case jsonQuality > yamlQuality, jsonQuality == yamlQuality && jsonSpecificity > yamlSpecificity:
+		return devfileJSONMediaType, true
+	default:
+		return devfileYAMLMediaType, true
+	}
+}
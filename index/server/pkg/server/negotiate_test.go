@@ -0,0 +1,277 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+)
+
+func TestNegotiateDevfileType(t *testing.T) {
+	tests := []struct {
+		name           string
+		accept         string
+		wantType       string
+		wantAcceptable bool
+	}{
+		{
+			name:           "Case 1: YAML by default",
+			wantType:       devfileYAMLMediaType,
+			wantAcceptable: true,
+		},
+		{
+			name:           "Case 2: Any media type",
+			accept:         "*/*",
+			wantType:       devfileYAMLMediaType,
+			wantAcceptable: true,
+		},
+		{
+			name:           "Case 3: JSON",
+			accept:         "application/json",
+			wantType:       devfileJSONMediaType,
+			wantAcceptable: true,
+		},
+		{
+			name:           "Case 4: JSON preferred over any media type",
+			accept:         "application/json, */*",
+			wantType:       devfileJSONMediaType,
+			wantAcceptable: true,
+		},
+		{
+			name:           "Case 5: YAML preferred by quality",
+			accept:         "application/json;q=0.5, text/yaml",
+			wantType:       devfileYAMLMediaType,
+			wantAcceptable: true,
+		},
+		{
+			name:           "Case 6: JSON preferred by quality",
+			accept:         "application/yaml;q=0.2, application/json;q=0.8",
+			wantType:       devfileJSONMediaType,
+			wantAcceptable: true,
+		},
+		{
+			name:           "Case 7: Browser",
+			accept:         "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			wantType:       devfileYAMLMediaType,
+			wantAcceptable: true,
+		},
+		{
+			name:   "Case 8: Not acceptable",
+			accept: "text/html",
+		},
+		{
+			name:   "Case 9: YAML and JSON excluded",
+			accept: "application/yaml;q=0, application/json;q=0, */*",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotType, gotAcceptable := negotiateDevfileType(test.accept)
+			if gotType != test.wantType || gotAcceptable != test.wantAcceptable {
+				t.Errorf("Got: %s, %v, Expected: %s, %v", gotType, gotAcceptable, test.wantType, test.wantAcceptable)
+			}
+		})
+	}
+}
+
+func TestConvertSchemaVersion(t *testing.T) {
+	containerDevfile := `schemaVersion: 2.2.0
+metadata:
+  name: container
+components:
+  - name: runtime
+    container:
+      image: runtime:latest
+`
+	imageDevfile := `schemaVersion: 2.2.0
+metadata:
+  name: image
+components:
+  - name: outerloop-build
+    image:
+      imageName: app:latest
+      dockerfile:
+        uri: Dockerfile
+`
+
+	tests := []struct {
+		name              string
+		devfile           string
+		schemaVersion     string
+		wantSchemaVersion string
+		wantErr           bool
+		wantConversionErr bool
+	}{
+		{
+			name:              "Case 1: Older schema version",
+			devfile:           containerDevfile,
+			schemaVersion:     "2.1.0",
+			wantSchemaVersion: "2.1.0",
+		},
+		{
+			name:              "Case 2: Schema version without bugfix version",
+			devfile:           containerDevfile,
+			schemaVersion:     "2.0",
+			wantSchemaVersion: "2.0.0",
+		},
+		{
+			name:              "Case 3: Same schema version",
+			devfile:           containerDevfile,
+			schemaVersion:     "2.2.0",
+			wantSchemaVersion: "2.2.0",
+		},
+		{
+			name:              "Case 4: Features of the newer schema version",
+			devfile:           imageDevfile,
+			schemaVersion:     "2.1.0",
+			wantErr:           true,
+			wantConversionErr: true,
+		},
+		{
+			name:          "Case 5: Newer schema version",
+			devfile:       containerDevfile,
+			schemaVersion: "2.3.0",
+			wantErr:       true,
+		},
+		{
+			name:          "Case 6: Unsupported schema version",
+			devfile:       containerDevfile,
+			schemaVersion: "1.0.0",
+			wantErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			converted, err := convertSchemaVersion([]byte(test.devfile), test.schemaVersion)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Got error: %v, Expected error: %v", err, test.wantErr)
+			}
+			var conversionErr *schemaConversionError
+			if gotConversionErr := errors.As(err, &conversionErr); gotConversionErr != test.wantConversionErr {
+				t.Errorf("Got error: %v, Expected conversion error: %v", err, test.wantConversionErr)
+			}
+			if test.wantErr {
+				return
+			}
+			devfileObj, err := parser.ParseFromData(converted)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := devfileObj.Data.GetSchemaVersion(); got != test.wantSchemaVersion {
+				t.Errorf("Got schema version: %s, Expected: %s", got, test.wantSchemaVersion)
+			}
+		})
+	}
+}
+
+func TestServeDevfileRepresentation(t *testing.T) {
+	setupVars()
+	useFlattenTestRegistry(t)
+
+	tests := []struct {
+		name              string
+		query             string
+		accept            string
+		wantCode          int
+		wantContentType   string
+		wantSchemaVersion string
+	}{
+		{
+			name:              "Case 1: YAML",
+			wantCode:          http.StatusOK,
+			wantContentType:   devfileYAMLMediaType,
+			wantSchemaVersion: "2.2.0",
+		},
+		{
+			name:              "Case 2: JSON",
+			accept:            "application/json",
+			wantCode:          http.StatusOK,
+			wantContentType:   devfileJSONMediaType,
+			wantSchemaVersion: "2.2.0",
+		},
+		{
+			name:              "Case 3: Flattened JSON of an older schema version",
+			query:             "flatten=true&schemaVersion=2.1.0",
+			accept:            "application/json",
+			wantCode:          http.StatusOK,
+			wantContentType:   devfileJSONMediaType,
+			wantSchemaVersion: "2.1.0",
+		},
+		{
+			name:     "Case 4: Not acceptable media type",
+			accept:   "text/html",
+			wantCode: http.StatusNotAcceptable,
+		},
+		{
+			name:     "Case 5: Newer schema version",
+			query:    "schemaVersion=2.3.0",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/devfiles/child/1.0.0?"+test.query, nil)
+			if test.accept != "" {
+				c.Request.Header.Set("Accept", test.accept)
+			}
+			c.Params = gin.Params{{Key: "stack", Value: "child"}, {Key: "version", Value: "1.0.0"}}
+			server.ServeDevfileWithVersion(c)
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Got status code: %d, Expected: %d, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if test.wantCode != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, test.wantContentType) {
+				t.Errorf("Got content type: %s, Expected: %s", got, test.wantContentType)
+			}
+			if got := w.Header().Values("Vary"); len(got) == 0 || got[0] != "Accept" {
+				t.Errorf("Got Vary: %v, Expected: Accept", got)
+			}
+			if test.wantContentType == devfileJSONMediaType && !json.Valid(w.Body.Bytes()) {
+				t.Errorf("Got devfile: %s, Expected JSON", w.Body.String())
+			}
+			var devfile struct {
+				SchemaVersion string `json:"schemaVersion"`
+			}
+			if err := yaml.Unmarshal(w.Body.Bytes(), &devfile); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if devfile.SchemaVersion != test.wantSchemaVersion {
+				t.Errorf("Got schema version: %s, Expected: %s", devfile.SchemaVersion, test.wantSchemaVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// OIDCAuthenticator authenticates OIDC JWT bearer tokens signed by a key of the JWKS of the identity provider
+type OIDCAuthenticator struct {
+	verifier      *oidc.IDTokenVerifier
+	usernameClaim string
+	groupsClaim   string
+}
+
+// NewOIDCAuthenticator creates an authenticator accepting tokens of the issuer for the audience, the audience is not
+// checked if empty. The identity name and groups are read from the given claims.
+func NewOIDCAuthenticator(issuer string, jwksUrl string, audience string, usernameClaim string, groupsClaim string) *OIDCAuthenticator {
+	ctx := oidc.ClientContext(context.Background(), &http.Client{Timeout: 10 * time.Second})
+	keySet := oidc.NewRemoteKeySet(ctx, jwksUrl)
+	return &OIDCAuthenticator{
+		verifier: oidc.NewVerifier(issuer, keySet, &oidc.Config{
+			ClientID:          audience,
+			SkipClientIDCheck: audience == "",
+			SupportedSigningAlgs: []string{
+				oidc.RS256, oidc.RS384, oidc.RS512,
+				oidc.ES256, oidc.ES384, oidc.ES512,
+				oidc.PS256, oidc.PS384, oidc.PS512,
+				oidc.EdDSA,
+			},
+		}),
+		usernameClaim: usernameClaim,
+		groupsClaim:   groupsClaim,
+	}
+}
+
+func (a *OIDCAuthenticator) Authenticate(req *http.Request) (*Identity, error) {
+	token, found := bearerToken(req)
+	if !found || strings.Count(token, ".") != 2 {
+		// not a JWT, possibly an API token
+		return nil, nil
+	}
+
+	idToken, err := a.verifier.Verify(req.Context(), token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC token: %v", err)
+	}
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("invalid OIDC token: %v", err)
+	}
+
+	name, _ := claims[a.usernameClaim].(string)
+	if name == "" {
+		return nil, fmt.Errorf("invalid OIDC token: claim %s is not set", a.usernameClaim)
+	}
+	identity := &Identity{Name: name}
+	switch groups := claims[a.groupsClaim].(type) {
+	case string:
+		identity.Groups = []string{groups}
+	case []interface{}:
+		for _, group := range groups {
+			if group, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, group)
+			}
+		}
+	}
+	return identity, nil
+}
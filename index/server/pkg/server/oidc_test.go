@@ -0,0 +1,200 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "devfile-registry"
+)
+
+// signTestJWT signs the claims with the RSA or EC key, the key id is set in the header
+func signTestJWT(t *testing.T, key crypto.Signer, kid string, claims map[string]interface{}) string {
+	alg := "RS256"
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		alg = "ES256"
+	}
+	encode := func(v interface{}) string {
+		content, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("failed to marshal the JWT part: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(content)
+	}
+	signed := encode(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("failed to sign the JWT: %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign the JWT: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// newTestJWKSServer serves the JWKS of the RSA and EC keys, the number of requests is counted
+func newTestJWKSServer(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey, requests *int) *httptest.Server {
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-key", "use": "sig", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec-key", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate the RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate the EC key: %v", err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate the EC key: %v", err)
+	}
+	var jwksRequests int
+	jwksServer := newTestJWKSServer(t, rsaKey, ecKey, &jwksRequests)
+	now := time.Now()
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{
+			"iss":    testIssuer,
+			"aud":    []string{testAudience, "other"},
+			"sub":    "alice",
+			"groups": []string{"java-developers", "admins"},
+			"exp":    now.Add(time.Hour).Unix(),
+			"nbf":    now.Add(-time.Hour).Unix(),
+		}
+		for claim, value := range overrides {
+			claims[claim] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		wantIdentity *Identity
+		wantErr      bool
+	}{
+		{
+			name:         "Case 1: Token signed by the RSA key",
+			token:        signTestJWT(t, rsaKey, "rsa-key", claims(nil)),
+			wantIdentity: &Identity{Name: "alice", Groups: []string{"java-developers", "admins"}},
+		},
+		{
+			name:         "Case 2: Token signed by the EC key with a single group",
+			token:        signTestJWT(t, ecKey, "ec-key", claims(map[string]interface{}{"aud": testAudience, "groups": "admins"})),
+			wantIdentity: &Identity{Name: "alice", Groups: []string{"admins"}},
+		},
+		{
+			name:  "Case 3: Opaque token is left to the other authenticators",
+			token: "api-token",
+		},
+		{
+			name:    "Case 4: Token signed by an unknown key",
+			token:   signTestJWT(t, otherKey, "ec-key", claims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "Case 5: Token with an unknown key id",
+			token:   signTestJWT(t, otherKey, "other-key", claims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "Case 6: Expired token",
+			token:   signTestJWT(t, rsaKey, "rsa-key", claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "Case 7: Token which is not valid yet",
+			token:   signTestJWT(t, rsaKey, "rsa-key", claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "Case 8: Token of another issuer",
+			token:   signTestJWT(t, rsaKey, "rsa-key", claims(map[string]interface{}{"iss": "https://other.example.com"})),
+			wantErr: true,
+		},
+		{
+			name:    "Case 9: Token for another audience",
+			token:   signTestJWT(t, rsaKey, "rsa-key", claims(map[string]interface{}{"aud": "other"})),
+			wantErr: true,
+		},
+		{
+			name:    "Case 10: Token without username claim",
+			token:   signTestJWT(t, rsaKey, "rsa-key", claims(map[string]interface{}{"sub": ""})),
+			wantErr: true,
+		},
+	}
+
+	authenticator := NewOIDCAuthenticator(testIssuer, jwksServer.URL, testAudience, "sub", "groups")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/index", nil)
+			req.Header.Set("Authorization", "Bearer "+test.token)
+
+			gotIdentity, err := authenticator.Authenticate(req)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Got error: %v, Expected error: %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(gotIdentity, test.wantIdentity) {
+				t.Errorf("Got identity: %+v, Expected identity: %+v", gotIdentity, test.wantIdentity)
+			}
+		})
+	}
+
+	if jwksRequests == 0 {
+		t.Errorf("Got no JWKS requests, Expected the JWKS to be fetched")
+	}
+}
@@ -0,0 +1,268 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+	versionpkg "github.com/hashicorp/go-version"
+)
+
+const (
+	totalCountHeader = "X-Total-Count"
+	maxPageLimit     = 1000
+)
+
+// indexCursor is the position of a page in a sorted index, the page starts after the stack or sample named in
+// the cursor. Cursors are encoded as base64 JSON and are only valid for the sort they were created for.
+type indexCursor struct {
+	Name  string `json:"name"`
+	Sort  Sort   `json:"sort"`
+	Order Order  `json:"order"`
+}
+
+// sortAndPaginate sorts the index and returns the requested page of it. The total count and the links to the
+// other pages are set as response headers. Paginated indexes are sorted by name unless another sort is requested,
+// the order of the index is kept if neither sorting nor pagination is requested.
+func sortAndPaginate(c *gin.Context, index []indexSchema.Schema, params IndexParams) ([]indexSchema.Schema, error) {
+	total := len(index)
+	c.Header(totalCountHeader, strconv.Itoa(total))
+
+	paginated := params.Limit != nil || params.Offset != nil || params.Cursor != nil
+	if params.Sort == nil && params.Order == nil && !paginated {
+		return index, nil
+	}
+
+	sortBy, order := SortName, Asc
+	if params.Sort != nil {
+		sortBy = *params.Sort
+	}
+	if sortBy == SortPopularity {
+		// The most popular stacks come first by default
+		order = Desc
+	}
+	if params.Order != nil {
+		order = *params.Order
+	}
+	popularity := indexPopularity(sortBy)
+	if err := sortIndex(index, sortBy, order, popularity); err != nil {
+		return nil, err
+	}
+	if !paginated {
+		return index, nil
+	}
+
+	if params.Offset != nil && params.Cursor != nil {
+		return nil, fmt.Errorf("offset and cursor cannot be used together")
+	}
+	limit := total
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > maxPageLimit {
+			return nil, fmt.Errorf("limit %d is not valid, it should be between 1 and %d", *params.Limit, maxPageLimit)
+		}
+		limit = *params.Limit
+	}
+
+	start := 0
+	if params.Offset != nil {
+		if *params.Offset < 0 {
+			return nil, fmt.Errorf("offset %d is not valid, it should not be negative", *params.Offset)
+		}
+		start = min(*params.Offset, total)
+	} else if params.Cursor != nil {
+		var err error
+		if start, err = cursorStart(index, *params.Cursor, sortBy, order, popularity); err != nil {
+			return nil, err
+		}
+	}
+	end := min(start+limit, total)
+
+	if params.Limit != nil {
+		setPageLinks(c, index, start, end, limit, params.Offset != nil, sortBy, order)
+	}
+	return index[start:end], nil
+}
+
+// sortIndex sorts the index in place, stacks and samples with the same sort key are sorted by name
+func sortIndex(index []indexSchema.Schema, sortBy Sort, order Order, popularity map[string]uint64) error {
+	switch sortBy {
+	case SortName, SortDisplayName, SortLastModified, SortVersion, SortPopularity:
+	default:
+		return fmt.Errorf("sort %s is not supported", sortBy)
+	}
+	switch order {
+	case Asc, Desc:
+	default:
+		return fmt.Errorf("order %s is not supported", order)
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return compareIndex(index[i], index[j], sortBy, order, popularity) < 0
+	})
+	return nil
+}
+
+// indexPopularity returns the popularity of the stacks if the index is sorted by popularity
+func indexPopularity(sortBy Sort) map[string]uint64 {
+	if sortBy != SortPopularity {
+		return nil
+	}
+	return stackPopularity()
+}
+
+// compareIndex compares two stacks or samples by the sort key in the given order, then by name and type. The
+// popularity of the stacks is only used by the popularity sort.
+func compareIndex(a indexSchema.Schema, b indexSchema.Schema, sortBy Sort, order Order, popularity map[string]uint64) int {
+	var result int
+	switch sortBy {
+	case SortName:
+		result = strings.Compare(a.Name, b.Name)
+	case SortDisplayName:
+		result = strings.Compare(strings.ToLower(a.DisplayName), strings.ToLower(b.DisplayName))
+	case SortLastModified:
+		result = parseLastModified(a.LastModified).Compare(parseLastModified(b.LastModified))
+	case SortVersion:
+		result = compareVersions(mostRecentVersion(a), mostRecentVersion(b))
+	case SortPopularity:
+		result = cmp.Compare(popularity[a.Name], popularity[b.Name])
+	}
+	if order == Desc {
+		result = -result
+	}
+	if result == 0 {
+		result = strings.Compare(a.Name, b.Name)
+	}
+	if result == 0 {
+		result = strings.Compare(string(a.Type), string(b.Type))
+	}
+	return result
+}
+
+// mostRecentVersion returns the most recent version of a stack or sample, nil if it has no valid version
+func mostRecentVersion(devfileIndex indexSchema.Schema) *versionpkg.Version {
+	versions := []string{devfileIndex.Version}
+	for _, version := range devfileIndex.Versions {
+		versions = append(versions, version.Version)
+	}
+	var mostRecent *versionpkg.Version
+	for _, version := range versions {
+		parsed, err := versionpkg.NewVersion(version)
+		if err == nil && (mostRecent == nil || parsed.GreaterThan(mostRecent)) {
+			mostRecent = parsed
+		}
+	}
+	return mostRecent
+}
+
+// compareVersions compares two versions, stacks and samples without valid version come first
+func compareVersions(a *versionpkg.Version, b *versionpkg.Version) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	default:
+		return a.Compare(b)
+	}
+}
+
+// encodeCursor creates the cursor of the page following the stack or sample
+func encodeCursor(devfileIndex indexSchema.Schema, sortBy Sort, order Order) string {
+	content, _ := json.Marshal(indexCursor{Name: devfileIndex.Name, Sort: sortBy, Order: order})
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+// cursorStart returns the position of the page of a cursor in the sorted index. The page starts after the stack or
+// sample of the cursor, even if it no longer matches the filters of the request.
+func cursorStart(index []indexSchema.Schema, encodedCursor string, sortBy Sort, order Order, popularity map[string]uint64) (int, error) {
+	var cursor indexCursor
+	content, err := base64.RawURLEncoding.DecodeString(encodedCursor)
+	if err == nil {
+		err = json.Unmarshal(content, &cursor)
+	}
+	if err != nil || cursor.Name == "" {
+		return 0, fmt.Errorf("cursor %s is not valid", encodedCursor)
+	}
+	if cursor.Sort != sortBy || cursor.Order != order {
+		return 0, fmt.Errorf("cursor %s was created for another sort", encodedCursor)
+	}
+
+	after, found := indexSchema.Schema{}, false
+	for _, devfileIndex := range index {
+		if devfileIndex.Name == cursor.Name {
+			after, found = devfileIndex, true
+			break
+		}
+	}
+	if !found {
+		store, err := getIndexStore()
+		if err != nil {
+			return 0, err
+		}
+		if after, found = store.Snapshot().Component(cursor.Name); !found {
+			return 0, fmt.Errorf("cursor %s is no longer valid, %s was removed from the registry", encodedCursor, cursor.Name)
+		}
+	}
+	return sort.Search(len(index), func(i int) bool {
+		return compareIndex(index[i], after, sortBy, order, popularity) > 0
+	}), nil
+}
+
+// setPageLinks sets the RFC 8288 links to the first, previous, next and last pages. Pages are linked by offset if
+// the request used an offset, and by cursor otherwise.
+func setPageLinks(c *gin.Context, index []indexSchema.Schema, start int, end int, limit int, byOffset bool, sortBy Sort, order Order) {
+	link := func(rel string, set map[string]string) string {
+		query := c.Request.URL.Query()
+		query.Del("offset")
+		query.Del("cursor")
+		for name, value := range set {
+			query.Set(name, value)
+		}
+		pageUrl := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		return fmt.Sprintf("<%s>; rel=%q", pageUrl.String(), rel)
+	}
+
+	total := len(index)
+	var links []string
+	if byOffset {
+		links = append(links, link("first", map[string]string{"offset": "0"}))
+		if start > 0 {
+			links = append(links, link("prev", map[string]string{"offset": strconv.Itoa(max(start-limit, 0))}))
+		}
+		if end < total {
+			links = append(links, link("next", map[string]string{"offset": strconv.Itoa(end)}))
+		}
+		if total > 0 {
+			links = append(links, link("last", map[string]string{"offset": strconv.Itoa((total - 1) / limit * limit)}))
+		}
+	} else {
+		links = append(links, link("first", nil))
+		if end < total && end > 0 {
+			links = append(links, link("next", map[string]string{"cursor": encodeCursor(index[end-1], sortBy, order)}))
+		}
+	}
+	c.Header("Link", strings.Join(links, ", "))
+}
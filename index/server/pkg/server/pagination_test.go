@@ -0,0 +1,226 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSortIndex(t *testing.T) {
+	index := []indexSchema.Schema{
+		{Name: "nodejs", DisplayName: "NodeJS Runtime", Version: "2.1.1", LastModified: "2024-01-02T10:00:00Z"},
+		{Name: "go", DisplayName: "go", Versions: []indexSchema.Version{{Version: "1.0.2"}, {Version: "2.0.0"}}},
+		{Name: "java-maven", DisplayName: "Maven Java", Version: "1.2.0", LastModified: "2024-03-01T10:00:00Z"},
+		{Name: "python", DisplayName: "Python", Version: "2.1.1", LastModified: "2023-12-01T10:00:00Z"},
+	}
+	tests := []struct {
+		name       string
+		sortBy     Sort
+		order      Order
+		popularity map[string]uint64
+		wantNames  []string
+		wantErr    bool
+	}{
+		{
+			name:      "Case 1: Sort by name",
+			sortBy:    SortName,
+			order:     Asc,
+			wantNames: []string{"go", "java-maven", "nodejs", "python"},
+		},
+		{
+			name:      "Case 2: Sort by display name, ignoring the case",
+			sortBy:    SortDisplayName,
+			order:     Asc,
+			wantNames: []string{"go", "java-maven", "nodejs", "python"},
+		},
+		{
+			name:      "Case 3: Sort by last modified date, descending",
+			sortBy:    SortLastModified,
+			order:     Desc,
+			wantNames: []string{"java-maven", "nodejs", "python", "go"},
+		},
+		{
+			name:      "Case 4: Sort by most recent version, descending with ties sorted by name",
+			sortBy:    SortVersion,
+			order:     Desc,
+			wantNames: []string{"nodejs", "python", "go", "java-maven"},
+		},
+		{
+			name:       "Case 5: Sort by popularity, descending with ties sorted by name",
+			sortBy:     SortPopularity,
+			order:      Desc,
+			popularity: map[string]uint64{"python": 12, "go": 3, "nodejs": 12},
+			wantNames:  []string{"nodejs", "python", "go", "java-maven"},
+		},
+		{
+			name:    "Case 6: Unsupported sort",
+			sortBy:  Sort("downloads"),
+			order:   Asc,
+			wantErr: true,
+		},
+		{
+			name:    "Case 7: Unsupported order",
+			sortBy:  SortName,
+			order:   Order("random"),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sorted := append([]indexSchema.Schema(nil), index...)
+			err := sortIndex(sorted, test.sortBy, test.order, test.popularity)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Got error: %v, Expected error: %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			var gotNames []string
+			for _, devfileIndex := range sorted {
+				gotNames = append(gotNames, devfileIndex.Name)
+			}
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Errorf("Got order: %v, Expected order: %v", gotNames, test.wantNames)
+			}
+		})
+	}
+}
+
+// servePage serves a page of the '/v2index/stack' endpoint and returns the names of its stacks
+func servePage(t *testing.T, target string) ([]string, *httptest.ResponseRecorder) {
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	c.Params = gin.Params{gin.Param{Key: "indexType", Value: "stack"}}
+
+	server.ServeDevfileIndexV2WithType(c)
+
+	if w.Code != http.StatusOK {
+		return nil, w
+	}
+	var index []indexSchema.Schema
+	if err := json.Unmarshal(w.Body.Bytes(), &index); err != nil {
+		t.Fatalf("failed to unmarshal the index: %v", err)
+	}
+	names := []string{}
+	for _, devfileIndex := range index {
+		names = append(names, devfileIndex.Name)
+	}
+	return names, w
+}
+
+// TestServeDevfileIndexV2Paginated tests the pagination of the '/v2index/:indexType' endpoint
+func TestServeDevfileIndexV2Paginated(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		target    string
+		wantCode  int
+		wantNames []string
+		wantLinks string
+	}{
+		{
+			name:      "Case 1: First page by offset",
+			target:    "/v2index/stack?limit=4&offset=0",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"go", "java-maven", "java-openliberty", "java-quarkus"},
+			wantLinks: `</v2index/stack?limit=4&offset=0>; rel="first", </v2index/stack?limit=4&offset=4>; rel="next", </v2index/stack?limit=4&offset=8>; rel="last"`,
+		},
+		{
+			name:      "Case 2: Last page by offset",
+			target:    "/v2index/stack?limit=4&offset=8",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"nodejs", "python", "python-django"},
+			wantLinks: `</v2index/stack?limit=4&offset=0>; rel="first", </v2index/stack?limit=4&offset=4>; rel="prev", </v2index/stack?limit=4&offset=8>; rel="last"`,
+		},
+		{
+			name:      "Case 3: Offset past the end",
+			target:    "/v2index/stack?limit=4&offset=20",
+			wantCode:  http.StatusOK,
+			wantNames: []string{},
+		},
+		{
+			name:      "Case 4: Filtered and sorted page",
+			target:    "/v2index/stack?tags=Java&sort=name&order=desc&limit=2&offset=1",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"java-wildfly", "java-vertx"},
+		},
+		{
+			name:     "Case 5: Offset and cursor",
+			target:   "/v2index/stack?limit=2&offset=2&cursor=abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Case 6: Invalid cursor",
+			target:   "/v2index/stack?limit=2&cursor=abc",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotNames, w := servePage(t, test.target)
+			if w.Code != test.wantCode {
+				t.Fatalf("Did not get expected status code, Got: %v, Expected: %v", w.Code, test.wantCode)
+			}
+			if test.wantCode != http.StatusOK {
+				return
+			}
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Errorf("Got stacks: %v, Expected stacks: %v", gotNames, test.wantNames)
+			}
+			if test.wantLinks != "" && w.Header().Get("Link") != test.wantLinks {
+				t.Errorf("Got links: %v, Expected links: %v", w.Header().Get("Link"), test.wantLinks)
+			}
+		})
+	}
+
+	// Following the cursors of the next links lists every stack once
+	var gotNames []string
+	target := "/v2index/stack?sort=version&order=desc&limit=3"
+	nextLink := regexp.MustCompile(`<([^>]+)>; rel="next"`)
+	for target != "" {
+		names, w := servePage(t, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Did not get expected status code for %s, Got: %v, Expected: %v", target, w.Code, http.StatusOK)
+		}
+		if gotTotal := w.Header().Get(totalCountHeader); gotTotal != "11" {
+			t.Errorf("Got total count: %v, Expected total count: 11", gotTotal)
+		}
+		gotNames = append(gotNames, names...)
+		target = ""
+		if match := nextLink.FindStringSubmatch(w.Header().Get("Link")); match != nil {
+			target = match[1]
+		}
+	}
+	allNames, _ := servePage(t, "/v2index/stack?sort=version&order=desc")
+	if !reflect.DeepEqual(gotNames, allNames) {
+		t.Errorf("Got stacks: %v, Expected stacks: %v", gotNames, allNames)
+	}
+}
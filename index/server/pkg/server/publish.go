@@ -0,0 +1,810 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	indexLibrary "github.com/devfile/registry-support/index/generator/library"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gin-gonic/gin"
+	versionpkg "github.com/hashicorp/go-version"
+	"github.com/mohae/deepcopy"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// ociLayoutMediaType is the media type of a stack version uploaded as an OCI image layout tarball
+	ociLayoutMediaType = "application/vnd.oci.image.layout.v1+tar"
+	// maxPublishSize is the maximum size of an uploaded stack version
+	maxPublishSize = 256 << 20
+	stackYamlName  = "stack.yaml"
+)
+
+var (
+	// publishMutex serializes the updates of the stacks folder and of the index
+	publishMutex sync.Mutex
+
+	// pushStack pushes a published stack version to the stack storage
+	pushStack = func(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version) error {
+		return stackStorage.Push(devfileIndex, versionComponent)
+	}
+
+	// deleteStack deletes a removed stack version from the stack storage
+	deleteStack = func(stackName string, versionComponent indexSchema.Version) error {
+		return stackStorage.Delete(stackName, versionComponent)
+	}
+
+	// newPublishGenerator creates the generator validating the published stacks
+	newPublishGenerator = func() *indexLibrary.Generator {
+		return indexLibrary.NewGenerator(indexLibrary.WithLogger(log.Default()))
+	}
+
+	stackNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+func init() {
+	// Let the OpenAPI validator accept OCI image layout uploads, the content is validated by the publish handler
+	openapi3filter.RegisterBodyDecoder(ociLayoutMediaType, openapi3filter.FileBodyDecoder)
+}
+
+// publishError is an error of the publish API answered with the given status code
+type publishError struct {
+	code int
+	err  error
+}
+
+func (e *publishError) Error() string {
+	return e.err.Error()
+}
+
+func newPublishError(code int, format string, a ...any) error {
+	return &publishError{code: code, err: fmt.Errorf(format, a...)}
+}
+
+// setPublishErrorResponse answers with the status code of a publish error, other errors are internal errors
+func setPublishErrorResponse(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	var pubErr *publishError
+	if errors.As(err, &pubErr) {
+		code = pubErr.code
+	}
+	if code == http.StatusNotFound {
+		c.JSON(code, gin.H{
+			"status": err.Error(),
+		})
+		return
+	}
+	log.Print(err.Error())
+	c.JSON(code, gin.H{
+		"status": "failed to publish the stack",
+		"error":  err.Error(),
+	})
+}
+
+// authorizePublish checks that the identity of a publish request, as authenticated by the authenticate middleware,
+// has the write permission on the stack. Stacks which are not part of the index are matched by name. The publish
+// API answers as if write requests were not supported when no access rule grants the write permission.
+func authorizePublish(c *gin.Context, name string) bool {
+	if accessRules == nil || !accessRules.grantsWrite() {
+		SetMethodNotAllowedJSONResponse(c)
+		return false
+	}
+	devfileIndex := indexSchema.Schema{Name: name}
+	if store, err := getIndexStore(); err == nil {
+		if existing, found := store.Snapshot().Component(name); found {
+			devfileIndex = existing
+		}
+	}
+	identity := getIdentity(c)
+	if accessRules.AllowedWrite(identity, devfileIndex) {
+		return true
+	}
+	if identity.Anonymous {
+		setUnauthorizedResponse(c)
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"status": fmt.Sprintf("%s is not allowed to publish %s", identity.Name, name),
+	})
+	return false
+}
+
+// publishDevfileWithVersion uploads a stack version, replacing the version if it already exists
+func publishDevfileWithVersion(c *gin.Context, name string, version string) {
+	if !authorizePublish(c, name) {
+		return
+	}
+	if err := validateStackVersionName(name, version); err != nil {
+		setPublishErrorResponse(c, err)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxPublishSize)
+	files, err := readPublishedFiles(c.Request)
+	if err != nil {
+		setPublishErrorResponse(c, newPublishError(http.StatusBadRequest, "failed to read the uploaded stack %s version %s: %v", name, version, err))
+		return
+	}
+
+	indexComponent, err := publishStackVersion(name, version, files)
+	if err != nil {
+		setPublishErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, indexComponent)
+}
+
+// deleteDevfileWithVersion deletes a stack version, the stack is removed with its last version
+func deleteDevfileWithVersion(c *gin.Context, name string, version string) {
+	if !authorizePublish(c, name) {
+		return
+	}
+
+	_, err := updateStack(name, "", func(stackDirPath string, stackInfo *indexSchema.StackInfo) error {
+		i := findStackVersion(stackInfo.Versions, version)
+		if i == -1 {
+			return newPublishError(http.StatusNotFound, "the devfile of %s didn't find version %s", name, version)
+		}
+		if stackInfo.Versions[i].Default && len(stackInfo.Versions) > 1 {
+			return newPublishError(http.StatusConflict, "version %s is the default version of %s, change the default version before deleting it", version, name)
+		}
+		stackInfo.Versions = append(stackInfo.Versions[:i], stackInfo.Versions[i+1:]...)
+		return os.RemoveAll(filepath.Join(stackDirPath, version))
+	})
+	if err != nil {
+		setPublishErrorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// updateDevfile changes the default version of a stack
+func updateDevfile(c *gin.Context, name string) {
+	if !authorizePublish(c, name) {
+		return
+	}
+
+	var update StackUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		setPublishErrorResponse(c, newPublishError(http.StatusBadRequest, "failed to read the stack update: %v", err))
+		return
+	}
+
+	indexComponent, err := updateStack(name, "", func(stackDirPath string, stackInfo *indexSchema.StackInfo) error {
+		if findStackVersion(stackInfo.Versions, update.DefaultVersion) == -1 {
+			return newPublishError(http.StatusNotFound, "the devfile of %s didn't find version %s", name, update.DefaultVersion)
+		}
+		for i := range stackInfo.Versions {
+			stackInfo.Versions[i].Default = stackInfo.Versions[i].Version == update.DefaultVersion
+		}
+		return nil
+	})
+	if err != nil {
+		setPublishErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, indexComponent)
+}
+
+// validateStackVersionName checks that the stack name is a valid devfile name and the version a semantic version,
+// so that neither can escape the stacks folder
+func validateStackVersionName(name string, version string) error {
+	if !stackNameRegexp.MatchString(name) {
+		return newPublishError(http.StatusBadRequest, "%s is not a valid stack name", name)
+	}
+	if _, err := versionpkg.NewSemver(version); err != nil || strings.ContainsAny(version, `/\`) {
+		return newPublishError(http.StatusBadRequest, "%s is not a valid stack version", version)
+	}
+	return nil
+}
+
+// readPublishedFiles reads the resources of an uploaded stack version by file name, either from the file parts of a
+// multipart form or from the layers of an OCI image layout tarball
+func readPublishedFiles(req *http.Request) (map[string][]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	addFile := func(name string, content []byte) error {
+		if name == "" || name != path.Base(name) || name == "." || name == ".." || strings.Contains(name, `\`) {
+			return fmt.Errorf("%q is not a valid resource name", name)
+		}
+		if _, found := files[name]; found {
+			return fmt.Errorf("resource %s is uploaded more than once", name)
+		}
+		files[name] = content
+		return nil
+	}
+
+	switch mediaType {
+	case "multipart/form-data":
+		if err := req.ParseMultipartForm(32 << 20); err != nil {
+			return nil, err
+		}
+		for _, fileHeader := range req.MultipartForm.File["file"] {
+			file, err := fileHeader.Open()
+			if err != nil {
+				return nil, err
+			}
+			content, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return nil, err
+			}
+			if err := addFile(fileHeader.Filename, content); err != nil {
+				return nil, err
+			}
+		}
+	case ociLayoutMediaType:
+		layers, err := readOCILayout(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		for _, layer := range layers {
+			if err := addFile(layer.name, layer.content); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("media type %s is not supported", mediaType)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no resources were uploaded")
+	}
+	return files, nil
+}
+
+type ociLayer struct {
+	name    string
+	content []byte
+}
+
+// readOCILayout reads the layers of the single image of an OCI image layout tarball, such as the ones created by
+// oras push, the layers are named by their title annotation
+func readOCILayout(r io.Reader) ([]ociLayer, error) {
+	blobs := make(map[string][]byte)
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		blobs[path.Clean(strings.TrimPrefix(header.Name, "./"))] = content
+	}
+
+	readBlob := func(desc ocispec.Descriptor) ([]byte, error) {
+		if err := desc.Digest.Validate(); err != nil {
+			return nil, err
+		}
+		content, found := blobs[path.Join(ocispec.ImageBlobsDir, desc.Digest.Algorithm().String(), desc.Digest.Encoded())]
+		if !found {
+			return nil, fmt.Errorf("blob %s is missing", desc.Digest)
+		}
+		if desc.Digest.Algorithm().FromBytes(content) != desc.Digest {
+			return nil, fmt.Errorf("blob %s does not match its digest", desc.Digest)
+		}
+		return content, nil
+	}
+
+	indexContent, found := blobs[ocispec.ImageIndexFile]
+	if !found {
+		return nil, fmt.Errorf("%s is missing from the OCI image layout", ocispec.ImageIndexFile)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexContent, &index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %v", ocispec.ImageIndexFile, err)
+	}
+	if len(index.Manifests) != 1 {
+		return nil, fmt.Errorf("the OCI image layout must contain a single image, found %d", len(index.Manifests))
+	}
+
+	manifestContent, err := readBlob(index.Manifests[0])
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestContent, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the image manifest: %v", err)
+	}
+
+	var layers []ociLayer
+	for _, layer := range manifest.Layers {
+		name, found := layer.Annotations[ocispec.AnnotationTitle]
+		if !found {
+			return nil, fmt.Errorf("layer %s has no %s annotation", layer.Digest, ocispec.AnnotationTitle)
+		}
+		content, err := readBlob(layer)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, ociLayer{name: name, content: content})
+	}
+	return layers, nil
+}
+
+// publishStackVersion writes the resources of a stack version to the stacks folder and updates the stack.yaml.
+// An uploaded stack.yaml updates the display name, description and icon of the stack.
+func publishStackVersion(name string, version string, files map[string][]byte) (*indexSchema.Schema, error) {
+	var uploadedInfo indexSchema.StackInfo
+	if content, found := files[stackYamlName]; found {
+		if err := yaml.Unmarshal(content, &uploadedInfo); err != nil {
+			return nil, newPublishError(http.StatusBadRequest, "failed to unmarshal %s: %v", stackYamlName, err)
+		}
+		if uploadedInfo.Name != "" && uploadedInfo.Name != name {
+			return nil, newPublishError(http.StatusBadRequest, "%s is for stack %s, not %s", stackYamlName, uploadedInfo.Name, name)
+		}
+		delete(files, stackYamlName)
+	}
+
+	var devfile indexSchema.Devfile
+	devfileFound := false
+	for fileName, content := range files {
+		if indexLibrary.IsDevfileName(fileName) {
+			if devfileFound {
+				return nil, newPublishError(http.StatusBadRequest, "stack %s version %s has more than one devfile", name, version)
+			}
+			if err := yaml.Unmarshal(content, &devfile); err != nil {
+				return nil, newPublishError(http.StatusBadRequest, "failed to unmarshal %s: %v", fileName, err)
+			}
+			devfileFound = true
+		}
+	}
+	if !devfileFound {
+		return nil, newPublishError(http.StatusBadRequest, "stack %s version %s has no devfile", name, version)
+	}
+	if devfile.Meta.Version != version {
+		return nil, newPublishError(http.StatusBadRequest, "the devfile version %s does not match version %s", devfile.Meta.Version, version)
+	}
+
+	return updateStack(name, version, func(stackDirPath string, stackInfo *indexSchema.StackInfo) error {
+		versionDirPath := filepath.Join(stackDirPath, version)
+		if err := os.RemoveAll(versionDirPath); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(versionDirPath, 0750); err != nil {
+			return err
+		}
+		for fileName, content := range files {
+			if err := os.WriteFile(filepath.Join(versionDirPath, fileName), content, 0600); err != nil {
+				return err
+			}
+		}
+
+		// The stack information defaults to the devfile metadata for new stacks
+		for _, field := range []struct {
+			value    *string
+			uploaded string
+			devfile  string
+		}{
+			{&stackInfo.DisplayName, uploadedInfo.DisplayName, devfile.Meta.DisplayName},
+			{&stackInfo.Description, uploadedInfo.Description, devfile.Meta.Description},
+			{&stackInfo.Icon, uploadedInfo.Icon, devfile.Meta.Icon},
+		} {
+			if field.uploaded != "" {
+				*field.value = field.uploaded
+			} else if *field.value == "" {
+				*field.value = field.devfile
+			}
+		}
+
+		if findStackVersion(stackInfo.Versions, version) == -1 {
+			stackInfo.Versions = append(stackInfo.Versions, indexSchema.Version{Version: version, Default: len(stackInfo.Versions) == 0})
+		}
+		return nil
+	})
+}
+
+// updateStack applies an update to the folder and stack.yaml of a stack, validates the updated stack with the
+// index generator, pushes the published version to the OCI registry and swaps the updated stack into the served
+// index. The stack folder, the index and the pushed version are restored if any step fails. Single version stacks are converted to the stack.yaml
+// layout before being updated. nil is returned if the update removed the last version of the stack.
+func updateStack(name string, publishedVersion string, update func(stackDirPath string, stackInfo *indexSchema.StackInfo) error) (*indexSchema.Schema, error) {
+	publishMutex.Lock()
+	defer publishMutex.Unlock()
+
+	store, err := getIndexStore()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := store.Snapshot()
+	existing, found := snapshot.Component(name)
+	if found && existing.Type != indexSchema.StackDevfileType {
+		return nil, newPublishError(http.StatusConflict, "%s is a %s, only stacks can be published", name, existing.Type)
+	}
+	if !found && publishedVersion == "" {
+		return nil, newPublishError(http.StatusNotFound, "the devfile of %s didn't exist", name)
+	}
+	if err := checkIndexSignable(); err != nil {
+		return nil, err
+	}
+
+	// Back up the stack folder so that it can be restored if the update fails
+	stackDirPath := filepath.Join(stacksPath, name)
+	backupDirPath, err := os.MkdirTemp("", "publish-"+name+"-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(backupDirPath)
+	backupStackDirPath := filepath.Join(backupDirPath, name)
+	_, err = os.Stat(stackDirPath)
+	stackExists := err == nil
+	if stackExists {
+		if err := copyDir(stackDirPath, backupStackDirPath); err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %v", stackDirPath, err)
+		}
+	}
+	restore := func() {
+		if err := os.RemoveAll(stackDirPath); err != nil {
+			log.Printf("failed to restore %s: %v", stackDirPath, err)
+			return
+		}
+		if stackExists {
+			if err := copyDir(backupStackDirPath, stackDirPath); err != nil {
+				log.Printf("failed to restore %s: %v", stackDirPath, err)
+			}
+		}
+	}
+
+	indexComponent, err := applyStackUpdate(name, stackDirPath, existing, found, publishedVersion, update)
+	if err != nil {
+		restore()
+		return nil, err
+	}
+
+	// Replace the stack in a copy of the served index, the index entries are shared with the snapshot
+	current, _ := snapshot.Index(allIndexType)
+	index := deepcopy.Copy(current).([]indexSchema.Schema)
+	replaced := false
+	for i := range index {
+		if index[i].Name == name {
+			if indexComponent == nil {
+				index = append(index[:i], index[i+1:]...)
+			} else {
+				index[i] = *indexComponent
+			}
+			replaced = true
+			break
+		}
+	}
+	if !replaced && indexComponent != nil {
+		index = append(index, *indexComponent)
+	}
+	if err := checkDependencies(current, index); err != nil {
+		restore()
+		return nil, newPublishError(http.StatusBadRequest, "stack %s breaks the stack dependencies: %v", name, err)
+	}
+	index, _ = indexLibrary.ResolveDependencies(index, true)
+
+	var pushedVersion *indexSchema.Version
+	if indexComponent != nil {
+		for i := range index {
+			if index[i].Name == name {
+				indexComponent = &index[i]
+				break
+			}
+		}
+		if publishedVersion != "" {
+			versionComponent := indexComponent.Versions[findStackVersion(indexComponent.Versions, publishedVersion)]
+			if err := pushStack(*indexComponent, versionComponent); err != nil {
+				restore()
+				return nil, err
+			}
+			markAvailable(name, publishedVersion)
+			pushedVersion = &versionComponent
+		}
+	}
+
+	// rollback restores the stack folder and reverts the push of the published version
+	rollback := func() {
+		restore()
+		if pushedVersion != nil {
+			rollbackPush(name, existing, *pushedVersion)
+		}
+	}
+	restoreIndex, err := writeIndex(index)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	if err := store.Reload(); err != nil {
+		restoreIndex()
+		rollback()
+		return nil, fmt.Errorf("failed to reload the published index: %v", err)
+	}
+
+	// The versions removed from the index are deleted from the storage once the index no longer serves them
+	for _, versionComponent := range existing.Versions {
+		if indexComponent == nil || findStackVersion(indexComponent.Versions, versionComponent.Version) == -1 {
+			if err := deleteStack(name, versionComponent); err != nil {
+				log.Printf("failed to delete %s version %s from the stack storage: %v", name, versionComponent.Version, err)
+			}
+		}
+	}
+	return indexComponent, nil
+}
+
+// rollbackPush reverts the push of a published stack version once the stack folder is restored. A version which
+// replaced an existing version is pushed again from the existing index entry, a new version is deleted.
+func rollbackPush(name string, existing indexSchema.Schema, pushedVersion indexSchema.Version) {
+	var err error
+	if i := findStackVersion(existing.Versions, pushedVersion.Version); i != -1 {
+		err = pushStack(existing, existing.Versions[i])
+	} else {
+		err = deleteStack(name, pushedVersion)
+	}
+	if err != nil {
+		log.Printf("failed to roll back the push of %s version %s: %v", name, pushedVersion.Version, err)
+	}
+}
+
+// checkDependencies checks that an update of the index does not add dangling parents or cycles to the stack
+// dependencies. The errors already found in the served index are left out so that an existing dangling parent
+// of another stack does not reject the update.
+func checkDependencies(current []indexSchema.Schema, updated []indexSchema.Schema) error {
+	existing := make(map[string]bool)
+	for _, err := range dependencyErrors(current) {
+		existing[err.Error()] = true
+	}
+	var errs []error
+	for _, err := range dependencyErrors(updated) {
+		if !existing[err.Error()] {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("stack dependency graph is not valid: %v", errs)
+	}
+	return nil
+}
+
+// dependencyErrors returns the dangling parents and cycles of the stack dependencies of the index
+func dependencyErrors(index []indexSchema.Schema) []error {
+	graph, errs := indexLibrary.BuildDependencyGraph(index)
+	return append(errs, graph.Cycles()...)
+}
+
+// applyStackUpdate applies an update to the stack folder and regenerates the index component of the stack
+func applyStackUpdate(name string, stackDirPath string, existing indexSchema.Schema, found bool, publishedVersion string,
+	update func(stackDirPath string, stackInfo *indexSchema.StackInfo) error) (*indexSchema.Schema, error) {
+	stackInfo, err := loadStackInfo(name, stackDirPath, existing, found)
+	if err != nil {
+		return nil, err
+	}
+	if err := update(stackDirPath, &stackInfo); err != nil {
+		return nil, err
+	}
+
+	if len(stackInfo.Versions) == 0 {
+		return nil, os.RemoveAll(stackDirPath)
+	}
+	stackYamlBytes, err := yaml.Marshal(stackInfo)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(stackDirPath, stackYamlName), stackYamlBytes, 0600); err != nil {
+		return nil, err
+	}
+
+	indexComponent, err := newPublishGenerator().GenerateStack(stacksPath, name)
+	if err != nil {
+		return nil, newPublishError(http.StatusBadRequest, "stack %s is not valid: %v", name, err)
+	}
+
+	// Keep the last modified dates of the versions that were not published
+	now := time.Now().UTC().Format(time.RFC3339)
+	for i, versionComponent := range indexComponent.Versions {
+		if versionComponent.Version == publishedVersion {
+			indexComponent.Versions[i].LastModified = now
+		} else if j := findStackVersion(existing.Versions, versionComponent.Version); j != -1 {
+			indexComponent.Versions[i].LastModified = existing.Versions[j].LastModified
+		}
+	}
+	indexComponent.LastModified = existing.LastModified
+	if publishedVersion != "" {
+		indexComponent.LastModified = now
+	}
+	return &indexComponent, nil
+}
+
+// loadStackInfo reads the stack.yaml of a stack, a single version stack is moved to a version folder and
+// described by a new stack.yaml
+func loadStackInfo(name string, stackDirPath string, existing indexSchema.Schema, found bool) (indexSchema.StackInfo, error) {
+	stackInfo := indexSchema.StackInfo{Name: name}
+	/* #nosec G304 -- stackDirPath is constructed from a validated stack name */
+	bytes, err := os.ReadFile(filepath.Join(stackDirPath, stackYamlName))
+	if err == nil {
+		err = yaml.Unmarshal(bytes, &stackInfo)
+		return stackInfo, err
+	}
+	if !os.IsNotExist(err) {
+		return stackInfo, err
+	}
+
+	entries, err := os.ReadDir(stackDirPath)
+	if os.IsNotExist(err) || (err == nil && len(entries) == 0) {
+		return stackInfo, os.MkdirAll(stackDirPath, 0750)
+	}
+	if err != nil {
+		return stackInfo, err
+	}
+	if !found || len(existing.Versions) == 0 {
+		return stackInfo, fmt.Errorf("stack %s folder is not part of the index", name)
+	}
+
+	version := existing.Versions[0].Version
+	versionDirPath := filepath.Join(stackDirPath, version)
+	if err := os.MkdirAll(versionDirPath, 0750); err != nil {
+		return stackInfo, err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(stackDirPath, entry.Name()), filepath.Join(versionDirPath, entry.Name())); err != nil {
+			return stackInfo, err
+		}
+	}
+	stackInfo.DisplayName = existing.DisplayName
+	stackInfo.Description = existing.Description
+	stackInfo.Icon = existing.Icon
+	stackInfo.Versions = []indexSchema.Version{{Version: version, Default: true}}
+	return stackInfo, nil
+}
+
+// checkIndexSignable refuses to publish to a signed registry without a signing key, clients verifying the index
+// signature would reject the entries of the updated index
+func checkIndexSignable() error {
+	if signingKeyPath != "" {
+		return nil
+	}
+	if _, err := os.Stat(indexSignaturePath); err == nil {
+		return newPublishError(http.StatusConflict, "the index is signed and the registry has no signing key to sign the updated index, set REGISTRY_INDEX_SIGNING_KEY to publish")
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// writeIndex replaces the index file and its signature with renames so that readers never see a partially written
+// index, the index is signed if the registry has a signing key. The returned function restores the previous index
+// and signature.
+func writeIndex(index []indexSchema.Schema) (func(), error) {
+	/* #nosec G304 -- indexPath is set by the registry administrator */
+	previousIndex, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to back up %s: %v", indexPath, err)
+	}
+	/* #nosec G304 -- indexSignaturePath is set by the registry administrator */
+	previousSignature, err := os.ReadFile(indexSignaturePath)
+	signed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to back up %s: %v", indexSignaturePath, err)
+	}
+	restoreIndex := func() {
+		/* #nosec G306 -- index file does not contain any sensitive data*/
+		if err := os.WriteFile(indexPath, previousIndex, 0644); err != nil {
+			log.Printf("failed to restore %s: %v", indexPath, err)
+		}
+		var err error
+		if signed {
+			/* #nosec G306 -- signature file does not contain any sensitive data*/
+			err = os.WriteFile(indexSignaturePath, previousSignature, 0644)
+		} else {
+			err = os.Remove(indexSignaturePath)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("failed to restore %s: %v", indexSignaturePath, err)
+		}
+	}
+
+	// The temporary files are only left once a step failed
+	tmpIndexPath := indexPath + ".tmp"
+	tmpSignaturePath := tmpIndexPath + indexLibrary.SignatureFileSuffix
+	defer os.Remove(tmpIndexPath)
+	defer os.Remove(tmpSignaturePath)
+
+	if err := indexLibrary.CreateIndexFile(index, tmpIndexPath); err != nil {
+		return nil, err
+	}
+	if signingKeyPath != "" {
+		if err := indexLibrary.SignIndexFile(index, filepath.Dir(stacksPath), tmpIndexPath, signingKeyPath); err != nil {
+			return nil, fmt.Errorf("failed to sign the published index: %v", err)
+		}
+	}
+	if err := os.Rename(tmpIndexPath, indexPath); err != nil {
+		return nil, fmt.Errorf("failed to replace %s: %v", indexPath, err)
+	}
+	if signingKeyPath != "" {
+		if err := os.Rename(tmpSignaturePath, indexSignaturePath); err != nil {
+			restoreIndex()
+			return nil, fmt.Errorf("failed to replace %s: %v", indexSignaturePath, err)
+		}
+	}
+	return restoreIndex, nil
+}
+
+func findStackVersion(versions []indexSchema.Version, version string) int {
+	for i := range versions {
+		if versions[i].Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyDir copies the files, folders and symbolic links under src to dst
+func copyDir(src string, dst string) error {
+	return filepath.WalkDir(src, func(srcPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(src, srcPath)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dst, relPath)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(dstPath, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(srcPath)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dstPath)
+		default:
+			/* #nosec G304 -- srcPath is under the stacks folder */
+			content, err := os.ReadFile(srcPath)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(dstPath, content, info.Mode().Perm())
+		}
+	})
+}
@@ -0,0 +1,772 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	indexLibrary "github.com/devfile/registry-support/index/generator/library"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testPublishToken = "publish-token"
+	testReadToken    = "read-token"
+
+	publishTestDevfile = `schemaVersion: 2.2.0
+metadata:
+  name: %s
+  version: %s
+  displayName: %s
+  description: Stack published through the publish API
+  icon: https://example.com/icon.svg
+  language: %s
+  projectType: %s
+  provider: Red Hat
+  supportUrl: https://github.com/devfile/api/issues
+  architectures:
+    - amd64
+`
+)
+
+// setupPublishRegistry creates a registry with the single version go stack and points the server to it,
+// the pushed and deleted stack versions are recorded instead of being pushed to an OCI registry
+func setupPublishRegistry(t *testing.T, pushErr error) (registryPath string, pushed *[]string, deleted *[]string) {
+	registryPath = t.TempDir()
+	goDirPath := filepath.Join(registryPath, "stacks", "go")
+	if err := os.MkdirAll(goDirPath, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(goDirPath, devfileName), []byte(fmt.Sprintf(publishTestDevfile, "go", "1.0.2", "Go Runtime", "Go", "Go")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	generator := func() *indexLibrary.Generator {
+		return indexLibrary.NewGenerator(
+			indexLibrary.WithIconResolver(func(string) bool { return true }),
+			indexLibrary.WithLogger(&testLogger{t: t}),
+		)
+	}
+	indexComponent, err := generator().GenerateStack(filepath.Join(registryPath, "stacks"), "go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := indexSchema.Schema{Name: "nodejs-basic", Type: indexSchema.SampleDevfileType}
+	if err := indexLibrary.CreateIndexFile([]indexSchema.Schema{indexComponent, sample}, filepath.Join(registryPath, "index.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	originalStacksPath, originalIndexPath, originalSignaturePath := stacksPath, indexPath, indexSignaturePath
+	originalSigningKey, originalStore := signingKeyPath, indexStore
+	originalPush, originalDelete, originalGenerator := pushStack, deleteStack, newPublishGenerator
+	t.Cleanup(func() {
+		stacksPath, indexPath, indexSignaturePath = originalStacksPath, originalIndexPath, originalSignaturePath
+		signingKeyPath, indexStore = originalSigningKey, originalStore
+		pushStack, deleteStack, newPublishGenerator = originalPush, originalDelete, originalGenerator
+	})
+
+	stacksPath = filepath.Join(registryPath, "stacks")
+	indexPath = filepath.Join(registryPath, "index.json")
+	indexSignaturePath = filepath.Join(registryPath, "index.json.sig")
+	signingKeyPath = ""
+	// ci can publish every stack, reader can only read them
+	setupAuthenticators(t, []Authenticator{&TokenAuthenticator{tokens: []apiToken{
+		{Token: testPublishToken, Name: "ci"},
+		{Token: testReadToken, Name: "reader"},
+	}}}, &AccessRules{Rules: []AccessRule{
+		{Identities: []string{everyoneIdentity}, Names: []string{"*"}},
+		{Identities: []string{"ci"}, Names: []string{"*"}, Permissions: []string{writePermission}},
+	}}, false)
+	indexStore, err = NewIndexStore(indexPath, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushed = &[]string{}
+	pushStack = func(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version) error {
+		if pushErr != nil {
+			return pushErr
+		}
+		*pushed = append(*pushed, versionComponent.Links["self"])
+		return nil
+	}
+	deleted = &[]string{}
+	deleteStack = func(stackName string, versionComponent indexSchema.Version) error {
+		*deleted = append(*deleted, versionComponent.Links["self"])
+		return nil
+	}
+	newPublishGenerator = generator
+	return registryPath, pushed, deleted
+}
+
+// servePublishRequest authenticates the request as the registry router does before calling the publish handler
+func servePublishRequest(c *gin.Context, handler func(c *gin.Context)) {
+	authenticate(c)
+	if !c.IsAborted() {
+		handler(c)
+	}
+}
+
+type testLogger struct {
+	t *testing.T
+}
+
+func (l *testLogger) Printf(format string, v ...any) {
+	l.t.Logf(format, v...)
+}
+
+// newMultipartRequest creates an upload request with the given files as multipart file parts
+func newMultipartRequest(t *testing.T, target string, files map[string]string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, content := range files {
+		part, err := writer.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPut, target, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// newOCILayoutRequest creates an upload request with the given files as the layers of an OCI image layout tarball
+func newOCILayoutRequest(t *testing.T, target string, files map[string]string) *http.Request {
+	body := &bytes.Buffer{}
+	tarWriter := tar.NewWriter(body)
+	writeFile := func(name string, content []byte) {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeBlob := func(mediaType string, content []byte, annotations map[string]string) ocispec.Descriptor {
+		desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(content), Size: int64(len(content)), Annotations: annotations}
+		writeFile(filepath.Join(ocispec.ImageBlobsDir, desc.Digest.Algorithm().String(), desc.Digest.Encoded()), content)
+		return desc
+	}
+	marshal := func(v any) []byte {
+		content, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+
+	manifest := ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    writeBlob(devfileConfigMediaType, []byte("{}"), nil),
+	}
+	for name, content := range files {
+		manifest.Layers = append(manifest.Layers, writeBlob(devfileMediaType, []byte(content), map[string]string{ocispec.AnnotationTitle: name}))
+	}
+	manifestDesc := writeBlob(ocispec.MediaTypeImageManifest, marshal(manifest), nil)
+	writeFile(ocispec.ImageLayoutFile, marshal(ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion}))
+	writeFile(ocispec.ImageIndexFile, marshal(ocispec.Index{Versioned: specs.Versioned{SchemaVersion: 2}, Manifests: []ocispec.Descriptor{manifestDesc}}))
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, target, body)
+	req.Header.Set("Content-Type", ociLayoutMediaType)
+	return req
+}
+
+// servedVersions returns the versions of a stack in the served index, the default version is marked with a star
+func servedVersions(t *testing.T, name string) []string {
+	store, err := getIndexStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []string{}
+	indexComponent, found := store.Snapshot().Component(name)
+	if !found {
+		return versions
+	}
+	for _, versionComponent := range indexComponent.Versions {
+		if versionComponent.Default {
+			versions = append(versions, versionComponent.Version+"*")
+		} else {
+			versions = append(versions, versionComponent.Version)
+		}
+	}
+	return versions
+}
+
+// TestPublishDevfileWithVersion tests uploading stack versions to '/devfiles/{stack}/{version}'
+func TestPublishDevfileWithVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registryPath, pushed, _ := setupPublishRegistry(t, nil)
+	goDevfile := func(version string) string {
+		return fmt.Sprintf(publishTestDevfile, "go", version, "Go Runtime", "Go", "Go")
+	}
+
+	tests := []struct {
+		name         string
+		stack        string
+		version      string
+		token        string
+		request      func(target string) *http.Request
+		wantCode     int
+		wantVersions []string
+		wantPushed   []string
+	}{
+		{
+			name:    "Case 1: missing token",
+			stack:   "go",
+			version: "2.0.0",
+			request: func(target string) *http.Request {
+				return newMultipartRequest(t, target, map[string]string{devfileName: goDevfile("2.0.0")})
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:    "Case 2: invalid token",
+			stack:   "go",
+			version: "2.0.0",
+			token:   "invalid",
+			request: func(target string) *http.Request {
+				return newMultipartRequest(t, target, map[string]string{devfileName: goDevfile("2.0.0")})
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:    "Case 3: token without the write permission",
+			stack:   "go",
+			version: "2.0.0",
+			token:   testReadToken,
+			request: func(target string) *http.Request {
+				return newMultipartRequest(t, target, map[string]string{devfileName: goDevfile("2.0.0")})
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:    "Case 4: multipart upload converts the single version stack",
+			stack:   "go",
+			version: "2.0.0",
+			token:   testPublishToken,
+			request: func(target string) *http.Request {
+				return newMultipartRequest(t, target, map[string]string{devfileName: goDevfile("2.0.0"), archiveName: "archive"})
+			},
+			wantCode:     http.StatusOK,
+			wantVersions: []string{"2.0.0", "1.0.2*"},
+			wantPushed:   []string{"devfile-catalog/go:2.0.0"},
+		},
+		{
+			name:    "Case 5: OCI image layout upload of a new stack",
+			stack:   "python",
+			version: "1.0.0",
+			token:   testPublishToken,
+			request: func(target string) *http.Request {
+				return newOCILayoutRequest(t, target, map[string]string{devfileName: fmt.Sprintf(publishTestDevfile, "python", "1.0.0", "Python", "Python", "Python")})
+			},
+			wantCode:     http.StatusOK,
+			wantVersions: []string{"1.0.0*"},
+			wantPushed:   []string{"devfile-catalog/go:2.0.0", "devfile-catalog/python:1.0.0"},
+		},
+		{
+			name:    "Case 6: devfile version does not match the uploaded version",
+			stack:   "go",
+			version: "3.0.0",
+			token:   testPublishToken,
+			request: func(target string) *http.Request {
+				return newMultipartRequest(t, target, map[string]string{devfileName: goDevfile("2.0.0")})
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:    "Case 7: invalid devfile restores the stack",
+			stack:   "go",
+			version: "3.0.0",
+			token:   testPublishToken,
+			request: func(target string) *http.Request {
+				return newMultipartRequest(t, target, map[string]string{devfileName: fmt.Sprintf(publishTestDevfile, "go", "3.0.0", "", "Go", "Go")})
+			},
+			wantCode:     http.StatusBadRequest,
+			wantVersions: []string{"2.0.0", "1.0.2*"},
+		},
+		{
+			name:    "Case 8: resource names cannot escape the stack folder",
+			stack:   "go",
+			version: "3.0.0",
+			token:   testPublishToken,
+			request: func(target string) *http.Request {
+				return newOCILayoutRequest(t, target, map[string]string{devfileName: goDevfile("3.0.0"), "../escape.yaml": ""})
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:    "Case 9: stack names cannot escape the stacks folder",
+			stack:   "..",
+			version: "3.0.0",
+			token:   testPublishToken,
+			request: func(target string) *http.Request {
+				return newMultipartRequest(t, target, map[string]string{devfileName: goDevfile("3.0.0")})
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = test.request(fmt.Sprintf("/devfiles/%s/%s", test.stack, test.version))
+			if test.token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+test.token)
+			}
+
+			server := &Server{}
+			servePublishRequest(c, func(c *gin.Context) { server.PutDevfileWithVersion(c, test.stack, test.version) })
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Did not get expected status code, Got: %v, Expected: %v, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if test.wantVersions != nil {
+				if got := servedVersions(t, test.stack); !reflect.DeepEqual(got, test.wantVersions) {
+					t.Errorf("Did not get expected versions, Got: %v, Expected: %v", got, test.wantVersions)
+				}
+			}
+			if test.wantPushed != nil && !reflect.DeepEqual(*pushed, test.wantPushed) {
+				t.Errorf("Did not get expected pushed versions, Got: %v, Expected: %v", *pushed, test.wantPushed)
+			}
+		})
+	}
+
+	for _, path := range []string{"go/stack.yaml", "go/1.0.2/devfile.yaml", "go/2.0.0/archive.tar", "python/1.0.0/devfile.yaml"} {
+		if _, err := os.Stat(filepath.Join(registryPath, "stacks", path)); err != nil {
+			t.Errorf("Expected %s to be published: %v", path, err)
+		}
+	}
+	for _, path := range []string{"stacks/go/3.0.0", "stacks/escape.yaml", "index.json.sig", "index.json.tmp"} {
+		if _, err := os.Stat(filepath.Join(registryPath, path)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to not exist: %v", path, err)
+		}
+	}
+}
+
+// TestPublishDevfileWithVersionPushFailure tests that a failed push leaves the stack and the index unchanged
+func TestPublishDevfileWithVersionPushFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registryPath, _, deleted := setupPublishRegistry(t, fmt.Errorf("registry is not available"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newMultipartRequest(t, "/devfiles/go/2.0.0", map[string]string{devfileName: fmt.Sprintf(publishTestDevfile, "go", "2.0.0", "Go Runtime", "Go", "Go")})
+	c.Request.Header.Set("Authorization", "Bearer "+testPublishToken)
+
+	server := &Server{}
+	servePublishRequest(c, func(c *gin.Context) { server.PutDevfileWithVersion(c, "go", "2.0.0") })
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Did not get expected status code, Got: %v, Expected: %v", w.Code, http.StatusInternalServerError)
+	}
+	if got := servedVersions(t, "go"); !reflect.DeepEqual(got, []string{"1.0.2*"}) {
+		t.Errorf("Did not get expected versions, Got: %v, Expected: %v", got, []string{"1.0.2*"})
+	}
+	entries, err := os.ReadDir(filepath.Join(registryPath, "stacks", "go"))
+	if err != nil || len(entries) != 1 || entries[0].Name() != devfileName {
+		t.Errorf("Expected the go stack to be restored, got: %v, %v", entries, err)
+	}
+	if len(*deleted) != 0 {
+		t.Errorf("Expected no deleted versions, got: %v", *deleted)
+	}
+}
+
+// TestPublishDevfileWithVersionIndexFailure tests that a published version is deleted from the storage when the
+// index cannot be written after the push
+func TestPublishDevfileWithVersionIndexFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, pushed, deleted := setupPublishRegistry(t, nil)
+	indexPath = filepath.Join(t.TempDir(), "missing", "index.json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newMultipartRequest(t, "/devfiles/go/2.0.0", map[string]string{devfileName: fmt.Sprintf(publishTestDevfile, "go", "2.0.0", "Go Runtime", "Go", "Go")})
+	c.Request.Header.Set("Authorization", "Bearer "+testPublishToken)
+
+	server := &Server{}
+	servePublishRequest(c, func(c *gin.Context) { server.PutDevfileWithVersion(c, "go", "2.0.0") })
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Did not get expected status code, Got: %v, Expected: %v", w.Code, http.StatusInternalServerError)
+	}
+	want := []string{"devfile-catalog/go:2.0.0"}
+	if !reflect.DeepEqual(*pushed, want) || !reflect.DeepEqual(*deleted, want) {
+		t.Errorf("Got pushed: %v, deleted: %v, Expected both: %v", *pushed, *deleted, want)
+	}
+}
+
+// TestPublishDevfileWithVersionReloadFailure tests that the index, the stack and the pushed version are restored
+// when the published index cannot be reloaded
+func TestPublishDevfileWithVersionReloadFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registryPath, pushed, deleted := setupPublishRegistry(t, nil)
+	previousIndex, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the store fails to reload once its stack index is removed
+	stackIndexPath := filepath.Join(registryPath, "stack-index.json")
+	if err := os.WriteFile(stackIndexPath, previousIndex, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if indexStore, err = NewIndexStore(indexPath, stackIndexPath, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(stackIndexPath); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newMultipartRequest(t, "/devfiles/go/2.0.0", map[string]string{devfileName: fmt.Sprintf(publishTestDevfile, "go", "2.0.0", "Go Runtime", "Go", "Go")})
+	c.Request.Header.Set("Authorization", "Bearer "+testPublishToken)
+
+	server := &Server{}
+	servePublishRequest(c, func(c *gin.Context) { server.PutDevfileWithVersion(c, "go", "2.0.0") })
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Did not get expected status code, Got: %v, Expected: %v", w.Code, http.StatusInternalServerError)
+	}
+	if index, err := os.ReadFile(indexPath); err != nil || !bytes.Equal(index, previousIndex) {
+		t.Errorf("Expected the index to be restored, got: %s, %v", index, err)
+	}
+	entries, err := os.ReadDir(filepath.Join(registryPath, "stacks", "go"))
+	if err != nil || len(entries) != 1 || entries[0].Name() != devfileName {
+		t.Errorf("Expected the go stack to be restored, got: %v, %v", entries, err)
+	}
+	want := []string{"devfile-catalog/go:2.0.0"}
+	if !reflect.DeepEqual(*pushed, want) || !reflect.DeepEqual(*deleted, want) {
+		t.Errorf("Got pushed: %v, deleted: %v, Expected both: %v", *pushed, *deleted, want)
+	}
+}
+
+// TestPublishSignedIndex tests that the published index is signed with the signing key of the registry, and that
+// signed registries without a signing key refuse to publish
+func TestPublishSignedIndex(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupPublishRegistry(t, nil)
+	if err := os.WriteFile(indexSignaturePath, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := writeTestFile(t, "signing.key", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+
+	tests := []struct {
+		name         string
+		signingKey   string
+		wantCode     int
+		wantVersions []string
+	}{
+		{
+			name:         "Case 1: signed registry without a signing key",
+			wantCode:     http.StatusConflict,
+			wantVersions: []string{"1.0.2*"},
+		},
+		{
+			name:         "Case 2: signed registry with a signing key",
+			signingKey:   keyPath,
+			wantCode:     http.StatusOK,
+			wantVersions: []string{"2.0.0", "1.0.2*"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signingKeyPath = test.signingKey
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = newMultipartRequest(t, "/devfiles/go/2.0.0", map[string]string{devfileName: fmt.Sprintf(publishTestDevfile, "go", "2.0.0", "Go Runtime", "Go", "Go")})
+			c.Request.Header.Set("Authorization", "Bearer "+testPublishToken)
+
+			server := &Server{}
+			servePublishRequest(c, func(c *gin.Context) { server.PutDevfileWithVersion(c, "go", "2.0.0") })
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Did not get expected status code, Got: %v, Expected: %v, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if got := servedVersions(t, "go"); !reflect.DeepEqual(got, test.wantVersions) {
+				t.Errorf("Did not get expected versions, Got: %v, Expected: %v", got, test.wantVersions)
+			}
+		})
+	}
+
+	signatureBytes, err := os.ReadFile(indexSignaturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signature indexSchema.IndexSignature
+	if err := json.Unmarshal(signatureBytes, &signature); err != nil {
+		t.Fatal(err)
+	}
+	signedEntries := []string{}
+	for _, entrySignature := range signature.Entries {
+		payload, err := base64.StdEncoding.DecodeString(entrySignature.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := base64.StdEncoding.DecodeString(entrySignature.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ed25519.Verify(publicKey, payload, sig) {
+			t.Errorf("Signature of %s does not verify", payload)
+		}
+		var entry indexSchema.SignedEntry
+		if err := json.Unmarshal(payload, &entry); err != nil {
+			t.Fatal(err)
+		}
+		signedEntries = append(signedEntries, entry.Name+":"+entry.Version)
+	}
+	if want := []string{"go:2.0.0", "go:1.0.2", "nodejs-basic:"}; !reflect.DeepEqual(signedEntries, want) {
+		t.Errorf("Did not get expected signed entries, Got: %v, Expected: %v", signedEntries, want)
+	}
+}
+
+// TestPublishDevfileWithVersionExistingDanglingParent tests that a dangling parent of another stack in the served
+// index does not reject the published stack versions
+func TestPublishDevfileWithVersionExistingDanglingParent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupPublishRegistry(t, nil)
+	store, err := getIndexStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, _ := store.Snapshot().Index(allIndexType)
+	index = append(index, indexSchema.Schema{
+		Name:     "java",
+		Type:     indexSchema.StackDevfileType,
+		Versions: []indexSchema.Version{{Version: "1.0.0", Default: true, Parent: &indexSchema.Parent{Id: "base"}}},
+	})
+	if err := indexLibrary.CreateIndexFile(index, indexPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		devfile  string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Case 1: stack without parent",
+			version:  "2.0.0",
+			devfile:  fmt.Sprintf(publishTestDevfile, "go", "2.0.0", "Go Runtime", "Go", "Go"),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Case 2: stack with a dangling parent",
+			version:  "3.0.0",
+			devfile:  "parent:\n  id: missing\n" + fmt.Sprintf(publishTestDevfile, "go", "3.0.0", "Go Runtime", "Go", "Go"),
+			wantCode: http.StatusBadRequest,
+			wantBody: "go:3.0.0 devfile references parent missing",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = newMultipartRequest(t, "/devfiles/go/"+test.version, map[string]string{devfileName: test.devfile})
+			c.Request.Header.Set("Authorization", "Bearer "+testPublishToken)
+
+			server := &Server{}
+			servePublishRequest(c, func(c *gin.Context) { server.PutDevfileWithVersion(c, "go", test.version) })
+
+			if w.Code != test.wantCode {
+				t.Errorf("Did not get expected status code, Got: %v, Expected: %v, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), test.wantBody) {
+				t.Errorf("Expected body containing %q, Got: %s", test.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestUpdateDevfile tests changing the default version and deleting versions of a stack
+func TestUpdateDevfile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registryPath, _, deleted := setupPublishRegistry(t, nil)
+	server := &Server{}
+
+	// publish a second version of the go stack
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newMultipartRequest(t, "/devfiles/go/2.0.0", map[string]string{devfileName: fmt.Sprintf(publishTestDevfile, "go", "2.0.0", "Go Runtime", "Go", "Go")})
+	c.Request.Header.Set("Authorization", "Bearer "+testPublishToken)
+	servePublishRequest(c, func(c *gin.Context) { server.PutDevfileWithVersion(c, "go", "2.0.0") })
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to publish go 2.0.0: %s", w.Body.String())
+	}
+
+	tests := []struct {
+		name         string
+		handler      func(c *gin.Context)
+		body         string
+		wantCode     int
+		wantVersions []string
+	}{
+		{
+			name:         "Case 1: the default version cannot be deleted",
+			handler:      func(c *gin.Context) { server.DeleteDevfileWithVersion(c, "go", "1.0.2") },
+			wantCode:     http.StatusConflict,
+			wantVersions: []string{"2.0.0", "1.0.2*"},
+		},
+		{
+			name:     "Case 2: default version does not exist",
+			handler:  func(c *gin.Context) { server.PutDevfile(c, "go") },
+			body:     `{"defaultVersion": "3.0.0"}`,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "Case 3: change the default version",
+			handler:      func(c *gin.Context) { server.PutDevfile(c, "go") },
+			body:         `{"defaultVersion": "2.0.0"}`,
+			wantCode:     http.StatusOK,
+			wantVersions: []string{"2.0.0*", "1.0.2"},
+		},
+		{
+			name:         "Case 4: delete a version",
+			handler:      func(c *gin.Context) { server.DeleteDevfileWithVersion(c, "go", "1.0.2") },
+			wantCode:     http.StatusNoContent,
+			wantVersions: []string{"2.0.0*"},
+		},
+		{
+			name:     "Case 5: delete a missing version",
+			handler:  func(c *gin.Context) { server.DeleteDevfileWithVersion(c, "go", "1.0.2") },
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Case 6: samples cannot be changed",
+			handler:  func(c *gin.Context) { server.DeleteDevfileWithVersion(c, "nodejs-basic", "1.0.0") },
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:         "Case 7: deleting the last version removes the stack",
+			handler:      func(c *gin.Context) { server.DeleteDevfileWithVersion(c, "go", "2.0.0") },
+			wantCode:     http.StatusNoContent,
+			wantVersions: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/devfiles/go", strings.NewReader(test.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Header.Set("Authorization", "Bearer "+testPublishToken)
+
+			servePublishRequest(c, test.handler)
+			// gin writes the status of responses without a body once the request is handled
+			c.Writer.WriteHeaderNow()
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Did not get expected status code, Got: %v, Expected: %v, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if test.wantVersions != nil {
+				if got := servedVersions(t, "go"); !reflect.DeepEqual(got, test.wantVersions) {
+					t.Errorf("Did not get expected versions, Got: %v, Expected: %v", got, test.wantVersions)
+				}
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(registryPath, "stacks", "go")); !os.IsNotExist(err) {
+		t.Errorf("Expected the go stack to be removed: %v", err)
+	}
+	if want := []string{"devfile-catalog/go:1.0.2", "devfile-catalog/go:2.0.0"}; !reflect.DeepEqual(*deleted, want) {
+		t.Errorf("Did not get expected deleted versions, Got: %v, Expected: %v", *deleted, want)
+	}
+}
+
+// TestPublishDisabled tests that the publish API is not available without an access rule granting the write permission
+func TestPublishDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupPublishRegistry(t, nil)
+	accessRules = &AccessRules{Rules: []AccessRule{{Identities: []string{everyoneIdentity}, Names: []string{"*"}}}}
+	server := &Server{}
+
+	tests := []struct {
+		name    string
+		handler func(c *gin.Context)
+	}{
+		{
+			name:    "Case 1: PUT /devfiles/{stack}/{version}",
+			handler: func(c *gin.Context) { server.PutDevfileWithVersion(c, "go", "2.0.0") },
+		},
+		{
+			name:    "Case 2: DELETE /devfiles/{stack}/{version}",
+			handler: func(c *gin.Context) { server.DeleteDevfileWithVersion(c, "go", "1.0.2") },
+		},
+		{
+			name:    "Case 3: PUT /devfiles/{stack}",
+			handler: func(c *gin.Context) { server.PutDevfile(c, "go") },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/devfiles/go", nil)
+			c.Request.Header.Set("Authorization", "Bearer "+testPublishToken)
+
+			servePublishRequest(c, test.handler)
+
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Errorf("Did not get expected status code, Got: %v, Expected: %v", w.Code, http.StatusMethodNotAllowed)
+			}
+		})
+	}
+}
@@ -17,94 +17,185 @@ package server
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"log"
-	"os"
+	"net/http"
 	"path"
-	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/containerd/containerd/errdefs"
 	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"oras.land/oras-go/pkg/content"
 	"oras.land/oras-go/pkg/oras"
+	"oras.land/oras-go/pkg/target"
 )
 
-// pushStackToRegistry pushes the given devfile stack to the OCI registry
-func pushStackToRegistry(versionComponent indexSchema.Version, stackName string) error {
-	// Load the devfile into memory and set up the pushing resource (file name, file content, media type, ref)
+// ociStorage stores the stack versions in the upstream OCI registry, the stack resources are pushed from the
+// stacks folder
+type ociStorage struct{}
+
+// Ready checks if the OCI registry is up and running
+func (*ociStorage) Ready() error {
+	resp, err := ociClient.Get(serverConfig.OCI.URL)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the OCI registry answered with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ServesOCI is true since the OCI registry is proxied under /v2
+func (*ociStorage) ServesOCI() bool {
+	return true
+}
+
+// stackAnnotations returns the OCI annotations of the manifest of a stack version
+func stackAnnotations(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version) map[string]string {
+	annotations := map[string]string{
+		ocispec.AnnotationTitle:   devfileIndex.Name,
+		ocispec.AnnotationVersion: versionComponent.Version,
+	}
+	if devfileIndex.DisplayName != "" {
+		annotations[ocispec.AnnotationTitle] = devfileIndex.DisplayName
+	}
+	if description := versionComponent.Description; description != "" {
+		annotations[ocispec.AnnotationDescription] = description
+	} else if devfileIndex.Description != "" {
+		annotations[ocispec.AnnotationDescription] = devfileIndex.Description
+	}
+	// The last modified date is used as creation date so that the manifest of a stack version does not change
+	// when it is pushed again
+	if versionComponent.LastModified != "" {
+		annotations[ocispec.AnnotationCreated] = versionComponent.LastModified
+	}
+	if source := gitSource(versionComponent.Git); source != "" {
+		annotations[ocispec.AnnotationSource] = source
+	} else if source := gitSource(devfileIndex.Git); source != "" {
+		annotations[ocispec.AnnotationSource] = source
+	}
+	return annotations
+}
+
+// gitSource returns the URL of the git remote a stack is sourced from, the remote named by RemoteName or
+// else the first remote by name
+func gitSource(git *indexSchema.Git) string {
+	if git == nil {
+		return ""
+	}
+	if git.Url != "" {
+		return git.Url
+	}
+	if remote, found := git.Remotes[git.RemoteName]; found {
+		return remote
+	}
+	names := make([]string, 0, len(git.Remotes))
+	for name := range git.Remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return ""
+	}
+	return git.Remotes[names[0]]
+}
+
+// buildStackManifest loads the resources of a stack version in a memory store with their OCI 1.1 artifact
+// manifest. The manifest is generated deterministically from the resources and the index.
+func buildStackManifest(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version, ref string) (*content.Memory, ocispec.Descriptor, error) {
+	return buildStackManifestFrom(devfileIndex, versionComponent, ref, func(resource string) ([]byte, error) {
+		return readStackResource(devfileIndex.Name, versionComponent.Version, resource)
+	})
+}
+
+// buildStackManifestFrom builds the OCI artifact of a stack version like buildStackManifest with the resources
+// returned by read
+func buildStackManifestFrom(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version, ref string, read func(resource string) ([]byte, error)) (*content.Memory, ocispec.Descriptor, error) {
+	// Load the devfile into memory and set up the pushing resource (file name, file content, media type, ref),
+	// the layers are annotated with their file name
 	memoryStore := content.NewMemory()
 	pushContents := []ocispec.Descriptor{}
 
-	ref := path.Join(registryService, "/", versionComponent.Links["self"])
-	for _, resource := range versionComponent.Resources {
-		if resource == "meta.yaml" || strings.HasSuffix(resource, "-offline.zip") {
-			// Some registries may still have the meta.yaml (we don't need it) or offline resources in it, so skip pushing these up
-			continue
-		}
-
-		// Get the media type that corresponds to the resource
-		// Some resources have media types that depends on the entire filename (e.g. devfile.yaml, archive.tar),
-		// others just depend on the file extension (e.g. vsx files)
-		var mediaType string
-		var found bool
-		switch resource {
-		case devfileName, devfileNameHidden, svgLogoName, pngLogoName, archiveName:
-			// Get the media type associated with the file
-			if mediaType, found = mediaTypeMapping[resource]; !found {
-				return errors.New("media type not found for file " + resource)
-			}
-		default:
-			// Probably vsx file, but get the extension of the file just in case
-			fileExtension := filepath.Ext(resource)
-			if mediaType, found = mediaTypeMapping[fileExtension]; !found {
-				return errors.New("media type not found for file extension" + fileExtension)
-			}
+	for _, resource := range pushedResources(versionComponent) {
+		mediaType, err := resourceMediaType(resource)
+		if err != nil {
+			return nil, ocispec.Descriptor{}, err
 		}
 
-		resourcePath := filepath.Join(stacksPath, stackName, versionComponent.Version, resource)
-		// Load the resource into memory and add to the push contents
-		if _, err := os.Stat(resourcePath); os.IsNotExist(err) {
-			resourcePath = filepath.Join(stacksPath, stackName, resource)
-		}
-		/* #nosec G304 -- resourcePath is constructed from filepath.Join which cleans the input paths */
-		resourceContent, err := os.ReadFile(resourcePath)
+		resourceContent, err := read(resource)
 		if err != nil {
-			return err
+			return nil, ocispec.Descriptor{}, err
 		}
 
 		desc, err := memoryStore.Add(resource, mediaType, resourceContent)
 		if err != nil {
-			return err
+			return nil, ocispec.Descriptor{}, err
 		}
 		pushContents = append(pushContents, desc)
 	}
 
-	configBytes := []byte("{}")
-	dig := digest.FromBytes(configBytes)
-	configDesc := ocispec.Descriptor{
-		MediaType: devfileConfigMediaType,
-		Digest:    dig,
-		Size:      int64(len(configBytes)),
+	// Artifacts without config use the empty JSON descriptor, the artifact type identifies devfile stacks
+	configDesc := ocispec.DescriptorEmptyJSON
+	configDesc.Data = nil
+	manifest := ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: devfileArtifactType,
+		Config:       configDesc,
+		Layers:       pushContents,
+		Annotations:  stackAnnotations(devfileIndex, versionComponent),
+	}
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+	manifestDesc := ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: devfileArtifactType,
+		Digest:       digest.FromBytes(manifestBytes),
+		Size:         int64(len(manifestBytes)),
 	}
 
-	manifest, manifestDesc, err := content.GenerateManifest(&configDesc, nil, pushContents...)
+	memoryStore.Set(configDesc, ocispec.DescriptorEmptyJSON.Data)
+	err = memoryStore.StoreManifest(ref, manifestDesc, manifestBytes)
 	if err != nil {
-		return err
+		return nil, ocispec.Descriptor{}, err
 	}
-	memoryStore.Set(configDesc, configBytes)
-	err = memoryStore.StoreManifest(ref, manifestDesc, manifest)
+	return memoryStore, manifestDesc, nil
+}
+
+// Push pushes the given devfile stack to the OCI registry, stack versions already pushed with the same
+// manifest are skipped
+func (*ociStorage) Push(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version) (err error) {
+	defer observeOCIRequest(ociPushOperation, time.Now(), &err)
+	stackName := devfileIndex.Name
+	ref := path.Join(serverConfig.OCI.host(), "/", versionComponent.Links["self"])
+	memoryStore, manifestDesc, err := buildStackManifest(devfileIndex, versionComponent, ref)
 	if err != nil {
 		return err
 	}
 
 	ctx := context.Background()
+	registry := serverConfig.OCI.registry()
+
+	if _, existing, err := registry.Resolve(ctx, ref); err == nil && existing.Digest == manifestDesc.Digest {
+		log.Printf("%s version %s is already pushed to %s with digest %s\n", stackName, versionComponent.Version, ref, existing.Digest)
+		return nil
+	}
 
 	log.Printf("Pushing %s version %s to %s...\n", stackName, versionComponent.Version, ref)
-	registry, err := content.NewRegistry(content.RegistryOptions{PlainHTTP: true})
 	desc, err := oras.Copy(ctx, memoryStore, ref, registry, "")
 	if err != nil {
 		return fmt.Errorf("failed to push %s version %s to %s: %v", stackName, versionComponent.Version, ref, err)
@@ -113,36 +204,78 @@ func pushStackToRegistry(versionComponent indexSchema.Version, stackName string)
 	return nil
 }
 
-// pullStackFromRegistry pulls the given devfile stack from the OCI registry
-func pullStackFromRegistry(versionComponent indexSchema.Version) ([]byte, error) {
-	// Pull the devfile from registry and save to disk
-	ref := path.Join(registryService, "/", versionComponent.Links["self"])
-
+// Delete deletes the manifest of the given devfile stack version from the OCI registry, the registry must allow
+// deletes. Versions which are not in the registry are skipped.
+func (*ociStorage) Delete(stackName string, versionComponent indexSchema.Version) (err error) {
+	defer observeOCIRequest(ociDeleteOperation, time.Now(), &err)
+	ref := path.Join(serverConfig.OCI.host(), "/", versionComponent.Links["self"])
 	ctx := context.Background()
-	registry, err := content.NewRegistry(content.RegistryOptions{PlainHTTP: true})
+
+	_, desc, err := serverConfig.OCI.registry().Resolve(ctx, ref)
+	if errdefs.IsNotFound(err) {
+		log.Printf("%s version %s is not in %s, skipping the delete\n", stackName, versionComponent.Version, ref)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to resolve %s: %v", ref, err)
+	}
+
+	repository, _, _ := strings.Cut(versionComponent.Links["self"], ":")
+	manifestURL := serverConfig.OCI.url().JoinPath("v2", repository, "manifests", desc.Digest.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, manifestURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	if serverConfig.OCI.Username != "" || serverConfig.OCI.Password != "" {
+		req.SetBasicAuth(serverConfig.OCI.Username, serverConfig.OCI.Password)
+	}
+	resp, err := ociClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s version %s from %s: %v", stackName, versionComponent.Version, ref, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusOK, http.StatusNotFound:
+		log.Printf("Deleted %s with digest %s\n", ref, desc.Digest)
+		return nil
+	default:
+		return fmt.Errorf("failed to delete %s version %s from %s: status code %d", stackName, versionComponent.Version, ref, resp.StatusCode)
+	}
+}
+
+// Pull pulls a resource of the given devfile stack from the OCI registry
+func (*ociStorage) Pull(ctx context.Context, stackName string, versionComponent indexSchema.Version, resource string) (bytes []byte, err error) {
+	defer observeOCIRequest(ociPullOperation, time.Now(), &err)
+	ref := path.Join(serverConfig.OCI.host(), "/", versionComponent.Links["self"])
+	log.Printf("Pulling %s from %s...\n", resource, ref)
+	return pullStackResource(ctx, serverConfig.OCI.registry(), ref, resource)
+}
+
+// pullStackResource pulls a resource of a stack version from the given target. Only the layers are
+// filtered by media type so that both artifact manifests and manifests with a devfile config can be pulled.
+func pullStackResource(ctx context.Context, from target.Target, ref string, resource string) (bytes []byte, err error) {
+	ctx, span := tracer.Start(ctx, "pullStackResource", trace.WithAttributes(
+		attribute.String("oci.reference", ref),
+		attribute.String("registry.resource", resource),
+	))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	mediaType, err := resourceMediaType(resource)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize memory store
 	memoryStore := content.NewMemory()
-	allowedMediaTypes := []string{devfileMediaType}
 
-	var devfile string
-	for _, resource := range versionComponent.Resources {
-		if resource == devfileName {
-			devfile = devfileName
-			break
-		}
-		if resource == devfileNameHidden {
-			devfile = devfileNameHidden
-			break
-		}
-	}
-	log.Printf("Pulling %s from %s...\n", devfile, ref)
-	desc, err := oras.Copy(ctx, registry, ref, memoryStore, "", oras.WithAllowedMediaTypes(allowedMediaTypes))
+	desc, err := oras.Copy(ctx, from, ref, memoryStore, "", oras.WithAllowedMediaTypes([]string{mediaType}))
 	if err != nil {
-		return nil, fmt.Errorf("failed to pull %s from %s: %v", devfile, ref, err)
+		return nil, fmt.Errorf("failed to pull %s from %s: %v", resource, ref, err)
 	}
-	_, bytes, ok := memoryStore.GetByName(devfile)
+	_, bytes, ok := memoryStore.GetByName(resource)
 	if !ok {
-		return nil, fmt.Errorf("failed to load %s to memory", devfile)
+		return nil, fmt.Errorf("failed to load %s to memory", resource)
 	}
 
 	log.Printf("Pulled from %s with digest %s\n", ref, desc.Digest)
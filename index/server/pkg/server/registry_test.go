@@ -0,0 +1,199 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/pkg/content"
+)
+
+func TestBuildStackManifest(t *testing.T) {
+	setupVars()
+	ref := "localhost/devfile-catalog/go:1.2.0"
+	devfileIndex := indexSchema.Schema{
+		Name:        "go",
+		DisplayName: "Go Runtime",
+		Description: "Go is an open source programming language",
+		Git: &indexSchema.Git{
+			Remotes: map[string]string{"upstream": "https://github.com/devfile/upstream", "origin": "https://github.com/devfile/origin"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		versionComponent indexSchema.Version
+		wantAnnotations  map[string]string
+	}{
+		{
+			name: "Case 1: Annotations from the stack",
+			versionComponent: indexSchema.Version{
+				Version:   "1.2.0",
+				Resources: []string{"devfile.yaml"},
+			},
+			wantAnnotations: map[string]string{
+				ocispec.AnnotationTitle:       "Go Runtime",
+				ocispec.AnnotationDescription: "Go is an open source programming language",
+				ocispec.AnnotationVersion:     "1.2.0",
+				ocispec.AnnotationSource:      "https://github.com/devfile/origin",
+			},
+		},
+		{
+			name: "Case 2: Annotations from the stack version",
+			versionComponent: indexSchema.Version{
+				Version:      "1.2.0",
+				Description:  "Go 1.2.0 stack",
+				LastModified: "2023-06-01T12:00:00Z",
+				Git: &indexSchema.Git{
+					Remotes:    map[string]string{"origin": "https://github.com/devfile/origin", "fork": "https://github.com/devfile/fork"},
+					RemoteName: "fork",
+				},
+				Resources: []string{"devfile.yaml"},
+			},
+			wantAnnotations: map[string]string{
+				ocispec.AnnotationTitle:       "Go Runtime",
+				ocispec.AnnotationDescription: "Go 1.2.0 stack",
+				ocispec.AnnotationVersion:     "1.2.0",
+				ocispec.AnnotationCreated:     "2023-06-01T12:00:00Z",
+				ocispec.AnnotationSource:      "https://github.com/devfile/fork",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memoryStore, manifestDesc, err := buildStackManifest(devfileIndex, test.versionComponent, ref)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if manifestDesc.ArtifactType != devfileArtifactType {
+				t.Errorf("Got descriptor artifact type: %s, Expected: %s", manifestDesc.ArtifactType, devfileArtifactType)
+			}
+
+			_, manifestBytes, found := memoryStore.Get(manifestDesc)
+			if !found {
+				t.Fatalf("Manifest %s not found in the memory store", manifestDesc.Digest)
+			}
+			var manifest ocispec.Manifest
+			if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if manifest.ArtifactType != devfileArtifactType {
+				t.Errorf("Got artifact type: %s, Expected: %s", manifest.ArtifactType, devfileArtifactType)
+			}
+			if manifest.Config.MediaType != ocispec.MediaTypeEmptyJSON || manifest.Config.Digest != ocispec.DescriptorEmptyJSON.Digest {
+				t.Errorf("Got config: %+v, Expected the empty descriptor", manifest.Config)
+			}
+			if _, _, found := memoryStore.Get(manifest.Config); !found {
+				t.Errorf("Config %s not found in the memory store", manifest.Config.Digest)
+			}
+			if !reflect.DeepEqual(manifest.Annotations, test.wantAnnotations) {
+				t.Errorf("Got annotations: %v, Expected: %v", manifest.Annotations, test.wantAnnotations)
+			}
+			if len(manifest.Layers) != len(test.versionComponent.Resources) {
+				t.Fatalf("Got %d layers, Expected: %d", len(manifest.Layers), len(test.versionComponent.Resources))
+			}
+			for i, layer := range manifest.Layers {
+				if title := layer.Annotations[ocispec.AnnotationTitle]; title != test.versionComponent.Resources[i] {
+					t.Errorf("Got layer title: %s, Expected: %s", title, test.versionComponent.Resources[i])
+				}
+			}
+
+			// Building the manifest again must give the same digest so that pushes can be skipped
+			_, rebuiltDesc, err := buildStackManifest(devfileIndex, test.versionComponent, ref)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if rebuiltDesc.Digest != manifestDesc.Digest {
+				t.Errorf("Got digest: %s, Expected: %s", rebuiltDesc.Digest, manifestDesc.Digest)
+			}
+		})
+	}
+}
+
+func TestPullStackResource(t *testing.T) {
+	setupVars()
+	ref := "localhost/devfile-catalog/go:1.2.0"
+	versionComponent := indexSchema.Version{
+		Version:   "1.2.0",
+		Resources: []string{"devfile.yaml"},
+	}
+	devfileContent, err := readStackResource("go", "1.2.0", devfileName)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Stacks pushed before artifact manifests have a devfile config and no artifact type
+	legacyStore := func() (*content.Memory, error) {
+		memoryStore := content.NewMemory()
+		desc, err := memoryStore.Add(devfileName, devfileMediaType, devfileContent)
+		if err != nil {
+			return nil, err
+		}
+		configBytes := []byte("{}")
+		configDesc := ocispec.Descriptor{
+			MediaType: devfileConfigMediaType,
+			Digest:    digest.FromBytes(configBytes),
+			Size:      int64(len(configBytes)),
+		}
+		memoryStore.Set(configDesc, configBytes)
+		manifest, manifestDesc, err := content.GenerateManifest(&configDesc, nil, desc)
+		if err != nil {
+			return nil, err
+		}
+		return memoryStore, memoryStore.StoreManifest(ref, manifestDesc, manifest)
+	}
+	artifactStore := func() (*content.Memory, error) {
+		memoryStore, _, err := buildStackManifest(indexSchema.Schema{Name: "go"}, versionComponent, ref)
+		return memoryStore, err
+	}
+
+	tests := []struct {
+		name  string
+		store func() (*content.Memory, error)
+	}{
+		{
+			name:  "Case 1: Pull from an artifact manifest",
+			store: artifactStore,
+		},
+		{
+			name:  "Case 2: Pull from a manifest with a devfile config",
+			store: legacyStore,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memoryStore, err := test.store()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			bytes, err := pullStackResource(context.Background(), memoryStore, ref, devfileName)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(bytes, devfileContent) {
+				t.Errorf("Got devfile: %s, Expected: %s", bytes, devfileContent)
+			}
+		})
+	}
+}
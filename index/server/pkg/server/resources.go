@@ -0,0 +1,302 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// bundleWriter writes the files of a stack bundle under the bundle folder, one file at a time
+type bundleWriter interface {
+	// WriteFile writes a file of the given size with the content read from r
+	WriteFile(name string, mode int64, size int64, r io.Reader) error
+	// Close writes the end of the bundle
+	Close() error
+}
+
+// lookupStackVersion looks up a version of a stack in the registry index. Errors are written to the response and
+// false is returned.
+func lookupStackVersion(c *gin.Context, name string, version string) (indexSchema.Schema, indexSchema.Version, bool) {
+	store, err := getIndexStore()
+	if err != nil {
+		log.Print(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":  err.Error(),
+			"status": fmt.Sprintf("failed to look up the stack %s", name),
+		})
+		return indexSchema.Schema{}, indexSchema.Version{}, false
+	}
+	snapshot := store.Snapshot()
+	// Samples have no stored resources, stacks the identity of the request cannot access are answered as not found
+	devfileIndex, found := snapshot.Component(name)
+	if !found || devfileIndex.Type != indexSchema.StackDevfileType || !authorized(c, devfileIndex) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": fmt.Sprintf("the stack %s didn't exist", name),
+		})
+		return indexSchema.Schema{}, indexSchema.Version{}, false
+	}
+	versionMap := snapshot.VersionMap(name)
+	if versionMap == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": "failed to parse the stack version",
+		})
+		return indexSchema.Schema{}, indexSchema.Version{}, false
+	}
+	foundVersion, found := versionMap[version]
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": fmt.Sprintf("version: %s not found in stack %s", version, name),
+		})
+		return indexSchema.Schema{}, indexSchema.Version{}, false
+	}
+	if err := unavailableError(name, foundVersion.Version); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":  err.Error(),
+			"status": fmt.Sprintf("version %s of stack %s is unavailable", foundVersion.Version, name),
+		})
+		return indexSchema.Schema{}, indexSchema.Version{}, false
+	}
+	return devfileIndex, foundVersion, true
+}
+
+// serveStackResource serves a single resource of a stack version with the media type of the resource
+func serveStackResource(c *gin.Context, name string, version string, resource string) {
+	devfileIndex, versionComponent, found := lookupStackVersion(c, name, version)
+	if !found {
+		return
+	}
+	if !isStackResource(versionComponent, resource) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": fmt.Sprintf("the resource %s didn't exist in version %s of stack %s", resource, versionComponent.Version, name),
+		})
+		return
+	}
+
+	content, err := stackStorage.Pull(c.Request.Context(), name, versionComponent, resource)
+	if err != nil {
+		log.Print(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":  err.Error(),
+			"status": fmt.Sprintf("Problem pulling %s of version %s from the stack storage", resource, versionComponent.Version),
+		})
+		return
+	}
+	mediaType, err := resourceMediaType(resource)
+	if err != nil {
+		mediaType = "application/octet-stream"
+	}
+	serveCacheable(c, mediaType, content, devfileLastModified(devfileIndex, version), devfileCacheControl)
+}
+
+// serveStackBundle streams all resources of a stack version as a zip or tar.gz archive, archive.tar is expanded
+// into the bundle if requested
+func serveStackBundle(c *gin.Context, name string, version string, params ServeStackBundleParams) {
+	format := Zip
+	if params.Format != nil {
+		format = *params.Format
+	}
+	if format != Zip && format != TarGz {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": fmt.Sprintf("the bundle format %s is not one of %s or %s", format, Zip, TarGz),
+		})
+		return
+	}
+	expand := params.ExpandArchive != nil && *params.ExpandArchive
+
+	devfileIndex, versionComponent, found := lookupStackVersion(c, name, version)
+	if !found {
+		return
+	}
+
+	recordStat(c, "download", name, versionComponent.Version)
+	modTime := devfileLastModified(devfileIndex, version)
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	bundleName := fmt.Sprintf("%s-%s", name, versionComponent.Version)
+
+	// The resources are pulled and written one at a time, the bundle is started once the first resource is pulled
+	// so that missing resources of the stack version can still be answered with an error status
+	var writer bundleWriter
+	startBundle := func() {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.%s\"", bundleName, format))
+		if format == TarGz {
+			c.Header("Content-Type", "application/gzip")
+			c.Status(http.StatusOK)
+			writer = newTarGzBundleWriter(c.Writer, bundleName, modTime)
+		} else {
+			c.Header("Content-Type", starterProjectMediaType)
+			c.Status(http.StatusOK)
+			writer = newZipBundleWriter(c.Writer, bundleName, modTime)
+		}
+	}
+
+	// Expanded files never replace the stack resources
+	resources := pushedResources(versionComponent)
+	written := map[string]bool{}
+	for _, resource := range resources {
+		written[resource] = true
+	}
+	for _, resource := range resources {
+		content, err := stackStorage.Pull(c.Request.Context(), name, versionComponent, resource)
+		if err != nil {
+			log.Print(err.Error())
+			if writer != nil {
+				// The status is already sent, the client sees a truncated archive
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":  err.Error(),
+				"status": fmt.Sprintf("Problem pulling %s of version %s from the stack storage", resource, versionComponent.Version),
+			})
+			return
+		}
+		if writer == nil {
+			startBundle()
+		}
+		if expand && resource == archiveName {
+			err = expandArchive(writer, content, written)
+		} else {
+			err = writer.WriteFile(resource, 0644, int64(len(content)), bytes.NewReader(content))
+		}
+		if err != nil {
+			log.Printf("failed to stream %s to the bundle of version %s of stack %s: %v", resource, versionComponent.Version, name, err)
+			return
+		}
+	}
+	if writer == nil {
+		startBundle()
+	}
+	if err := writer.Close(); err != nil {
+		log.Printf("failed to stream the bundle of version %s of stack %s: %v", versionComponent.Version, name, err)
+	}
+}
+
+// expandArchive copies the regular files of a tar archive to the bundle, entries escaping the archive root are
+// skipped. Files with the name of a file already in the bundle are renamed under the archive folder, or skipped if
+// this name is taken too.
+func expandArchive(writer bundleWriter, archive []byte, written map[string]bool) error {
+	archiveFolder := strings.TrimSuffix(archiveName, path.Ext(archiveName))
+	tarReader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			continue
+		}
+		if written[name] {
+			name = path.Join(archiveFolder, name)
+		}
+		if written[name] {
+			log.Printf("skipping %s of %s, the bundle already has a file with this name", header.Name, archiveName)
+			continue
+		}
+		written[name] = true
+		if err := writer.WriteFile(name, header.Mode&0777, header.Size, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// zipBundleWriter writes a stack bundle as a zip archive
+type zipBundleWriter struct {
+	zipWriter  *zip.Writer
+	bundleName string
+	modTime    time.Time
+}
+
+// newZipBundleWriter creates a writer of a zip bundle to w
+func newZipBundleWriter(w io.Writer, bundleName string, modTime time.Time) *zipBundleWriter {
+	return &zipBundleWriter{zipWriter: zip.NewWriter(w), bundleName: bundleName, modTime: modTime}
+}
+
+func (z *zipBundleWriter) WriteFile(name string, mode int64, _ int64, r io.Reader) error {
+	header := &zip.FileHeader{
+		Name:     path.Join(z.bundleName, name),
+		Method:   zip.Deflate,
+		Modified: z.modTime,
+	}
+	header.SetMode(os.FileMode(mode))
+	writer, err := z.zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, r)
+	return err
+}
+
+func (z *zipBundleWriter) Close() error {
+	return z.zipWriter.Close()
+}
+
+// tarGzBundleWriter writes a stack bundle as a gzip compressed tar archive
+type tarGzBundleWriter struct {
+	gzipWriter *gzip.Writer
+	tarWriter  *tar.Writer
+	bundleName string
+	modTime    time.Time
+}
+
+// newTarGzBundleWriter creates a writer of a tar.gz bundle to w
+func newTarGzBundleWriter(w io.Writer, bundleName string, modTime time.Time) *tarGzBundleWriter {
+	gzipWriter := gzip.NewWriter(w)
+	return &tarGzBundleWriter{gzipWriter: gzipWriter, tarWriter: tar.NewWriter(gzipWriter), bundleName: bundleName, modTime: modTime}
+}
+
+func (t *tarGzBundleWriter) WriteFile(name string, mode int64, size int64, r io.Reader) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Join(t.bundleName, name),
+		Mode:     mode,
+		Size:     size,
+		ModTime:  t.modTime,
+	}
+	if err := t.tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.Copy(t.tarWriter, r)
+	return err
+}
+
+func (t *tarGzBundleWriter) Close() error {
+	if err := t.tarWriter.Close(); err != nil {
+		return err
+	}
+	return t.gzipWriter.Close()
+}
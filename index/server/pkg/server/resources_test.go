@@ -0,0 +1,317 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const resourceTestIndex = `[
+  {"name": "go", "type": "stack", "versions": [
+    {"version": "1.0.0", "default": true, "schemaVersion": "2.2.0",
+     "resources": ["devfile.yaml", "logo.svg", "archive.tar", "go.vsx", "meta.yaml"]}
+  ]},
+  {"name": "go-sample", "type": "sample", "git": {"remotes": {"origin": "https://github.com/devfile-samples/go-sample.git"}}}
+]`
+
+// useResourceTestRegistry serves the resource test index with the resources stored in memory
+func useResourceTestRegistry(t *testing.T) {
+	originalStore := indexStore
+	t.Cleanup(func() {
+		indexStore = originalStore
+	})
+	store, err := NewIndexStore(writeTestFile(t, "index.json", resourceTestIndex), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexStore = store
+
+	var archive bytes.Buffer
+	tarWriter := tar.NewWriter(&archive)
+	for name, content := range map[string]string{"main.go": "package main\n", "devfile.yaml": "schemaVersion: 2.0.0\n", "../escape": "escape", "/etc/passwd": "root"} {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := newMemoryStorage()
+	storage.Put("go", "1.0.0", devfileName, []byte("schemaVersion: 2.2.0\n"))
+	storage.Put("go", "1.0.0", svgLogoName, []byte("<svg/>"))
+	storage.Put("go", "1.0.0", archiveName, archive.Bytes())
+	storage.Put("go", "1.0.0", "go.vsx", []byte("vsx"))
+	useStackStorage(t, storage)
+}
+
+func TestServeStackResource(t *testing.T) {
+	setupVars()
+	useResourceTestRegistry(t)
+
+	tests := []struct {
+		name            string
+		stack           string
+		version         string
+		resource        string
+		wantCode        int
+		wantContentType string
+		wantContent     string
+	}{
+		{
+			name:            "Case 1: Logo",
+			stack:           "go",
+			version:         "1.0.0",
+			resource:        svgLogoName,
+			wantCode:        http.StatusOK,
+			wantContentType: svgLogoMediaType,
+			wantContent:     "<svg/>",
+		},
+		{
+			name:            "Case 2: Vsx file of the default version",
+			stack:           "go",
+			version:         "default",
+			resource:        "go.vsx",
+			wantCode:        http.StatusOK,
+			wantContentType: vsxMediaType,
+			wantContent:     "vsx",
+		},
+		{
+			name:            "Case 3: Devfile of the latest version",
+			stack:           "go",
+			version:         "latest",
+			resource:        devfileName,
+			wantCode:        http.StatusOK,
+			wantContentType: devfileMediaType,
+			wantContent:     "schemaVersion: 2.2.0\n",
+		},
+		{
+			name:     "Case 4: Resource which is not stored",
+			stack:    "go",
+			version:  "1.0.0",
+			resource: "meta.yaml",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Case 5: Resource outside of the stack",
+			stack:    "go",
+			version:  "1.0.0",
+			resource: "../../index.json",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Case 6: Version not found",
+			stack:    "go",
+			version:  "2.0.0",
+			resource: svgLogoName,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Case 7: Sample",
+			stack:    "go-sample",
+			version:  "latest",
+			resource: devfileName,
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/devfiles/"+test.stack+"/"+test.version+"/resources/"+url.PathEscape(test.resource), nil)
+			c.Params = gin.Params{{Key: "stack", Value: test.stack}, {Key: "version", Value: test.version}, {Key: "resource", Value: test.resource}}
+			server.ServeStackResource(c)
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Got status code: %d, Expected: %d, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if test.wantCode != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Type"); got != test.wantContentType {
+				t.Errorf("Got content type: %s, Expected: %s", got, test.wantContentType)
+			}
+			if got := w.Body.String(); got != test.wantContent {
+				t.Errorf("Got content: %s, Expected: %s", got, test.wantContent)
+			}
+		})
+	}
+}
+
+func TestServeStackBundle(t *testing.T) {
+	setupVars()
+	useResourceTestRegistry(t)
+
+	tests := []struct {
+		name            string
+		query           string
+		wantCode        int
+		wantContentType string
+		wantFiles       map[string]string
+	}{
+		{
+			name:            "Case 1: Zip bundle",
+			wantCode:        http.StatusOK,
+			wantContentType: starterProjectMediaType,
+			wantFiles: map[string]string{
+				"go-1.0.0/devfile.yaml": "schemaVersion: 2.2.0\n",
+				"go-1.0.0/logo.svg":     "<svg/>",
+				"go-1.0.0/archive.tar":  "",
+				"go-1.0.0/go.vsx":       "vsx",
+			},
+		},
+		{
+			name:            "Case 2: Tar.gz bundle with the archive expanded",
+			query:           "format=tar.gz&expandArchive=true",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/gzip",
+			wantFiles: map[string]string{
+				"go-1.0.0/devfile.yaml": "schemaVersion: 2.2.0\n",
+				"go-1.0.0/logo.svg":     "<svg/>",
+				"go-1.0.0/main.go":      "package main\n",
+				"go-1.0.0/go.vsx":       "vsx",
+				// the devfile of the archive does not replace the devfile of the stack
+				"go-1.0.0/archive/devfile.yaml": "schemaVersion: 2.0.0\n",
+			},
+		},
+		{
+			name:     "Case 3: Unsupported format",
+			query:    "format=rar",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/devfiles/go/latest/bundle?"+test.query, nil)
+			c.Params = gin.Params{{Key: "stack", Value: "go"}, {Key: "version", Value: "latest"}}
+			server.ServeStackBundle(c)
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Got status code: %d, Expected: %d, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if test.wantCode != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Type"); got != test.wantContentType {
+				t.Errorf("Got content type: %s, Expected: %s", got, test.wantContentType)
+			}
+			var files map[string]string
+			if test.wantContentType == starterProjectMediaType {
+				files = readZipBundle(t, w.Body.Bytes())
+			} else {
+				files = readTarGzBundle(t, w.Body.Bytes())
+			}
+			// The archive is compared by name only
+			if _, found := files["go-1.0.0/archive.tar"]; found {
+				files["go-1.0.0/archive.tar"] = ""
+			}
+			if !reflect.DeepEqual(files, test.wantFiles) {
+				t.Errorf("Got files: %v, Expected: %v", sortedKeys(files), sortedKeys(test.wantFiles))
+			}
+		})
+	}
+}
+
+// readZipBundle reads the files of a zip bundle by name
+func readZipBundle(t *testing.T, bundle []byte) map[string]string {
+	zipReader, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	files := map[string]string{}
+	for _, file := range zipReader.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, found := files[file.Name]; found {
+			t.Errorf("Got %s twice in the bundle", file.Name)
+		}
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+// readTarGzBundle reads the files of a tar.gz bundle by name
+func readTarGzBundle(t *testing.T, bundle []byte) map[string]string {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(bundle))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	files := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, found := files[header.Name]; found {
+			t.Errorf("Got %s twice in the bundle", header.Name)
+		}
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+func sortedKeys(files map[string]string) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,480 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// bm25K1 and bm25B are the term frequency saturation and the field length normalization of BM25
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// prefixMatchWeight and typoMatchWeight weigh the terms matching a query term by prefix or with typos
+	prefixMatchWeight = 0.8
+	typoMatchWeight   = 0.6
+	// minPrefixLength is the minimum length of the query terms matching by prefix
+	minPrefixLength = 2
+
+	// snippetLength is the approximate length in characters of the snippets of long fields
+	snippetLength = 160
+	// snippetContext is the number of characters kept before the first match of a snippet
+	snippetContext = 40
+
+	highlightStart = "<em>"
+	highlightEnd   = "</em>"
+)
+
+// searchField is a field of the stacks and samples indexed for search, matches are weighted by the boost of
+// the field
+type searchField struct {
+	name   string
+	boost  float64
+	values func(devfileIndex indexSchema.Schema) []string
+	// snippet is set for long fields, highlights show the part of the field around the first match
+	snippet bool
+}
+
+var searchFields = []searchField{
+	{name: "name", boost: 3, values: func(d indexSchema.Schema) []string { return []string{d.Name} }},
+	{name: "displayName", boost: 2.5, values: func(d indexSchema.Schema) []string { return []string{d.DisplayName} }},
+	{name: "tags", boost: 2, values: func(d indexSchema.Schema) []string { return d.Tags }},
+	{name: "language", boost: 1.5, values: func(d indexSchema.Schema) []string { return []string{d.Language} }},
+	{name: "projectType", boost: 1.5, values: func(d indexSchema.Schema) []string { return []string{d.ProjectType} }},
+	{name: "description", boost: 1, values: func(d indexSchema.Schema) []string { return []string{d.Description} }, snippet: true},
+}
+
+// SearchIndex is an in-memory inverted index of the stacks and samples of an index view, ranked with BM25 over
+// boosted fields
+type SearchIndex struct {
+	entries []indexSchema.Schema
+	// postings lists the stacks and samples containing each term, by term
+	postings map[string][]searchPosting
+	// terms is the sorted vocabulary, used for prefix and typo matching
+	terms []string
+	// fieldLengths is the number of terms of each field of each stack or sample
+	fieldLengths    [][]int
+	avgFieldLengths []float64
+}
+
+// searchPosting is the frequency of a term in the fields of a stack or sample
+type searchPosting struct {
+	entry       int
+	frequencies []int
+}
+
+// searchToken is a term of a field value with its position in the value
+type searchToken struct {
+	term       string
+	start, end int
+}
+
+// NewSearchIndex indexes the stacks and samples of an index
+func NewSearchIndex(index []indexSchema.Schema) *SearchIndex {
+	s := &SearchIndex{
+		entries:         index,
+		postings:        make(map[string][]searchPosting),
+		fieldLengths:    make([][]int, len(index)),
+		avgFieldLengths: make([]float64, len(searchFields)),
+	}
+	for entry, devfileIndex := range index {
+		s.fieldLengths[entry] = make([]int, len(searchFields))
+		frequencies := make(map[string][]int)
+		for field, searchField := range searchFields {
+			for _, value := range searchField.values(devfileIndex) {
+				for _, token := range tokenize(value) {
+					if frequencies[token.term] == nil {
+						frequencies[token.term] = make([]int, len(searchFields))
+					}
+					frequencies[token.term][field]++
+					s.fieldLengths[entry][field]++
+				}
+			}
+			s.avgFieldLengths[field] += float64(s.fieldLengths[entry][field])
+		}
+		for term, termFrequencies := range frequencies {
+			s.postings[term] = append(s.postings[term], searchPosting{entry: entry, frequencies: termFrequencies})
+		}
+	}
+	for field := range searchFields {
+		if len(index) > 0 {
+			s.avgFieldLengths[field] /= float64(len(index))
+		}
+	}
+	for term := range s.postings {
+		s.terms = append(s.terms, term)
+	}
+	sort.Strings(s.terms)
+	return s
+}
+
+// Search returns the stacks and samples matching the query, sorted by decreasing relevance. Each query term
+// matches the indexed terms equal to it, starting with it, or within a small edit distance of it.
+func (s *SearchIndex) Search(query string) []SearchHit {
+	scores := make(map[int]float64)
+	matchedTerms := make(map[int]map[string]bool)
+	for _, queryToken := range uniqueTokens(query) {
+		// the best matching term of each stack or sample counts for the query term
+		termScores := make(map[int]float64)
+		termMatches := make(map[int]string)
+		for term, weight := range s.expand(queryToken) {
+			idf := s.idf(term)
+			for _, posting := range s.postings[term] {
+				score := weight * idf * s.bm25(posting)
+				if score > termScores[posting.entry] {
+					termScores[posting.entry] = score
+					termMatches[posting.entry] = term
+				}
+			}
+		}
+		for entry, score := range termScores {
+			scores[entry] += score
+			if matchedTerms[entry] == nil {
+				matchedTerms[entry] = make(map[string]bool)
+			}
+			matchedTerms[entry][termMatches[entry]] = true
+		}
+	}
+
+	hits := make([]SearchHit, 0, len(scores))
+	for entry, score := range scores {
+		hits = append(hits, SearchHit{
+			Entry:      s.entries[entry],
+			Score:      math.Round(score*1000) / 1000,
+			Highlights: highlightFields(s.entries[entry], matchedTerms[entry]),
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Entry.Name < hits[j].Entry.Name
+	})
+	return hits
+}
+
+// expand returns the indexed terms matching a query term with their weights
+func (s *SearchIndex) expand(queryTerm string) map[string]float64 {
+	matches := make(map[string]float64)
+	if _, found := s.postings[queryTerm]; found {
+		matches[queryTerm] = 1
+	}
+	if utf8.RuneCountInString(queryTerm) >= minPrefixLength {
+		for i := sort.SearchStrings(s.terms, queryTerm); i < len(s.terms) && strings.HasPrefix(s.terms[i], queryTerm); i++ {
+			if _, found := matches[s.terms[i]]; !found {
+				matches[s.terms[i]] = prefixMatchWeight
+			}
+		}
+	}
+	if maxDistance := maxTypos(queryTerm); maxDistance > 0 {
+		for _, term := range s.terms {
+			if _, found := matches[term]; !found && editDistance(queryTerm, term, maxDistance) <= maxDistance {
+				matches[term] = typoMatchWeight
+			}
+		}
+	}
+	return matches
+}
+
+// idf is the BM25 inverse document frequency of a term
+func (s *SearchIndex) idf(term string) float64 {
+	documentFrequency := float64(len(s.postings[term]))
+	return math.Log(1 + (float64(len(s.entries))-documentFrequency+0.5)/(documentFrequency+0.5))
+}
+
+// bm25 sums the BM25 term frequency scores of the fields of a posting, weighted by the field boosts
+func (s *SearchIndex) bm25(posting searchPosting) float64 {
+	var score float64
+	for field, frequency := range posting.frequencies {
+		if frequency == 0 {
+			continue
+		}
+		lengthRatio := 1.0
+		if s.avgFieldLengths[field] > 0 {
+			lengthRatio = float64(s.fieldLengths[posting.entry][field]) / s.avgFieldLengths[field]
+		}
+		tf := float64(frequency)
+		score += searchFields[field].boost * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*lengthRatio))
+	}
+	return score
+}
+
+// tokenize splits a text into lowercase terms of letters and digits
+func tokenize(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+	for i, r := range text {
+		isTermRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isTermRune && start < 0 {
+			start = i
+		} else if !isTermRune && start >= 0 {
+			tokens = append(tokens, searchToken{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, searchToken{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// uniqueTokens returns the distinct terms of a query
+func uniqueTokens(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, token := range tokenize(query) {
+		if !seen[token.term] {
+			seen[token.term] = true
+			terms = append(terms, token.term)
+		}
+	}
+	return terms
+}
+
+// maxTypos is the edit distance tolerated for a query term, short terms must match exactly
+func maxTypos(term string) int {
+	switch length := utf8.RuneCountInString(term); {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the optimal string alignment distance of two terms, transpositions count as one edit. The
+// computation stops early once the distance exceeds maxDistance, in which case maxDistance+1 is returned.
+func editDistance(a string, b string, maxDistance int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > maxDistance {
+		return maxDistance + 1
+	}
+	previous2 := make([]int, len(rb)+1)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > maxDistance {
+			return maxDistance + 1
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(rb)]
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// highlightFields returns the HTML escaped snippets of the fields of a stack or sample containing the matched
+// terms, the matched words are enclosed in <em> tags
+func highlightFields(devfileIndex indexSchema.Schema, matchedTerms map[string]bool) map[string]string {
+	highlights := make(map[string]string)
+	for _, searchField := range searchFields {
+		var snippets []string
+		for _, value := range searchField.values(devfileIndex) {
+			if snippet, matched := highlight(value, matchedTerms, searchField.snippet); matched {
+				snippets = append(snippets, snippet)
+			}
+		}
+		if len(snippets) > 0 {
+			highlights[searchField.name] = strings.Join(snippets, ", ")
+		}
+	}
+	return highlights
+}
+
+// highlight encloses the matched terms of a value in <em> tags, long values are cut around the first match
+func highlight(value string, matchedTerms map[string]bool, snippet bool) (string, bool) {
+	var matches []searchToken
+	for _, token := range tokenize(value) {
+		if matchedTerms[token.term] {
+			matches = append(matches, token)
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	start, end := 0, len(value)
+	if snippet && len(value) > snippetLength {
+		start = max(matches[0].start-snippetContext, 0)
+		end = min(start+snippetLength, len(value))
+		// cut on word boundaries
+		if start > 0 {
+			if space := strings.IndexByte(value[start:matches[0].start], ' '); space >= 0 {
+				start += space + 1
+			}
+		}
+		if end < len(value) {
+			if space := strings.LastIndexByte(value[matches[0].end:end], ' '); space >= 0 {
+				end = matches[0].end + space
+			}
+		}
+	}
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("...")
+	}
+	position := start
+	for _, match := range matches {
+		if match.start < start || match.end > end {
+			continue
+		}
+		builder.WriteString(html.EscapeString(value[position:match.start]))
+		builder.WriteString(highlightStart + html.EscapeString(value[match.start:match.end]) + highlightEnd)
+		position = match.end
+	}
+	builder.WriteString(html.EscapeString(value[position:end]))
+	if end < len(value) {
+		builder.WriteString("...")
+	}
+	return builder.String(), true
+}
+
+// serveDevfileSearch serves the search hits of the stacks and samples matching the query and the structured filters
+// of the V2 index, sorted by decreasing relevance
+func serveDevfileSearch(c *gin.Context, params ServeDevfileSearchParams) {
+	// Sets Access-Control-Allow-Origin response header to allow cross origin requests
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	if strings.TrimSpace(params.Q) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "the search query must not be empty",
+		})
+		return
+	}
+	indexType := allIndexType
+	if params.Type != nil {
+		indexType = string(*params.Type)
+	}
+
+	store, err := getIndexStore()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": fmt.Sprintf("failed to read the devfile index: %v", err),
+		})
+		return
+	}
+	snapshot := store.Snapshot()
+	index, found := snapshot.Index(indexType)
+	searchIndex, _ := snapshot.SearchIndex(indexType)
+	if !found {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": fmt.Sprintf("the devfile with %s type doesn't exist", indexType),
+		})
+		return
+	}
+
+	// Apply the structured filters of the V2 index, the search hits are restricted to the filtered index
+	indexParams := params.toIndexParams()
+	index = filterAuthorized(c, index)
+	if indexParams.Deprecated != nil {
+		util.FilterDevfileDeprecated(&index, *indexParams.Deprecated, false)
+	}
+	index, code, err := filterIndexByParams(index, false, indexParams)
+	if err != nil {
+		c.JSON(code, gin.H{
+			"status": err.Error(),
+		})
+		return
+	}
+	filtered := make(map[string]indexSchema.Schema, len(index))
+	for _, devfileIndex := range index {
+		filtered[string(devfileIndex.Type)+"/"+devfileIndex.Name] = devfileIndex
+	}
+
+	hits := []SearchHit{}
+	for _, hit := range searchIndex.Search(params.Q) {
+		if devfileIndex, found := filtered[string(hit.Entry.Type)+"/"+hit.Entry.Name]; found {
+			// the filters can drop the versions out of range
+			hit.Entry = devfileIndex
+			hits = append(hits, hit)
+		}
+	}
+
+	total := len(hits)
+	c.Header(totalCountHeader, fmt.Sprint(total))
+	start, end := 0, total
+	if params.Offset != nil {
+		if *params.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status": fmt.Sprintf("offset %d is not valid, it should not be negative", *params.Offset),
+			})
+			return
+		}
+		start = min(*params.Offset, total)
+	}
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > maxPageLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status": fmt.Sprintf("limit %d is not valid, it should be between 1 and %d", *params.Limit, maxPageLimit),
+			})
+			return
+		}
+		end = min(start+*params.Limit, total)
+	}
+	hits = hits[start:end]
+
+	bytes, err := json.MarshalIndent(hits, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": fmt.Sprintf("failed to serialize the search hits: %v", err),
+		})
+		return
+	}
+	lastModified := time.Time{}
+	for _, hit := range hits {
+		if modified := parseLastModified(hit.Entry.LastModified); modified.After(lastModified) {
+			lastModified = modified
+		}
+	}
+	serveCacheable(c, "application/json; charset=utf-8", bytes, lastModified, indexCacheControl)
+}
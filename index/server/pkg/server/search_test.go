@@ -0,0 +1,204 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{name: "Case 1: Equal terms", a: "quarkus", b: "quarkus", want: 0},
+		{name: "Case 2: Substitution", a: "quarkos", b: "quarkus", want: 1},
+		{name: "Case 3: Transposition", a: "qaurkus", b: "quarkus", want: 1},
+		{name: "Case 4: Insertion and deletion", a: "pyhton3", b: "python", want: 2},
+		{name: "Case 5: Distance past the maximum", a: "nodejs", b: "django", want: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := editDistance(test.a, test.b, 2); got != test.want {
+				t.Errorf("Got distance: %d, Expected distance: %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	searchIndex := NewSearchIndex([]indexSchema.Schema{
+		{Name: "java-quarkus", DisplayName: "Quarkus Java", Description: "Quarkus with Java", Tags: []string{"Java", "Quarkus"}, Language: "Java"},
+		{Name: "java-maven", DisplayName: "Maven Java", Description: "Upstream Maven and OpenJDK 11", Tags: []string{"Java", "Maven"}, Language: "Java"},
+		{Name: "python", DisplayName: "Python", Description: "Python Stack with Python 3.7", Tags: []string{"Python", "pip"}, Language: "Python"},
+		{Name: "nodejs", DisplayName: "NodeJS Runtime", Description: "Stack with NodeJS 16 & <Express>", Tags: []string{"NodeJS", "Express"}, Language: "JavaScript"},
+	})
+
+	tests := []struct {
+		name           string
+		query          string
+		wantNames      []string
+		wantHighlights map[string]string
+	}{
+		{
+			name:      "Case 1: Exact matches rank before prefix matches",
+			query:     "quarkus java",
+			wantNames: []string{"java-quarkus", "java-maven", "nodejs"},
+			wantHighlights: map[string]string{
+				"name":        "<em>java</em>-<em>quarkus</em>",
+				"displayName": "<em>Quarkus</em> <em>Java</em>",
+				"tags":        "<em>Java</em>, <em>Quarkus</em>",
+				"language":    "<em>Java</em>",
+				"description": "<em>Quarkus</em> with <em>Java</em>",
+			},
+		},
+		{
+			name:      "Case 2: Prefix match",
+			query:     "pyth",
+			wantNames: []string{"python"},
+		},
+		{
+			name:      "Case 3: Typo match",
+			query:     "expres",
+			wantNames: []string{"nodejs"},
+			wantHighlights: map[string]string{
+				"description": "Stack with NodeJS 16 &amp; &lt;<em>Express</em>&gt;",
+				"tags":        "<em>Express</em>",
+			},
+		},
+		{
+			name:      "Case 4: No match",
+			query:     "golang",
+			wantNames: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hits := searchIndex.Search(test.query)
+			gotNames := []string{}
+			for _, hit := range hits {
+				gotNames = append(gotNames, hit.Entry.Name)
+			}
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Fatalf("Got hits: %v, Expected hits: %v", gotNames, test.wantNames)
+			}
+			if test.wantHighlights != nil && !reflect.DeepEqual(hits[0].Highlights, test.wantHighlights) {
+				t.Errorf("Got highlights: %v, Expected highlights: %v", hits[0].Highlights, test.wantHighlights)
+			}
+		})
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	description := "This stack provides a complete development environment with build tools, a runtime, debug " +
+		"support and hot reload for services written with the Quarkus framework, including native image builds " +
+		"with GraalVM and container image builds for the OpenShift platform. It also ships sample configurations."
+	want := "...reload for services written with the <em>Quarkus</em> framework, including native image builds " +
+		"with GraalVM and container image builds for the OpenShift platform. It..."
+
+	got, matched := highlight(description, map[string]bool{"quarkus": true}, true)
+	if !matched || got != want {
+		t.Errorf("Got snippet: %q, Expected snippet: %q", got, want)
+	}
+}
+
+// TestServeDevfileSearch tests the '/v2search' endpoint
+func TestServeDevfileSearch(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+
+	tests := []struct {
+		name      string
+		target    string
+		wantCode  int
+		wantNames []string
+		wantTotal string
+	}{
+		{
+			name:      "Case 1: Search all types",
+			target:    "/v2search?q=quarkus",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"java-quarkus", "code-with-quarkus"},
+			wantTotal: "2",
+		},
+		{
+			name:      "Case 2: Search samples",
+			target:    "/v2search?q=quarkus&type=sample",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"code-with-quarkus"},
+			wantTotal: "1",
+		},
+		{
+			name:      "Case 3: Search composed with structured filters",
+			target:    "/v2search?q=java&type=stack&tags=Maven&limit=1",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"java-maven"},
+			wantTotal: "2",
+		},
+		{
+			name:     "Case 4: Empty query",
+			target:   "/v2search?q=%20",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Case 5: Missing query",
+			target:   "/v2search",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, test.target, nil)
+
+			server.ServeDevfileSearch(c)
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Did not get expected status code, Got: %v, Expected: %v", w.Code, test.wantCode)
+			}
+			if test.wantCode != http.StatusOK {
+				return
+			}
+			var hits []SearchHit
+			if err := json.Unmarshal(w.Body.Bytes(), &hits); err != nil {
+				t.Fatalf("failed to unmarshal the search hits: %v", err)
+			}
+			gotNames := []string{}
+			for _, hit := range hits {
+				gotNames = append(gotNames, hit.Entry.Name)
+			}
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Errorf("Got hits: %v, Expected hits: %v", gotNames, test.wantNames)
+			}
+			if gotTotal := w.Header().Get(totalCountHeader); gotTotal != test.wantTotal {
+				t.Errorf("Got total count: %v, Expected total count: %v", gotTotal, test.wantTotal)
+			}
+		})
+	}
+}
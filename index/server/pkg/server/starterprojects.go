@@ -0,0 +1,345 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	dfutil "github.com/devfile/library/v2/pkg/util"
+	libutil "github.com/devfile/registry-support/index/generator/library"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+)
+
+const starterProjectCacheExt = ".zip"
+
+// starterProjectKey identifies the archive of a starter project in the cache
+type starterProjectKey struct {
+	Stack    string
+	Version  string
+	Project  string
+	Revision string
+	SubDir   string
+}
+
+// digest returns the content address of the starter project archive in the cache
+func (k starterProjectKey) digest() string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{k.Stack, k.Version, k.Project, k.Revision, k.SubDir}, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// starterProjectError is an error of a starter project download with the status it is answered with
+type starterProjectError struct {
+	status string
+	err    error
+}
+
+func (e *starterProjectError) Error() string {
+	return e.err.Error()
+}
+
+func (e *starterProjectError) Unwrap() error {
+	return e.err
+}
+
+// starterProjectCache is an on-disk cache of the starter project archives. Concurrent downloads of the same
+// starter project are shared, archives expire after the TTL and the oldest ones are evicted once the cache
+// exceeds its size limit.
+type starterProjectCache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+	group   singleflight.Group
+	// mutex guards the eviction of the archives
+	mutex sync.Mutex
+	now   func() time.Time
+}
+
+// starterProjectsCache is the starter project cache of the running registry server
+var starterProjectsCache = newStarterProjectCache(DefaultConfig().StarterProjects)
+
+// newStarterProjectCache creates the starter project cache of the configuration, archives are not cached if
+// the TTL is zero
+func newStarterProjectCache(config StarterProjectConfig) *starterProjectCache {
+	return &starterProjectCache{
+		dir:     config.CacheDir,
+		ttl:     config.CacheTTL,
+		maxSize: config.CacheMaxSize,
+		now:     time.Now,
+	}
+}
+
+// get returns the archive of the starter project from the cache, or downloads it with the download function
+// into a temporary path unique to the download. Concurrent calls for the same starter project share the download.
+func (c *starterProjectCache) get(key starterProjectKey, download func(downloadTmpLoc string) ([]byte, error)) ([]byte, error) {
+	// Concurrent calls sharing a download count as hits, only the call running the download is a miss
+	result := cacheHit
+	defer func() {
+		starterProjectCacheRequests.WithLabelValues(result).Inc()
+	}()
+
+	digest := key.digest()
+	if archive, found := c.load(digest); found {
+		return archive, nil
+	}
+
+	archive, err, _ := c.group.Do(digest, func() (interface{}, error) {
+		// The archive may have been stored by a download which ended in the meantime
+		if archive, found := c.load(digest); found {
+			return archive, nil
+		}
+
+		tmpDir, err := os.MkdirTemp("", "starter-project-")
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := os.RemoveAll(tmpDir); err != nil {
+				log.Print(err.Error())
+			}
+		}()
+
+		result = cacheMiss
+		start := time.Now()
+		archive, err := download(filepath.Join(tmpDir, key.Project))
+		starterProjectDownloadDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
+			return nil, err
+		}
+		if err := c.store(digest, archive); err != nil {
+			// The archive is still served when it cannot be cached
+			log.Printf("failed to cache starter project %s of %s:%s: %v", key.Project, key.Stack, key.Version, err)
+		}
+		return archive, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return archive.([]byte), nil
+}
+
+// enabled returns true if the archives are stored in the cache
+func (c *starterProjectCache) enabled() bool {
+	return c.ttl > 0 && c.dir != ""
+}
+
+// load reads an archive from the cache if it has not expired
+func (c *starterProjectCache) load(digest string) ([]byte, bool) {
+	if !c.enabled() {
+		return nil, false
+	}
+	archivePath := filepath.Join(c.dir, digest+starterProjectCacheExt)
+	info, err := os.Stat(archivePath)
+	if err != nil || c.now().Sub(info.ModTime()) >= c.ttl {
+		return nil, false
+	}
+	/* #nosec G304 -- the archive path is built from a digest */
+	archive, err := os.ReadFile(archivePath)
+	if err != nil {
+		return nil, false
+	}
+	return archive, true
+}
+
+// store writes an archive to the cache and evicts the expired and oldest archives to stay under the size limit
+func (c *starterProjectCache) store(digest string, archive []byte) error {
+	if !c.enabled() || (c.maxSize > 0 && int64(len(archive)) > c.maxSize) {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0750); err != nil {
+		return err
+	}
+
+	// Archives are renamed into the cache once written so that partial archives are never read
+	tmpFile, err := os.CreateTemp(c.dir, "download-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(archive); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	now := c.now()
+	if err := os.Chtimes(tmpFile.Name(), now, now); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile.Name(), filepath.Join(c.dir, digest+starterProjectCacheExt)); err != nil {
+		return err
+	}
+	return c.evict()
+}
+
+// evict removes the expired archives, then the oldest ones until the cache is under its size limit
+func (c *starterProjectCache) evict() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	var archives []os.FileInfo
+	var size int64
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != starterProjectCacheExt {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed by another eviction
+			continue
+		}
+		if c.now().Sub(info.ModTime()) >= c.ttl {
+			if err := os.Remove(filepath.Join(c.dir, info.Name())); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		archives = append(archives, info)
+		size += info.Size()
+	}
+
+	if c.maxSize <= 0 {
+		return nil
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].ModTime().Before(archives[j].ModTime())
+	})
+	for _, archive := range archives {
+		if size <= c.maxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, archive.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to evict starter project archive %s: %v", archive.Name(), err)
+		}
+		size -= archive.Size()
+	}
+	return nil
+}
+
+// downloadStarterProject downloads the archive of a starter project from its git repository or zip location,
+// zip locations which are not URLs are read from the stack folder
+func downloadStarterProject(ctx context.Context, selStarterProject v1alpha2.StarterProject, stackLoc string, downloadTmpLoc string) ([]byte, error) {
+	starterProject := selStarterProject.Name
+
+	if selStarterProject.Git != nil {
+		gitScheme := indexSchema.Git{
+			Remotes:    selStarterProject.Git.Remotes,
+			RemoteName: "origin",
+			SubDir:     selStarterProject.SubDir,
+		}
+
+		if selStarterProject.Git.CheckoutFrom != nil {
+			if selStarterProject.Git.CheckoutFrom.Remote != "" {
+				gitScheme.RemoteName = selStarterProject.Git.CheckoutFrom.Remote
+			}
+			gitScheme.Revision = selStarterProject.Git.CheckoutFrom.Revision
+		}
+
+		gitScheme.Url = gitScheme.Remotes[gitScheme.RemoteName]
+
+		_, span := tracer.Start(ctx, "DownloadStackFromGit", trace.WithAttributes(
+			attribute.String("starter_project.name", starterProject),
+			attribute.String("vcs.repository.url", gitScheme.Url),
+			attribute.String("vcs.revision", gitScheme.Revision),
+		))
+		downloadBytes, err := libutil.DownloadStackFromGit(&gitScheme, downloadTmpLoc, false)
+		endSpan(span, err)
+		if err != nil {
+			return nil, &starterProjectError{
+				status: fmt.Sprintf("Problem with downloading starter project %s from location: %s", starterProject, gitScheme.Url),
+				err:    err,
+			}
+		}
+		return downloadBytes, nil
+	}
+
+	_, err := url.ParseRequestURI(selStarterProject.Zip.Location)
+	if err == nil {
+		_, span := tracer.Start(ctx, "DownloadStackFromZipUrl", trace.WithAttributes(
+			attribute.String("starter_project.name", starterProject),
+			attribute.String("url.full", selStarterProject.Zip.Location),
+		))
+		downloadBytes, err := libutil.DownloadStackFromZipUrl(selStarterProject.Zip.Location, selStarterProject.SubDir, downloadTmpLoc)
+		endSpan(span, err)
+		if err != nil {
+			return nil, &starterProjectError{
+				status: fmt.Sprintf("Problem with downloading starter project %s", starterProject),
+				err:    err,
+			}
+		}
+		return downloadBytes, nil
+	}
+
+	localLoc := path.Join(stackLoc, selStarterProject.Zip.Location)
+	log.Printf("zip location is not a valid http url: %v\nTrying local path %s..", err, localLoc)
+
+	// If subdirectory is specified for starter project download then extract subdirectory
+	// and create new archive for download.
+	if selStarterProject.SubDir != "" {
+		downloadFilePath := fmt.Sprintf("%s.zip", downloadTmpLoc)
+
+		if _, err := dfutil.Unzip(localLoc, downloadTmpLoc, selStarterProject.SubDir); err != nil {
+			return nil, &starterProjectError{
+				status: fmt.Sprintf("Problem with reading subDir '%s' of starter project %s at %s",
+					selStarterProject.SubDir,
+					starterProject,
+					localLoc),
+				err: err,
+			}
+		}
+
+		if err := libutil.ZipDir(downloadTmpLoc, downloadFilePath); err != nil {
+			return nil, &starterProjectError{
+				status: fmt.Sprintf("Problem with archiving subDir '%s' of starter project %s at %s",
+					selStarterProject.SubDir,
+					starterProject,
+					downloadFilePath),
+				err: err,
+			}
+		}
+
+		localLoc = downloadFilePath
+	}
+
+	downloadBytes, err := os.ReadFile(filepath.Clean(localLoc))
+	if err != nil {
+		return nil, &starterProjectError{
+			status: fmt.Sprintf("Problem with reading starter project %s at %s", starterProject, localLoc),
+			err:    err,
+		}
+	}
+	return downloadBytes, nil
+}
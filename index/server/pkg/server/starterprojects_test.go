@@ -0,0 +1,261 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useStarterProjectCache sets a starter project cache in a temporary directory for the duration of a test
+func useStarterProjectCache(t *testing.T) *starterProjectCache {
+	previous := starterProjectsCache
+	t.Cleanup(func() {
+		starterProjectsCache = previous
+	})
+	starterProjectsCache = newStarterProjectCache(StarterProjectConfig{
+		CacheDir:     t.TempDir(),
+		CacheTTL:     time.Hour,
+		CacheMaxSize: 1 << 20,
+	})
+	return starterProjectsCache
+}
+
+func TestStarterProjectCacheSharesDownloads(t *testing.T) {
+	cache := useStarterProjectCache(t)
+	key := starterProjectKey{Stack: "go", Version: "1.2.0", Project: "go-starter"}
+
+	var downloads int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+	download := func(downloadTmpLoc string) ([]byte, error) {
+		if atomic.AddInt32(&downloads, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return []byte("archive"), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]byte, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			archive, err := cache.get(key, download)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			results[i] = archive
+		}(i)
+	}
+	<-started
+	// Let the other requests join the download in progress
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if downloads != 1 {
+		t.Errorf("Got %d downloads, Expected: 1", downloads)
+	}
+	for _, archive := range results {
+		if string(archive) != "archive" {
+			t.Errorf("Got archive: %s, Expected: archive", archive)
+		}
+	}
+
+	// Later requests are served from the cache
+	if _, err := cache.get(key, download); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if downloads != 1 {
+		t.Errorf("Got %d downloads, Expected the archive to be cached", downloads)
+	}
+}
+
+func TestStarterProjectCacheTTL(t *testing.T) {
+	cache := useStarterProjectCache(t)
+	now := time.Now()
+	cache.now = func() time.Time {
+		return now
+	}
+	key := starterProjectKey{Stack: "go", Version: "1.2.0", Project: "go-starter"}
+	downloads := 0
+	download := func(string) ([]byte, error) {
+		downloads++
+		return []byte("archive"), nil
+	}
+
+	tests := []struct {
+		name          string
+		elapsed       time.Duration
+		wantDownloads int
+	}{
+		{
+			name:          "Case 1: Archive is downloaded",
+			wantDownloads: 1,
+		},
+		{
+			name:          "Case 2: Archive is served from the cache before the TTL",
+			elapsed:       59 * time.Minute,
+			wantDownloads: 1,
+		},
+		{
+			name:          "Case 3: Archive is downloaded again after the TTL",
+			elapsed:       time.Hour,
+			wantDownloads: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache.now = func() time.Time {
+				return now.Add(test.elapsed)
+			}
+			if _, err := cache.get(key, download); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if downloads != test.wantDownloads {
+				t.Errorf("Got %d downloads, Expected: %d", downloads, test.wantDownloads)
+			}
+		})
+	}
+}
+
+func TestStarterProjectCacheKeys(t *testing.T) {
+	cache := useStarterProjectCache(t)
+	keys := []starterProjectKey{
+		{Stack: "go", Version: "1.2.0", Project: "go-starter"},
+		{Stack: "go", Version: "1.1.0", Project: "go-starter"},
+		{Stack: "go", Version: "1.2.0", Project: "go-starter", Revision: "main"},
+		{Stack: "go", Version: "1.2.0", Project: "go-starter", SubDir: "app"},
+	}
+	var tmpLocs []string
+	for i, key := range keys {
+		archive := []byte{byte(i)}
+		got, err := cache.get(key, func(downloadTmpLoc string) ([]byte, error) {
+			tmpLocs = append(tmpLocs, downloadTmpLoc)
+			return archive, nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(got, archive) {
+			t.Errorf("Got archive: %v for %+v, Expected: %v", got, key, archive)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, tmpLoc := range tmpLocs {
+		if seen[tmpLoc] {
+			t.Errorf("Got download path %s more than once, Expected unique paths", tmpLoc)
+		}
+		seen[tmpLoc] = true
+		if filepath.Base(tmpLoc) != "go-starter" {
+			t.Errorf("Got download path: %s, Expected it to end with the starter project name", tmpLoc)
+		}
+		if _, err := os.Stat(filepath.Dir(tmpLoc)); !os.IsNotExist(err) {
+			t.Errorf("Got download path %s not removed after the download", tmpLoc)
+		}
+	}
+}
+
+func TestStarterProjectCacheEviction(t *testing.T) {
+	cache := useStarterProjectCache(t)
+	cache.maxSize = 10
+	now := time.Now()
+	archive := []byte("12345")
+
+	keys := []starterProjectKey{
+		{Stack: "go", Project: "first"},
+		{Stack: "go", Project: "second"},
+		{Stack: "go", Project: "third"},
+	}
+	for i, key := range keys {
+		cache.now = func() time.Time {
+			return now.Add(time.Duration(i) * time.Minute)
+		}
+		if _, err := cache.get(key, func(string) ([]byte, error) { return archive, nil }); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		key        starterProjectKey
+		wantCached bool
+	}{
+		{
+			name: "Case 1: Oldest archive is evicted",
+			key:  keys[0],
+		},
+		{
+			name:       "Case 2: Newer archive is kept",
+			key:        keys[1],
+			wantCached: true,
+		},
+		{
+			name:       "Case 3: Newest archive is kept",
+			key:        keys[2],
+			wantCached: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, cached := cache.load(test.key.digest()); cached != test.wantCached {
+				t.Errorf("Got cached: %v, Expected: %v", cached, test.wantCached)
+			}
+		})
+	}
+
+	// Archives above the size limit are served without being cached
+	big := starterProjectKey{Stack: "go", Project: "big"}
+	if _, err := cache.get(big, func(string) ([]byte, error) { return make([]byte, 11), nil }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, cached := cache.load(big.digest()); cached {
+		t.Errorf("Got archive above the size limit cached")
+	}
+}
+
+func TestStarterProjectCacheErrors(t *testing.T) {
+	cache := useStarterProjectCache(t)
+	key := starterProjectKey{Stack: "go", Version: "1.2.0", Project: "go-starter"}
+	downloadErr := &starterProjectError{status: "Problem with downloading starter project go-starter", err: errors.New("not found")}
+
+	_, err := cache.get(key, func(string) ([]byte, error) {
+		return nil, downloadErr
+	})
+	var gotErr *starterProjectError
+	if !errors.As(err, &gotErr) || gotErr.status != downloadErr.status {
+		t.Errorf("Got error: %v, Expected: %v", err, downloadErr)
+	}
+
+	// Failed downloads are not cached
+	archive, err := cache.get(key, func(string) ([]byte, error) {
+		return []byte("archive"), nil
+	})
+	if err != nil || string(archive) != "archive" {
+		t.Errorf("Got archive: %s and error: %v, Expected the download to be retried", archive, err)
+	}
+}
@@ -0,0 +1,201 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+// maxPushBackoff is the maximum delay between the retries of a failed push
+const maxPushBackoff = 30 * time.Second
+
+// pushJob is a stack version pushed to the storage on startup
+type pushJob struct {
+	devfileIndex     indexSchema.Schema
+	versionComponent indexSchema.Version
+}
+
+// pushFailure is a stack version which failed to be pushed to the storage
+type pushFailure struct {
+	stackName string
+	version   string
+	err       error
+}
+
+// errNotPushed is the error of the stack versions of a reloaded index which are not pushed to the storage yet
+var errNotPushed = errors.New("the stack version is not pushed to the storage yet")
+
+var (
+	// unavailableVersions are the stack versions which failed to be pushed on startup, by stack name and version
+	unavailableVersions      = map[string]map[string]error{}
+	unavailableVersionsMutex sync.RWMutex
+)
+
+// pushWithRetries pushes a stack version, retrying with an exponential backoff on failure
+func pushWithRetries(job pushJob, config StorageConfig) error {
+	backoff := config.PushBackoff
+	var err error
+	for attempt := 0; attempt <= config.PushRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("Retrying to push %s version %s in %v: %v", job.devfileIndex.Name, job.versionComponent.Version, backoff, err)
+			time.Sleep(backoff)
+			backoff = min(backoff*2, maxPushBackoff)
+		}
+		if err = stackStorage.Push(job.devfileIndex, job.versionComponent); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// pushStacks pushes the stack versions of the index to the storage with a bounded pool of workers, the stack
+// versions still failing after the retries are returned
+func pushStacks(index []indexSchema.Schema, config StorageConfig) []pushFailure {
+	jobs := make(chan pushJob)
+	var failures []pushFailure
+	var failuresMutex sync.Mutex
+
+	var workers sync.WaitGroup
+	for i := 0; i < config.PushWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				if err := pushWithRetries(job, config); err != nil {
+					failuresMutex.Lock()
+					failures = append(failures, pushFailure{stackName: job.devfileIndex.Name, version: job.versionComponent.Version, err: err})
+					failuresMutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, devfileIndex := range index {
+		for _, versionComponent := range devfileIndex.Versions {
+			if len(versionComponent.Resources) != 0 {
+				jobs <- pushJob{devfileIndex: devfileIndex, versionComponent: versionComponent}
+			}
+		}
+	}
+	close(jobs)
+	workers.Wait()
+
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].stackName != failures[j].stackName {
+			return failures[i].stackName < failures[j].stackName
+		}
+		return failures[i].version < failures[j].version
+	})
+	return failures
+}
+
+// pushReloadedStacks pushes the stack versions of a reloaded index which are new, changed or still unavailable to
+// the storage. The versions are unavailable until they are pushed, and the versions failing to be pushed stay
+// unavailable until a later reload pushes them.
+func pushReloadedStacks(previous *IndexSnapshot, current *IndexSnapshot, config StorageConfig) {
+	stackIndex, _ := current.Index(string(indexSchema.StackDevfileType))
+	var changedIndex []indexSchema.Schema
+	var pending []pushFailure
+	for _, devfileIndex := range stackIndex {
+		previousIndex, _ := previous.Component(devfileIndex.Name)
+		var versions []indexSchema.Version
+		for _, versionComponent := range devfileIndex.Versions {
+			if len(versionComponent.Resources) == 0 {
+				continue
+			}
+			i := findStackVersion(previousIndex.Versions, versionComponent.Version)
+			if i != -1 && reflect.DeepEqual(previousIndex.Versions[i], versionComponent) &&
+				unavailableError(devfileIndex.Name, versionComponent.Version) == nil {
+				continue
+			}
+			versions = append(versions, versionComponent)
+			pending = append(pending, pushFailure{stackName: devfileIndex.Name, version: versionComponent.Version, err: errNotPushed})
+		}
+		if len(versions) != 0 {
+			devfileIndex.Versions = versions
+			changedIndex = append(changedIndex, devfileIndex)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	markUnavailable(pending)
+	failures := pushStacks(changedIndex, config)
+	failed := make(map[string]bool, len(failures))
+	for _, failure := range failures {
+		log.Printf("failed to push %s version %s of the reloaded index: %v", failure.stackName, failure.version, failure.err)
+		failed[fmt.Sprintf("%s:%s", failure.stackName, failure.version)] = true
+	}
+	markUnavailable(failures)
+	for _, job := range pending {
+		if !failed[fmt.Sprintf("%s:%s", job.stackName, job.version)] {
+			markAvailable(job.stackName, job.version)
+		}
+	}
+	log.Printf("Pushed %d of the %d new or changed stack versions of the reloaded index", len(pending)-len(failures), len(pending))
+}
+
+// markUnavailable marks the stack versions which failed to be pushed as unavailable
+func markUnavailable(failures []pushFailure) {
+	unavailableVersionsMutex.Lock()
+	defer unavailableVersionsMutex.Unlock()
+	for _, failure := range failures {
+		if unavailableVersions[failure.stackName] == nil {
+			unavailableVersions[failure.stackName] = map[string]error{}
+		}
+		unavailableVersions[failure.stackName][failure.version] = failure.err
+	}
+}
+
+// markAvailable marks a stack version as available once it is pushed
+func markAvailable(stackName string, version string) {
+	unavailableVersionsMutex.Lock()
+	defer unavailableVersionsMutex.Unlock()
+	delete(unavailableVersions[stackName], version)
+	if len(unavailableVersions[stackName]) == 0 {
+		delete(unavailableVersions, stackName)
+	}
+}
+
+// unavailableError returns the push error of an unavailable stack version, nil if the version is available
+func unavailableError(stackName string, version string) error {
+	unavailableVersionsMutex.RLock()
+	defer unavailableVersionsMutex.RUnlock()
+	return unavailableVersions[stackName][version]
+}
+
+// listUnavailable lists the unavailable stack versions as '<stack>:<version>'
+func listUnavailable() []string {
+	unavailableVersionsMutex.RLock()
+	defer unavailableVersionsMutex.RUnlock()
+	unavailable := []string{}
+	for stackName, versions := range unavailableVersions {
+		for version := range versions {
+			unavailable = append(unavailable, fmt.Sprintf("%s:%s", stackName, version))
+		}
+	}
+	sort.Strings(unavailable)
+	return unavailable
+}
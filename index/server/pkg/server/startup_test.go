@@ -0,0 +1,273 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	indexLibrary "github.com/devfile/registry-support/index/generator/library"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// flakyStorage is a storage failing the pushes of stack versions a given number of times
+type flakyStorage struct {
+	memoryStorage
+	mutex    sync.Mutex
+	failures map[string]int
+	attempts map[string]int
+	active   int32
+	maxSeen  int32
+}
+
+func (f *flakyStorage) Push(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version) error {
+	active := atomic.AddInt32(&f.active, 1)
+	defer atomic.AddInt32(&f.active, -1)
+	for {
+		seen := atomic.LoadInt32(&f.maxSeen)
+		if active <= seen || atomic.CompareAndSwapInt32(&f.maxSeen, seen, active) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+
+	key := fmt.Sprintf("%s:%s", devfileIndex.Name, versionComponent.Version)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.attempts[key]++
+	if f.attempts[key] <= f.failures[key] {
+		return errors.New("registry unavailable")
+	}
+	return nil
+}
+
+// resetUnavailable clears the unavailable stack versions after a test
+func resetUnavailable(t *testing.T) {
+	t.Cleanup(func() {
+		unavailableVersionsMutex.Lock()
+		defer unavailableVersionsMutex.Unlock()
+		unavailableVersions = map[string]map[string]error{}
+	})
+}
+
+func TestPushStacks(t *testing.T) {
+	index := []indexSchema.Schema{}
+	for i := 0; i < 10; i++ {
+		index = append(index, indexSchema.Schema{
+			Name: fmt.Sprintf("stack-%d", i),
+			Versions: []indexSchema.Version{
+				{Version: "1.0.0", Resources: []string{"devfile.yaml"}},
+				{Version: "2.0.0"},
+			},
+		})
+	}
+	storage := &flakyStorage{
+		failures: map[string]int{"stack-1:1.0.0": 2, "stack-4:1.0.0": 10},
+		attempts: map[string]int{},
+	}
+	useStackStorage(t, storage)
+
+	failures := pushStacks(index, StorageConfig{PushWorkers: 3, PushRetries: 2, PushBackoff: time.Millisecond})
+
+	if len(failures) != 1 || failures[0].stackName != "stack-4" || failures[0].version != "1.0.0" {
+		t.Errorf("Got failures: %v, Expected stack-4 version 1.0.0 to fail", failures)
+	}
+	if storage.attempts["stack-1:1.0.0"] != 3 || storage.attempts["stack-4:1.0.0"] != 3 {
+		t.Errorf("Got attempts: %v, Expected 3 attempts of the failing pushes", storage.attempts)
+	}
+	if len(storage.attempts) != 10 {
+		t.Errorf("Got %d pushed versions, Expected the 10 versions with resources", len(storage.attempts))
+	}
+	if storage.maxSeen > 3 {
+		t.Errorf("Got %d parallel pushes, Expected at most 3", storage.maxSeen)
+	}
+}
+
+func TestPushReloadedStacks(t *testing.T) {
+	resetUnavailable(t)
+	// snapshot loads a snapshot of the go stack with the given versions
+	snapshot := func(versions ...indexSchema.Version) *IndexSnapshot {
+		indexFilePath := filepath.Join(t.TempDir(), "index.json")
+		index := []indexSchema.Schema{{Name: "go", Type: indexSchema.StackDevfileType, Versions: versions}}
+		if err := indexLibrary.CreateIndexFile(index, indexFilePath); err != nil {
+			t.Fatal(err)
+		}
+		store, err := NewIndexStore(indexFilePath, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store.Snapshot()
+	}
+	previous := snapshot(
+		indexSchema.Version{Version: "1.0.2", Resources: []string{"devfile.yaml"}},
+		indexSchema.Version{Version: "1.1.0", Resources: []string{"devfile.yaml"}},
+	)
+	current := snapshot(
+		indexSchema.Version{Version: "1.0.2", Resources: []string{"devfile.yaml"}},
+		indexSchema.Version{Version: "1.1.0", Resources: []string{"devfile.yaml", "logo.png"}},
+		indexSchema.Version{Version: "2.0.0", Resources: []string{"devfile.yaml"}},
+	)
+	storage := &flakyStorage{
+		failures: map[string]int{"go:2.0.0": 1},
+		attempts: map[string]int{},
+	}
+	useStackStorage(t, storage)
+	config := StorageConfig{PushWorkers: 2}
+
+	// the changed and new versions are pushed, the versions failing to be pushed are unavailable
+	pushReloadedStacks(previous, current, config)
+	if want := map[string]int{"go:1.1.0": 1, "go:2.0.0": 1}; !reflect.DeepEqual(storage.attempts, want) {
+		t.Errorf("Got attempts: %v, Expected: %v", storage.attempts, want)
+	}
+	if unavailable := listUnavailable(); !reflect.DeepEqual(unavailable, []string{"go:2.0.0"}) {
+		t.Errorf("Got unavailable versions: %v, Expected: [go:2.0.0]", unavailable)
+	}
+
+	// the unavailable versions are pushed again on the next reload
+	pushReloadedStacks(current, current, config)
+	if want := map[string]int{"go:1.1.0": 1, "go:2.0.0": 2}; !reflect.DeepEqual(storage.attempts, want) {
+		t.Errorf("Got attempts: %v, Expected: %v", storage.attempts, want)
+	}
+	if unavailable := listUnavailable(); len(unavailable) != 0 {
+		t.Errorf("Got unavailable versions: %v, Expected none", unavailable)
+	}
+}
+
+func TestDegradedMode(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+	resetUnavailable(t)
+	useStackStorage(t, &filesystemStorage{})
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+
+	markUnavailable([]pushFailure{{stackName: "go", version: "1.2.0", err: errors.New("registry unavailable")}})
+
+	serveDevfile := func(version string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/devfiles/go/%s", version), nil)
+		c.Params = append(c.Params, gin.Param{Key: "stack", Value: "go"}, gin.Param{Key: "version", Value: version})
+		server.ServeDevfileWithVersion(c)
+		return w.Code
+	}
+	if code := serveDevfile("1.2.0"); code != http.StatusServiceUnavailable {
+		t.Errorf("Got status code: %d, Expected: %d", code, http.StatusServiceUnavailable)
+	}
+	if code := serveDevfile("1.1.0"); code != http.StatusOK {
+		t.Errorf("Got status code: %d, Expected the other versions to be available", code)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/health", nil)
+	server.ServeHealthCheck(c)
+	var health HealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+		t.Fatal(err)
+	}
+	if health.UnavailableVersions == nil || !reflect.DeepEqual(*health.UnavailableVersions, []string{"go:1.2.0"}) {
+		t.Errorf("Got health: %+v, Expected go:1.2.0 to be unavailable", health)
+	}
+
+	markAvailable("go", "1.2.0")
+	if code := serveDevfile("1.2.0"); code != http.StatusOK {
+		t.Errorf("Got status code: %d, Expected the version to be available once pushed", code)
+	}
+	if unavailable := listUnavailable(); len(unavailable) != 0 {
+		t.Errorf("Got unavailable versions: %v, Expected none", unavailable)
+	}
+}
+
+func TestOCIStoragePushSkipsExisting(t *testing.T) {
+	setupVars()
+	versionComponent := indexSchema.Version{
+		Version:   "1.2.0",
+		Resources: []string{"devfile.yaml"},
+		Links:     map[string]string{"self": "devfile-catalog/go:1.2.0"},
+	}
+	_, manifestDesc, err := buildStackManifest(indexSchema.Schema{Name: "go"}, versionComponent, "localhost/devfile-catalog/go:1.2.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		remoteDigest  string
+		wantErr       bool
+		wantUploading bool
+	}{
+		{
+			name:         "Case 1: Same manifest is skipped",
+			remoteDigest: manifestDesc.Digest.String(),
+		},
+		{
+			name:          "Case 2: Different manifest is pushed",
+			remoteDigest:  "sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			wantErr:       true,
+			wantUploading: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var uploading int32
+			ociServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead && strings.HasSuffix(r.URL.Path, "/manifests/1.2.0") {
+					w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+					w.Header().Set("Docker-Content-Digest", test.remoteDigest)
+					w.Header().Set("Content-Length", fmt.Sprint(manifestDesc.Size))
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				// Uploads fail so that a push is detected
+				atomic.AddInt32(&uploading, 1)
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer ociServer.Close()
+
+			previousConfig := serverConfig
+			defer func() {
+				serverConfig = previousConfig
+			}()
+			config := DefaultConfig()
+			config.OCI.URL = ociServer.URL
+			serverConfig = config
+
+			err := (&ociStorage{}).Push(indexSchema.Schema{Name: "go"}, versionComponent)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Got error: %v, Expected error: %v", err, test.wantErr)
+			}
+			if (atomic.LoadInt32(&uploading) != 0) != test.wantUploading {
+				t.Errorf("Got %d upload requests, Expected uploading: %v", uploading, test.wantUploading)
+			}
+		})
+	}
+}
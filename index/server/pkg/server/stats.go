@@ -0,0 +1,418 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// statsDayFormat is the format of the day buckets of the download statistics
+	statsDayFormat = "2006-01-02"
+	// statsKeySeparator separates the dimensions of a download statistics key
+	statsKeySeparator = "\x00"
+	// popularityWindow is the time window the popularity of the stacks is counted over
+	popularityWindow = 30 * 24 * time.Hour
+)
+
+// statsBucket is the bolt bucket of the daily event counts
+var statsBucket = []byte("stats")
+
+// popularityEvents are the events of eventTrackMap which count towards the popularity of a stack
+var popularityEvents = []string{"view", "download", "spdownload"}
+
+// downloadStats is the store of the download statistics of the running registry server, statistics are not
+// recorded if nil
+var downloadStats *downloadStatsStore
+
+// statsKey is a daily count of an event of eventTrackMap for a stack version and client. The stack and version
+// are empty for the events of the index.
+type statsKey struct {
+	Day     string
+	Event   string
+	Stack   string
+	Version string
+	Client  string
+}
+
+func (k statsKey) encode() []byte {
+	return []byte(strings.Join([]string{k.Day, k.Event, k.Stack, k.Version, k.Client}, statsKeySeparator))
+}
+
+func decodeStatsKey(key []byte) (statsKey, bool) {
+	parts := strings.Split(string(key), statsKeySeparator)
+	if len(parts) != 5 {
+		return statsKey{}, false
+	}
+	return statsKey{Day: parts[0], Event: parts[1], Stack: parts[2], Version: parts[3], Client: parts[4]}, true
+}
+
+// downloadStatsStore counts the events in memory and adds them to the daily counts of a bolt database in the
+// background, so that recording an event never waits for the disk
+type downloadStatsStore struct {
+	db        *bolt.DB
+	retention time.Duration
+	mutex     sync.Mutex
+	pending   map[statsKey]uint64
+	now       func() time.Time
+	closing   chan struct{}
+	done      chan struct{}
+}
+
+// openDownloadStats opens the bolt database of the download statistics and starts adding the recorded events
+// to it every flush interval
+func openDownloadStats(config StatsConfig) (*downloadStatsStore, error) {
+	db, err := bolt.Open(config.File, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the download statistics %s: %v", config.File, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(statsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize the download statistics %s: %v", config.File, err)
+	}
+	s := &downloadStatsStore{
+		db:        db,
+		retention: config.Retention,
+		pending:   map[statsKey]uint64{},
+		now:       time.Now,
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go s.run(config.FlushInterval)
+	return s, nil
+}
+
+func (s *downloadStatsStore) run(flushInterval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.flush(); err != nil {
+				log.Printf("failed to store the download statistics: %v", err)
+			}
+		case <-s.closing:
+			return
+		}
+	}
+}
+
+// record counts an event for the current day
+func (s *downloadStatsStore) record(event string, stack string, version string, client string) {
+	key := statsKey{Day: s.now().UTC().Format(statsDayFormat), Event: event, Stack: stack, Version: version, Client: client}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pending[key]++
+}
+
+// flush adds the pending counts to the database and removes the days older than the retention
+func (s *downloadStatsStore) flush() error {
+	s.mutex.Lock()
+	pending := s.pending
+	s.pending = map[statsKey]uint64{}
+	s.mutex.Unlock()
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(statsBucket)
+		for key, count := range pending {
+			encoded := key.encode()
+			if stored := bucket.Get(encoded); len(stored) == 8 {
+				count += binary.BigEndian.Uint64(stored)
+			}
+			value := make([]byte, 8)
+			binary.BigEndian.PutUint64(value, count)
+			if err := bucket.Put(encoded, value); err != nil {
+				return err
+			}
+		}
+		if s.retention <= 0 {
+			return nil
+		}
+		// Keys start with their day, so the expired days come first
+		oldest := s.now().UTC().Add(-s.retention).Format(statsDayFormat)
+		expired := [][]byte{}
+		cursor := bucket.Cursor()
+		for key, _ := cursor.First(); key != nil && string(key) < oldest; key, _ = cursor.Next() {
+			expired = append(expired, append([]byte{}, key...))
+		}
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Keep the counts for the next flush
+		s.mutex.Lock()
+		for key, count := range pending {
+			s.pending[key] += count
+		}
+		s.mutex.Unlock()
+	}
+	return err
+}
+
+// counts returns the counts of the days since the given day, including the counts not stored yet
+func (s *downloadStatsStore) counts(sinceDay string) (map[statsKey]uint64, error) {
+	counts := map[statsKey]uint64{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(statsBucket).Cursor()
+		for key, value := cursor.Seek([]byte(sinceDay)); key != nil; key, value = cursor.Next() {
+			decoded, ok := decodeStatsKey(key)
+			if ok && len(value) == 8 {
+				counts[decoded] += binary.BigEndian.Uint64(value)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, count := range s.pending {
+		if key.Day >= sinceDay {
+			counts[key] += count
+		}
+	}
+	return counts, nil
+}
+
+// Close stores the pending counts and closes the database
+func (s *downloadStatsStore) Close() error {
+	close(s.closing)
+	<-s.done
+	if err := s.flush(); err != nil {
+		log.Printf("failed to store the download statistics: %v", err)
+	}
+	return s.db.Close()
+}
+
+// windowStart returns the first day of a time window ending today, the window is rounded up to whole days
+func (s *downloadStatsStore) windowStart(window time.Duration) string {
+	days := int((window + 24*time.Hour - 1) / (24 * time.Hour))
+	if days < 1 {
+		days = 1
+	}
+	return s.now().UTC().AddDate(0, 0, 1-days).Format(statsDayFormat)
+}
+
+// parseStatsWindow parses a time window such as '7d' or '24h', 'all' is the whole history and is returned as zero
+func parseStatsWindow(window string) (time.Duration, error) {
+	if window == "all" {
+		return 0, nil
+	}
+	if days, found := strings.CutSuffix(window, "d"); found {
+		parsed, err := strconv.Atoi(days)
+		if err != nil || parsed < 1 {
+			return 0, fmt.Errorf("the window %s is not a positive number of days", window)
+		}
+		return time.Duration(parsed) * 24 * time.Hour, nil
+	}
+	parsed, err := time.ParseDuration(window)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("the window %s is not 'all', a number of days such as '7d' or a positive duration", window)
+	}
+	return parsed, nil
+}
+
+// recordStat counts an event of eventTrackMap in the download statistics. Indirect calls of the registry and
+// devfile libraries are not counted, since the client counts the event itself, and unknown clients are counted
+// as 'other'.
+func recordStat(c *gin.Context, event string, stack string, version string) {
+	if downloadStats == nil || util.IsIndirectCall(c) {
+		return
+	}
+	downloadStats.record(event, stack, version, util.GetKnownClient(c))
+}
+
+// resolveStatsVersion resolves the 'default' and 'latest' aliases of a stack version for the download statistics
+func resolveStatsVersion(name string, version string) string {
+	store, err := getIndexStore()
+	if err != nil {
+		return version
+	}
+	if foundVersion, found := store.Snapshot().VersionMap(name)[version]; found {
+		return foundVersion.Version
+	}
+	return version
+}
+
+// stackPopularity returns the number of views and downloads of each stack over the popularity window, empty if
+// the download statistics are disabled
+func stackPopularity() map[string]uint64 {
+	popularity := map[string]uint64{}
+	if downloadStats == nil {
+		return popularity
+	}
+	counts, err := downloadStats.counts(downloadStats.windowStart(popularityWindow))
+	if err != nil {
+		log.Printf("failed to read the download statistics: %v", err)
+		return popularity
+	}
+	for key, count := range counts {
+		for _, event := range popularityEvents {
+			if key.Event == event && key.Stack != "" {
+				popularity[key.Stack] += count
+			}
+		}
+	}
+	return popularity
+}
+
+// serveDevfileStats serves the download statistics of the stacks the identity of the request can access
+func serveDevfileStats(c *gin.Context, params ServeDevfileStatsParams) {
+	if downloadStats == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": "the download statistics are disabled",
+		})
+		return
+	}
+	window := "30d"
+	if util.StrPtrIsSet(params.Window) {
+		window = *params.Window
+	}
+	duration, err := parseStatsWindow(window)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": err.Error(),
+		})
+		return
+	}
+
+	stats := Stats{
+		Until:  openapi_types.Date{Time: downloadStats.now().UTC().Truncate(24 * time.Hour)},
+		Counts: EventCounts{},
+		Stacks: []StackStats{},
+	}
+	sinceDay := ""
+	if duration > 0 {
+		sinceDay = downloadStats.windowStart(duration)
+		since, _ := time.Parse(statsDayFormat, sinceDay)
+		stats.Since = &openapi_types.Date{Time: since}
+	}
+	counts, err := downloadStats.counts(sinceDay)
+	if err != nil {
+		log.Print(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": fmt.Sprintf("failed to read the download statistics: %v", err),
+		})
+		return
+	}
+
+	stacks := map[string]*statsAggregate{}
+	for key, count := range counts {
+		switch {
+		case util.StrPtrIsSet(params.Stack) && key.Stack != *params.Stack:
+			continue
+		case key.Stack == "":
+			// Events of the index are only counted in the totals
+		case !authorizedName(c, key.Stack):
+			continue
+		default:
+			aggregate, found := stacks[key.Stack]
+			if !found {
+				aggregate = newStatsAggregate()
+				stacks[key.Stack] = aggregate
+			}
+			aggregate.add(key, count)
+		}
+		stats.Counts[key.Event] += int64(count)
+	}
+
+	for name, aggregate := range stacks {
+		stats.Stacks = append(stats.Stacks, aggregate.stackStats(name))
+	}
+	sort.Slice(stats.Stacks, func(i, j int) bool {
+		if stats.Stacks[i].Total != stats.Stacks[j].Total {
+			return stats.Stacks[i].Total > stats.Stacks[j].Total
+		}
+		return stats.Stacks[i].Name < stats.Stacks[j].Name
+	})
+	c.JSON(http.StatusOK, stats)
+}
+
+// statsAggregate sums the event counts of a stack by version and client
+type statsAggregate struct {
+	counts   EventCounts
+	versions map[string]EventCounts
+	clients  map[string]EventCounts
+}
+
+func newStatsAggregate() *statsAggregate {
+	return &statsAggregate{
+		counts:   EventCounts{},
+		versions: map[string]EventCounts{},
+		clients:  map[string]EventCounts{},
+	}
+}
+
+func (a *statsAggregate) add(key statsKey, count uint64) {
+	a.counts[key.Event] += int64(count)
+	if key.Version != "" {
+		if a.versions[key.Version] == nil {
+			a.versions[key.Version] = EventCounts{}
+		}
+		a.versions[key.Version][key.Event] += int64(count)
+	}
+	if a.clients[key.Client] == nil {
+		a.clients[key.Client] = EventCounts{}
+	}
+	a.clients[key.Client][key.Event] += int64(count)
+}
+
+func (a *statsAggregate) stackStats(name string) StackStats {
+	stackStats := StackStats{
+		Name:     name,
+		Counts:   a.counts,
+		Versions: []VersionStats{},
+		Clients:  []ClientStats{},
+	}
+	for _, event := range popularityEvents {
+		stackStats.Total += a.counts[event]
+	}
+	for version, counts := range a.versions {
+		stackStats.Versions = append(stackStats.Versions, VersionStats{Version: version, Counts: counts})
+	}
+	sort.Slice(stackStats.Versions, func(i, j int) bool {
+		return stackStats.Versions[i].Version < stackStats.Versions[j].Version
+	})
+	for client, counts := range a.clients {
+		stackStats.Clients = append(stackStats.Clients, ClientStats{Client: client, Counts: counts})
+	}
+	sort.Slice(stackStats.Clients, func(i, j int) bool {
+		return stackStats.Clients[i].Client < stackStats.Clients[j].Client
+	})
+	return stackStats
+}
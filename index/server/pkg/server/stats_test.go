@@ -0,0 +1,279 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useDownloadStats records the download statistics in a temporary database on the given day
+func useDownloadStats(t *testing.T, now time.Time) *downloadStatsStore {
+	store, err := openDownloadStats(StatsConfig{
+		File:          filepath.Join(t.TempDir(), "stats.db"),
+		FlushInterval: time.Hour,
+		Retention:     30 * 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	store.now = func() time.Time { return now }
+	originalStats := downloadStats
+	downloadStats = store
+	t.Cleanup(func() {
+		downloadStats = originalStats
+		store.Close()
+	})
+	return store
+}
+
+func TestDownloadStatsStore(t *testing.T) {
+	today := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := useDownloadStats(t, today)
+
+	store.now = func() time.Time { return today.AddDate(0, 0, -40) }
+	store.record("view", "go", "1.0.0", "odo")
+	store.now = func() time.Time { return today.AddDate(0, 0, -3) }
+	store.record("view", "go", "1.0.0", "odo")
+	if err := store.flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	store.now = func() time.Time { return today }
+	store.record("view", "go", "1.0.0", "odo")
+	store.record("download", "go", "", "odo")
+
+	// The days older than the retention are removed, the pending counts are included
+	counts, err := store.counts("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[statsKey]uint64{
+		{Day: "2024-03-07", Event: "view", Stack: "go", Version: "1.0.0", Client: "odo"}: 1,
+		{Day: "2024-03-10", Event: "view", Stack: "go", Version: "1.0.0", Client: "odo"}: 1,
+		{Day: "2024-03-10", Event: "download", Stack: "go", Client: "odo"}:               1,
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("Got counts: %v, Expected: %v", counts, want)
+	}
+
+	// Flushed counts are added to the stored ones
+	store.record("view", "go", "1.0.0", "odo")
+	if err := store.flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.flush(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	counts, err = store.counts(store.windowStart(24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := counts[statsKey{Day: "2024-03-10", Event: "view", Stack: "go", Version: "1.0.0", Client: "odo"}]; got != 2 || len(counts) != 2 {
+		t.Errorf("Got counts: %v, Expected 2 views of today", counts)
+	}
+}
+
+func TestParseStatsWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name:   "Case 1: Days",
+			window: "7d",
+			want:   7 * 24 * time.Hour,
+		},
+		{
+			name:   "Case 2: Duration",
+			window: "36h",
+			want:   36 * time.Hour,
+		},
+		{
+			name:   "Case 3: Whole history",
+			window: "all",
+		},
+		{
+			name:    "Case 4: Negative days",
+			window:  "-1d",
+			wantErr: true,
+		},
+		{
+			name:    "Case 5: Invalid window",
+			window:  "week",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseStatsWindow(test.window)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Got error: %v, Expected error: %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("Got: %v, Expected: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestServeDevfileStats(t *testing.T) {
+	setupVars()
+	today := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := useDownloadStats(t, today)
+	store.record("list", "", "", "odo")
+	store.record("view", "go", "1.0.0", "odo")
+	store.record("view", "go", "1.0.0", "vscode")
+	store.record("spdownload", "go", "1.0.0", "odo")
+	store.record("view", "python", "2.0.0", "odo")
+	store.now = func() time.Time { return today.AddDate(0, 0, -10) }
+	store.record("view", "python", "1.0.0", "odo")
+	store.record("view", "python", "1.0.0", "odo")
+	store.now = func() time.Time { return today }
+
+	tests := []struct {
+		name       string
+		query      string
+		wantCode   int
+		wantCounts EventCounts
+		wantStacks []StackStats
+	}{
+		{
+			name:       "Case 1: Last 7 days",
+			query:      "window=7d",
+			wantCode:   http.StatusOK,
+			wantCounts: EventCounts{"list": 1, "view": 3, "spdownload": 1},
+			wantStacks: []StackStats{
+				{
+					Name:     "go",
+					Total:    3,
+					Counts:   EventCounts{"view": 2, "spdownload": 1},
+					Versions: []VersionStats{{Version: "1.0.0", Counts: EventCounts{"view": 2, "spdownload": 1}}},
+					Clients: []ClientStats{
+						{Client: "odo", Counts: EventCounts{"view": 1, "spdownload": 1}},
+						{Client: "vscode", Counts: EventCounts{"view": 1}},
+					},
+				},
+				{
+					Name:     "python",
+					Total:    1,
+					Counts:   EventCounts{"view": 1},
+					Versions: []VersionStats{{Version: "2.0.0", Counts: EventCounts{"view": 1}}},
+					Clients:  []ClientStats{{Client: "odo", Counts: EventCounts{"view": 1}}},
+				},
+			},
+		},
+		{
+			name:       "Case 2: Whole history of a stack",
+			query:      "window=all&stack=python",
+			wantCode:   http.StatusOK,
+			wantCounts: EventCounts{"view": 3},
+			wantStacks: []StackStats{
+				{
+					Name:   "python",
+					Total:  3,
+					Counts: EventCounts{"view": 3},
+					Versions: []VersionStats{
+						{Version: "1.0.0", Counts: EventCounts{"view": 2}},
+						{Version: "2.0.0", Counts: EventCounts{"view": 1}},
+					},
+					Clients: []ClientStats{{Client: "odo", Counts: EventCounts{"view": 3}}},
+				},
+			},
+		},
+		{
+			name:     "Case 3: Invalid window",
+			query:    "window=week",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v2stats?"+test.query, nil)
+			server.ServeDevfileStats(c)
+
+			if w.Code != test.wantCode {
+				t.Fatalf("Got status code: %d, Expected: %d, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if test.wantCode != http.StatusOK {
+				return
+			}
+			var stats Stats
+			if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(stats.Counts, test.wantCounts) {
+				t.Errorf("Got counts: %v, Expected: %v", stats.Counts, test.wantCounts)
+			}
+			if !reflect.DeepEqual(stats.Stacks, test.wantStacks) {
+				t.Errorf("Got stacks: %+v, Expected: %+v", stats.Stacks, test.wantStacks)
+			}
+		})
+	}
+}
+
+func TestServeDevfileStatsDisabled(t *testing.T) {
+	originalStats := downloadStats
+	downloadStats = nil
+	defer func() {
+		downloadStats = originalStats
+	}()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v2stats", nil)
+	serveDevfileStats(c, ServeDevfileStatsParams{})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Got status code: %d, Expected: %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestRecordStat(t *testing.T) {
+	store := useDownloadStats(t, time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC))
+	for _, client := range []string{"odo", "registry-library", "my-script", "my-other-script"} {
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/devfiles/go", nil)
+		c.Request.Header.Set("Client", client)
+		recordStat(c, "view", "go", "1.0.0")
+	}
+
+	// Indirect calls of the registry library are not counted and unknown clients are counted together
+	want := map[statsKey]uint64{
+		{Day: "2024-03-10", Event: "view", Stack: "go", Version: "1.0.0", Client: "odo"}:   1,
+		{Day: "2024-03-10", Event: "view", Stack: "go", Version: "1.0.0", Client: "other"}: 2,
+	}
+	if !reflect.DeepEqual(store.pending, want) {
+		t.Errorf("Got counts: %v, Expected: %v", store.pending, want)
+	}
+}
@@ -0,0 +1,265 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	indexLibrary "github.com/devfile/registry-support/index/generator/library"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+const (
+	// Storage types
+	ociStorageType        = "oci"
+	filesystemStorageType = "filesystem"
+	memoryStorageType     = "memory"
+)
+
+// StackStorage stores the resources of the stack versions served by the registry
+type StackStorage interface {
+	// Ready checks if the storage can serve the stacks
+	Ready() error
+	// ServesOCI reports whether the storage is an OCI registry proxied under /v2, the OCI artifacts of the other
+	// storages are built by the index server
+	ServesOCI() bool
+	// Push stores the resources of a version of the stack, read from the stacks folder
+	Push(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version) error
+	// Pull returns a resource of a stack version, ctx carries the trace context of the request it is pulled for
+	Pull(ctx context.Context, stackName string, versionComponent indexSchema.Version, resource string) ([]byte, error)
+	// Delete removes the resources of a version of the stack, deleting a version which is not stored succeeds
+	Delete(stackName string, versionComponent indexSchema.Version) error
+}
+
+// stackStorage is the storage of the running registry server
+var stackStorage StackStorage = &ociStorage{}
+
+// newStackStorage creates the storage of the given type
+func newStackStorage(storageType string) (StackStorage, error) {
+	switch storageType {
+	case ociStorageType:
+		return &ociStorage{}, nil
+	case filesystemStorageType:
+		return &filesystemStorage{}, nil
+	case memoryStorageType:
+		return newMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("the storage type %s is not one of %s, %s or %s", storageType, ociStorageType,
+			filesystemStorageType, memoryStorageType)
+	}
+}
+
+// pushedResources returns the resources of a stack version to store
+func pushedResources(versionComponent indexSchema.Version) []string {
+	resources := []string{}
+	for _, resource := range versionComponent.Resources {
+		if resource == "meta.yaml" || strings.HasSuffix(resource, "-offline.zip") {
+			// Some registries may still have the meta.yaml (we don't need it) or offline resources in it, so skip pushing these up
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// resourceMediaType returns the media type of a stack resource
+func resourceMediaType(resource string) (string, error) {
+	// Some resources have media types that depends on the entire filename (e.g. devfile.yaml, archive.tar),
+	// others just depend on the file extension (e.g. vsx files)
+	var mediaType string
+	var found bool
+	switch resource {
+	case devfileName, devfileNameHidden, devfileNameYml, devfileNameYmlHidden, svgLogoName, pngLogoName, archiveName:
+		// Get the media type associated with the file
+		if mediaType, found = mediaTypeMapping[resource]; !found {
+			return "", errors.New("media type not found for file " + resource)
+		}
+	default:
+		// Probably vsx file, but get the extension of the file just in case
+		fileExtension := strings.TrimPrefix(filepath.Ext(resource), ".")
+		if mediaType, found = mediaTypeMapping[fileExtension]; !found {
+			return "", errors.New("media type not found for file extension" + fileExtension)
+		}
+	}
+	return mediaType, nil
+}
+
+// readStackResource reads a resource of a stack version from the stacks folder, resources of single version
+// stacks are stored in the stack folder
+func readStackResource(stackName string, version string, resource string) ([]byte, error) {
+	resourcePath := filepath.Join(stacksPath, stackName, version, resource)
+	if _, err := os.Stat(resourcePath); os.IsNotExist(err) {
+		resourcePath = filepath.Join(stacksPath, stackName, resource)
+	}
+	/* #nosec G304 -- resourcePath is constructed from filepath.Join which cleans the input paths */
+	return os.ReadFile(resourcePath)
+}
+
+// isStackResource checks if the resource is one of the stored resources of the stack version, so that the
+// resource name cannot reach files outside of the stack folder
+func isStackResource(versionComponent indexSchema.Version, resource string) bool {
+	for _, stored := range pushedResources(versionComponent) {
+		if stored == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// devfileResource returns the name of the devfile in the resources of a stack version
+func devfileResource(versionComponent indexSchema.Version) (string, error) {
+	for _, resource := range versionComponent.Resources {
+		if indexLibrary.IsDevfileName(resource) {
+			return resource, nil
+		}
+	}
+	return "", fmt.Errorf("%s has no devfile in its resources %v", versionComponent.Links["self"], versionComponent.Resources)
+}
+
+// pullDevfile returns the devfile of a stack version from the storage
+func pullDevfile(ctx context.Context, stackName string, versionComponent indexSchema.Version) ([]byte, error) {
+	devfile, err := devfileResource(versionComponent)
+	if err != nil {
+		return nil, err
+	}
+	return stackStorage.Pull(ctx, stackName, versionComponent, devfile)
+}
+
+// filesystemStorage serves the stack versions directly from the stacks folder
+type filesystemStorage struct{}
+
+// Ready checks if the stacks folder exists
+func (*filesystemStorage) Ready() error {
+	_, err := os.Stat(stacksPath)
+	return err
+}
+
+// ServesOCI is false, the OCI artifacts are built from the stacks folder
+func (*filesystemStorage) ServesOCI() bool {
+	return false
+}
+
+// Push checks that the resources of the stack version are in the stacks folder, the folder is the storage
+func (*filesystemStorage) Push(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version) error {
+	stackName := devfileIndex.Name
+	for _, resource := range pushedResources(versionComponent) {
+		if _, err := readStackResource(stackName, versionComponent.Version, resource); err != nil {
+			return fmt.Errorf("failed to read %s of %s version %s: %v", resource, stackName, versionComponent.Version, err)
+		}
+	}
+	return nil
+}
+
+// Pull reads a resource of a stack version from the stacks folder
+func (*filesystemStorage) Pull(_ context.Context, stackName string, versionComponent indexSchema.Version, resource string) ([]byte, error) {
+	if !isStackResource(versionComponent, resource) {
+		return nil, fmt.Errorf("%s is not a resource of %s version %s", resource, stackName, versionComponent.Version)
+	}
+	bytes, err := readStackResource(stackName, versionComponent.Version, resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s of %s version %s: %v", resource, stackName, versionComponent.Version, err)
+	}
+	return bytes, nil
+}
+
+// Delete does nothing, the stack version folder is removed from the stacks folder by the publish API
+func (*filesystemStorage) Delete(string, indexSchema.Version) error {
+	return nil
+}
+
+// memoryStorage keeps the stack versions in memory, used for testing
+type memoryStorage struct {
+	mutex     sync.RWMutex
+	resources map[string][]byte
+}
+
+// newMemoryStorage creates an empty in-memory storage
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{resources: map[string][]byte{}}
+}
+
+// memoryKey is the key of a stack version resource in the in-memory storage
+func memoryKey(stackName string, version string, resource string) string {
+	return path.Join(stackName, version, resource)
+}
+
+// Ready always succeeds
+func (*memoryStorage) Ready() error {
+	return nil
+}
+
+// ServesOCI is false, the OCI artifacts are built from the resources in memory
+func (*memoryStorage) ServesOCI() bool {
+	return false
+}
+
+// Push loads the resources of the stack version from the stacks folder into memory
+func (m *memoryStorage) Push(devfileIndex indexSchema.Schema, versionComponent indexSchema.Version) error {
+	stackName := devfileIndex.Name
+	loaded := map[string][]byte{}
+	for _, resource := range pushedResources(versionComponent) {
+		bytes, err := readStackResource(stackName, versionComponent.Version, resource)
+		if err != nil {
+			return fmt.Errorf("failed to read %s of %s version %s: %v", resource, stackName, versionComponent.Version, err)
+		}
+		loaded[memoryKey(stackName, versionComponent.Version, resource)] = bytes
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for key, bytes := range loaded {
+		m.resources[key] = bytes
+	}
+	return nil
+}
+
+// Put stores a resource of a stack version
+func (m *memoryStorage) Put(stackName string, version string, resource string, bytes []byte) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.resources[memoryKey(stackName, version, resource)] = bytes
+}
+
+// Pull returns a resource of a stack version stored in memory
+func (m *memoryStorage) Pull(_ context.Context, stackName string, versionComponent indexSchema.Version, resource string) ([]byte, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	bytes, found := m.resources[memoryKey(stackName, versionComponent.Version, resource)]
+	if !found {
+		return nil, fmt.Errorf("%s of %s version %s is not stored", resource, stackName, versionComponent.Version)
+	}
+	return bytes, nil
+}
+
+// Delete removes the resources of a stack version from memory
+func (m *memoryStorage) Delete(stackName string, versionComponent indexSchema.Version) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	prefix := memoryKey(stackName, versionComponent.Version, "") + "/"
+	for key := range m.resources {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.resources, key)
+		}
+	}
+	return nil
+}
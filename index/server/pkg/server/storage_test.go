@@ -0,0 +1,275 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// useStackStorage sets the stack storage for the duration of a test
+func useStackStorage(t *testing.T, storage StackStorage) {
+	originalStorage := stackStorage
+	t.Cleanup(func() {
+		stackStorage = originalStorage
+	})
+	stackStorage = storage
+}
+
+func TestFilesystemStorage(t *testing.T) {
+	setupVars()
+	storage := &filesystemStorage{}
+	goVersion := indexSchema.Version{Version: "1.2.0", Resources: []string{"devfile.yaml"}}
+	nodejsVersion := indexSchema.Version{Version: "1.0.0", Resources: []string{"archive.tar", "devfile.yaml"}}
+
+	tests := []struct {
+		name        string
+		stackName   string
+		version     indexSchema.Version
+		resource    string
+		wantPath    string
+		wantErr     bool
+		wantPushErr bool
+	}{
+		{
+			name:      "Case 1: Resource of a multi version stack",
+			stackName: "go",
+			version:   goVersion,
+			resource:  "devfile.yaml",
+			wantPath:  filepath.Join(stacksPath, "go", "1.2.0", "devfile.yaml"),
+		},
+		{
+			name:      "Case 2: Resource of a single version stack",
+			stackName: "nodejs",
+			version:   nodejsVersion,
+			resource:  "archive.tar",
+			wantPath:  filepath.Join(stacksPath, "nodejs", "archive.tar"),
+		},
+		{
+			name:      "Case 3: File outside of the resources",
+			stackName: "go",
+			version:   goVersion,
+			resource:  "../stack.yaml",
+			wantErr:   true,
+		},
+		{
+			name:        "Case 4: Missing resource",
+			stackName:   "go",
+			version:     indexSchema.Version{Version: "1.2.0", Resources: []string{"devfile.yaml", "logo.svg"}},
+			resource:    "logo.svg",
+			wantErr:     true,
+			wantPushErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := storage.Push(indexSchema.Schema{Name: test.stackName}, test.version); (err != nil) != test.wantPushErr {
+				t.Errorf("Got push error: %v, Expected error: %v", err, test.wantPushErr)
+			}
+
+			gotBytes, err := storage.Pull(context.Background(), test.stackName, test.version, test.resource)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			wantBytes, err := os.ReadFile(test.wantPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(gotBytes, wantBytes) {
+				t.Errorf("Did not get the content of %s", test.wantPath)
+			}
+		})
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	setupVars()
+	storage := newMemoryStorage()
+	version := indexSchema.Version{Version: "1.1.0", Resources: []string{"devfile.yaml"}}
+
+	if _, err := storage.Pull(context.Background(), "go", version, "devfile.yaml"); err == nil {
+		t.Errorf("Expected an error pulling a resource not pushed")
+	}
+	if err := storage.Push(indexSchema.Schema{Name: "go"}, version); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	gotBytes, err := storage.Pull(context.Background(), "go", version, "devfile.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantBytes, err := os.ReadFile(filepath.Join(stacksPath, "go", "1.1.0", "devfile.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gotBytes, wantBytes) {
+		t.Errorf("Did not get the pushed devfile")
+	}
+
+	storage.Put("go", "1.1.0", "devfile.yaml", []byte("schemaVersion: 2.2.0"))
+	if gotBytes, _ = storage.Pull(context.Background(), "go", version, "devfile.yaml"); string(gotBytes) != "schemaVersion: 2.2.0" {
+		t.Errorf("Got: %s, Expected the devfile put", gotBytes)
+	}
+
+	storage.Put("go", "1.1.0-rc", "devfile.yaml", []byte("schemaVersion: 2.2.0"))
+	if err := storage.Delete("go", version); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := storage.Pull(context.Background(), "go", version, "devfile.yaml"); err == nil {
+		t.Errorf("Expected an error pulling a deleted resource")
+	}
+	if _, err := storage.Pull(context.Background(), "go", indexSchema.Version{Version: "1.1.0-rc"}, "devfile.yaml"); err != nil {
+		t.Errorf("Expected the other versions to be kept: %v", err)
+	}
+}
+
+func TestServeDevfileWithoutOCIRegistry(t *testing.T) {
+	setupVars()
+	gin.SetMode(gin.TestMode)
+	useStackStorage(t, &filesystemStorage{})
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/devfiles/go/1.2.0", nil)
+	c.Params = append(c.Params, gin.Param{Key: "stack", Value: "go"}, gin.Param{Key: "version", Value: "1.2.0"})
+	server.ServeDevfileWithVersion(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Did not get expected status code, Got: %v, Expected: %v, Body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	wantBytes, err := os.ReadFile(filepath.Join(stacksPath, "go", "1.2.0", "devfile.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(w.Body.Bytes(), wantBytes) {
+		t.Errorf("Did not get the devfile of the stacks folder")
+	}
+
+	// The stacks are served as OCI artifacts without an OCI registry
+	serveOCI := func(method string, proxyPath string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/v2"+proxyPath, nil)
+		c.Params = append(c.Params, gin.Param{Key: "proxyPath", Value: proxyPath})
+		ServeOciProxy(c)
+		c.Writer.WriteHeaderNow()
+		return w
+	}
+	if w = serveOCI(http.MethodGet, "/"); w.Code != http.StatusOK {
+		t.Errorf("Got status code: %v for the API version check, Expected: %v", w.Code, http.StatusOK)
+	}
+
+	w = serveOCI(http.MethodGet, "/devfile-catalog/go/manifests/1.2.0")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Got status code: %v for the manifest, Expected: %v, Body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(w.Body.Bytes(), &manifest); err != nil {
+		t.Fatalf("Failed to decode the manifest: %v", err)
+	}
+	manifestDigest := w.Header().Get("Docker-Content-Digest")
+	if manifestDigest != digest.FromBytes(w.Body.Bytes()).String() || len(manifest.Layers) != 1 {
+		t.Fatalf("Got manifest with digest %s and %d layers, Expected the digest of the content and 1 layer", manifestDigest, len(manifest.Layers))
+	}
+	if manifest.Layers[0].Digest != digest.FromBytes(wantBytes) {
+		t.Errorf("Got layer digest: %s, Expected the digest of the devfile", manifest.Layers[0].Digest)
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		proxyPath string
+		wantCode  int
+		wantBytes []byte
+	}{
+		{
+			name:      "Case 1: Manifest by digest",
+			method:    http.MethodHead,
+			proxyPath: "/devfile-catalog/go/manifests/" + manifestDigest,
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "Case 2: Devfile blob",
+			method:    http.MethodGet,
+			proxyPath: "/devfile-catalog/go/blobs/" + manifest.Layers[0].Digest.String(),
+			wantCode:  http.StatusOK,
+			wantBytes: wantBytes,
+		},
+		{
+			name:      "Case 3: Config blob",
+			method:    http.MethodGet,
+			proxyPath: "/devfile-catalog/go/blobs/" + manifest.Config.Digest.String(),
+			wantCode:  http.StatusOK,
+			wantBytes: ocispec.DescriptorEmptyJSON.Data,
+		},
+		{
+			name:      "Case 4: Unknown version",
+			method:    http.MethodGet,
+			proxyPath: "/devfile-catalog/go/manifests/9.9.9",
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "Case 5: Unknown blob",
+			method:    http.MethodGet,
+			proxyPath: "/devfile-catalog/go/blobs/" + digest.FromString("unknown").String(),
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "Case 6: Stack of another repository",
+			method:    http.MethodGet,
+			proxyPath: "/other-catalog/go/manifests/1.2.0",
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "Case 7: Sample",
+			method:    http.MethodGet,
+			proxyPath: "/devfile-catalog/nodejs-basic/manifests/1.0.0",
+			wantCode:  http.StatusNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveOCI(test.method, test.proxyPath)
+			if w.Code != test.wantCode {
+				t.Fatalf("Got status code: %v, Expected: %v, Body: %s", w.Code, test.wantCode, w.Body.String())
+			}
+			if test.wantBytes != nil && !bytes.Equal(w.Body.Bytes(), test.wantBytes) {
+				t.Errorf("Got: %s, Expected: %s", w.Body.Bytes(), test.wantBytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	indexLibrary "github.com/devfile/registry-support/index/generator/library"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/fsnotify/fsnotify"
+	"github.com/mohae/deepcopy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	allIndexType = "all"
+
+	// reloadDelay groups the file events of an index update into a single reload
+	reloadDelay = 200 * time.Millisecond
+)
+
+var (
+	indexStore      *IndexStore
+	indexStoreMutex sync.Mutex
+)
+
+// IndexSnapshot is an immutable view of the registry index, the stack and sample views and the version
+// maps of each stack and sample are computed once when the snapshot is loaded
+type IndexSnapshot struct {
+	views       map[string][]indexSchema.Schema
+	components  map[string]indexSchema.Schema
+	versionMaps map[string]map[string]indexSchema.Version
+	// base64Sources are the index files of the views with base64 encoded icons by index type
+	base64Sources map[string]base64Source
+	// base64Views are the views with base64 encoded icons by index type
+	base64Views sync.Map
+	searchViews map[string]*searchView
+	// flattenedViews are the flattened devfiles by stack or sample version, and by registry host for the versions
+	// referencing a registry URL of that host
+	flattenedViews sync.Map
+	// lastModified is the most recent modification time of the index files, the load time if unknown
+	lastModified time.Time
+}
+
+// base64Source is the index file an index view is read from and the file its base64 encoded view is cached to
+type base64Source struct {
+	sourcePath string
+	base64Path string
+}
+
+// base64View is an index view with its icons encoded to base64, encoded on first use
+type base64View struct {
+	once  sync.Once
+	index []indexSchema.Schema
+	err   error
+}
+
+// searchView is the search index of an index view, indexed on first use
+type searchView struct {
+	once  sync.Once
+	index *SearchIndex
+}
+
+// IndexStore holds the current snapshot of the registry index and swaps in a new snapshot when the index
+// files change. Readers get a consistent snapshot without locking.
+type IndexStore struct {
+	indexPath       string
+	stackIndexPath  string
+	sampleIndexPath string
+	snapshot        atomic.Pointer[IndexSnapshot]
+	reloadMutex     sync.Mutex
+}
+
+// NewIndexStore loads the index files into a new store. The stack and sample views are derived from the
+// index when their file paths are empty.
+func NewIndexStore(indexPath string, stackIndexPath string, sampleIndexPath string) (*IndexStore, error) {
+	store := &IndexStore{
+		indexPath:       indexPath,
+		stackIndexPath:  stackIndexPath,
+		sampleIndexPath: sampleIndexPath,
+	}
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// getIndexStore returns the index store of the server, the store is loaded from the configured index files
+// on first use if it has not been set at startup
+func getIndexStore() (*IndexStore, error) {
+	indexStoreMutex.Lock()
+	defer indexStoreMutex.Unlock()
+	if indexStore == nil {
+		store, err := NewIndexStore(indexPath, stackIndexPath, sampleIndexPath)
+		if err != nil {
+			return nil, err
+		}
+		indexStore = store
+	}
+	return indexStore, nil
+}
+
+// Snapshot returns the current snapshot of the index
+func (s *IndexStore) Snapshot() *IndexSnapshot {
+	return s.snapshot.Load()
+}
+
+// Reload reads the index files and swaps in the new snapshot, the current snapshot is kept on error
+func (s *IndexStore) Reload() (err error) {
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+	defer observeIndexReload(&err)
+	// Reloads are not part of a request, each reload is traced on its own
+	_, span := tracer.Start(context.Background(), "IndexStore.Reload", trace.WithAttributes(attribute.String("registry.index_path", s.indexPath)))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	index, err := util.ReadIndexPath(s.indexPath)
+	if err != nil {
+		return fmt.Errorf("failed to read index file %s: %v", s.indexPath, err)
+	}
+
+	var stackIndex, sampleIndex []indexSchema.Schema
+	for _, devfileIndex := range index {
+		if devfileIndex.Type == indexSchema.StackDevfileType {
+			stackIndex = append(stackIndex, devfileIndex)
+		} else if devfileIndex.Type == indexSchema.SampleDevfileType {
+			sampleIndex = append(sampleIndex, devfileIndex)
+		}
+	}
+	stackSourcePath, sampleSourcePath := s.indexPath, s.indexPath
+	if s.stackIndexPath != "" {
+		if stackIndex, err = util.ReadIndexPath(s.stackIndexPath); err != nil {
+			return fmt.Errorf("failed to read index file %s: %v", s.stackIndexPath, err)
+		}
+		stackSourcePath = s.stackIndexPath
+	}
+	if s.sampleIndexPath != "" {
+		if sampleIndex, err = util.ReadIndexPath(s.sampleIndexPath); err != nil {
+			return fmt.Errorf("failed to read index file %s: %v", s.sampleIndexPath, err)
+		}
+		sampleSourcePath = s.sampleIndexPath
+	}
+
+	snapshot := &IndexSnapshot{
+		lastModified: indexFilesLastModified(s.indexPath, s.stackIndexPath, s.sampleIndexPath),
+		views: map[string][]indexSchema.Schema{
+			allIndexType:                          index,
+			string(indexSchema.StackDevfileType):  stackIndex,
+			string(indexSchema.SampleDevfileType): sampleIndex,
+		},
+		components:  make(map[string]indexSchema.Schema, len(index)),
+		versionMaps: make(map[string]map[string]indexSchema.Version, len(index)),
+		base64Sources: map[string]base64Source{
+			allIndexType:                          {sourcePath: s.indexPath, base64Path: base64IndexPath},
+			string(indexSchema.StackDevfileType):  {sourcePath: stackSourcePath, base64Path: stackBase64IndexPath},
+			string(indexSchema.SampleDevfileType): {sourcePath: sampleSourcePath, base64Path: sampleBase64IndexPath},
+		},
+		searchViews: map[string]*searchView{
+			allIndexType:                          {},
+			string(indexSchema.StackDevfileType):  {},
+			string(indexSchema.SampleDevfileType): {},
+		},
+	}
+	for _, devfileIndex := range index {
+		// the first entry wins when a stack and a sample share a name, as in the index order lookup
+		if _, found := snapshot.components[devfileIndex.Name]; found {
+			continue
+		}
+		snapshot.components[devfileIndex.Name] = devfileIndex
+		if len(devfileIndex.Versions) == 0 {
+			continue
+		}
+		// stacks with invalid versions have no version map, the error is returned when they are requested
+		if versionMap, err := util.MakeVersionMap(devfileIndex); err == nil {
+			snapshot.versionMaps[devfileIndex.Name] = versionMap
+		}
+	}
+
+	s.snapshot.Store(snapshot)
+	setIndexEntries(stackIndex, sampleIndex)
+	return nil
+}
+
+// indexFilesLastModified returns the most recent modification time of the index files, empty paths are
+// skipped and the current time is returned if none can be read
+func indexFilesLastModified(paths ...string) time.Time {
+	var lastModified time.Time
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(lastModified) {
+			lastModified = info.ModTime()
+		}
+	}
+	if lastModified.IsZero() {
+		return time.Now()
+	}
+	return lastModified
+}
+
+// Watch reloads the store whenever one of the index files is written, created or replaced, until done is closed.
+// The parent directories are watched so that index files replaced by a rename are picked up. reloaded is called
+// with the previous and the new snapshot after each reload, if not nil.
+func (s *IndexStore) Watch(done <-chan struct{}, reloaded func(previous *IndexSnapshot, current *IndexSnapshot)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	files := make(map[string]bool)
+	for _, path := range []string{s.indexPath, s.stackIndexPath, s.sampleIndexPath} {
+		if path == "" {
+			continue
+		}
+		path = filepath.Clean(path)
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("failed to watch %s: %v", path, err)
+		}
+		files[path] = true
+	}
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-done:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			changed := event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename)
+			if changed && files[filepath.Clean(event.Name)] {
+				reload = time.After(reloadDelay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("index file watcher error: %v", err)
+		case <-reload:
+			reload = nil
+			previous := s.Snapshot()
+			if err := s.Reload(); err != nil {
+				log.Printf("failed to reload the index, keeping the current index: %v", err)
+				continue
+			}
+			log.Println("Reloaded the index")
+			if reloaded != nil {
+				reloaded(previous, s.Snapshot())
+			}
+		}
+	}
+}
+
+// Index returns the view of the given index type, "all", "stack" or "sample". The returned slice can be
+// filtered in place, the index entries must not be modified.
+func (s *IndexSnapshot) Index(indexType string) ([]indexSchema.Schema, bool) {
+	index, found := s.views[indexType]
+	if !found {
+		return nil, false
+	}
+	return append([]indexSchema.Schema(nil), index...), true
+}
+
+// Base64Index returns the view of the given index type with its icons encoded to base64. The encoded index is
+// cached to the base64 index file, which is reused as long as it is newer than the index file it comes from.
+// Failed encodings are not kept so that they are retried on the next request.
+func (s *IndexSnapshot) Base64Index(indexType string) ([]indexSchema.Schema, error) {
+	source, found := s.base64Sources[indexType]
+	if !found {
+		return nil, fmt.Errorf("the devfile with %s type doesn't exist", indexType)
+	}
+	value, _ := s.base64Views.LoadOrStore(indexType, &base64View{})
+	view := value.(*base64View)
+	view.once.Do(func() {
+		view.index, view.err = s.loadBase64View(indexType, source)
+	})
+	if view.err != nil {
+		s.base64Views.CompareAndDelete(indexType, view)
+	}
+	return append([]indexSchema.Schema(nil), view.index...), view.err
+}
+
+func (s *IndexSnapshot) loadBase64View(indexType string, source base64Source) ([]indexSchema.Schema, error) {
+	base64Info, err := os.Stat(source.base64Path)
+	if err == nil {
+		if sourceInfo, err := os.Stat(source.sourcePath); err == nil && !base64Info.ModTime().Before(sourceInfo.ModTime()) {
+			return util.ReadIndexPath(source.base64Path)
+		}
+	}
+
+	index := deepcopy.Copy(s.views[indexType]).([]indexSchema.Schema)
+	if err := util.EncodeIconsToBase64(index); err != nil {
+		return nil, err
+	}
+	if err := indexLibrary.CreateIndexFile(index, source.base64Path); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// SearchIndex returns the search index of the given index type, "all", "stack" or "sample"
+func (s *IndexSnapshot) SearchIndex(indexType string) (*SearchIndex, bool) {
+	view, found := s.searchViews[indexType]
+	if !found {
+		return nil, false
+	}
+	view.once.Do(func() {
+		view.index = NewSearchIndex(s.views[indexType])
+	})
+	return view.index, true
+}
+
+// LastModified returns the modification time of the index files the snapshot was loaded from
+func (s *IndexSnapshot) LastModified() time.Time {
+	return s.lastModified
+}
+
+// Component returns the stack or sample with the given name
+func (s *IndexSnapshot) Component(name string) (indexSchema.Schema, bool) {
+	devfileIndex, found := s.components[name]
+	return devfileIndex, found
+}
+
+// VersionMap returns the version map of the stack or sample with the given name, including the "default"
+// and "latest" aliases. nil is returned if the versions could not be parsed.
+func (s *IndexSnapshot) VersionMap(name string) map[string]indexSchema.Version {
+	return s.versionMaps[name]
+}
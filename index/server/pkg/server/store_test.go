@@ -0,0 +1,239 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	indexLibrary "github.com/devfile/registry-support/index/generator/library"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func testStoreIndex(goVersions ...string) []indexSchema.Schema {
+	var versions []indexSchema.Version
+	for i, version := range goVersions {
+		versions = append(versions, indexSchema.Version{Version: version, Default: i == 0})
+	}
+	return []indexSchema.Schema{
+		{Name: "go", Type: indexSchema.StackDevfileType, Versions: versions},
+		{Name: "nodejs-basic", Type: indexSchema.SampleDevfileType},
+	}
+}
+
+func TestIndexStore(t *testing.T) {
+	indexFilePath := filepath.Join(t.TempDir(), "index.json")
+	if err := indexLibrary.CreateIndexFile(testStoreIndex("1.0.2", "2.0.0"), indexFilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewIndexStore(indexFilePath, "", "")
+	if err != nil {
+		t.Fatalf("Unexpected err: %v", err)
+	}
+	snapshot := store.Snapshot()
+
+	tests := []struct {
+		name      string
+		indexType string
+		wantNames []string
+		wantFound bool
+	}{
+		{
+			name:      "Case 1: all view",
+			indexType: "all",
+			wantNames: []string{"go", "nodejs-basic"},
+			wantFound: true,
+		},
+		{
+			name:      "Case 2: stack view",
+			indexType: string(indexSchema.StackDevfileType),
+			wantNames: []string{"go"},
+			wantFound: true,
+		},
+		{
+			name:      "Case 3: sample view",
+			indexType: string(indexSchema.SampleDevfileType),
+			wantNames: []string{"nodejs-basic"},
+			wantFound: true,
+		},
+		{
+			name:      "Case 4: unknown view",
+			indexType: "plugin",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index, found := snapshot.Index(test.indexType)
+			if found != test.wantFound {
+				t.Fatalf("Got found: %t, Expected: %t", found, test.wantFound)
+			}
+			var names []string
+			for _, devfileIndex := range index {
+				names = append(names, devfileIndex.Name)
+			}
+			if len(names) != len(test.wantNames) || (len(names) > 0 && names[0] != test.wantNames[0]) {
+				t.Errorf("Got: %v, Expected: %v", names, test.wantNames)
+			}
+		})
+	}
+
+	// filtering a view in place does not affect the snapshot
+	index, _ := snapshot.Index("all")
+	index[0] = indexSchema.Schema{Name: "modified"}
+	if component, found := snapshot.Component("go"); !found || component.Name != "go" {
+		t.Errorf("Snapshot was modified: %+v", component)
+	}
+	if again, _ := snapshot.Index("all"); again[0].Name != "go" {
+		t.Errorf("Snapshot view was modified: %+v", again[0])
+	}
+
+	if latest := snapshot.VersionMap("go")["latest"].Version; latest != "2.0.0" {
+		t.Errorf("Got latest version: %s, Expected: 2.0.0", latest)
+	}
+
+	// the last modified time is the modification time of the index file
+	modified := time.Date(2024, 4, 19, 10, 45, 48, 0, time.UTC)
+	if err := os.Chtimes(indexFilePath, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Reload(); err != nil {
+		t.Fatalf("Unexpected err: %v", err)
+	}
+	if got := store.Snapshot().LastModified(); !got.Equal(modified) {
+		t.Errorf("Got last modified: %v, Expected: %v", got, modified)
+	}
+}
+
+// TestIndexStoreNameCollision tests that a sample sharing the name of a stack does not replace the stack
+func TestIndexStoreNameCollision(t *testing.T) {
+	index := append(testStoreIndex("1.0.2"), indexSchema.Schema{Name: "go", Type: indexSchema.SampleDevfileType})
+	indexFilePath := filepath.Join(t.TempDir(), "index.json")
+	if err := indexLibrary.CreateIndexFile(index, indexFilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewIndexStore(indexFilePath, "", "")
+	if err != nil {
+		t.Fatalf("Unexpected err: %v", err)
+	}
+	snapshot := store.Snapshot()
+	if component, found := snapshot.Component("go"); !found || component.Type != indexSchema.StackDevfileType {
+		t.Errorf("Got component: %+v, Expected the go stack", component)
+	}
+	if snapshot.VersionMap("go") == nil {
+		t.Error("Expected the version map of the go stack")
+	}
+}
+
+// TestIndexStoreBase64Retry tests that a failed encoding of the icons is retried on the next request
+func TestIndexStoreBase64Retry(t *testing.T) {
+	dir := t.TempDir()
+	iconPath := filepath.Join(dir, "icon.svg")
+	index := testStoreIndex("1.0.2")
+	index[0].Icon = iconPath
+	indexFilePath := filepath.Join(dir, "index.json")
+	if err := indexLibrary.CreateIndexFile(index, indexFilePath); err != nil {
+		t.Fatal(err)
+	}
+	originalBase64IndexPath := base64IndexPath
+	defer func() {
+		base64IndexPath = originalBase64IndexPath
+	}()
+	base64IndexPath = filepath.Join(dir, "index-base64.json")
+
+	store, err := NewIndexStore(indexFilePath, "", "")
+	if err != nil {
+		t.Fatalf("Unexpected err: %v", err)
+	}
+	snapshot := store.Snapshot()
+	if _, err := snapshot.Base64Index("all"); err == nil {
+		t.Fatal("Expected the missing icon to fail the encoding")
+	}
+
+	if err := os.WriteFile(iconPath, []byte("<svg/>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	base64Index, err := snapshot.Base64Index("all")
+	if err != nil {
+		t.Fatalf("Unexpected err: %v", err)
+	}
+	if !strings.HasPrefix(base64Index[0].Icon, "data:image/svg+xml;base64,") {
+		t.Errorf("Got icon: %s, Expected the base64 encoded icon", base64Index[0].Icon)
+	}
+}
+
+func TestIndexStoreWatch(t *testing.T) {
+	indexFilePath := filepath.Join(t.TempDir(), "index.json")
+	if err := indexLibrary.CreateIndexFile(testStoreIndex("1.0.2"), indexFilePath); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewIndexStore(indexFilePath, "", "")
+	if err != nil {
+		t.Fatalf("Unexpected err: %v", err)
+	}
+
+	done := make(chan struct{})
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- store.Watch(done, nil)
+	}()
+	defer func() {
+		close(done)
+		if err := <-watchErr; err != nil {
+			t.Errorf("Unexpected watch err: %v", err)
+		}
+	}()
+
+	// waitForLatest waits until the latest go version of the store snapshot is the expected version
+	waitForLatest := func(want string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if store.Snapshot().VersionMap("go")["latest"].Version == want {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("Got latest version: %s, Expected: %s", store.Snapshot().VersionMap("go")["latest"].Version, want)
+	}
+
+	// give the watcher time to start before updating the index
+	time.Sleep(100 * time.Millisecond)
+	if err := indexLibrary.CreateIndexFile(testStoreIndex("1.0.2", "2.0.0"), indexFilePath); err != nil {
+		t.Fatal(err)
+	}
+	waitForLatest("2.0.0")
+
+	// an invalid index keeps the current snapshot
+	if err := os.WriteFile(indexFilePath, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * reloadDelay)
+	waitForLatest("2.0.0")
+
+	// an index replaced by a rename is reloaded
+	tmpFilePath := indexFilePath + ".tmp"
+	if err := indexLibrary.CreateIndexFile(testStoreIndex("1.0.2", "2.0.0", "3.0.0"), tmpFilePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpFilePath, indexFilePath); err != nil {
+		t.Fatal(err)
+	}
+	waitForLatest("3.0.0")
+}
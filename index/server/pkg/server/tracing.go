@@ -0,0 +1,60 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of the registry server
+const tracerName = "github.com/devfile/registry-support/index/server"
+
+// tracer starts the spans of the registry server with the global tracer provider, the spans are only recorded
+// once a tracer provider exporting them is set
+var tracer = otel.Tracer(tracerName)
+
+// setupTracing propagates the W3C trace context and baggage of the requests, and exports the spans with the
+// tracer provider if it is not nil
+func setupTracing(tracerProvider *sdktrace.TracerProvider) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if tracerProvider != nil {
+		otel.SetTracerProvider(tracerProvider)
+	}
+}
+
+// endSpan records the error of the operation of the span if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedTransport wraps the transport of an upstream so that its requests are traced in client spans named
+// after the upstream, and carry the trace context of the requests they are made for
+func tracedTransport(transport http.RoundTripper, upstream string) http.RoundTripper {
+	return otelhttp.NewTransport(transport, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return upstream + " " + r.Method
+	}))
+}
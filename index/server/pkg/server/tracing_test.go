@@ -0,0 +1,152 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/devfile/registry-support/index/server/pkg/ocitest"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testTraceParent = "00-" + testTraceID + "-00f067aa0ba902b7-01"
+)
+
+// testSpanRecorder records the spans of the registry server for the duration of a test. The tracer of the
+// server is bound to the first tracer provider set globally, so the recorder is shared by the tests.
+var testSpanRecorder = tracetest.NewSpanRecorder()
+
+// useTestTracing propagates the trace context and records the spans, returns a router tracing the requests
+func useTestTracing(t *testing.T) *gin.Engine {
+	previousPropagator, previousProvider := otel.GetTextMapPropagator(), otel.GetTracerProvider()
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(previousPropagator)
+		otel.SetTracerProvider(previousProvider)
+	})
+	setupTracing(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(testSpanRecorder)))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(otelgin.Middleware("devfile-registry"))
+	return router
+}
+
+// recordedSpans returns the ended spans of the trace by name
+func recordedSpans(traceID string) map[string]sdktrace.ReadOnlySpan {
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range testSpanRecorder.Ended() {
+		if span.SpanContext().TraceID().String() == traceID {
+			spans[span.Name()] = span
+		}
+	}
+	return spans
+}
+
+func TestTraceDevfileRequest(t *testing.T) {
+	router := useTestTracing(t)
+	closeServer, err := setupMockOCIServer()
+	if err != nil {
+		t.Fatalf("Did not setup mock OCI server properly: %v", err)
+	}
+	defer closeServer()
+	setupVars()
+	server := &ServerInterfaceWrapper{
+		Handler:      &Server{},
+		ErrorHandler: testErrorHandler,
+	}
+	router.GET("/devfiles/:stack/:version", server.ServeDevfileWithVersion)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/devfiles/go/default", nil)
+	req.Header.Set("traceparent", testTraceParent)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Got status code: %d, Expected: %d", w.Code, http.StatusOK)
+	}
+
+	// The spans continue the trace of the client and are nested in the span of the request
+	spans := recordedSpans(testTraceID)
+	serverSpan, found := spans["/devfiles/:stack/:version"]
+	if !found {
+		t.Fatalf("Expected a span for the request in the trace of the client, got spans: %v", spans)
+	}
+	if serverSpan.SpanKind() != trace.SpanKindServer {
+		t.Errorf("Got span kind: %v, Expected: %v", serverSpan.SpanKind(), trace.SpanKindServer)
+	}
+	fetchSpan, found := spans["fetchDevfile"]
+	if !found {
+		t.Fatalf("Expected a fetchDevfile span, got spans: %v", spans)
+	}
+	if fetchSpan.Parent().SpanID() != serverSpan.SpanContext().SpanID() {
+		t.Errorf("Expected the fetchDevfile span to be a child of the request span")
+	}
+	pullSpan, found := spans["pullStackResource"]
+	if !found {
+		t.Fatalf("Expected a pullStackResource span, got spans: %v", spans)
+	}
+	if pullSpan.Parent().SpanID() != fetchSpan.SpanContext().SpanID() {
+		t.Errorf("Expected the pullStackResource span to be a child of the fetchDevfile span")
+	}
+}
+
+func TestTraceOciProxy(t *testing.T) {
+	router := useTestTracing(t)
+	var gotTraceParent string
+	ociServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceParent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ociServer.Close()
+
+	previousConfig, previousClient := serverConfig, ociClient
+	defer func() {
+		serverConfig, ociClient = previousConfig, previousClient
+	}()
+	config := DefaultConfig()
+	config.OCI.URL = ociServer.URL
+	if err := applyConfig(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	router.GET("/v2/*proxyPath", ServeOciProxy)
+
+	w := ocitest.NewProxyRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v2/devfile-catalog/go/manifests/1.0.0", nil)
+	req.Header.Set("traceparent", testTraceParent)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Got status code: %d, Expected: %d", w.Code, http.StatusOK)
+	}
+
+	// The OCI registry gets the trace context of the client span of the proxy
+	spans := recordedSpans(testTraceID)
+	clientSpan, found := spans["oci GET"]
+	if !found {
+		t.Fatalf("Expected a client span for the OCI registry, got spans: %v", spans)
+	}
+	wantTraceParent := "00-" + testTraceID + "-" + clientSpan.SpanContext().SpanID().String() + "-01"
+	if gotTraceParent != wantTraceParent {
+		t.Errorf("Got traceparent: %s, Expected: %s", gotTraceParent, wantTraceParent)
+	}
+}
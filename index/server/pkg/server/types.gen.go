@@ -16,19 +16,74 @@
 package server
 
 import (
+	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
 	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 	"github.com/devfile/registry-support/index/generator/schema"
 )
 
+const (
+	BasicScopes  = "basic.Scopes"
+	BearerScopes = "bearer.Scopes"
+)
+
+// Defines values for BundleFormat.
+const (
+	TarGz BundleFormat = "tar.gz"
+	Zip   BundleFormat = "zip"
+)
+
+// Defines values for Order.
+const (
+	Asc  Order = "asc"
+	Desc Order = "desc"
+)
+
+// Defines values for Sort.
+const (
+	SortDisplayName  Sort = "displayName"
+	SortLastModified Sort = "lastModified"
+	SortName         Sort = "name"
+	SortPopularity   Sort = "popularity"
+	SortVersion      Sort = "version"
+)
+
+// Defines values for SearchTypeParam.
+const (
+	SearchTypeParamAll    SearchTypeParam = "all"
+	SearchTypeParamSample SearchTypeParam = "sample"
+	SearchTypeParamStack  SearchTypeParam = "stack"
+)
+
+// Defines values for ServeDevfileSearchParamsType.
+const (
+	ServeDevfileSearchParamsTypeAll    ServeDevfileSearchParamsType = "all"
+	ServeDevfileSearchParamsTypeSample ServeDevfileSearchParamsType = "sample"
+	ServeDevfileSearchParamsTypeStack  ServeDevfileSearchParamsType = "stack"
+)
+
 // Architectures Optional list of processor architectures that the devfile supports, empty list suggests that the devfile can be used on any architecture
 type Architectures = []string
 
 // AttributeNames List of the YAML free-form attribute names
 type AttributeNames = []string
 
+// BundleFormat Archive format of a stack bundle
+type BundleFormat string
+
+// ClientStats Event counts of a client of a stack, from the `Client` header, unknown clients are counted as `other`
+type ClientStats struct {
+	Client string `json:"client"`
+
+	// Counts Number of events by event, 'list', 'view', 'download' and 'spdownload'
+	Counts EventCounts `json:"counts"`
+}
+
 // CommandGroups List of command groups defined in devfile
 type CommandGroups = []string
 
+// Cursor Opaque cursor of the page following a previous page, as given by the next link of the previous page
+type Cursor = string
+
 // Default Flag for default devfile registry entry version
 type Default = bool
 
@@ -47,6 +102,21 @@ type Devfile = v1alpha2.Devfile
 // DisplayName User readable name of devfile registry entry
 type DisplayName = string
 
+// EventCounts Number of events by event, 'list', 'view', 'download' and 'spdownload'
+type EventCounts map[string]int64
+
+// ExpandArchive Flag to expand the archive of a stack into its bundle
+type ExpandArchive = bool
+
+// Fields Comma separated field paths the index entries are projected to, e.g. `name,displayName,versions.version`
+type Fields = string
+
+// Filter Boolean filter expression over the index fields, e.g. `(language = Java OR language = Go) AND NOT deprecated`, supporting AND, OR, NOT, parentheses and the operators `=`, `!=`, `~` (contains), `<`, `<=`, `>` and `>=`
+type Filter = string
+
+// Flatten Flag to flatten a devfile with its parents and plugins
+type Flatten = bool
+
 // GitRemoteName Git repository remote name
 type GitRemoteName = string
 
@@ -79,6 +149,9 @@ type IndexParams struct {
 	// CommandGroups List of command groups defined in devfile
 	CommandGroups *CommandGroups `json:"commandGroups,omitempty"`
 
+	// Cursor Opaque cursor of the page following a previous page, as given by the next link of the previous page
+	Cursor *Cursor `json:"cursor,omitempty"`
+
 	// Default Flag for default devfile registry entry version
 	Default *Default `json:"default,omitempty"`
 
@@ -94,6 +167,12 @@ type IndexParams struct {
 	// DisplayName User readable name of devfile registry entry
 	DisplayName *DisplayName `json:"displayName,omitempty"`
 
+	// Fields Comma separated field paths the index entries are projected to, e.g. `name,displayName,versions.version`
+	Fields *Fields `json:"fields,omitempty"`
+
+	// Filter Boolean filter expression over the index fields, e.g. `(language = Java OR language = Go) AND NOT deprecated`, supporting AND, OR, NOT, parentheses and the operators `=`, `!=`, `~` (contains), `<`, `<=`, `>` and `>=`
+	Filter *Filter `json:"filter,omitempty"`
+
 	// GitRemoteName Git repository remote name
 	GitRemoteName *GitRemoteName `json:"gitRemoteName,omitempty"`
 
@@ -121,6 +200,9 @@ type IndexParams struct {
 	// Language Programming language of the devfile workspace
 	Language *Language `json:"language,omitempty"`
 
+	// Limit Maximum number of stacks and samples of a page
+	Limit *Limit `json:"limit,omitempty"`
+
 	// LinkNames Names of devfile links
 	LinkNames *LinkNames `json:"linkNames,omitempty"`
 
@@ -148,6 +230,12 @@ type IndexParams struct {
 	// Name Name of devfile registry entry
 	Name *Name `json:"name,omitempty"`
 
+	// Offset Number of stacks and samples to skip before the page
+	Offset *Offset `json:"offset,omitempty"`
+
+	// Order Sort order, ascending by default
+	Order *Order `json:"order,omitempty"`
+
 	// ProjectType Type of project the devfile supports
 	ProjectType *ProjectType `json:"projectType,omitempty"`
 
@@ -157,6 +245,9 @@ type IndexParams struct {
 	// Resources List of file resources for the devfile
 	Resources *Resources `json:"resources,omitempty"`
 
+	// Sort Field to sort the stacks and samples by, the name, the display name, the last modified date, the most recent version or the number of views and downloads of the last 30 days
+	Sort *Sort `json:"sort,omitempty"`
+
 	// StarterProjects List of starter project names
 	StarterProjects *StarterProjects `json:"starterProjects,omitempty"`
 
@@ -170,12 +261,18 @@ type IndexParams struct {
 // IndexSchema The index file schema
 type IndexSchema = schema.Schema
 
+// IndexSignature The signature of the index file
+type IndexSignature = schema.IndexSignature
+
 // Language Programming language of the devfile workspace
 type Language = string
 
 // LastModified Last modified date of a stack or sample
 type LastModified = string
 
+// Limit Maximum number of stacks and samples of a page
+type Limit = int
+
 // LinkNames Names of devfile links
 type LinkNames = []string
 
@@ -185,6 +282,12 @@ type Links = []Url
 // Name Name of devfile registry entry
 type Name = string
 
+// Offset Number of stacks and samples to skip before the page
+type Offset = int
+
+// Order Sort order, ascending by default
+type Order string
+
 // ProjectType Type of project the devfile supports
 type ProjectType = string
 
@@ -197,9 +300,58 @@ type Resources = []string
 // SchemaVersion Devfile schema version number
 type SchemaVersion = string
 
+// SearchHit A stack or sample matching a search query
+type SearchHit struct {
+	// Entry The index schema of the stack or sample
+	Entry schema.Schema `json:"entry"`
+
+	// Highlights Snippets of the matched fields by field name, the matched words are enclosed in <em> tags
+	Highlights map[string]string `json:"highlights"`
+
+	// Score The relevance of the stack or sample, hits are sorted by decreasing score
+	Score float64 `json:"score"`
+}
+
+// Sort Field to sort the stacks and samples by, the name, the display name, the last modified date, the most recent version or the number of views and downloads of the last 30 days
+type Sort string
+
+// StackStats Event counts of a stack
+type StackStats struct {
+	Clients []ClientStats `json:"clients"`
+
+	// Counts Number of events by event, 'list', 'view', 'download' and 'spdownload'
+	Counts EventCounts `json:"counts"`
+	Name   string      `json:"name"`
+
+	// Total Number of view, download and starter project download events of the stack
+	Total    int64          `json:"total"`
+	Versions []VersionStats `json:"versions"`
+}
+
+// StackUpdate StackUpdate defines the stack information changed through the publish API.
+type StackUpdate struct {
+	// DefaultVersion The version to make the default version of the stack.
+	DefaultVersion string `json:"defaultVersion"`
+}
+
 // StarterProjects List of starter project names
 type StarterProjects = []string
 
+// Stats Download statistics of the registry over a time window
+type Stats struct {
+	// Counts Number of events by event, 'list', 'view', 'download' and 'spdownload'
+	Counts EventCounts `json:"counts"`
+
+	// Since First day of the time window, unset for the whole history
+	Since *openapi_types.Date `json:"since,omitempty"`
+
+	// Stacks Stacks sorted by decreasing total
+	Stacks []StackStats `json:"stacks"`
+
+	// Until Last day of the time window
+	Until openapi_types.Date `json:"until"`
+}
+
 // Tags List of devfile subject tags
 type Tags = []string
 
@@ -209,15 +361,28 @@ type Url = string
 // Version Devfile registry entry version number
 type Version = string
 
+// VersionStats Event counts of a stack version
+type VersionStats struct {
+	// Counts Number of events by event, 'list', 'view', 'download' and 'spdownload'
+	Counts  EventCounts `json:"counts"`
+	Version string      `json:"version"`
+}
+
 // ArchParam Optional list of processor architectures that the devfile supports, empty list suggests that the devfile can be used on any architecture
 type ArchParam = Architectures
 
 // AttributeNamesParam defines model for attributeNamesParam.
 type AttributeNamesParam = []string
 
+// BundleFormatParam Archive format of a stack bundle
+type BundleFormatParam = BundleFormat
+
 // CommandGroupsParam List of command groups defined in devfile
 type CommandGroupsParam = CommandGroups
 
+// CursorParam Opaque cursor of the page following a previous page, as given by the next link of the previous page
+type CursorParam = Cursor
+
 // DefaultParam Flag for default devfile registry entry version
 type DefaultParam = Default
 
@@ -233,6 +398,18 @@ type DescriptionParam = Description
 // DisplayNameParam User readable name of devfile registry entry
 type DisplayNameParam = DisplayName
 
+// ExpandArchiveParam Flag to expand the archive of a stack into its bundle
+type ExpandArchiveParam = ExpandArchive
+
+// FieldsParam Comma separated field paths the index entries are projected to, e.g. `name,displayName,versions.version`
+type FieldsParam = Fields
+
+// FilterParam Boolean filter expression over the index fields, e.g. `(language = Java OR language = Go) AND NOT deprecated`, supporting AND, OR, NOT, parentheses and the operators `=`, `!=`, `~` (contains), `<`, `<=`, `>` and `>=`
+type FilterParam = Filter
+
+// FlattenParam Flag to flatten a devfile with its parents and plugins
+type FlattenParam = Flatten
+
 // GitRemoteNameParam Git repository remote name
 type GitRemoteNameParam = GitRemoteName
 
@@ -260,6 +437,9 @@ type IconUriParam = IconUri
 // LanguageParam Programming language of the devfile workspace
 type LanguageParam = Language
 
+// LimitParam Maximum number of stacks and samples of a page
+type LimitParam = Limit
+
 // LinkNamesParam Names of devfile links
 type LinkNamesParam = LinkNames
 
@@ -287,18 +467,42 @@ type MinVersionParam = Version
 // NameParam Name of devfile registry entry
 type NameParam = Name
 
+// OffsetParam Number of stacks and samples to skip before the page
+type OffsetParam = Offset
+
+// OrderParam Sort order, ascending by default
+type OrderParam = Order
+
 // ProjectTypeParam Type of project the devfile supports
 type ProjectTypeParam = ProjectType
 
 // ProviderParam Name of provider of the devfile registry entry
 type ProviderParam = Provider
 
+// QueryParam defines model for queryParam.
+type QueryParam = string
+
 // ResourcesParam List of file resources for the devfile
 type ResourcesParam = Resources
 
+// SchemaVersionParam Devfile schema version number
+type SchemaVersionParam = SchemaVersion
+
+// SearchTypeParam defines model for searchTypeParam.
+type SearchTypeParam string
+
+// SortParam Field to sort the stacks and samples by, the name, the display name, the last modified date, the most recent version or the number of views and downloads of the last 30 days
+type SortParam = Sort
+
 // StarterProjectsParam List of starter project names
 type StarterProjectsParam = StarterProjects
 
+// StatsStackParam defines model for statsStackParam.
+type StatsStackParam = string
+
+// StatsWindowParam defines model for statsWindowParam.
+type StatsWindowParam = string
+
 // SupportUrlParam Url field type
 type SupportUrlParam = Url
 
@@ -319,9 +523,17 @@ type DevfileNotFoundResponse struct {
 // DevfileResponse Describes the structure of a cloud-native devworkspace and development environment.
 type DevfileResponse = Devfile
 
+// ForbiddenResponse defines model for forbiddenResponse.
+type ForbiddenResponse struct {
+	Status *string `json:"status,omitempty"`
+}
+
 // HealthResponse defines model for healthResponse.
 type HealthResponse struct {
 	Message string `json:"message"`
+
+	// UnavailableVersions Stack versions which failed to be pushed to the storage, as '<stack>:<version>'
+	UnavailableVersions *[]string `json:"unavailableVersions,omitempty"`
 }
 
 // IndexResponse The index file schema
@@ -332,6 +544,42 @@ type MethodNotAllowedResponse struct {
 	Message string `json:"message"`
 }
 
+// NotAcceptableResponse defines model for notAcceptableResponse.
+type NotAcceptableResponse struct {
+	Error  *string `json:"error,omitempty"`
+	Status *string `json:"status,omitempty"`
+}
+
+// PublishErrorResponse defines model for publishErrorResponse.
+type PublishErrorResponse struct {
+	Error  *string `json:"error,omitempty"`
+	Status *string `json:"status,omitempty"`
+}
+
+// SearchErrorResponse defines model for searchErrorResponse.
+type SearchErrorResponse struct {
+	Status *string `json:"status,omitempty"`
+}
+
+// SearchResponse defines model for searchResponse.
+type SearchResponse = []SearchHit
+
+// SignatureResponse The signature of the index file
+type SignatureResponse = IndexSignature
+
+// StatsErrorResponse defines model for statsErrorResponse.
+type StatsErrorResponse struct {
+	Status *string `json:"status,omitempty"`
+}
+
+// StatsResponse Download statistics of the registry over a time window
+type StatsResponse = Stats
+
+// UnauthorizedResponse defines model for unauthorizedResponse.
+type UnauthorizedResponse struct {
+	Status *string `json:"status,omitempty"`
+}
+
 // V2IndexResponse defines model for v2IndexResponse.
 type V2IndexResponse = schema.Schema
 
@@ -342,6 +590,12 @@ type ServeDevfileParams struct {
 
 	// MaxSchemaVersion The maximum devfile schema version
 	MaxSchemaVersion *MaxSchemaVersionParam `form:"maxSchemaVersion,omitempty" json:"maxSchemaVersion,omitempty"`
+
+	// Flatten Boolean to resolve the parents and plugins of the devfile into a single flattened devfile
+	Flatten *FlattenParam `form:"flatten,omitempty" json:"flatten,omitempty"`
+
+	// SchemaVersion The older devfile schema version to convert the devfile to, e.g. 2.1.0
+	SchemaVersion *SchemaVersionParam `form:"schemaVersion,omitempty" json:"schemaVersion,omitempty"`
 }
 
 // ServeDevfileStarterProjectParams defines parameters for ServeDevfileStarterProject.
@@ -360,6 +614,27 @@ type ServeDevfileWithVersionParams struct {
 
 	// MaxSchemaVersion The maximum devfile schema version
 	MaxSchemaVersion *MaxSchemaVersionParam `form:"maxSchemaVersion,omitempty" json:"maxSchemaVersion,omitempty"`
+
+	// Flatten Boolean to resolve the parents and plugins of the devfile into a single flattened devfile
+	Flatten *FlattenParam `form:"flatten,omitempty" json:"flatten,omitempty"`
+
+	// SchemaVersion The older devfile schema version to convert the devfile to, e.g. 2.1.0
+	SchemaVersion *SchemaVersionParam `form:"schemaVersion,omitempty" json:"schemaVersion,omitempty"`
+}
+
+// PutDevfileWithVersionMultipartBody defines parameters for PutDevfileWithVersion.
+type PutDevfileWithVersionMultipartBody struct {
+	// File The resources of the stack version, a stack.yaml file updates the stack information.
+	File []openapi_types.File `json:"file"`
+}
+
+// ServeStackBundleParams defines parameters for ServeStackBundle.
+type ServeStackBundleParams struct {
+	// Format Archive format of the bundle, `zip` by default
+	Format *BundleFormatParam `form:"format,omitempty" json:"format,omitempty"`
+
+	// ExpandArchive Boolean to expand the entries of `archive.tar` into the bundle instead of including it as is, entries named like a stack resource are placed under an `archive` folder
+	ExpandArchive *ExpandArchiveParam `form:"expandArchive,omitempty" json:"expandArchive,omitempty"`
 }
 
 // ServeDevfileStarterProjectWithVersionParams defines parameters for ServeDevfileStarterProjectWithVersion.
@@ -407,6 +682,9 @@ type ServeDevfileIndexV1Params struct {
 	// Deprecated Boolean to filter stacks if they are deprecated or not
 	Deprecated *DeprecatedParam `form:"deprecated,omitempty" json:"deprecated,omitempty"`
 
+	// Filter Boolean filter expression to filter stacks and samples
+	Filter *FilterParam `form:"filter,omitempty" json:"filter,omitempty"`
+
 	// Resources Collection of search strings to filter stacks by their
 	// resource files
 	Resources *ResourcesParam `form:"resources,omitempty" json:"resources,omitempty"`
@@ -487,6 +765,9 @@ type ServeDevfileIndexV1WithTypeParams struct {
 	// Deprecated Boolean to filter stacks if they are deprecated or not
 	Deprecated *DeprecatedParam `form:"deprecated,omitempty" json:"deprecated,omitempty"`
 
+	// Filter Boolean filter expression to filter stacks and samples
+	Filter *FilterParam `form:"filter,omitempty" json:"filter,omitempty"`
+
 	// Resources Collection of search strings to filter stacks by their
 	// resource files
 	Resources *ResourcesParam `form:"resources,omitempty" json:"resources,omitempty"`
@@ -579,6 +860,9 @@ type ServeDevfileIndexV2Params struct {
 	// Deprecated Boolean to filter stacks if they are deprecated or not
 	Deprecated *DeprecatedParam `form:"deprecated,omitempty" json:"deprecated,omitempty"`
 
+	// Filter Boolean filter expression to filter stacks and samples
+	Filter *FilterParam `form:"filter,omitempty" json:"filter,omitempty"`
+
 	// Default Boolean to filter stacks if they are default or not
 	Default *DefaultParam `form:"default,omitempty" json:"default,omitempty"`
 
@@ -638,6 +922,24 @@ type ServeDevfileIndexV2Params struct {
 
 	// MaxLastModified The maximum (latest) last modified date of a stack or sample
 	MaxLastModified *MaxLastModifiedParam `form:"maxLastModified,omitempty" json:"maxLastModified,omitempty"`
+
+	// Sort The field to sort the stacks and samples by
+	Sort *SortParam `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Order The sort order, ascending by default
+	Order *OrderParam `form:"order,omitempty" json:"order,omitempty"`
+
+	// Limit The maximum number of stacks and samples to return, all are returned if unset
+	Limit *LimitParam `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset The number of stacks and samples to skip, cannot be used with a cursor
+	Offset *OffsetParam `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Cursor The cursor of the page to return, cannot be used with an offset
+	Cursor *CursorParam `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Fields Field paths to include in the response, all fields are included by default
+	Fields *FieldsParam `form:"fields,omitempty" json:"fields,omitempty"`
 }
 
 // ServeDevfileIndexV2WithTypeParams defines parameters for ServeDevfileIndexV2WithType.
@@ -688,6 +990,9 @@ type ServeDevfileIndexV2WithTypeParams struct {
 	// Deprecated Boolean to filter stacks if they are deprecated or not
 	Deprecated *DeprecatedParam `form:"deprecated,omitempty" json:"deprecated,omitempty"`
 
+	// Filter Boolean filter expression to filter stacks and samples
+	Filter *FilterParam `form:"filter,omitempty" json:"filter,omitempty"`
+
 	// Default Boolean to filter stacks if they are default or not
 	Default *DefaultParam `form:"default,omitempty" json:"default,omitempty"`
 
@@ -747,4 +1052,164 @@ type ServeDevfileIndexV2WithTypeParams struct {
 
 	// MaxLastModified The maximum (latest) last modified date of a stack or sample
 	MaxLastModified *MaxLastModifiedParam `form:"maxLastModified,omitempty" json:"maxLastModified,omitempty"`
+
+	// Sort The field to sort the stacks and samples by
+	Sort *SortParam `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Order The sort order, ascending by default
+	Order *OrderParam `form:"order,omitempty" json:"order,omitempty"`
+
+	// Limit The maximum number of stacks and samples to return, all are returned if unset
+	Limit *LimitParam `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset The number of stacks and samples to skip, cannot be used with a cursor
+	Offset *OffsetParam `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Cursor The cursor of the page to return, cannot be used with an offset
+	Cursor *CursorParam `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Fields Field paths to include in the response, all fields are included by default
+	Fields *FieldsParam `form:"fields,omitempty" json:"fields,omitempty"`
 }
+
+// ServeDevfileSearchParams defines parameters for ServeDevfileSearch.
+type ServeDevfileSearchParams struct {
+	// Q The search query
+	Q QueryParam `form:"q" json:"q"`
+
+	// Type The type of devfiles to search, all types by default
+	Type *ServeDevfileSearchParamsType `form:"type,omitempty" json:"type,omitempty"`
+
+	// Name Search string to filter stacks by their name
+	Name *NameParam `form:"name,omitempty" json:"name,omitempty"`
+
+	// DisplayName Search string to filter stacks by their display names
+	DisplayName *DisplayNameParam `form:"displayName,omitempty" json:"displayName,omitempty"`
+
+	// Description Search string to filter stacks by the description text
+	Description *DescriptionParam `form:"description,omitempty" json:"description,omitempty"`
+
+	// AttributeNames Collection of search strings to filter stacks by the names of
+	// defined free-form attributes
+	AttributeNames *AttributeNamesParam `form:"attributeNames,omitempty" json:"attributeNames,omitempty"`
+
+	// Tags Collection of search strings to filter stacks by their tags
+	Tags *TagsParam `form:"tags,omitempty" json:"tags,omitempty"`
+
+	// Arch Collection of search strings to filter stacks by their architectures
+	Arch *ArchParam `form:"arch,omitempty" json:"arch,omitempty"`
+
+	// Icon Toggle on encoding content passed
+	Icon *IconParam `form:"icon,omitempty" json:"icon,omitempty"`
+
+	// IconUri Search string to filter stacks by their icon uri
+	IconUri *IconUriParam `form:"iconUri,omitempty" json:"iconUri,omitempty"`
+
+	// ProjectType Search string to filter stacks by their project type
+	ProjectType *ProjectTypeParam `form:"projectType,omitempty" json:"projectType,omitempty"`
+
+	// Language Search string to filter stacks by their programming language
+	Language *LanguageParam `form:"language,omitempty" json:"language,omitempty"`
+
+	// MinVersion The minimum stack version
+	MinVersion *MinVersionParam `form:"minVersion,omitempty" json:"minVersion,omitempty"`
+
+	// MaxVersion The maximum stack version
+	MaxVersion *MaxVersionParam `form:"maxVersion,omitempty" json:"maxVersion,omitempty"`
+
+	// MinSchemaVersion The minimum devfile schema version
+	MinSchemaVersion *MinSchemaVersionParam `form:"minSchemaVersion,omitempty" json:"minSchemaVersion,omitempty"`
+
+	// MaxSchemaVersion The maximum devfile schema version
+	MaxSchemaVersion *MaxSchemaVersionParam `form:"maxSchemaVersion,omitempty" json:"maxSchemaVersion,omitempty"`
+
+	// Deprecated Boolean to filter stacks if they are deprecated or not
+	Deprecated *DeprecatedParam `form:"deprecated,omitempty" json:"deprecated,omitempty"`
+
+	// Filter Boolean filter expression to filter stacks and samples
+	Filter *FilterParam `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Default Boolean to filter stacks if they are default or not
+	Default *DefaultParam `form:"default,omitempty" json:"default,omitempty"`
+
+	// Resources Collection of search strings to filter stacks by their
+	// resource files
+	Resources *ResourcesParam `form:"resources,omitempty" json:"resources,omitempty"`
+
+	// StarterProjects Collection of search strings to filter stacks by the names
+	// of the starter projects
+	StarterProjects *StarterProjectsParam `form:"starterProjects,omitempty" json:"starterProjects,omitempty"`
+
+	// LinkNames Collection of search strings to filter stacks by the names
+	// of the link sources
+	LinkNames *LinkNamesParam `form:"linkNames,omitempty" json:"linkNames,omitempty"`
+
+	// Links Collection of search strings to filter stacks by their link
+	// sources
+	Links *LinksParam `form:"links,omitempty" json:"links,omitempty"`
+
+	// CommandGroups Collection of search strings to filter stacks by their present command
+	// groups
+	CommandGroups *CommandGroupsParam `form:"commandGroups,omitempty" json:"commandGroups,omitempty"`
+
+	// DeploymentScopes Collection of search strings to filter stacks by their present deployment
+	// scopes
+	DeploymentScopes *DeploymentScopesParam `form:"deploymentScopes,omitempty" json:"deploymentScopes,omitempty"`
+
+	// GitRemoteNames Collection of search strings to filter stacks by the names of
+	// the git remotes
+	GitRemoteNames *GitRemoteNamesParam `form:"gitRemoteNames,omitempty" json:"gitRemoteNames,omitempty"`
+
+	// GitRemotes Collection of search strings to filter stacks by the URIs of
+	// the git remotes
+	GitRemotes *GitRemotesParam `form:"gitRemotes,omitempty" json:"gitRemotes,omitempty"`
+
+	// GitUrl Search string to filter stacks by their git urls
+	GitUrl *GitUrlParam `form:"gitUrl,omitempty" json:"gitUrl,omitempty"`
+
+	// GitRemoteName Search string to filter stacks by their git remote name
+	GitRemoteName *GitRemoteNameParam `form:"gitRemoteName,omitempty" json:"gitRemoteName,omitempty"`
+
+	// GitSubDir Search string to filter stacks by their target subdirectory
+	// of the git repository
+	GitSubDir *GitSubDirParam `form:"gitSubDir,omitempty" json:"gitSubDir,omitempty"`
+
+	// GitRevision Search string to filter stacks by their git revision
+	GitRevision *GitRevisionParam `form:"gitRevision,omitempty" json:"gitRevision,omitempty"`
+
+	// Provider Search string to filter stacks by the stack provider
+	Provider *ProviderParam `form:"provider,omitempty" json:"provider,omitempty"`
+
+	// SupportUrl Search string to filter stacks by their given support url
+	SupportUrl *SupportUrlParam `form:"supportUrl,omitempty" json:"supportUrl,omitempty"`
+
+	// MinLastModified The minimum (earliest) last modified date of a stack or sample
+	MinLastModified *MinLastModifiedParam `form:"minLastModified,omitempty" json:"minLastModified,omitempty"`
+
+	// MaxLastModified The maximum (latest) last modified date of a stack or sample
+	MaxLastModified *MaxLastModifiedParam `form:"maxLastModified,omitempty" json:"maxLastModified,omitempty"`
+
+	// Limit The maximum number of stacks and samples to return, all are returned if unset
+	Limit *LimitParam `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset The number of stacks and samples to skip, cannot be used with a cursor
+	Offset *OffsetParam `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ServeDevfileSearchParamsType defines parameters for ServeDevfileSearch.
+type ServeDevfileSearchParamsType string
+
+// ServeDevfileStatsParams defines parameters for ServeDevfileStats.
+type ServeDevfileStatsParams struct {
+	// Window Time window of the statistics ending today, a number of days such as `7d`, a duration such as `24h` or `all`, `30d` by default
+	Window *StatsWindowParam `form:"window,omitempty" json:"window,omitempty"`
+
+	// Stack Name of the stack to get the statistics of, all stacks by default
+	Stack *StatsStackParam `form:"stack,omitempty" json:"stack,omitempty"`
+}
+
+// PutDevfileJSONRequestBody defines body for PutDevfile for application/json ContentType.
+type PutDevfileJSONRequestBody = StackUpdate
+
+// PutDevfileWithVersionMultipartRequestBody defines body for PutDevfileWithVersion for multipart/form-data ContentType.
+type PutDevfileWithVersionMultipartRequestBody PutDevfileWithVersionMultipartBody
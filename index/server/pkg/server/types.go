@@ -30,6 +30,7 @@ func (params *ServeDevfileIndexV2Params) toIndexParams() IndexParams {
 		MinSchemaVersion: params.MinSchemaVersion,
 		MaxSchemaVersion: params.MaxSchemaVersion,
 		Deprecated:       params.Deprecated,
+		Filter:           params.Filter,
 		Default:          params.Default,
 		Resources:        params.Resources,
 		StarterProjects:  params.StarterProjects,
@@ -47,6 +48,12 @@ func (params *ServeDevfileIndexV2Params) toIndexParams() IndexParams {
 		SupportUrl:       params.SupportUrl,
 		MinLastModified:  params.MinLastModified,
 		MaxLastModified:  params.MaxLastModified,
+		Sort:             params.Sort,
+		Order:            params.Order,
+		Limit:            params.Limit,
+		Offset:           params.Offset,
+		Cursor:           params.Cursor,
+		Fields:           params.Fields,
 	}
 }
 
@@ -67,6 +74,7 @@ func (params *ServeDevfileIndexV2WithTypeParams) toIndexParams() IndexParams {
 		MinSchemaVersion: params.MinSchemaVersion,
 		MaxSchemaVersion: params.MaxSchemaVersion,
 		Deprecated:       params.Deprecated,
+		Filter:           params.Filter,
 		Default:          params.Default,
 		Resources:        params.Resources,
 		StarterProjects:  params.StarterProjects,
@@ -84,6 +92,52 @@ func (params *ServeDevfileIndexV2WithTypeParams) toIndexParams() IndexParams {
 		SupportUrl:       params.SupportUrl,
 		MinLastModified:  params.MinLastModified,
 		MaxLastModified:  params.MaxLastModified,
+		Sort:             params.Sort,
+		Order:            params.Order,
+		Limit:            params.Limit,
+		Offset:           params.Offset,
+		Cursor:           params.Cursor,
+		Fields:           params.Fields,
+	}
+}
+
+func (params *ServeDevfileSearchParams) toIndexParams() IndexParams {
+	return IndexParams{
+		Name:             params.Name,
+		DisplayName:      params.DisplayName,
+		Description:      params.Description,
+		AttributeNames:   params.AttributeNames,
+		Tags:             params.Tags,
+		Icon:             params.Icon,
+		IconUri:          params.IconUri,
+		Arch:             params.Arch,
+		ProjectType:      params.ProjectType,
+		Language:         params.Language,
+		MinVersion:       params.MinVersion,
+		MaxVersion:       params.MaxVersion,
+		MinSchemaVersion: params.MinSchemaVersion,
+		MaxSchemaVersion: params.MaxSchemaVersion,
+		Deprecated:       params.Deprecated,
+		Filter:           params.Filter,
+		Default:          params.Default,
+		Resources:        params.Resources,
+		StarterProjects:  params.StarterProjects,
+		LinkNames:        params.LinkNames,
+		Links:            params.Links,
+		CommandGroups:    params.CommandGroups,
+		DeploymentScopes: params.DeploymentScopes,
+		GitRemoteNames:   params.GitRemoteNames,
+		GitRemotes:       params.GitRemotes,
+		GitUrl:           params.GitUrl,
+		GitRemoteName:    params.GitRemoteName,
+		GitSubDir:        params.GitSubDir,
+		GitRevision:      params.GitRevision,
+		Provider:         params.Provider,
+		SupportUrl:       params.SupportUrl,
+		MinLastModified:  params.MinLastModified,
+		MaxLastModified:  params.MaxLastModified,
+		Limit:            params.Limit,
+		Offset:           params.Offset,
 	}
 }
 
@@ -100,6 +154,7 @@ func (params *ServeDevfileIndexV1Params) toIndexParams() IndexParams {
 		ProjectType:     params.ProjectType,
 		Language:        params.Language,
 		Deprecated:      params.Deprecated,
+		Filter:          params.Filter,
 		Resources:       params.Resources,
 		StarterProjects: params.StarterProjects,
 		LinkNames:       params.LinkNames,
@@ -128,6 +183,7 @@ func (params *ServeDevfileIndexV1WithTypeParams) toIndexParams() IndexParams {
 		ProjectType:     params.ProjectType,
 		Language:        params.Language,
 		Deprecated:      params.Deprecated,
+		Filter:          params.Filter,
 		Resources:       params.Resources,
 		StarterProjects: params.StarterProjects,
 		LinkNames:       params.LinkNames,
@@ -0,0 +1,492 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	versionpkg "github.com/hashicorp/go-version"
+	"github.com/mohae/deepcopy"
+)
+
+const (
+	// ExpressionFieldType field of the devfile type in filter expressions, 'stack' or 'sample'
+	ExpressionFieldType = "type"
+	// ExpressionFieldDeprecated field of the deprecation in filter expressions, can be used as a boolean
+	ExpressionFieldDeprecated = "deprecated"
+)
+
+// ExpressionError error of a filter expression that cannot be parsed
+type ExpressionError struct {
+	// Position of the error in the expression, starts at 1
+	Position int
+	// Message describing the error
+	Message string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("invalid filter expression at position %d: %s", e.Position, e.Message)
+}
+
+// FilterExpression a parsed filter expression matching index entries
+type FilterExpression interface {
+	// Matches checks if the index entry matches the expression
+	Matches(s *indexSchema.Schema, v1Index bool) bool
+}
+
+type andExpression struct {
+	left, right FilterExpression
+}
+
+func (e andExpression) Matches(s *indexSchema.Schema, v1Index bool) bool {
+	return e.left.Matches(s, v1Index) && e.right.Matches(s, v1Index)
+}
+
+type orExpression struct {
+	left, right FilterExpression
+}
+
+func (e orExpression) Matches(s *indexSchema.Schema, v1Index bool) bool {
+	return e.left.Matches(s, v1Index) || e.right.Matches(s, v1Index)
+}
+
+type notExpression struct {
+	operand FilterExpression
+}
+
+func (e notExpression) Matches(s *indexSchema.Schema, v1Index bool) bool {
+	return !e.operand.Matches(s, v1Index)
+}
+
+// comparison compares the values of a field with a requested value, it matches if any of the
+// values of the field satisfies the operator
+type comparison struct {
+	field    string
+	operator string
+	value    string
+	version  *versionpkg.Version
+	date     time.Time
+}
+
+func (e comparison) Matches(s *indexSchema.Schema, v1Index bool) bool {
+	if e.operator == "!=" {
+		equal := e
+		equal.operator = "="
+		return !equal.Matches(s, v1Index)
+	}
+
+	for _, fieldValue := range expressionFieldValues(s, e.field, v1Index) {
+		if e.matchValue(fieldValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchValue checks if a single value of the field satisfies the operator
+func (e comparison) matchValue(fieldValue string) bool {
+	if e.operator == "~" {
+		return fuzzyMatch(fieldValue, e.value)
+	}
+
+	var cmp int
+	switch {
+	case e.version != nil:
+		fieldVersion, err := versionpkg.NewVersion(fieldValue)
+		if err != nil {
+			return false
+		}
+		cmp = fieldVersion.Compare(e.version)
+	case e.field == ParamLastModified:
+		fieldDate, err := ConvertRFC3339Date(&fieldValue)
+		if err != nil {
+			return false
+		}
+		cmp = fieldDate.Truncate(truncateOptions).Compare(e.date.Truncate(truncateOptions))
+	default:
+		return preProcessString(fieldValue) == preProcessString(e.value)
+	}
+
+	switch e.operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// deprecatedExpression matches deprecated index entries, used for the bare 'deprecated' field
+type deprecatedExpression struct{}
+
+func (deprecatedExpression) Matches(s *indexSchema.Schema, v1Index bool) bool {
+	return isDeprecated(s, v1Index)
+}
+
+// IsExpressionField checks if the name is a field of filter expressions
+func IsExpressionField(name string) bool {
+	return IsFieldParameter(name) || IsArrayParameter(name) || name == ParamLastModified ||
+		name == ExpressionFieldType || name == ExpressionFieldDeprecated
+}
+
+// isOrderedField checks if the field supports the range operators
+func isOrderedField(name string) bool {
+	return name == ParamVersion || name == ParamSchemaVersion || name == ParamLastModified
+}
+
+// expressionFieldValues gets the values of a field for an index entry, including the values of each
+// version for the v2 index
+func expressionFieldValues(s *indexSchema.Schema, field string, v1Index bool) []string {
+	values := []string{}
+
+	switch field {
+	case ExpressionFieldType:
+		return append(values, string(s.Type))
+	case ExpressionFieldDeprecated:
+		return append(values, fmt.Sprintf("%v", isDeprecated(s, v1Index)))
+	case ParamLastModified:
+		for _, version := range s.Versions {
+			values = append(values, version.LastModified)
+		}
+		return values
+	}
+
+	if options, found := strFieldOptions(field, v1Index); found {
+		if options.GetFromIndexField != nil {
+			values = append(values, options.GetFromIndexField(s))
+		}
+		if !v1Index && options.GetFromVersionField != nil {
+			for versionIndex := range s.Versions {
+				values = append(values, options.GetFromVersionField(&s.Versions[versionIndex]))
+			}
+		}
+	} else if options, found := strArrayFieldOptions(field, v1Index); found {
+		if options.GetFromIndexField != nil {
+			values = append(values, options.GetFromIndexField(s)...)
+		}
+		if !v1Index && options.GetFromVersionField != nil {
+			for versionIndex := range s.Versions {
+				values = append(values, options.GetFromVersionField(&s.Versions[versionIndex])...)
+			}
+		}
+	}
+
+	nonEmpty := []string{}
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	return nonEmpty
+}
+
+// FilterDevfileExpression filters devfiles matching a parsed filter expression
+func FilterDevfileExpression(index []indexSchema.Schema, expression FilterExpression, v1Index bool) FilterResult {
+	filteredIndex := deepcopy.Copy(index).([]indexSchema.Schema)
+
+	for i := 0; i < len(filteredIndex); i++ {
+		if !expression.Matches(&filteredIndex[i], v1Index) {
+			filterOut(&filteredIndex, &i)
+		}
+	}
+
+	return FilterResult{
+		Name:  "Expression_Filter",
+		Index: filteredIndex,
+	}
+}
+
+/* Expression parsing */
+
+const (
+	tokenEnd = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenOpenParen
+	tokenCloseParen
+	tokenComma
+)
+
+type expressionToken struct {
+	kind  int
+	text  string
+	start int
+}
+
+// isKeyword checks if the token is the unquoted keyword, keywords are case insensitive
+func (t expressionToken) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// describe describes the token for error messages
+func (t expressionToken) describe() string {
+	if t.kind == tokenEnd {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// isWordRune checks if the rune can be part of an unquoted word
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(`()=!~<>,"'`, r)
+}
+
+// tokenizeExpression splits a filter expression into tokens, token positions start at 1
+func tokenizeExpression(expression string) ([]expressionToken, error) {
+	runes := []rune(expression)
+	tokens := []expressionToken{}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, expressionToken{kind: tokenOpenParen, text: "(", start: start})
+			i++
+		case r == ')':
+			tokens = append(tokens, expressionToken{kind: tokenCloseParen, text: ")", start: start})
+			i++
+		case r == ',':
+			tokens = append(tokens, expressionToken{kind: tokenComma, text: ",", start: start})
+			i++
+		case r == '=' || r == '~':
+			tokens = append(tokens, expressionToken{kind: tokenOperator, text: string(r), start: start})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			operator := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				operator += "="
+			} else if r == '!' {
+				return nil, &ExpressionError{Position: start, Message: "unexpected character '!', expected '!='"}
+			}
+			tokens = append(tokens, expressionToken{kind: tokenOperator, text: operator, start: start})
+			i += len(operator)
+		case r == '"' || r == '\'':
+			var value strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					value.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == r {
+					closed = true
+					i++
+					break
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, &ExpressionError{Position: start, Message: "unterminated quoted string"}
+			}
+			tokens = append(tokens, expressionToken{kind: tokenString, text: value.String(), start: start})
+		default:
+			end := i
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, expressionToken{kind: tokenWord, text: string(runes[i:end]), start: start})
+			i = end
+		}
+	}
+
+	return append(tokens, expressionToken{kind: tokenEnd, start: len(runes) + 1}), nil
+}
+
+// expressionParser recursive descent parser of filter expressions
+type expressionParser struct {
+	tokens []expressionToken
+	pos    int
+}
+
+func (p *expressionParser) peek() expressionToken {
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) next() expressionToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEnd {
+		p.pos++
+	}
+	return token
+}
+
+// ParseFilterExpression parses a filter expression of the form
+//
+//	expression := and { OR and }
+//	and        := not { ( AND | "," ) not }
+//	not        := NOT not | primary
+//	primary    := "(" expression ")" | field operator value | deprecated
+//
+// where operators are '=', '!=', '~' (or 'contains'), and the range operators '<', '<=', '>' and '>='
+// on the version, schemaVersion and lastModified fields. Returns an *ExpressionError if the expression
+// cannot be parsed.
+func ParseFilterExpression(expression string) (FilterExpression, error) {
+	tokens, err := tokenizeExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	if tokens[0].kind == tokenEnd {
+		return nil, &ExpressionError{Position: 1, Message: "empty expression"}
+	}
+
+	parser := &expressionParser{tokens: tokens}
+	parsed, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != tokenEnd {
+		return nil, &ExpressionError{Position: token.start, Message: fmt.Sprintf("unexpected %s", token.describe())}
+	}
+	return parsed, nil
+}
+
+func (p *expressionParser) parseOr() (FilterExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseAnd() (FilterExpression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("and") || p.peek().kind == tokenComma {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseNot() (FilterExpression, error) {
+	if p.peek().isKeyword("not") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *expressionParser) parsePrimary() (FilterExpression, error) {
+	token := p.next()
+
+	switch {
+	case token.kind == tokenOpenParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenCloseParen {
+			return nil, &ExpressionError{
+				Position: closing.start,
+				Message:  fmt.Sprintf("expected ')' to close '(' at position %d, found %s", token.start, closing.describe()),
+			}
+		}
+		return inner, nil
+	case token.kind != tokenWord || token.isKeyword("and") || token.isKeyword("or") || token.isKeyword("not"):
+		return nil, &ExpressionError{Position: token.start, Message: fmt.Sprintf("expected a field, found %s", token.describe())}
+	case !IsExpressionField(token.text):
+		return nil, &ExpressionError{Position: token.start, Message: fmt.Sprintf("unknown field %q", token.text)}
+	}
+
+	field := token.text
+	operator := p.peek()
+	if operator.isKeyword("contains") {
+		operator.text = "~"
+	} else if operator.kind != tokenOperator {
+		if field == ExpressionFieldDeprecated {
+			return deprecatedExpression{}, nil
+		}
+		return nil, &ExpressionError{
+			Position: operator.start,
+			Message:  fmt.Sprintf("expected an operator after field %q, found %s", field, operator.describe()),
+		}
+	}
+	p.next()
+
+	isRange := strings.HasPrefix(operator.text, "<") || strings.HasPrefix(operator.text, ">")
+	if isRange && !isOrderedField(field) {
+		return nil, &ExpressionError{
+			Position: operator.start,
+			Message: fmt.Sprintf("operator %q is only supported on the %s, %s and %s fields", operator.text,
+				ParamVersion, ParamSchemaVersion, ParamLastModified),
+		}
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, &ExpressionError{
+			Position: value.start,
+			Message:  fmt.Sprintf("expected a value after operator %q, found %s", operator.text, value.describe()),
+		}
+	}
+
+	result := comparison{field: field, operator: operator.text, value: value.text}
+	if operator.text != "~" {
+		switch field {
+		case ParamVersion, ParamSchemaVersion:
+			version, err := versionpkg.NewVersion(value.text)
+			if err != nil {
+				return nil, &ExpressionError{Position: value.start, Message: fmt.Sprintf("invalid version %q", value.text)}
+			}
+			result.version = version
+		case ParamLastModified:
+			date, err := ConvertNonRFC3339Date(value.text)
+			if err != nil {
+				return nil, &ExpressionError{
+					Position: value.start,
+					Message:  fmt.Sprintf("invalid date %q, expected the format YYYY-MM-DD", value.text),
+				}
+			}
+			result.date = date
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,275 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+// expressionTestIndex index used by the filter expression tests
+var expressionTestIndex = []indexSchema.Schema{
+	{
+		Name:        "go",
+		DisplayName: "Go Runtime",
+		Language:    "Go",
+		Type:        indexSchema.StackDevfileType,
+		Tags:        []string{"Go", "Testing"},
+		Versions: []indexSchema.Version{
+			{
+				Version:       "1.0.0",
+				SchemaVersion: "2.1.0",
+				LastModified:  "2023-01-10T12:00:00Z",
+			},
+			{
+				Version:       "2.0.0",
+				SchemaVersion: "2.2.0",
+				Default:       true,
+				LastModified:  "2023-06-01T12:00:00Z",
+			},
+		},
+	},
+	{
+		Name:        "java-maven",
+		DisplayName: "Maven Java",
+		Language:    "Java",
+		Type:        indexSchema.StackDevfileType,
+		Tags:        []string{"Java", "Maven"},
+		Versions: []indexSchema.Version{
+			{
+				Version:       "1.1.0",
+				SchemaVersion: "2.1.0",
+				Default:       true,
+				LastModified:  "2022-11-20T12:00:00Z",
+				Tags:          []string{"Deprecated"},
+			},
+		},
+	},
+	{
+		Name:        "nodejs-basic",
+		DisplayName: "Basic Node.js",
+		Language:    "JavaScript",
+		Type:        indexSchema.SampleDevfileType,
+		Tags:        []string{"NodeJS", "Express"},
+		Versions: []indexSchema.Version{
+			{
+				Version:       "1.0.0",
+				SchemaVersion: "2.2.0",
+				Default:       true,
+				LastModified:  "2023-03-15T12:00:00Z",
+			},
+		},
+	},
+}
+
+func TestFilterDevfileExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		v1Index    bool
+		wantNames  []string
+	}{
+		{
+			name:       "Case 1: Equality is case insensitive",
+			expression: "language = java",
+			wantNames:  []string{"java-maven"},
+		},
+		{
+			name:       "Case 2: OR of equalities",
+			expression: "language = Go OR language = JavaScript",
+			wantNames:  []string{"go", "nodejs-basic"},
+		},
+		{
+			name:       "Case 3: NOT with parentheses",
+			expression: "not (type = sample or language = go)",
+			wantNames:  []string{"java-maven"},
+		},
+		{
+			name:       "Case 4: Comma as AND",
+			expression: "type = stack, tags = java",
+			wantNames:  []string{"java-maven"},
+		},
+		{
+			name:       "Case 5: AND binds tighter than OR",
+			expression: "name = go or type = stack and tags = maven",
+			wantNames:  []string{"go", "java-maven"},
+		},
+		{
+			name:       "Case 6: Contains on display name",
+			expression: `displayName contains "node"`,
+			wantNames:  []string{"nodejs-basic"},
+		},
+		{
+			name:       "Case 7: Fuzzy operator on array field",
+			expression: "tags ~ exp",
+			wantNames:  []string{"nodejs-basic"},
+		},
+		{
+			name:       "Case 8: Not equal",
+			expression: "language != go",
+			wantNames:  []string{"java-maven", "nodejs-basic"},
+		},
+		{
+			name:       "Case 9: Version range matches any version",
+			expression: "version >= 2.0",
+			wantNames:  []string{"go"},
+		},
+		{
+			name:       "Case 10: Schema version range",
+			expression: "schemaVersion < 2.2.0",
+			wantNames:  []string{"go", "java-maven"},
+		},
+		{
+			name:       "Case 11: Last modified range",
+			expression: "lastModified > 2023-03-15",
+			wantNames:  []string{"go"},
+		},
+		{
+			name:       "Case 12: Last modified date equality",
+			expression: "lastModified = 2023-03-15",
+			wantNames:  []string{"nodejs-basic"},
+		},
+		{
+			name:       "Case 13: Bare deprecated field",
+			expression: "deprecated",
+			wantNames:  []string{"java-maven"},
+		},
+		{
+			name:       "Case 14: Deprecation of the v1 index is only tagged on the entry",
+			expression: "NOT deprecated",
+			v1Index:    true,
+			wantNames:  []string{"go", "java-maven", "nodejs-basic"},
+		},
+		{
+			name:       "Case 15: Missing git fields do not match",
+			expression: "gitUrl ~ github",
+			wantNames:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expression, err := ParseFilterExpression(test.expression)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			result := FilterDevfileExpression(expressionTestIndex, expression, test.v1Index)
+			gotNames := []string{}
+			for _, entry := range result.Index {
+				gotNames = append(gotNames, entry.Name)
+			}
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Errorf("Got: %v, Expected: %v", gotNames, test.wantNames)
+			}
+		})
+	}
+}
+
+func TestParseFilterExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		expression   string
+		wantPosition int
+		wantMessage  string
+	}{
+		{
+			name:         "Case 1: Empty expression",
+			expression:   "  ",
+			wantPosition: 1,
+			wantMessage:  "invalid filter expression at position 1: empty expression",
+		},
+		{
+			name:         "Case 2: Unknown field",
+			expression:   "name = go or colour = red",
+			wantPosition: 14,
+			wantMessage:  `invalid filter expression at position 14: unknown field "colour"`,
+		},
+		{
+			name:         "Case 3: Missing value",
+			expression:   "name =",
+			wantPosition: 7,
+			wantMessage:  `invalid filter expression at position 7: expected a value after operator "=", found end of expression`,
+		},
+		{
+			name:         "Case 4: Unclosed parenthesis",
+			expression:   "(name = go or name = java",
+			wantPosition: 26,
+			wantMessage:  "invalid filter expression at position 26: expected ')' to close '(' at position 1, found end of expression",
+		},
+		{
+			name:         "Case 5: Unterminated string",
+			expression:   `displayName = "Go`,
+			wantPosition: 15,
+			wantMessage:  "invalid filter expression at position 15: unterminated quoted string",
+		},
+		{
+			name:         "Case 6: Range operator on unordered field",
+			expression:   "name > go",
+			wantPosition: 6,
+			wantMessage:  `invalid filter expression at position 6: operator ">" is only supported on the version, schemaVersion and lastModified fields`,
+		},
+		{
+			name:         "Case 7: Invalid version",
+			expression:   "version >= latest",
+			wantPosition: 12,
+			wantMessage:  `invalid filter expression at position 12: invalid version "latest"`,
+		},
+		{
+			name:         "Case 8: Invalid date",
+			expression:   "lastModified < 01/02/2023",
+			wantPosition: 16,
+			wantMessage:  `invalid filter expression at position 16: invalid date "01/02/2023", expected the format YYYY-MM-DD`,
+		},
+		{
+			name:         "Case 9: Missing operator",
+			expression:   "language go",
+			wantPosition: 10,
+			wantMessage:  `invalid filter expression at position 10: expected an operator after field "language", found "go"`,
+		},
+		{
+			name:         "Case 10: Trailing token",
+			expression:   "name = go)",
+			wantPosition: 10,
+			wantMessage:  `invalid filter expression at position 10: unexpected ")"`,
+		},
+		{
+			name:         "Case 11: Dangling OR",
+			expression:   "name = go OR",
+			wantPosition: 13,
+			wantMessage:  "invalid filter expression at position 13: expected a field, found end of expression",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseFilterExpression(test.expression)
+			var expressionErr *ExpressionError
+			if !errors.As(err, &expressionErr) {
+				t.Fatalf("Expected an expression error, got: %v", err)
+			}
+			if expressionErr.Position != test.wantPosition {
+				t.Errorf("Got position: %d, Expected: %d", expressionErr.Position, test.wantPosition)
+			}
+			if err.Error() != test.wantMessage {
+				t.Errorf("Got: %q, Expected: %q", err.Error(), test.wantMessage)
+			}
+		})
+	}
+}
@@ -433,43 +433,57 @@ func FilterDevfileVersion(index []indexSchema.Schema, minVersion, maxVersion *st
 // FilterDevfileDeprecated inplace filters devfiles based on stack deprecation
 func FilterDevfileDeprecated(index *[]indexSchema.Schema, deprecated, v1Index bool) {
 	for i := 0; i < len(*index); i++ {
-		toFilterOutIndex := !deprecated
-		foundDeprecated := false
-
-		for _, tag := range (*index)[i].Tags {
-			if tag == "Deprecated" {
-				foundDeprecated = true
-				break
-			}
+		if isDeprecated(&(*index)[i], v1Index) != deprecated {
+			filterOut(index, &i)
 		}
+	}
+}
 
-		if !foundDeprecated {
-			toFilterOutIndex = deprecated
+// isDeprecated checks if a stack or sample is tagged as deprecated, either on the index entry or, for
+// the v2 index, on its default version
+func isDeprecated(s *indexSchema.Schema, v1Index bool) bool {
+	for _, tag := range s.Tags {
+		if tag == "Deprecated" {
+			return true
+		}
+	}
 
-			if !v1Index {
-				for versionIndex := 0; versionIndex < len((*index)[i].Versions); versionIndex++ {
-					if (*index)[i].Versions[versionIndex].Default {
-						for _, tag := range (*index)[i].Versions[versionIndex].Tags {
-							if tag == "Deprecated" {
-								toFilterOutIndex = !deprecated
-								break
-							}
-						}
-						break
+	if !v1Index {
+		for versionIndex := 0; versionIndex < len(s.Versions); versionIndex++ {
+			if s.Versions[versionIndex].Default {
+				for _, tag := range s.Versions[versionIndex].Tags {
+					if tag == "Deprecated" {
+						return true
 					}
 				}
+				break
 			}
 		}
-
-		if toFilterOutIndex {
-			filterOut(index, &i)
-		}
 	}
+
+	return false
 }
 
 // FilterDevfileStrField filters by given string field, returns unchanged index if given parameter name is unrecognized
 func FilterDevfileStrField(index []indexSchema.Schema, paramName, requestedValue string, v1Index bool) FilterResult {
 	filterName := fmt.Sprintf("Fuzzy_Field_Filter_On_%s", paramName)
+	options, found := strFieldOptions(paramName, v1Index)
+	if !found {
+		return FilterResult{
+			Name:  filterName,
+			Index: index,
+		}
+	}
+
+	return FilterResult{
+		Name:  filterName,
+		Index: filterDevfileFieldFuzzy(index, requestedValue, options),
+	}
+}
+
+// strFieldOptions returns the filter options getting the values of a string field, false is returned if the
+// parameter name is unrecognized
+func strFieldOptions(paramName string, v1Index bool) (FilterOptions[string], bool) {
 	options := FilterOptions[string]{
 		V1Index: v1Index,
 	}
@@ -522,30 +536,54 @@ func FilterDevfileStrField(index []indexSchema.Schema, paramName, requestedValue
 		}
 	case ParamGitUrl:
 		options.GetFromIndexField = func(s *indexSchema.Schema) string {
+			if s.Git == nil {
+				return ""
+			}
 			return s.Git.Url
 		}
 		options.GetFromVersionField = func(v *indexSchema.Version) string {
+			if v.Git == nil {
+				return ""
+			}
 			return v.Git.Url
 		}
 	case ParamGitRemoteName:
 		options.GetFromIndexField = func(s *indexSchema.Schema) string {
+			if s.Git == nil {
+				return ""
+			}
 			return s.Git.RemoteName
 		}
 		options.GetFromVersionField = func(v *indexSchema.Version) string {
+			if v.Git == nil {
+				return ""
+			}
 			return v.Git.RemoteName
 		}
 	case ParamGitSubDir:
 		options.GetFromIndexField = func(s *indexSchema.Schema) string {
+			if s.Git == nil {
+				return ""
+			}
 			return s.Git.SubDir
 		}
 		options.GetFromVersionField = func(v *indexSchema.Version) string {
+			if v.Git == nil {
+				return ""
+			}
 			return v.Git.SubDir
 		}
 	case ParamGitRevision:
 		options.GetFromIndexField = func(s *indexSchema.Schema) string {
+			if s.Git == nil {
+				return ""
+			}
 			return s.Git.Revision
 		}
 		options.GetFromVersionField = func(v *indexSchema.Version) string {
+			if v.Git == nil {
+				return ""
+			}
 			return v.Git.Revision
 		}
 	case ParamProvider:
@@ -557,16 +595,9 @@ func FilterDevfileStrField(index []indexSchema.Schema, paramName, requestedValue
 			return s.SupportUrl
 		}
 	default:
-		return FilterResult{
-			Name:  filterName,
-			Index: index,
-		}
-	}
-
-	return FilterResult{
-		Name:  filterName,
-		Index: filterDevfileFieldFuzzy(index, requestedValue, options),
+		return options, false
 	}
+	return options, true
 }
 
 // AndFilter filters results of given filters to only overlapping results
@@ -629,6 +660,23 @@ func AndFilter(results ...*FilterResult) FilterResult {
 // FilterDevfileStrArrayField filters devfiles based on an array field
 func FilterDevfileStrArrayField(index []indexSchema.Schema, paramName string, requestedValues []string, v1Index bool) FilterResult {
 	filterName := fmt.Sprintf("Fuzzy_Array_Filter_On_%s", paramName)
+	options, found := strArrayFieldOptions(paramName, v1Index)
+	if !found {
+		return FilterResult{
+			Name:  filterName,
+			Index: index,
+		}
+	}
+
+	return FilterResult{
+		Name:  filterName,
+		Index: filterDevfileArrayFuzzy(index, requestedValues, options),
+	}
+}
+
+// strArrayFieldOptions returns the filter options getting the values of a string array field, false is returned
+// if the parameter name is unrecognized
+func strArrayFieldOptions(paramName string, v1Index bool) (FilterOptions[[]string], bool) {
 	options := FilterOptions[[]string]{
 		FilterOutEmpty: true,
 		V1Index:        v1Index,
@@ -785,16 +833,9 @@ func FilterDevfileStrArrayField(index []indexSchema.Schema, paramName string, re
 			return gitRemotes
 		}
 	default:
-		return FilterResult{
-			Name:  filterName,
-			Index: index,
-		}
-	}
-
-	return FilterResult{
-		Name:  filterName,
-		Index: filterDevfileArrayFuzzy(index, requestedValues, options),
+		return options, false
 	}
+	return options, true
 }
 
 // FilterLastModifiedDate filters based on the last modified date of a stack or sample
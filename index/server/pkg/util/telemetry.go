@@ -18,6 +18,7 @@ package util
 import (
 	"log"
 	"net"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/text/language"
@@ -30,20 +31,49 @@ const (
 	consoleId              = "openshift-console"
 	registryLibrary        = "registry-library"
 	devfileLibraryIndirect = "devfile-library-indirect"
+	odoClient              = "odo"
+	otherClient            = "other"
 )
 
+// knownClients are the clients of the registry which are told apart by their Client header
+var knownClients = map[string]bool{
+	defaultUser:            true,
+	viewerId:               true,
+	consoleId:              true,
+	registryLibrary:        true,
+	devfileLibraryIndirect: true,
+	odoClient:              true,
+}
+
 var telemetryKey = GetOptionalEnv("TELEMETRY_KEY", "").(string)
 
-// TrackEvent tracks event for telemetry
-func TrackEvent(event analytics.Message) error {
-	// Initialize client for telemetry
-	client := analytics.New(telemetryKey)
-	defer client.Close()
+// telemetry is the dispatcher of the telemetry events of the running registry server, events are not tracked if nil
+var telemetry atomic.Pointer[Telemetry]
+
+// SetTelemetry sets the dispatcher the tracked events are sent with, nil disables the tracking
+func SetTelemetry(t *Telemetry) {
+	telemetry.Store(t)
+}
+
+// CloseTelemetry stops the tracking, the queued events are sent before the sinks are closed
+func CloseTelemetry() {
+	if t := telemetry.Swap(nil); t != nil {
+		t.Close()
+	}
+}
 
-	err := client.Enqueue(event)
+// TrackEvent queues the event for the telemetry sinks without waiting for its delivery, events are dropped
+// when the queue is full
+func TrackEvent(event analytics.Message) error {
+	t := telemetry.Load()
+	if t == nil {
+		return nil
+	}
+	telemetryEvent, err := newTelemetryEvent(event)
 	if err != nil {
 		return err
 	}
+	t.Track(telemetryEvent)
 	return nil
 }
 
@@ -67,6 +97,16 @@ func GetClient(c *gin.Context) string {
 	return client
 }
 
+// GetKnownClient gets the client of the request if it is a known client of the registry, 'other' otherwise, so
+// that arbitrary Client headers are not recorded
+func GetKnownClient(c *gin.Context) string {
+	client := GetClient(c)
+	if !knownClients[client] {
+		return otherClient
+	}
+	return client
+}
+
 // SetContext suppresses the collection of IP addresses in Segment but infers the country code from the HTTP `Accept-Language` header
 func SetContext(c *gin.Context) *analytics.Context {
 	aContext := analytics.Context{}
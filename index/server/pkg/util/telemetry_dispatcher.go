@@ -0,0 +1,192 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+// TelemetryEvent is a telemetry event independent of the sink it is sent to
+type TelemetryEvent struct {
+	Event      string                 `json:"event"`
+	UserId     string                 `json:"userId"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Country    string                 `json:"country,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// TelemetrySink delivers batches of telemetry events to a backend
+type TelemetrySink interface {
+	// Name identifies the sink in the logs and metrics
+	Name() string
+	// Send delivers a batch of events
+	Send(events []TelemetryEvent) error
+	// Close flushes the pending events and releases the sink
+	Close() error
+}
+
+var (
+	telemetryDroppedEvents = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "registry_telemetry_events_dropped_total",
+		Help: "Number of telemetry events dropped because the queue was full.",
+	})
+	telemetrySentEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "registry_telemetry_events_sent_total",
+		Help: "Number of telemetry events sent by sink.",
+	}, []string{"sink"})
+	telemetryFailedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "registry_telemetry_events_failed_total",
+		Help: "Number of telemetry events which failed to be sent by sink.",
+	}, []string{"sink"})
+)
+
+// TelemetryCollectors returns the metrics of the telemetry queue and sinks
+func TelemetryCollectors() []prometheus.Collector {
+	return []prometheus.Collector{telemetryDroppedEvents, telemetrySentEvents, telemetryFailedEvents}
+}
+
+// Telemetry queues telemetry events and sends them in batches to its sinks from a single goroutine, so that
+// tracking an event never waits for a sink
+type Telemetry struct {
+	sinks         []TelemetrySink
+	queue         chan TelemetryEvent
+	batchSize     int
+	flushInterval time.Duration
+	closing       chan struct{}
+	closeOnce     sync.Once
+	done          chan struct{}
+}
+
+// NewTelemetry starts a dispatcher with a queue of queueSize events, batches are sent when they reach batchSize
+// events or every flushInterval
+func NewTelemetry(sinks []TelemetrySink, queueSize int, batchSize int, flushInterval time.Duration) *Telemetry {
+	t := &Telemetry{
+		sinks:         sinks,
+		queue:         make(chan TelemetryEvent, queueSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		closing:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Track queues an event, false is returned if the event is dropped because the queue is full or the
+// dispatcher is closed
+func (t *Telemetry) Track(event TelemetryEvent) bool {
+	select {
+	case <-t.closing:
+		return false
+	default:
+	}
+	select {
+	case t.queue <- event:
+		return true
+	default:
+		telemetryDroppedEvents.Inc()
+		return false
+	}
+}
+
+// Close sends the queued events and closes the sinks
+func (t *Telemetry) Close() {
+	t.closeOnce.Do(func() {
+		close(t.closing)
+	})
+	<-t.done
+}
+
+func (t *Telemetry) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]TelemetryEvent, 0, t.batchSize)
+	add := func(event TelemetryEvent) {
+		batch = append(batch, event)
+		if len(batch) >= t.batchSize {
+			t.send(batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case event := <-t.queue:
+			add(event)
+		case <-ticker.C:
+			t.send(batch)
+			batch = batch[:0]
+		case <-t.closing:
+			for {
+				select {
+				case event := <-t.queue:
+					add(event)
+				default:
+					t.send(batch)
+					for _, sink := range t.sinks {
+						if err := sink.Close(); err != nil {
+							log.Printf("failed to close the %s telemetry sink: %v", sink.Name(), err)
+						}
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// send delivers a batch to every sink, a failing sink does not prevent the delivery to the others
+func (t *Telemetry) send(batch []TelemetryEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	for _, sink := range t.sinks {
+		if err := sink.Send(batch); err != nil {
+			log.Printf("failed to send %d telemetry events to the %s sink: %v", len(batch), sink.Name(), err)
+			telemetryFailedEvents.WithLabelValues(sink.Name()).Add(float64(len(batch)))
+			continue
+		}
+		telemetrySentEvents.WithLabelValues(sink.Name()).Add(float64(len(batch)))
+	}
+}
+
+// newTelemetryEvent converts a Segment track message to a telemetry event
+func newTelemetryEvent(message analytics.Message) (TelemetryEvent, error) {
+	track, ok := message.(analytics.Track)
+	if !ok {
+		return TelemetryEvent{}, fmt.Errorf("unsupported telemetry message %T", message)
+	}
+	event := TelemetryEvent{
+		Event:      track.Event,
+		UserId:     track.UserId,
+		Timestamp:  track.Timestamp,
+		Properties: track.Properties,
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if track.Context != nil {
+		event.Country = track.Context.Location.Country
+	}
+	return event, nil
+}
@@ -0,0 +1,168 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+// recordingSink records the batches it is sent, optionally failing
+type recordingSink struct {
+	mutex   sync.Mutex
+	batches [][]TelemetryEvent
+	closed  bool
+	fail    bool
+	// block delays the first batch until it is closed
+	block chan struct{}
+}
+
+func (*recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Send(events []TelemetryEvent) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.batches = append(s.batches, append([]TelemetryEvent{}, events...))
+	if s.fail {
+		return errors.New("sink failure")
+	}
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *recordingSink) batchSizes() []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sizes := []int{}
+	for _, batch := range s.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func TestTelemetryBatches(t *testing.T) {
+	sink := &recordingSink{}
+	failing := &recordingSink{fail: true}
+	telemetry := NewTelemetry([]TelemetrySink{failing, sink}, 10, 2, time.Hour)
+	for i := 0; i < 5; i++ {
+		if !telemetry.Track(TelemetryEvent{Event: "view devfile"}) {
+			t.Fatalf("Event %d was dropped", i)
+		}
+	}
+	telemetry.Close()
+
+	// Full batches are sent as soon as they are complete, the remaining events are sent on close
+	if got := sink.batchSizes(); len(got) != 3 || got[0] != 2 || got[1] != 2 || got[2] != 1 {
+		t.Errorf("Got batch sizes: %v, Expected: [2 2 1]", got)
+	}
+	if !sink.closed || !failing.closed {
+		t.Errorf("Expected the sinks to be closed")
+	}
+	if len(failing.batchSizes()) != 3 {
+		t.Errorf("Expected the failing sink to be sent every batch")
+	}
+	if telemetry.Track(TelemetryEvent{Event: "view devfile"}) {
+		t.Errorf("Expected events tracked after close to be dropped")
+	}
+}
+
+func TestTelemetryFlushInterval(t *testing.T) {
+	sink := &recordingSink{}
+	telemetry := NewTelemetry([]TelemetrySink{sink}, 10, 100, 10*time.Millisecond)
+	defer telemetry.Close()
+	telemetry.Track(TelemetryEvent{Event: "view devfile"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.batchSizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the event to be sent after the flush interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTelemetryDropsWhenFull(t *testing.T) {
+	sink := &recordingSink{block: make(chan struct{})}
+	telemetry := NewTelemetry([]TelemetrySink{sink}, 2, 1, time.Hour)
+
+	// The first event is taken by the blocked sink, two more fill the queue
+	tracked := 0
+	for i := 0; i < 10; i++ {
+		if telemetry.Track(TelemetryEvent{Event: "view devfile"}) {
+			tracked++
+		}
+	}
+	if tracked > 3 || tracked < 2 {
+		t.Errorf("Got %d tracked events, Expected the events above the queue size to be dropped", tracked)
+	}
+	close(sink.block)
+	telemetry.Close()
+}
+
+func TestCloseTelemetry(t *testing.T) {
+	sink := &recordingSink{}
+	SetTelemetry(NewTelemetry([]TelemetrySink{sink}, 10, 100, time.Hour))
+	if err := TrackEvent(analytics.Track{Event: "view devfile", UserId: "user"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	CloseTelemetry()
+
+	// The queued events are sent on close and the events tracked after are ignored
+	if got := sink.batchSizes(); len(got) != 1 || got[0] != 1 || !sink.closed {
+		t.Errorf("Got batch sizes: %v and closed: %t, Expected: [1] and closed", got, sink.closed)
+	}
+	if err := TrackEvent(analytics.Track{Event: "view devfile", UserId: "user"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	CloseTelemetry()
+}
+
+func TestNewTelemetryEvent(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	event, err := newTelemetryEvent(analytics.Track{
+		Event:      "view devfile",
+		UserId:     "user",
+		Timestamp:  timestamp,
+		Context:    &analytics.Context{Location: analytics.LocationInfo{Country: "CA"}},
+		Properties: analytics.NewProperties().Set("name", "go"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if event.Event != "view devfile" || event.UserId != "user" || !event.Timestamp.Equal(timestamp) ||
+		event.Country != "CA" || event.Properties["name"] != "go" {
+		t.Errorf("Got event: %+v", event)
+	}
+
+	if _, err := newTelemetryEvent(analytics.Identify{UserId: "user"}); err == nil {
+		t.Errorf("Expected an error for a message which is not a track")
+	}
+}
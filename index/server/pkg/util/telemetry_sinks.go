@@ -0,0 +1,246 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+const (
+	SegmentSinkType = "segment"
+	OTLPSinkType    = "otlp"
+	JSONLSinkType   = "jsonl"
+	WebhookSinkType = "webhook"
+
+	// sinkTimeout is the timeout of the requests of the HTTP sinks
+	sinkTimeout = 10 * time.Second
+	// otlpScope is the instrumentation scope of the OTLP log records
+	otlpScope = "github.com/devfile/registry-support/index/server"
+)
+
+// segmentSink sends the events to Segment with a single long-lived client, which batches them itself
+type segmentSink struct {
+	client analytics.Client
+}
+
+// NewSegmentSink creates a sink sending the events to Segment with the write key
+func NewSegmentSink(writeKey string) TelemetrySink {
+	return &segmentSink{client: analytics.New(writeKey)}
+}
+
+func (*segmentSink) Name() string {
+	return SegmentSinkType
+}
+
+func (s *segmentSink) Send(events []TelemetryEvent) error {
+	for _, event := range events {
+		track := analytics.Track{
+			Event:      event.Event,
+			UserId:     event.UserId,
+			Timestamp:  event.Timestamp,
+			Properties: event.Properties,
+			// Segment does not collect the IP address, the country is inferred from the request headers
+			Context: &analytics.Context{
+				IP:       net.IPv4(0, 0, 0, 0),
+				Location: analytics.LocationInfo{Country: event.Country},
+			},
+		}
+		if err := s.client.Enqueue(track); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *segmentSink) Close() error {
+	return s.client.Close()
+}
+
+// jsonlSink appends the events to a file, one JSON object per line
+type jsonlSink struct {
+	file *os.File
+}
+
+// NewJSONLSink creates a sink appending the events to the file at path, the file is created if it does not exist
+func NewJSONLSink(path string) (TelemetrySink, error) {
+	/* #nosec G304 -- the telemetry file is given by the registry administrator */
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the telemetry file %s: %v", path, err)
+	}
+	return &jsonlSink{file: file}, nil
+}
+
+func (*jsonlSink) Name() string {
+	return JSONLSinkType
+}
+
+func (s *jsonlSink) Send(events []TelemetryEvent) error {
+	// The batch is written at once so that concurrent readers never see a partial batch
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	_, err := s.file.Write(buffer.Bytes())
+	return err
+}
+
+func (s *jsonlSink) Close() error {
+	return s.file.Close()
+}
+
+// webhookSink posts each batch of events as a JSON array to a URL
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink posting the events to the webhook URL
+func NewWebhookSink(url string) TelemetrySink {
+	return &webhookSink{url: url, client: &http.Client{Timeout: sinkTimeout}}
+}
+
+func (*webhookSink) Name() string {
+	return WebhookSinkType
+}
+
+func (s *webhookSink) Send(events []TelemetryEvent) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	return postJSON(context.Background(), s.client, s.url, body)
+}
+
+func (*webhookSink) Close() error {
+	return nil
+}
+
+// otlpSink exports the events as OTLP log records with the OTLP/HTTP log exporter, export errors are reported by
+// the OpenTelemetry error handler
+type otlpSink struct {
+	provider *sdklog.LoggerProvider
+	logger   otellog.Logger
+}
+
+// NewOTLPSink creates a sink exporting the events to the OTLP/HTTP logs endpoint, e.g.
+// 'http://otel-collector:4318/v1/logs', with the service name as resource
+func NewOTLPSink(endpoint string, serviceName string) (TelemetrySink, error) {
+	exporter, err := otlploghttp.New(context.Background(), otlploghttp.WithEndpointURL(endpoint), otlploghttp.WithTimeout(sinkTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP log exporter of %s: %v", endpoint, err)
+	}
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	return &otlpSink{provider: provider, logger: provider.Logger(otlpScope)}, nil
+}
+
+func (*otlpSink) Name() string {
+	return OTLPSinkType
+}
+
+// otlpLogValue converts a property to a log value, values which are not scalars are encoded as JSON strings
+func otlpLogValue(value interface{}) otellog.Value {
+	switch value := value.(type) {
+	case string:
+		return otellog.StringValue(value)
+	case bool:
+		return otellog.BoolValue(value)
+	case int:
+		return otellog.IntValue(value)
+	case int64:
+		return otellog.Int64Value(value)
+	case float64:
+		return otellog.Float64Value(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(value))
+		}
+		return otellog.StringValue(string(encoded))
+	}
+}
+
+// Send emits the events and flushes them, so that each batch of events is exported at once
+func (s *otlpSink) Send(events []TelemetryEvent) error {
+	ctx := context.Background()
+	observed := time.Now()
+	for _, event := range events {
+		var record otellog.Record
+		record.SetTimestamp(event.Timestamp)
+		record.SetObservedTimestamp(observed)
+		record.SetSeverity(otellog.SeverityInfo)
+		record.SetSeverityText("INFO")
+		record.SetEventName(event.Event)
+		record.SetBody(otellog.StringValue(event.Event))
+		record.AddAttributes(
+			otellog.String("event.name", event.Event),
+			otellog.String("user.id", event.UserId),
+		)
+		if event.Country != "" {
+			record.AddAttributes(otellog.String("client.geo.country_iso_code", event.Country))
+		}
+		for key, value := range event.Properties {
+			record.AddAttributes(otellog.KeyValue{Key: "registry." + key, Value: otlpLogValue(value)})
+		}
+		s.logger.Emit(ctx, record)
+	}
+	return s.provider.ForceFlush(ctx)
+}
+
+func (s *otlpSink) Close() error {
+	return s.provider.Shutdown(context.Background())
+}
+
+// postJSON posts a JSON body and fails on a non 2xx response
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded with status %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+var testTelemetryEvents = []TelemetryEvent{
+	{
+		Event:      "view devfile",
+		UserId:     "user",
+		Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Country:    "CA",
+		Properties: map[string]interface{}{"name": "go", "client": "odo"},
+	},
+	{
+		Event:     "list devfile",
+		UserId:    "user",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC),
+	},
+}
+
+func TestJSONLSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	if err := os.WriteFile(path, []byte("{\"event\":\"existing\"}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sink, err := NewJSONLSink(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := sink.Send(testTelemetryEvents); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Got %d lines, Expected the events to be appended: %s", len(lines), content)
+	}
+	var event TelemetryEvent
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if event.Event != "view devfile" || event.Country != "CA" || event.Properties["name"] != "go" {
+		t.Errorf("Got event: %+v", event)
+	}
+}
+
+func TestHTTPSinks(t *testing.T) {
+	newWebhookSink := func(url string) (TelemetrySink, error) {
+		return NewWebhookSink(url), nil
+	}
+	tests := []struct {
+		name            string
+		sink            func(url string) (TelemetrySink, error)
+		status          int
+		wantContentType string
+		wantErr         bool
+		wantBody        func(t *testing.T, body []byte)
+	}{
+		{
+			name:            "Case 1: Webhook",
+			sink:            newWebhookSink,
+			status:          http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: func(t *testing.T, body []byte) {
+				var events []TelemetryEvent
+				if err := json.Unmarshal(body, &events); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(events) != 2 || events[0].Event != "view devfile" {
+					t.Errorf("Got events: %+v", events)
+				}
+			},
+		},
+		{
+			name:            "Case 2: Webhook failure",
+			sink:            newWebhookSink,
+			status:          http.StatusInternalServerError,
+			wantContentType: "application/json",
+			wantErr:         true,
+		},
+		{
+			name: "Case 3: OTLP",
+			sink: func(url string) (TelemetrySink, error) {
+				return NewOTLPSink(url, "devfile-registry")
+			},
+			status:          http.StatusOK,
+			wantContentType: "application/x-protobuf",
+			wantBody: func(t *testing.T, body []byte) {
+				var request collogspb.ExportLogsServiceRequest
+				if err := proto.Unmarshal(body, &request); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				resourceLogs := request.GetResourceLogs()
+				if len(resourceLogs) != 1 || len(resourceLogs[0].GetScopeLogs()) != 1 {
+					t.Fatalf("Got request: %v", &request)
+				}
+				if service := resourceLogs[0].GetResource().GetAttributes()[0]; service.GetKey() != "service.name" ||
+					service.GetValue().GetStringValue() != "devfile-registry" {
+					t.Errorf("Got resource attribute: %v", service)
+				}
+				records := resourceLogs[0].GetScopeLogs()[0].GetLogRecords()
+				if len(records) != 2 || records[0].GetEventName() != "view devfile" || records[0].GetTimeUnixNano() != 1704164645000000000 {
+					t.Fatalf("Got log records: %v", records)
+				}
+				attributes := map[string]string{}
+				for _, attribute := range records[0].GetAttributes() {
+					attributes[attribute.GetKey()] = attribute.GetValue().GetStringValue()
+				}
+				if attributes["registry.name"] != "go" || attributes["client.geo.country_iso_code"] != "CA" || attributes["user.id"] != "user" {
+					t.Errorf("Got attributes: %v", attributes)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") != test.wantContentType {
+					t.Errorf("Got content type: %s, Expected: %s", r.Header.Get("Content-Type"), test.wantContentType)
+				}
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			sink, err := test.sink(server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer sink.Close()
+			err = sink.Send(testTelemetryEvents)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Got error: %v, Expected error: %v", err, test.wantErr)
+			}
+			if test.wantBody != nil {
+				test.wantBody(t, body)
+			}
+		})
+	}
+}
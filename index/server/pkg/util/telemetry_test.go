@@ -87,6 +87,39 @@ func TestGetUser(t *testing.T) {
 	}
 }
 
+func TestGetKnownClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{
+			name:   "Client header is unset",
+			header: http.Header{},
+			want:   defaultUser,
+		},
+		{
+			name:   "Known client",
+			header: http.Header{"Client": {odoClient}},
+			want:   odoClient,
+		},
+		{
+			name:   "Unknown client",
+			header: http.Header{"Client": {"testclient"}},
+			want:   otherClient,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := GetKnownClient(&gin.Context{Request: &http.Request{Header: test.header}})
+			if client != test.want {
+				t.Errorf("Got: %v, Expected: %v", client, test.want)
+			}
+		})
+	}
+}
+
 func TestSetContext(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,34 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// NewOTLPSpanExporter creates an exporter of the spans to the OTLP/HTTP traces endpoint, e.g.
+// 'http://otel-collector:4318/v1/traces'
+func NewOTLPSpanExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(endpoint), otlptracehttp.WithTimeout(sinkTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP span exporter of %s: %v", endpoint, err)
+	}
+	return exporter, nil
+}
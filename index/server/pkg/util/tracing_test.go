@@ -0,0 +1,116 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPSpanExporter(t *testing.T) {
+	var gotRequest coltracepb.ExportTraceServiceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/x-protobuf" {
+			t.Errorf("Got content type: %s, Expected: application/x-protobuf", contentType)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := proto.Unmarshal(body, &gotRequest); err != nil {
+			t.Errorf("failed to unmarshal the request: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	exporter, err := NewOTLPSpanExporter(server.URL)
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "devfile-registry"))),
+	)
+	defer func() {
+		_ = provider.Shutdown(context.Background())
+	}()
+	tracer := provider.Tracer("registry-test")
+	ctx, parent := tracer.Start(context.Background(), "GET /devfiles/:stack", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "pullStackResource", trace.WithAttributes(
+		attribute.String("registry.resource", "devfile.yaml"),
+		attribute.Int64("size", 42),
+	))
+	child.RecordError(errors.New("manifest unknown"))
+	child.SetStatus(codes.Error, "manifest unknown")
+	child.End()
+
+	if len(gotRequest.ResourceSpans) != 1 || len(gotRequest.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("Expected one resource and one scope, got: %v", &gotRequest)
+	}
+	resourceSpans := gotRequest.ResourceSpans[0]
+	if attributes := resourceSpans.Resource.Attributes; len(attributes) != 1 || attributes[0].Value.GetStringValue() != "devfile-registry" {
+		t.Errorf("Got resource attributes: %v, Expected the service name", attributes)
+	}
+	if scope := resourceSpans.ScopeSpans[0].Scope.Name; scope != "registry-test" {
+		t.Errorf("Got scope: %s, Expected: registry-test", scope)
+	}
+	spans := resourceSpans.ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("Got %d spans, Expected: 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "pullStackResource" || span.Kind != tracepb.Span_SPAN_KIND_INTERNAL {
+		t.Errorf("Got span %s of kind %v, Expected pullStackResource of kind %v", span.Name, span.Kind, tracepb.Span_SPAN_KIND_INTERNAL)
+	}
+	parentContext := parent.SpanContext()
+	if trace.TraceID(span.TraceId) != parentContext.TraceID() || trace.SpanID(span.ParentSpanId) != parentContext.SpanID() {
+		t.Errorf("Got trace %x and parent %x, Expected the trace and span of the parent", span.TraceId, span.ParentSpanId)
+	}
+	if span.Status.Code != tracepb.Status_STATUS_CODE_ERROR || span.Status.Message != "manifest unknown" {
+		t.Errorf("Got status: %v, Expected the OTLP error status", span.Status)
+	}
+	if len(span.Events) != 1 || span.Events[0].Name != "exception" {
+		t.Errorf("Got events: %v, Expected the exception of the error", span.Events)
+	}
+	if len(span.Attributes) != 2 {
+		t.Errorf("Got attributes: %v, Expected: 2", span.Attributes)
+	}
+	for _, attribute := range span.Attributes {
+		switch attribute.Key {
+		case "registry.resource":
+			if got := attribute.Value.GetStringValue(); got != "devfile.yaml" {
+				t.Errorf("Got attribute %s: %s, Expected: devfile.yaml", attribute.Key, got)
+			}
+		case "size":
+			if got := attribute.Value.GetIntValue(); got != 42 {
+				t.Errorf("Got attribute %s: %d, Expected: 42", attribute.Key, got)
+			}
+		default:
+			t.Errorf("Got unexpected attribute %s", attribute.Key)
+		}
+	}
+}
@@ -64,14 +64,9 @@ func EncodeIndexIconToBase64(indexPath string, base64IndexPath string) ([]byte,
 	}
 
 	// encode all index icons to base64 format
-	for i, indexEntry := range index {
-		if indexEntry.Icon != "" {
-			base64Icon, err := encodeToBase64(indexEntry.Icon)
-			index[i].Icon = base64Icon
-			if err != nil {
-				return nil, err
-			}
-		}
+	err = EncodeIconsToBase64(index)
+	if err != nil {
+		return nil, err
 	}
 	err = indexLibrary.CreateIndexFile(index, base64IndexPath)
 	if err != nil {
@@ -84,6 +79,20 @@ func EncodeIndexIconToBase64(indexPath string, base64IndexPath string) ([]byte,
 	return bytes, nil
 }
 
+// EncodeIconsToBase64 encodes the icons of the index entries to base64 format in place
+func EncodeIconsToBase64(index []indexSchema.Schema) error {
+	for i, indexEntry := range index {
+		if indexEntry.Icon != "" {
+			base64Icon, err := encodeToBase64(indexEntry.Icon)
+			index[i].Icon = base64Icon
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // encodeToBase64 encodes the content from the given uri to base64 format
 func encodeToBase64(uri string) (string, error) {
 	url, err := url.Parse(uri)
@@ -43,6 +43,7 @@ var (
 	newIndexSchema bool
 	user           string
 	architectures  []string
+	publicKeyPath  string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -93,6 +94,7 @@ func init() {
 					User: "user",
 				},
 				SkipTLSVerify: skipTLSVerify,
+				PublicKeyPath: publicKeyPath,
 			}
 
 			if len(architectures) > 0 {
@@ -115,6 +117,7 @@ func init() {
 	pullCmd.Flags().BoolVar(&skipTLSVerify, "skip-tls-verify", false, "skip TLS verification")
 	pullCmd.Flags().BoolVar(&newIndexSchema, "new-index-schema", false, "pull new index schema")
 	pullCmd.Flags().StringVar(&user, "user", "", "consumer name")
+	pullCmd.Flags().StringVar(&publicKeyPath, "public-key", "", "public key to verify the registry index signature with")
 
 	var listCmd = &cobra.Command{
 		Use:   "list",
@@ -130,6 +133,7 @@ func init() {
 					User: "user",
 				},
 				SkipTLSVerify: skipTLSVerify,
+				PublicKeyPath: publicKeyPath,
 			}
 
 			if len(architectures) > 0 {
@@ -146,6 +150,7 @@ func init() {
 	listCmd.Flags().StringArrayVar(&architectures, "arch", []string{}, "architecture filter; example: --arch amd64 --arch arm64")
 	listCmd.Flags().BoolVar(&skipTLSVerify, "skip-tls-verify", false, "skip TLS verification")
 	listCmd.Flags().StringVar(&user, "user", "", "consumer name")
+	listCmd.Flags().StringVar(&publicKeyPath, "public-key", "", "public key to verify the registry index signature with")
 
 	var downloadCmd = &cobra.Command{
 		Use:   "download <registry name> <stack name> <starter project name>",
@@ -161,6 +166,7 @@ func init() {
 					User: "user",
 				},
 				SkipTLSVerify: skipTLSVerify,
+				PublicKeyPath: publicKeyPath,
 			}
 
 			err = library.DownloadStarterProjectAsDir(destDir, registry, stack, starterProject, options)
@@ -173,6 +179,7 @@ func init() {
 	downloadCmd.Flags().BoolVar(&skipTLSVerify, "skip-tls-verify", false, "skip TLS verification")
 	downloadCmd.Flags().BoolVar(&newIndexSchema, "new-index-schema", false, "use new index schema")
 	downloadCmd.Flags().StringVar(&user, "user", "", "consumer name")
+	downloadCmd.Flags().StringVar(&publicKeyPath, "public-key", "", "public key to verify the registry index signature with")
 
 	rootCmd.AddCommand(pullCmd, listCmd, downloadCmd)
 }
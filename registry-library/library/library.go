@@ -17,6 +17,7 @@ package library
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -36,6 +37,7 @@ import (
 	"github.com/containerd/containerd/remotes/docker"
 	indexSchema "github.com/devfile/registry-support/index/generator/schema"
 	versionpkg "github.com/hashicorp/go-version"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/pkg/content"
 	"oras.land/oras-go/pkg/oras"
 )
@@ -96,6 +98,13 @@ type RegistryOptions struct {
 	NewIndexSchema bool
 	// HTTPTimeout overrides the request and response timeout values for the custom HTTP clients set by the registry library.  If unset or a negative value is specified, the default timeout of 30s will be used.
 	HTTPTimeout *int
+	// PublicKeyPath is the path to a PEM encoded ed25519 or ECDSA (e.g. cosign) public key. If specified, the index signature served by
+	// the registry is verified with the key, and GetRegistryIndex fails unless every returned stack and sample is covered by the signature.
+	PublicKeyPath string
+	// Context carries the cancelation, deadline and trace context of the requests to the registry. The trace
+	// context is propagated to the registry with the W3C trace context headers, using the global OpenTelemetry
+	// propagator and tracer provider of the client. If unset, context.Background() is used.
+	Context context.Context
 }
 
 type RegistryFilter struct {
@@ -178,7 +187,7 @@ func GetRegistryIndex(registryURL string, options RegistryOptions, devfileTypes
 	}
 
 	url := urlObj.String()
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(options.context(), http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -199,6 +208,13 @@ func GetRegistryIndex(registryURL string, options RegistryOptions, devfileTypes
 	if err != nil {
 		return nil, err
 	}
+
+	if options.PublicKeyPath != "" {
+		err = verifyRegistryIndex(registryURL, registryIndex, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify the index of registry %s: %v", registryURL, err)
+		}
+	}
 	return registryIndex, nil
 }
 
@@ -264,7 +280,7 @@ func PullStackByMediaTypesFromRegistry(registry string, stack string, allowedMed
 	}
 
 	// Pull stack initialization
-	ctx := orasctx.Background()
+	ctx := orasctx.WithLoggerDiscarded(options.context())
 	urlObj, err := url.Parse(registry)
 	if err != nil {
 		return err
@@ -284,11 +300,26 @@ func PullStackByMediaTypesFromRegistry(registry string, stack string, allowedMed
 	defer fileStore.Close()
 
 	// Pull stack from registry and save it to disk
-	_, err = oras.Copy(ctx, resolver, ref, fileStore, ref, oras.WithAllowedMediaTypes(allowedMediaTypes))
+	var layers []ocispec.Descriptor
+	_, err = oras.Copy(ctx, resolver, ref, fileStore, ref, oras.WithAllowedMediaTypes(allowedMediaTypes),
+		oras.WithLayerDescriptors(func(descriptors []ocispec.Descriptor) { layers = descriptors }))
 	if err != nil {
 		return fmt.Errorf("failed to pull stack %s from %s with allowed media types %v: %v", stack, ref, allowedMediaTypes, err)
 	}
 
+	// Verify the pulled resources against the index signature, the stack link is tagged with the stack version
+	if options.PublicKeyPath != "" {
+		stackName, _, err := SplitVersionFromStack(stack)
+		if err != nil {
+			return fmt.Errorf("problem in stack/version tag: %v", err)
+		}
+		version := stackLink[strings.LastIndex(stackLink, ":")+1:]
+		err = verifyPulledResources(registry, stackName, version, destDir, layers, options)
+		if err != nil {
+			return fmt.Errorf("failed to verify stack %s pulled from %s: %v", stack, ref, err)
+		}
+	}
+
 	// Decompress archive.tar
 	archivePath := filepath.Join(destDir, "archive.tar")
 	if _, err := os.Stat(archivePath); err == nil {
@@ -452,7 +483,7 @@ func DownloadStarterProjectAsBytes(registryURL string, stack string, starterProj
 	}
 
 	url := fmt.Sprintf("%s://%s", urlObj.Scheme, path.Join(urlObj.Host, "devfiles", stackName, "starter-projects", starterProject))
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(options.context(), http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
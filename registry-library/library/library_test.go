@@ -18,6 +18,7 @@ package library
 import (
 	"archive/zip"
 	bytespkg "bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -33,6 +34,9 @@ import (
 	"testing"
 
 	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -1178,3 +1182,38 @@ func validatePullFunctions(t *testing.T, wantFiles []string, path string) {
 		}
 	}
 }
+
+func TestRegistryOptionsContext(t *testing.T) {
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previousPropagator)
+
+	var gotTraceParent string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceParent = r.Header.Get("traceparent")
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer testServer.Close()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+
+	if _, err := GetRegistryIndex(testServer.URL, RegistryOptions{Context: ctx}, indexSchema.StackDevfileType); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(gotTraceParent, traceID.String()) {
+		t.Errorf("Got traceparent header: %q, Expected the trace id %s", gotTraceParent, traceID)
+	}
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetRegistryIndex(testServer.URL, RegistryOptions{Context: canceledCtx}, indexSchema.StackDevfileType); err == nil {
+		t.Errorf("Expected an error for a canceled context")
+	}
+}
@@ -0,0 +1,253 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// Key types of the registry index signature
+	Ed25519KeyType = "ed25519"
+	ECDSAKeyType   = "ecdsa-p256-sha256"
+
+	signatureEndpoint = "signature"
+)
+
+// verifyRegistryIndex verifies the signed entries served by the registry with the public key set in the registry
+// options, then checks that every stack version and sample of the registry index is covered by a signed entry
+func verifyRegistryIndex(registryURL string, registryIndex []indexSchema.Schema, options RegistryOptions) error {
+	publicKey, err := loadPublicKey(options.PublicKeyPath)
+	if err != nil {
+		return err
+	}
+	entries, err := getSignedEntries(registryURL, publicKey, options)
+	if err != nil {
+		return err
+	}
+	return checkSignedIndex(registryIndex, entries)
+}
+
+// loadPublicKey reads a PEM encoded ed25519 or ECDSA public key, such as the public key generated by cosign
+func loadPublicKey(publicKeyPath string) (any, error) {
+	/* #nosec G304 -- publicKeyPath is provided by the library user */
+	bytes, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s: %v", publicKeyPath, err)
+	}
+	block, _ := pem.Decode(bytes)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("%s is not a PEM encoded public key", publicKeyPath)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %v", publicKeyPath, err)
+	}
+	switch publicKey.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		return publicKey, nil
+	default:
+		return nil, fmt.Errorf("%s is not an ed25519 or ECDSA public key", publicKeyPath)
+	}
+}
+
+// getSignedEntries fetches the registry index signature and returns its signed entries once each of them is verified
+// with the public key
+func getSignedEntries(registryURL string, publicKey any, options RegistryOptions) ([]indexSchema.SignedEntry, error) {
+	urlObj, err := url.Parse(registryURL)
+	if err != nil {
+		return nil, err
+	}
+	endpointURL, err := url.Parse(signatureEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(options.context(), http.MethodGet, urlObj.ResolveReference(endpointURL).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	setHeaders(&req.Header, options)
+
+	resp, err := getHTTPClient(options).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the index signature of registry %s: status code %d", registryURL, resp.StatusCode)
+	}
+	bytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var signature indexSchema.IndexSignature
+	err = json.Unmarshal(bytes, &signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the index signature of registry %s: %v", registryURL, err)
+	}
+	var entries []indexSchema.SignedEntry
+	for _, entrySignature := range signature.Entries {
+		entry, err := verifyEntrySignature(signature.KeyType, entrySignature, publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify the index signature of registry %s: %v", registryURL, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// verifyEntrySignature verifies the signature of a signed entry with the public key and returns the signed entry
+func verifyEntrySignature(keyType string, entrySignature indexSchema.EntrySignature, publicKey any) (indexSchema.SignedEntry, error) {
+	var entry indexSchema.SignedEntry
+
+	payloadBytes, err := base64.StdEncoding.DecodeString(entrySignature.Payload)
+	if err != nil {
+		return entry, fmt.Errorf("failed to decode a signed entry: %v", err)
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(entrySignature.Signature)
+	if err != nil {
+		return entry, fmt.Errorf("failed to decode the signature of a signed entry: %v", err)
+	}
+
+	var verified bool
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		verified = keyType == Ed25519KeyType && ed25519.Verify(key, payloadBytes, signatureBytes)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payloadBytes)
+		verified = keyType == ECDSAKeyType && ecdsa.VerifyASN1(key, digest[:], signatureBytes)
+	}
+	if !verified {
+		return entry, fmt.Errorf("a signed entry does not match the public key")
+	}
+
+	err = json.Unmarshal(payloadBytes, &entry)
+	if err != nil {
+		return entry, fmt.Errorf("failed to unmarshal a signed entry: %v", err)
+	}
+	return entry, nil
+}
+
+// checkSignedIndex checks that the digest of every stack version and sample of the registry index matches the digest
+// of its signed entry
+func checkSignedIndex(registryIndex []indexSchema.Schema, signedEntries []indexSchema.SignedEntry) error {
+	digests := make(map[string][]string)
+	entries := make(map[string]indexSchema.SignedEntry)
+	for _, entry := range signedEntries {
+		name := fmt.Sprintf("%s/%s", entry.Type, entry.Name)
+		digests[name] = append(digests[name], entry.Digest)
+		entries[fmt.Sprintf("%s:%s", name, entry.Version)] = entry
+	}
+
+	for _, indexComponent := range registryIndex {
+		name := fmt.Sprintf("%s/%s", indexComponent.Type, indexComponent.Name)
+		if len(indexComponent.Versions) == 0 && indexComponent.Version == "" {
+			// samples of the old index schema only contain the git information of the default version
+			digest, err := indexSchema.EntryDigest(indexComponent, nil)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(digests[name], digest) {
+				return fmt.Errorf("%s %s does not match the index signature", indexComponent.Type, indexComponent.Name)
+			}
+			continue
+		}
+
+		// the old index schema only contains the default version at the top level
+		versions := indexComponent.Versions
+		if len(versions) == 0 {
+			versions = []indexSchema.Version{{
+				Version:         indexComponent.Version,
+				Links:           indexComponent.Links,
+				Resources:       indexComponent.Resources,
+				StarterProjects: indexComponent.StarterProjects,
+			}}
+		}
+		for _, version := range versions {
+			entry, found := entries[fmt.Sprintf("%s:%s", name, version.Version)]
+			if !found {
+				return fmt.Errorf("%s %s version %s is not covered by the index signature", indexComponent.Type, indexComponent.Name, version.Version)
+			}
+			digest, err := indexSchema.EntryDigest(indexComponent, &version)
+			if err != nil {
+				return err
+			}
+			if digest != entry.Digest {
+				return fmt.Errorf("%s %s version %s does not match the index signature", indexComponent.Type, indexComponent.Name, version.Version)
+			}
+		}
+	}
+	return nil
+}
+
+// verifyPulledResources checks the digest of each resource pulled to destDir for a stack version against the resource
+// digests signed in the index signature
+func verifyPulledResources(registryURL string, stack string, version string, destDir string, layers []ocispec.Descriptor, options RegistryOptions) error {
+	publicKey, err := loadPublicKey(options.PublicKeyPath)
+	if err != nil {
+		return err
+	}
+	entries, err := getSignedEntries(registryURL, publicKey, options)
+	if err != nil {
+		return err
+	}
+	var resources map[string]string
+	found := false
+	for _, entry := range entries {
+		if entry.Type == indexSchema.StackDevfileType && entry.Name == stack && entry.Version == version {
+			resources = entry.Resources
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("stack %s version %s is not covered by the index signature", stack, version)
+	}
+
+	for _, layer := range layers {
+		resource := layer.Annotations[ocispec.AnnotationTitle]
+		signedDigest, found := resources[resource]
+		if !found {
+			return fmt.Errorf("resource %s of stack %s version %s is not covered by the index signature", resource, stack, version)
+		}
+		/* #nosec G304 -- resource is checked against the signed resources of the stack */
+		bytes, err := os.ReadFile(filepath.Join(destDir, resource))
+		if err != nil {
+			return fmt.Errorf("failed to read resource %s of stack %s version %s: %v", resource, stack, version, err)
+		}
+		if indexSchema.ContentDigest(bytes) != signedDigest {
+			return fmt.Errorf("resource %s of stack %s version %s does not match the index signature", resource, stack, version)
+		}
+	}
+	return nil
+}
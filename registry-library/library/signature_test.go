@@ -0,0 +1,324 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var signedIndex = []indexSchema.Schema{
+	{
+		Name:        "go",
+		Type:        indexSchema.StackDevfileType,
+		Description: "Go stack",
+		Versions: []indexSchema.Version{
+			{Version: "1.0.2", Links: map[string]string{"self": "devfile-catalog/go:1.0.2"}, Resources: []string{"logo.png", "devfile.yaml"}},
+			{Version: "2.0.0", Default: true, Links: map[string]string{"self": "devfile-catalog/go:2.0.0"}, Resources: []string{"devfile.yaml"}},
+		},
+	},
+	{
+		Name: "nodejs-basic",
+		Type: indexSchema.SampleDevfileType,
+		Git:  &indexSchema.Git{Remotes: map[string]string{"origin": "https://github.com/devfile-samples/nodejs-basic.git"}},
+	},
+}
+
+var signedResources = map[string]map[string]string{
+	"go:1.0.2": {"devfile.yaml": "sha256:1b", "logo.png": "sha256:2c"},
+	"go:2.0.0": {"devfile.yaml": indexSchema.ContentDigest([]byte("schemaVersion: 2.2.0"))},
+}
+
+// signedEntries returns the signed entries of the signed index, as built by the index generator
+func signedEntries(t *testing.T) []indexSchema.SignedEntry {
+	var entries []indexSchema.SignedEntry
+	for _, indexComponent := range signedIndex {
+		if len(indexComponent.Versions) == 0 {
+			digest, err := indexSchema.EntryDigest(indexComponent, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries = append(entries, indexSchema.SignedEntry{Name: indexComponent.Name, Type: indexComponent.Type, Digest: digest})
+			continue
+		}
+		for _, version := range indexComponent.Versions {
+			digest, err := indexSchema.EntryDigest(indexComponent, &version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries = append(entries, indexSchema.SignedEntry{
+				Name:      indexComponent.Name,
+				Type:      indexComponent.Type,
+				Version:   version.Version,
+				Digest:    digest,
+				Resources: signedResources[fmt.Sprintf("%s:%s", indexComponent.Name, version.Version)],
+			})
+		}
+	}
+	return entries
+}
+
+// signedRegistryServer serves the signature of the signed entries, each entry signed with the given key
+func signedRegistryServer(t *testing.T, key crypto.Signer, keyType string) *httptest.Server {
+	signature := indexSchema.IndexSignature{KeyType: keyType}
+	for _, entry := range signedEntries(t) {
+		payloadBytes, err := json.Marshal(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var signatureBytes []byte
+		if keyType == Ed25519KeyType {
+			signatureBytes, err = key.Sign(rand.Reader, payloadBytes, crypto.Hash(0))
+		} else {
+			digest := sha256.Sum256(payloadBytes)
+			signatureBytes, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature.Entries = append(signature.Entries, indexSchema.EntrySignature{
+			Payload:   base64.StdEncoding.EncodeToString(payloadBytes),
+			Signature: base64.StdEncoding.EncodeToString(signatureBytes),
+		})
+	}
+	bytes, err := json.Marshal(signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+signatureEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(bytes)
+	})
+	return httptest.NewServer(mux)
+}
+
+// writePublicKey writes the public key of the signer as a PEM file
+func writePublicKey(t *testing.T, key crypto.Signer) string {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyPath := filepath.Join(t.TempDir(), "key.pub")
+	err = os.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return publicKeyPath
+}
+
+func TestVerifyRegistryIndex(t *testing.T) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		signingKey crypto.Signer
+		keyType    string
+		verifyKey  crypto.Signer
+		index      []indexSchema.Schema
+		wantErr    bool
+	}{
+		{
+			name:       "Case 1: index signed with ed25519 key",
+			signingKey: ed25519Key,
+			keyType:    Ed25519KeyType,
+			verifyKey:  ed25519Key,
+			index:      signedIndex,
+		},
+		{
+			name:       "Case 2: index signed with ECDSA key",
+			signingKey: ecdsaKey,
+			keyType:    ECDSAKeyType,
+			verifyKey:  ecdsaKey,
+			index:      signedIndex,
+		},
+		{
+			name:       "Case 3: old index schema with the default version",
+			signingKey: ed25519Key,
+			keyType:    Ed25519KeyType,
+			verifyKey:  ed25519Key,
+			index: []indexSchema.Schema{
+				{Name: "go", Type: indexSchema.StackDevfileType, Description: "Go stack", Version: "2.0.0",
+					Links: map[string]string{"self": "devfile-catalog/go:2.0.0"}, Resources: []string{"devfile.yaml"}},
+				{Name: "nodejs-basic", Type: indexSchema.SampleDevfileType, Git: signedIndex[1].Git},
+			},
+		},
+		{
+			name:       "Case 4: signature does not match the public key",
+			signingKey: ed25519Key,
+			keyType:    Ed25519KeyType,
+			verifyKey:  otherKey,
+			index:      signedIndex,
+			wantErr:    true,
+		},
+		{
+			name:       "Case 5: stack version not covered by the signature",
+			signingKey: ed25519Key,
+			keyType:    Ed25519KeyType,
+			verifyKey:  ed25519Key,
+			index: []indexSchema.Schema{
+				{Name: "go", Type: indexSchema.StackDevfileType, Versions: []indexSchema.Version{{Version: "3.0.0"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "Case 6: stack resources do not match the signed resources",
+			signingKey: ed25519Key,
+			keyType:    Ed25519KeyType,
+			verifyKey:  ed25519Key,
+			index: []indexSchema.Schema{
+				{Name: "go", Type: indexSchema.StackDevfileType, Description: "Go stack", Versions: []indexSchema.Version{
+					{Version: "2.0.0", Links: map[string]string{"self": "devfile-catalog/go:2.0.0"}, Resources: []string{"devfile.yaml", "archive.tar"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "Case 7: sample not covered by the signature",
+			signingKey: ed25519Key,
+			keyType:    Ed25519KeyType,
+			verifyKey:  ed25519Key,
+			index:      []indexSchema.Schema{{Name: "python-basic", Type: indexSchema.SampleDevfileType}},
+			wantErr:    true,
+		},
+		{
+			name:       "Case 8: stack version link does not match the signed link",
+			signingKey: ed25519Key,
+			keyType:    Ed25519KeyType,
+			verifyKey:  ed25519Key,
+			index: []indexSchema.Schema{
+				{Name: "go", Type: indexSchema.StackDevfileType, Description: "Go stack", Versions: []indexSchema.Version{
+					{Version: "2.0.0", Links: map[string]string{"self": "attacker/go:2.0.0"}, Resources: []string{"devfile.yaml"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "Case 9: sample git remote does not match the signed remote",
+			signingKey: ed25519Key,
+			keyType:    Ed25519KeyType,
+			verifyKey:  ed25519Key,
+			index: []indexSchema.Schema{
+				{Name: "nodejs-basic", Type: indexSchema.SampleDevfileType, Git: &indexSchema.Git{Remotes: map[string]string{"origin": "https://example.com/nodejs-basic.git"}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := signedRegistryServer(t, test.signingKey, test.keyType)
+			defer server.Close()
+
+			options := RegistryOptions{PublicKeyPath: writePublicKey(t, test.verifyKey)}
+			err := verifyRegistryIndex(server.URL, test.index, options)
+			if !test.wantErr && err != nil {
+				t.Errorf("Unexpected err: %+v", err)
+			} else if test.wantErr && err == nil {
+				t.Error("Expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestVerifyPulledResources(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := signedRegistryServer(t, key, Ed25519KeyType)
+	defer server.Close()
+	options := RegistryOptions{PublicKeyPath: writePublicKey(t, key)}
+
+	devfileLayer := ocispec.Descriptor{Annotations: map[string]string{ocispec.AnnotationTitle: "devfile.yaml"}}
+	tests := []struct {
+		name    string
+		version string
+		content string
+		layers  []ocispec.Descriptor
+		wantErr bool
+	}{
+		{
+			name:    "Case 1: pulled resources match the signed digests",
+			version: "2.0.0",
+			content: "schemaVersion: 2.2.0",
+			layers:  []ocispec.Descriptor{devfileLayer},
+		},
+		{
+			name:    "Case 2: pulled resource does not match the signed digest",
+			version: "2.0.0",
+			content: "schemaVersion: 2.1.0",
+			layers:  []ocispec.Descriptor{devfileLayer},
+			wantErr: true,
+		},
+		{
+			name:    "Case 3: pulled resource not covered by the signature",
+			version: "2.0.0",
+			content: "schemaVersion: 2.2.0",
+			layers:  []ocispec.Descriptor{devfileLayer, {Annotations: map[string]string{ocispec.AnnotationTitle: "archive.tar"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Case 4: stack version not covered by the signature",
+			version: "3.0.0",
+			content: "schemaVersion: 2.2.0",
+			layers:  []ocispec.Descriptor{devfileLayer},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			destDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(destDir, "devfile.yaml"), []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			err := verifyPulledResources(server.URL, "go", test.version, destDir, test.layers, options)
+			if !test.wantErr && err != nil {
+				t.Errorf("Unexpected err: %+v", err)
+			} else if test.wantErr && err == nil {
+				t.Error("Expected error but got nil")
+			}
+		})
+	}
+}
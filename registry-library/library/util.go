@@ -18,6 +18,7 @@ package library
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
@@ -31,6 +32,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // SplitVersionFromStack takes a stack/version tag and splits the stack name from the version
@@ -171,7 +173,15 @@ func setHeaders(headers *http.Header, options RegistryOptions) {
 	}
 }
 
-// getHTTPClient returns a new http client object
+// context returns the context of the requests to the registry
+func (options RegistryOptions) context() context.Context {
+	if options.Context == nil {
+		return context.Background()
+	}
+	return options.Context
+}
+
+// getHTTPClient returns a new http client object, the requests propagate the trace context of their context
 func getHTTPClient(options RegistryOptions) *http.Client {
 
 	overriddenTimeout := httpRequestResponseTimeout
@@ -183,12 +193,12 @@ func getHTTPClient(options RegistryOptions) *http.Client {
 	}
 
 	return &http.Client{
-		Transport: &http.Transport{
+		Transport: otelhttp.NewTransport(&http.Transport{
 			Proxy:                 http.ProxyFromEnvironment,
 			ResponseHeaderTimeout: overriddenTimeout,
 			/*#nosec G402 -- documented user option for dev/test, not for prod use */
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: options.SkipTLSVerify},
-		},
+		}),
 		Timeout: overriddenTimeout,
 	}
 }